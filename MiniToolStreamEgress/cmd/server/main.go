@@ -2,18 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
-	vault "github.com/hashicorp/vault/api"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
@@ -23,7 +26,14 @@ import (
 	minioRepo "github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/repository/minio"
 	tarantoolRepo "github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/repository/tarantool"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/jwtkeys"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/mtls"
+	"github.com/moroshma/MiniToolStream/pkg/oidc"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStream/pkg/revocation"
 	"github.com/moroshma/MiniToolStreamConnector/auth"
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
 )
@@ -113,6 +123,9 @@ func main() {
 		UseSSL:          cfg.MinIO.UseSSL,
 		BucketName:      cfg.MinIO.BucketName,
 	}
+	if cfg.Tenancy.Enabled {
+		minioCfg.TenantBuckets = cfg.Tenancy.SeparateBuckets()
+	}
 
 	storageRepo, err := minioRepo.NewRepository(minioCfg, appLogger)
 	if err != nil {
@@ -128,13 +141,70 @@ func main() {
 		cfg.Server.PollInterval,
 	)
 
-	// Initialize gRPC handler
+	if cfg.Encryption.Enabled {
+		messageUC.SetEnvelope(encryption.NewEnvelope(vaultClient, "", nil))
+		appLogger.Info("Payload decryption enabled", logger.String("transit_mount", cfg.Vault.TransitMount))
+	}
+
+	// Initialize gRPC handlers
 	egressHandler := grpcHandler.NewEgressHandler(messageUC, appLogger)
+	subjectHandler := grpcHandler.NewSubjectHandler(usecase.NewSubjectUseCase(messageRepo, appLogger), appLogger)
+	schemaHandler := grpcHandler.NewSchemaHandler(usecase.NewSchemaUseCase(messageRepo, appLogger), appLogger)
+	consumerHandler := grpcHandler.NewConsumerHandler(usecase.NewConsumerUseCase(messageRepo, appLogger), appLogger)
+
+	var tenants *grpcHandler.Tenants
+	if cfg.Tenancy.Enabled {
+		imports := make([]grpcHandler.TenantImport, 0, len(cfg.Tenancy.Imports))
+		for _, imp := range cfg.Tenancy.Imports {
+			imports = append(imports, grpcHandler.TenantImport{
+				Tenant:  imp.Tenant,
+				From:    imp.From,
+				Subject: imp.Subject,
+				Prefix:  imp.ImportPrefix(),
+			})
+		}
+		tenants = grpcHandler.NewTenants(imports)
+		egressHandler.SetTenants(tenants)
+		subjectHandler.SetTenants(tenants)
+		schemaHandler.SetTenants(tenants)
+		consumerHandler.SetTenants(tenants)
+		appLogger.Info("Tenant namespaces enabled",
+			logger.Int("tenants", len(cfg.Tenancy.Tenants)),
+			logger.Int("imports", len(imports)),
+			logger.Int("separate_buckets", len(minioCfg.TenantBuckets)),
+		)
+	}
 
 	// Initialize JWT authentication if enabled
-	var grpcServer *grpc.Server
 	// Set max message size to 1GB (for large file transfers)
 	maxMsgSize := 1024 * 1024 * 1024 // 1GB
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxMsgSize),
+		grpc.MaxSendMsgSize(maxMsgSize),
+	}
+
+	// Client certificates are mapped to claims only when authentication is enabled
+	var identities mtls.Identities
+	if cfg.Server.TLS.Enabled {
+		reloader, err := mtls.NewReloader(cfg.Server.TLS.Files())
+		if err != nil {
+			appLogger.Fatal("Failed to load TLS certificates", logger.Error(err))
+		}
+		logCertificate(reloader, appLogger)
+		go watchCertificates(ctx, reloader, cfg.Server.TLS.ReloadInterval, appLogger)
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+		identities = cfg.Server.TLS.Identities()
+		appLogger.Info("✓ TLS enabled",
+			logger.Bool("client_ca", cfg.Server.TLS.ClientCAFile != ""),
+			logger.Bool("require_client_cert", cfg.Server.TLS.RequireClientCert),
+			logger.Int("client_identities", len(identities)),
+		)
+	}
+
+	// Interceptors run in order: authentication, authorization, then quotas
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+	var streamInterceptors []grpc.StreamServerInterceptor
 
 	if cfg.Auth.Enabled {
 		appLogger.Info("JWT authentication enabled",
@@ -142,32 +212,89 @@ func main() {
 			logger.String("vault_path", cfg.Auth.JWTVaultPath),
 		)
 
-		jwtManager, err := initJWTManager(ctx, vaultClient.Client(), &cfg.Auth, appLogger)
-		if err != nil {
-			appLogger.Fatal("Failed to initialize JWT manager", logger.Error(err))
+		// With an external provider or client certificates the Vault keys are optional
+		var validator tokenValidator
+		if needJWTKeys(vaultClient, &cfg.Auth, identities) {
+			jwtKeys, err := initJWTKeys(ctx, vaultClient, &cfg.Auth, appLogger)
+			if err != nil {
+				appLogger.Fatal("Failed to initialize JWT keys", logger.Error(err))
+			}
+			go watchJWTKeys(ctx, jwtKeys, cfg.Auth.KeyReloadInterval, appLogger)
+			validator = jwtKeys
 		}
 
-		// Create gRPC server with JWT interceptors (stream interceptor for Subscribe/Fetch)
-		grpcServer = grpc.NewServer(
-			grpc.StreamInterceptor(conditionalStreamAuthInterceptor(jwtManager, cfg.Auth.RequireAuth)),
-			grpc.MaxRecvMsgSize(maxMsgSize),
-			grpc.MaxSendMsgSize(maxMsgSize),
-		)
+		if cfg.Auth.OIDC.Enabled {
+			provider, err := initOIDC(ctx, &cfg.Auth.OIDC, appLogger)
+			if err != nil {
+				appLogger.Fatal("Failed to initialize OIDC provider", logger.Error(err))
+			}
+			go watchJWKS(ctx, provider, cfg.Auth.OIDC.RefreshInterval, appLogger)
+			validator = &issuerRouter{provider: provider, fallback: validator}
+		}
+
+		if validator == nil {
+			appLogger.Warn("No JWT keys or OIDC provider configured, only client certificates are accepted")
+		} else if cfg.Auth.CheckRevocation {
+			validator = &revocationFilter{
+				tokenValidator: validator,
+				checker:        revocation.NewChecker(messageRepo, cfg.Auth.RevocationCacheTTL),
+				logger:         appLogger,
+			}
+			appLogger.Info("Token revocation check enabled", logger.String("cache_ttl", cfg.Auth.RevocationCacheTTL.String()))
+		}
+
+		// JWT interceptors: stream for Subscribe/Fetch, unary for GetLastSequence/AckMessage
+		unaryInterceptors = append(unaryInterceptors, conditionalUnaryAuthInterceptor(validator, identities, cfg.Auth.RequireAuth))
+		streamInterceptors = append(streamInterceptors, conditionalStreamAuthInterceptor(validator, identities, cfg.Auth.RequireAuth))
+
+		authorizer := authz.NewAuthorizer(grpcHandler.EgressPolicy, appLogger)
+		if cfg.Auth.ConsumerOwnership {
+			authorizer.SetConsumerOwners(grpcHandler.NewConsumerOwners(usecase.NewConsumerUseCase(messageRepo, appLogger), tenants))
+			appLogger.Info("Durable consumer ownership enabled")
+		}
+		unaryInterceptors = append(unaryInterceptors, authorizer.UnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, authorizer.StreamInterceptor())
 		appLogger.Info("✓ JWT authentication configured")
 	} else {
 		appLogger.Info("JWT authentication disabled")
-		grpcServer = grpc.NewServer(
-			grpc.MaxRecvMsgSize(maxMsgSize),
-			grpc.MaxSendMsgSize(maxMsgSize),
+	}
+
+	// Quotas run after authentication, which provides the client ID
+	if policy := cfg.Quotas.Policy(); policy != nil {
+		if cfg.Tenancy.Enabled {
+			policy.Subjects = append(policy.Subjects, cfg.Tenancy.SubjectLimits()...)
+		}
+		limiter := quota.NewLimiter(policy, messageRepo, "fetch")
+		streamInterceptors = append(streamInterceptors, grpcHandler.QuotaStreamInterceptor(limiter, tenants, appLogger))
+		unaryInterceptors = append(unaryInterceptors, grpcHandler.QuotaUnaryInterceptor(limiter, tenants, appLogger))
+		appLogger.Info("Fetch quotas enabled",
+			logger.Int("client_overrides", len(policy.Clients)),
+			logger.Int("subject_limits", len(policy.Subjects)),
 		)
 	}
+
+	if len(unaryInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(unaryInterceptors...))
+	}
+	if len(streamInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(streamInterceptors...))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 	appLogger.Info("gRPC max message size configured", logger.Int("max_mb", maxMsgSize/(1024*1024)))
 
 	pb.RegisterEgressServiceServer(grpcServer, egressHandler)
+	pb.RegisterSubjectServiceServer(grpcServer, subjectHandler)
+	pb.RegisterSchemaServiceServer(grpcServer, schemaHandler)
+	pb.RegisterConsumerServiceServer(grpcServer, consumerHandler)
 
 	// Register reflection for grpcurl
 	reflection.Register(grpcServer)
 
+	// The Authorizer denies methods without a rule, a service added without one is a bug
+	if missing := grpcHandler.EgressPolicy.Missing(grpcServer.GetServiceInfo()); len(missing) > 0 {
+		appLogger.Fatal("gRPC methods have no authorization rule", logger.Any("methods", missing))
+	}
+
 	// Start listening
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
 	if err != nil {
@@ -193,49 +320,282 @@ func main() {
 	}
 }
 
-// initJWTManager initializes JWT manager from Vault
-func initJWTManager(ctx context.Context, vaultClient *vault.Client, cfg *config.AuthConfig, log *logger.Logger) (*auth.JWTManager, error) {
+// tokenValidator validates bearer tokens
+type tokenValidator interface {
+	ValidateToken(token string) (*auth.Claims, error)
+}
+
+// revocationFilter rejects revoked tokens after validating them
+type revocationFilter struct {
+	tokenValidator
+	checker *revocation.Checker
+	logger  *logger.Logger
+}
+
+// ValidateToken validates a token and checks it was not revoked
+func (f *revocationFilter) ValidateToken(token string) (*auth.Claims, error) {
+	claims, err := f.tokenValidator.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checker.Check(claims); err != nil {
+		if errors.Is(err, revocation.ErrRevoked) {
+			f.logger.Warn("Revoked token rejected",
+				logger.String("client_id", claims.ClientID),
+				logger.String("jti", claims.ID),
+			)
+			return nil, err
+		}
+		f.logger.Error("Failed to check token revocation", logger.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to check token revocation")
+	}
+	return claims, nil
+}
+
+// needJWTKeys reports whether the JWT key set must be loaded from Vault
+// Without Vault an OIDC provider or client certificates mapped to identities authenticate clients instead
+func needJWTKeys(vaultClient *config.VaultClient, cfg *config.AuthConfig, identities mtls.Identities) bool {
+	return vaultClient != nil || (!cfg.OIDC.Enabled && len(identities) == 0)
+}
+
+// initJWTKeys loads the JWT key set from Vault
+func initJWTKeys(ctx context.Context, vaultClient *config.VaultClient, cfg *config.AuthConfig, log *logger.Logger) (*jwtkeys.Verifier, error) {
 	if vaultClient == nil {
 		return nil, fmt.Errorf("vault client is required for JWT authentication")
 	}
 
 	log.Info("Loading JWT keys from Vault", logger.String("path", cfg.JWTVaultPath))
-	jwtManager, err := auth.NewJWTManagerFromVault(ctx, vaultClient, cfg.JWTVaultPath, cfg.JWTIssuer)
+	verifier := jwtkeys.NewVerifier(vaultClient, cfg.JWTVaultPath, cfg.JWTIssuer)
+	if err := verifier.Reload(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	logJWTKeys(verifier.Keys(), log)
+
+	return verifier, nil
+}
+
+// watchJWTKeys reloads the JWT key set every interval and on SIGHUP
+// A failed reload keeps the previous keys
+func watchJWTKeys(ctx context.Context, verifier *jwtkeys.Verifier, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, reloading JWT keys")
+		case <-tick:
+		}
+
+		if err := verifier.Reload(ctx); err != nil {
+			log.Error("Failed to reload JWT keys", logger.Error(err))
+			continue
+		}
+		logJWTKeys(verifier.Keys(), log)
+	}
+}
+
+// logJWTKeys logs the keys tokens are accepted from
+func logJWTKeys(keys *jwtkeys.KeySet, log *logger.Logger) {
+	accepted := 0
+	for _, key := range keys.Keys {
+		if !key.Retired() {
+			accepted++
+		}
+	}
+	log.Debug("JWT keys loaded",
+		logger.String("active_kid", keys.ActiveID),
+		logger.Int("accepted", accepted),
+		logger.Int("retired", len(keys.Keys)-accepted),
+	)
+}
+
+// issuerRouter validates tokens of the external provider with its JWKS and other tokens with the Vault keys
+type issuerRouter struct {
+	provider *oidc.Verifier
+	fallback tokenValidator // nil when the Vault keys are not loaded
+}
+
+// ValidateToken validates a token with the verifier of its issuer
+func (r *issuerRouter) ValidateToken(token string) (*auth.Claims, error) {
+	if r.fallback == nil || r.provider.Issued(token) {
+		return r.provider.ValidateToken(token)
+	}
+	return r.fallback.ValidateToken(token)
+}
+
+// initOIDC loads the rules and the JWKS of the external provider
+func initOIDC(ctx context.Context, cfg *config.OIDCConfig, log *logger.Logger) (*oidc.Verifier, error) {
+	rules, err := oidc.LoadRules(cfg.RulesFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create JWT manager: %w", err)
+		return nil, err
 	}
 
-	return jwtManager, nil
+	var source oidc.Source = oidc.FileSource(cfg.JWKSFile)
+	if cfg.JWKSURL != "" {
+		source = &oidc.URLSource{URL: cfg.JWKSURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	}
+
+	log.Info("OIDC provider enabled",
+		logger.String("issuer", cfg.Issuer),
+		logger.String("audience", cfg.Audience),
+		logger.Int("rules", len(rules.Rules)),
+		logger.String("refresh_interval", cfg.RefreshInterval.String()),
+	)
+	verifier := oidc.NewVerifier(source, cfg.Issuer, cfg.Audience, rules)
+	if err := verifier.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+	log.Debug("JWKS loaded", logger.String("kids", strings.Join(verifier.KeyIDs(), ",")))
+
+	return verifier, nil
+}
+
+// watchJWKS refreshes the provider's JWKS every interval and on SIGHUP
+// Tokens naming an unknown key also trigger a refresh, at most every 30s
+func watchJWKS(ctx context.Context, verifier *oidc.Verifier, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, refreshing JWKS")
+		case <-tick:
+		}
+
+		if err := verifier.Refresh(ctx); err != nil {
+			log.Error("Failed to refresh JWKS", logger.Error(err))
+			continue
+		}
+		log.Debug("JWKS loaded", logger.String("kids", strings.Join(verifier.KeyIDs(), ",")))
+	}
 }
 
-// conditionalStreamAuthInterceptor creates a stream interceptor that conditionally requires authentication
-func conditionalStreamAuthInterceptor(jwtManager *auth.JWTManager, requireAuth bool) grpc.StreamServerInterceptor {
-	if requireAuth {
-		// Require authentication for all requests
-		return auth.StreamServerInterceptor(jwtManager)
+// watchCertificates reloads the TLS certificates every interval and on SIGHUP
+// A failed reload keeps the previous certificates
+func watchCertificates(ctx context.Context, reloader *mtls.Reloader, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
 	}
 
-	// Optional authentication - validate if present, allow if not
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, reloading TLS certificates")
+		case <-tick:
+		}
+
+		if err := reloader.Reload(); err != nil {
+			log.Error("Failed to reload TLS certificates", logger.Error(err))
+			continue
+		}
+		logCertificate(reloader, log)
+	}
+}
+
+// logCertificate logs the server certificate in use
+func logCertificate(reloader *mtls.Reloader, log *logger.Logger) {
+	cert, err := reloader.Certificate()
+	if err != nil {
+		log.Error("Failed to parse server certificate", logger.Error(err))
+		return
+	}
+	log.Debug("TLS certificate loaded",
+		logger.String("subject", cert.Subject.CommonName),
+		logger.String("expires_at", cert.NotAfter.Format(time.RFC3339)),
+	)
+}
+
+// conditionalUnaryAuthInterceptor creates a unary interceptor that validates bearer tokens
+// Requests without a token may authenticate with a client certificate mapped by identities,
+// otherwise they are rejected when requireAuth is set and pass unauthenticated
+// A nil validator rejects every bearer token
+func conditionalUnaryAuthInterceptor(validator tokenValidator, identities mtls.Identities, requireAuth bool) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		claims, err := tryAuthenticate(ctx, validator)
+		if err != nil {
+			// Token was provided but invalid - reject the request
+			return nil, err
+		}
+		if claims == nil {
+			claims, _ = identities.PeerClaims(ctx)
+		}
+		if claims == nil {
+			if requireAuth {
+				return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+			}
+			return handler(ctx, req)
+		}
+		ctx = context.WithValue(ctx, auth.ClaimsContextKey{}, claims)
+		return handler(ctx, req)
+	}
+}
+
+// conditionalStreamAuthInterceptor creates a stream interceptor that validates bearer tokens
+// Requests without a token may authenticate with a client certificate mapped by identities,
+// otherwise they are rejected when requireAuth is set and pass unauthenticated
+// A nil validator rejects every bearer token
+func conditionalStreamAuthInterceptor(validator tokenValidator, identities mtls.Identities, requireAuth bool) grpc.StreamServerInterceptor {
 	return func(
 		srv interface{},
 		stream grpc.ServerStream,
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		// Try to get token from metadata
-		claims, err := tryAuthenticate(stream.Context(), jwtManager)
+		claims, err := tryAuthenticate(stream.Context(), validator)
 		if err != nil {
 			// Token was provided but invalid - reject the request
 			return err
 		}
-		if claims != nil {
-			wrappedStream := &authenticatedStream{
-				ServerStream: stream,
-				ctx:          context.WithValue(stream.Context(), auth.ClaimsContextKey{}, claims),
+		if claims == nil {
+			claims, _ = identities.PeerClaims(stream.Context())
+		}
+		if claims == nil {
+			if requireAuth {
+				return status.Error(codes.Unauthenticated, "missing bearer token")
 			}
-			return handler(srv, wrappedStream)
+			return handler(srv, stream)
+		}
+		wrappedStream := &authenticatedStream{
+			ServerStream: stream,
+			ctx:          context.WithValue(stream.Context(), auth.ClaimsContextKey{}, claims),
 		}
-		return handler(srv, stream)
+		return handler(srv, wrappedStream)
 	}
 }
 
@@ -250,7 +610,7 @@ func (s *authenticatedStream) Context() context.Context {
 }
 
 // tryAuthenticate attempts to authenticate but doesn't fail if no token present
-func tryAuthenticate(ctx context.Context, jwtManager *auth.JWTManager) (*auth.Claims, error) {
+func tryAuthenticate(ctx context.Context, validator tokenValidator) (*auth.Claims, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, nil
@@ -266,9 +626,18 @@ func tryAuthenticate(ctx context.Context, jwtManager *auth.JWTManager) (*auth.Cl
 		return nil, nil
 	}
 
+	// Without a validator only client certificates authenticate
+	if validator == nil {
+		return nil, status.Error(codes.Unauthenticated, "bearer tokens are not accepted, authenticate with a client certificate")
+	}
+
 	token = strings.TrimPrefix(token, "Bearer ")
-	claims, err := jwtManager.ValidateToken(token)
+	claims, err := validator.ValidateToken(token)
 	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			// Already a status, e.g. the revocation store is unavailable
+			return nil, err
+		}
 		// Token was provided but invalid - return the error
 		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestConfig_Validate_Success(t *testing.T) {
@@ -330,3 +331,308 @@ func TestVaultConfig_GetVaultToken_TokenPrecedence(t *testing.T) {
 		t.Errorf("expected direct token to take precedence, got '%s'", token)
 	}
 }
+
+func TestConfig_Validate_EncryptionWithoutVault(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Encryption: EncryptionConfig{
+			Enabled: true,
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error when encryption is enabled without vault")
+	}
+}
+
+func TestConfig_Validate_Quotas(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Quotas: QuotaConfig{
+			Enabled:  true,
+			Default:  QuotaLimitConfig{MessagesPerSecond: 100},
+			Clients:  []ClientQuotaConfig{{ClientID: "analytics", QuotaLimitConfig: QuotaLimitConfig{BytesPerSecond: 1 << 20}}},
+			Subjects: []SubjectQuotaConfig{{Subject: "logs.*", QuotaLimitConfig: QuotaLimitConfig{MessagesPerSecond: 10}}},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policy := cfg.Quotas.Policy()
+	if policy.Clients["analytics"].BytesPerSecond != 1<<20 || policy.Subjects[0].Pattern != "logs.*" {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	cfg.Quotas.Clients[0].ClientID = ""
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for client quotas without a client_id")
+	}
+}
+
+func TestConfig_Validate_Tenancy(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Tenancy: TenancyConfig{
+			Enabled: true,
+			Tenants: []TenantConfig{{Name: "acme", SeparateBucket: true}, {Name: "globex"}},
+			Imports: []TenantImportConfig{{Tenant: "globex", From: "acme", Subject: "orders.*"}},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Tenancy.SeparateBuckets(); len(got) != 1 || got[0] != "acme" {
+		t.Errorf("expected acme to have a bucket of its own, got %v", got)
+	}
+	if got := cfg.Tenancy.Imports[0].ImportPrefix(); got != "acme" {
+		t.Errorf("expected the import prefix to default to the exporting tenant, got %q", got)
+	}
+
+	cfg.Tenancy.Imports[0].From = "globex"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for a tenant importing from itself")
+	}
+	cfg.Tenancy.Imports[0].From = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an import from the default tenant without a prefix")
+	}
+	cfg.Tenancy.Imports = nil
+
+	cfg.Tenancy.Tenants[1].Limits = QuotaLimitConfig{MessagesPerSecond: 10}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for tenant limits without quotas")
+	}
+	cfg.Quotas.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits := cfg.Tenancy.SubjectLimits(); len(limits) != 1 || limits[0].Pattern != "$TENANT.globex.*" {
+		t.Errorf("unexpected tenant limits: %+v", limits)
+	}
+
+	cfg.Tenancy.Tenants[1].Name = "Globex"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an invalid tenant name")
+	}
+}
+
+func TestConfig_Validate_ConsumerOwnershipWithoutAuth(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			ConsumerOwnership: true,
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when consumer ownership is enabled without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConfig_Validate_NegativeKeyReloadInterval(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			Enabled:           true,
+			KeyReloadInterval: -time.Minute,
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for negative key reload interval")
+	}
+}
+
+func TestConfig_Validate_RevocationWithoutAuth(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			CheckRevocation: true,
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when revocation check is enabled without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Auth.RevocationCacheTTL = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for negative revocation cache ttl")
+	}
+}
+
+func TestConfig_Validate_TLS(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50052,
+			TLS: TLSConfig{
+				Enabled:  true,
+				CertFile: "/etc/tls/tls.crt",
+			},
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for tls without a key file")
+	}
+
+	cfg.Server.TLS.KeyFile = "/etc/tls/tls.key"
+	cfg.Server.TLS.RequireClientCert = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when requiring client certificates without a CA")
+	}
+
+	cfg.Server.TLS.ClientCAFile = "/etc/tls/ca.crt"
+	cfg.Server.TLS.ClientIdentities = []ClientIdentityConfig{
+		{SAN: "archiver.stream.svc", ClientID: "archiver", Permissions: []string{"subscribe"}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for client identities without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := cfg.Server.TLS.Identities(); ids[0].ClientID != "archiver" || ids[0].SAN != "archiver.stream.svc" {
+		t.Errorf("unexpected identities: %+v", ids)
+	}
+
+	cfg.Server.TLS.ClientIdentities[0].SAN = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an identity without a name")
+	}
+
+	cfg.Server.TLS.ClientIdentities[0].SAN = "archiver.stream.svc"
+	cfg.Server.TLS.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for client identities without tls")
+	}
+}
+
+func TestConfig_Validate_OIDC(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50052,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			JWTIssuer: "minitoolstream",
+			OIDC: OIDCConfig{
+				Enabled:   true,
+				Issuer:    "https://idp.example.com/realms/stream",
+				Audience:  "minitoolstream",
+				JWKSFile:  "/etc/minitoolstream/jwks.json",
+				RulesFile: "/etc/minitoolstream/oidc-rules.yaml",
+			},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for oidc without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Auth.OIDC.JWKSURL = "https://idp.example.com/realms/stream/protocol/openid-connect/certs"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for both jwks_url and jwks_file")
+	}
+
+	cfg.Auth.OIDC.JWKSFile = ""
+	cfg.Auth.OIDC.Issuer = "minitoolstream"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an issuer shared with the vault keys")
+	}
+
+	cfg.Auth.OIDC.Issuer = "https://idp.example.com/realms/stream"
+	cfg.Auth.OIDC.Audience = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for a missing audience")
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 
 	vault "github.com/hashicorp/vault/api"
@@ -63,6 +64,86 @@ func (vc *VaultClient) GetSecret(ctx context.Context, path string) (map[string]i
 	return secret.Data, nil
 }
 
+// GenerateDataKey asks the transit engine for a new data key wrapped by keyName
+// Returns the plaintext key and its wrapped form, which carries the key version
+func (vc *VaultClient) GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error) {
+	if vc == nil {
+		return nil, "", fmt.Errorf("vault client is not initialized")
+	}
+
+	path := fmt.Sprintf("%s/datakey/plaintext/%s", vc.config.TransitMount, keyName)
+	secret, err := vc.client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"bits": 256,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, "", fmt.Errorf("empty data key response for key %s", keyName)
+	}
+
+	wrapped, _ := secret.Data["ciphertext"].(string)
+	encoded, _ := secret.Data["plaintext"].(string)
+	if wrapped == "" || encoded == "" {
+		return nil, "", fmt.Errorf("incomplete data key response for key %s", keyName)
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode data key: %w", err)
+	}
+
+	return plaintext, wrapped, nil
+}
+
+// DecryptDataKey unwraps a data key produced by GenerateDataKey
+// Older key versions stay usable after rotation, so existing objects need no rewrite
+func (vc *VaultClient) DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error) {
+	if vc == nil {
+		return nil, fmt.Errorf("vault client is not initialized")
+	}
+
+	path := fmt.Sprintf("%s/decrypt/%s", vc.config.TransitMount, keyName)
+	secret, err := vc.client.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"ciphertext": wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("empty decrypt response for key %s", keyName)
+	}
+
+	encoded, _ := secret.Data["plaintext"].(string)
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(plaintext) == 0 {
+		return nil, fmt.Errorf("failed to decode data key for key %s", keyName)
+	}
+
+	return plaintext, nil
+}
+
+// ReadKeySet reads the JWT key set stored at a full KV v2 path, such as auth.jwt_vault_path
+func (vc *VaultClient) ReadKeySet(ctx context.Context, path string) (map[string]interface{}, error) {
+	if vc == nil {
+		return nil, fmt.Errorf("vault client is not initialized")
+	}
+
+	secret, err := vc.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT keys from vault: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("JWT keys not found: %s", path)
+	}
+
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JWT keys not found: %s", path)
+	}
+	return data, nil
+}
+
 // Client returns the underlying Vault client
 func (vc *VaultClient) Client() *vault.Client {
 	if vc == nil {
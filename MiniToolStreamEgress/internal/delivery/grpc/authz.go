@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// PermissionAck allows moving a consumer's position with AckMessage
+const PermissionAck = "ack"
+
+// EgressPolicy lists the rules of every method the egress server registers
+// A method without a rule is denied, see TestEgressPolicy_CoversEveryMethod
+var EgressPolicy = authz.Policy{
+	"Subscribe":       {Permission: auth.PermissionSubscribe, Subject: true, Durable: true},
+	"Fetch":           {Permission: auth.PermissionFetch, Subject: true, Durable: true},
+	"GetLastSequence": {Permission: auth.PermissionFetch, Subject: true},
+	"AckMessage":      {Permission: PermissionAck, Subject: true, Durable: true},
+
+	"GetMessage": {Permission: auth.PermissionFetch, Subject: true},
+	"ReadRange":  {Permission: auth.PermissionFetch, Subject: true},
+	"FetchRange": {Permission: auth.PermissionFetch, Subject: true},
+
+	"ListSubjects":   {Permission: authz.PermissionAdmin},
+	"GetSubjectInfo": {Permission: authz.PermissionAdmin},
+
+	"TransferConsumer": {Permission: authz.PermissionAdmin, Subject: true},
+
+	"GetSchema":          {Permission: auth.PermissionFetch, Subject: true},
+	"ListSchemaVersions": {Permission: auth.PermissionFetch, Subject: true},
+	// Schemas are changed through ingress, egress answers Unimplemented
+	"RegisterSchema":         {Permission: authz.PermissionAdmin},
+	"SetSchemaCompatibility": {Permission: authz.PermissionAdmin},
+
+	// Reflection for grpcurl describes the API to any authenticated client
+	"ServerReflectionInfo": {},
+}
+
+// ConsumerOwners checks who may use a durable consumer, named as stored
+type ConsumerOwners interface {
+	// CheckConsumerOwner returns entity.ErrNotConsumerOwner if clientID may not use the consumer
+	CheckConsumerOwner(ctx context.Context, durableName, subject, clientID string) error
+}
+
+// subjectRequest is implemented by requests naming a subject
+type subjectRequest interface {
+	GetSubject() string
+}
+
+// NewConsumerOwners checks owners for the Authorizer on the stored names of the client's tenant
+// tenants must match the handler's
+func NewConsumerOwners(owners ConsumerOwners, tenants *Tenants) authz.ConsumerOwners {
+	return &tenantOwners{owners: owners, tenants: tenants}
+}
+
+type tenantOwners struct {
+	owners  ConsumerOwners
+	tenants *Tenants
+}
+
+// CheckConsumerOwner implements authz.ConsumerOwners
+func (o *tenantOwners) CheckConsumerOwner(ctx context.Context, durableName, subj, clientID string) error {
+	storedSubject, storedDurable, err := o.tenants.scope(ctx, subj, durableName)
+	if err != nil {
+		// The handler rejects an invalid tenant
+		return nil
+	}
+	err = o.owners.CheckConsumerOwner(ctx, storedDurable, storedSubject, clientID)
+	if errors.Is(err, entity.ErrNotConsumerOwner) {
+		return authz.ErrNotConsumerOwner
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+type mockConsumerOwners struct {
+	checkFunc func(ctx context.Context, durableName, subject, clientID string) error
+}
+
+func (m *mockConsumerOwners) CheckConsumerOwner(ctx context.Context, durableName, subject, clientID string) error {
+	if m.checkFunc != nil {
+		return m.checkFunc(ctx, durableName, subject, clientID)
+	}
+	return nil
+}
+
+func TestEgressPolicy_CoversEveryMethod(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	// Every service the server registers, the Authorizer denies methods without a rule
+	server := grpc.NewServer()
+	pb.RegisterEgressServiceServer(server, NewEgressHandler(nil, log))
+	pb.RegisterSubjectServiceServer(server, NewSubjectHandler(nil, log))
+	pb.RegisterSchemaServiceServer(server, NewSchemaHandler(nil, log))
+	pb.RegisterConsumerServiceServer(server, NewConsumerHandler(nil, log))
+	reflection.Register(server)
+
+	for _, method := range EgressPolicy.Missing(server.GetServiceInfo()) {
+		t.Errorf("%s has no authorization rule", method)
+	}
+}
+
+func TestEgressPolicy_Rules(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	interceptor := authz.NewAuthorizer(EgressPolicy, log).UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.AckResponse{Success: true}, nil
+	}
+
+	reader := &auth.Claims{ClientID: "reader", Permissions: []string{"subscribe", "fetch"}, AllowedSubjects: []string{"orders.*"}}
+	acker := &auth.Claims{ClientID: "acker", Permissions: []string{"fetch", "ack"}, AllowedSubjects: []string{"orders.*"}}
+
+	tests := []struct {
+		name     string
+		claims   *auth.Claims
+		method   string
+		req      interface{}
+		wantCode codes.Code
+	}{
+		{"unauthenticated", nil, "/EgressService/AckMessage", &pb.AckRequest{Subject: "payments", DurableName: "billing"}, codes.OK},
+		{"last sequence allowed", reader, "/EgressService/GetLastSequence", &pb.GetLastSequenceRequest{Subject: "orders.42"}, codes.OK},
+		{"last sequence of another subject", reader, "/EgressService/GetLastSequence", &pb.GetLastSequenceRequest{Subject: "payments"}, codes.PermissionDenied},
+		{"ack without ack permission", reader, "/EgressService/AckMessage", &pb.AckRequest{Subject: "orders.42", DurableName: "billing"}, codes.PermissionDenied},
+		{"ack allowed", acker, "/EgressService/AckMessage", &pb.AckRequest{Subject: "orders.42", DurableName: "billing"}, codes.OK},
+		{"ack of another subject", acker, "/EgressService/AckMessage", &pb.AckRequest{Subject: "payments", DurableName: "billing"}, codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = context.WithValue(ctx, auth.ClaimsContextKey{}, tt.claims)
+			}
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("expected %v, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestConsumerOwners(t *testing.T) {
+	var checked []string
+	owners := NewConsumerOwners(&mockConsumerOwners{
+		checkFunc: func(ctx context.Context, durableName, subject, clientID string) error {
+			checked = append(checked, durableName+" "+subject+" "+clientID)
+			if clientID != "acme/billing-service" {
+				return entity.ErrNotConsumerOwner
+			}
+			return nil
+		},
+	}, NewTenants([]TenantImport{{Tenant: "acme", From: "globex", Subject: "orders.*", Prefix: "globex"}}))
+
+	ctx := func(clientID string) context.Context {
+		return context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: clientID})
+	}
+
+	if err := owners.CheckConsumerOwner(ctx("acme/billing-service"), "billing", "globex.orders.42", "acme/billing-service"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checked) != 1 || checked[0] != "$TENANT.acme.billing $TENANT.globex.orders.42 acme/billing-service" {
+		t.Errorf("expected stored names, got %v", checked)
+	}
+
+	err := owners.CheckConsumerOwner(ctx("acme/reporting"), "billing", "orders.42", "acme/reporting")
+	if !errors.Is(err, authz.ErrNotConsumerOwner) {
+		t.Errorf("expected authz.ErrNotConsumerOwner, got %v", err)
+	}
+
+	checked = nil
+	if err := owners.CheckConsumerOwner(ctx("acme/billing-service"), "$TENANT.globex.billing", "orders.42", "acme/billing-service"); err != nil {
+		t.Errorf("expected invalid durable names to be left to the handler, got %v", err)
+	}
+	if len(checked) != 0 {
+		t.Errorf("expected no ownership check for an invalid durable name, got %v", checked)
+	}
+}
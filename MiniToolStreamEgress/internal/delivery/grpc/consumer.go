@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// ConsumerHandler implements the gRPC ConsumerService
+// Every method requires the admin permission
+type ConsumerHandler struct {
+	pb.UnimplementedConsumerServiceServer
+	consumerUC *usecase.ConsumerUseCase
+	logger     *logger.Logger
+	tenants    *Tenants
+}
+
+// NewConsumerHandler creates a new ConsumerService handler
+func NewConsumerHandler(consumerUC *usecase.ConsumerUseCase, log *logger.Logger) *ConsumerHandler {
+	return &ConsumerHandler{
+		consumerUC: consumerUC,
+		logger:     log,
+	}
+}
+
+// SetTenants limits admins of a tenant to the consumers and clients of their tenant, nil disables it
+func (h *ConsumerHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// TransferConsumer implements the TransferConsumer RPC method
+func (h *ConsumerHandler) TransferConsumer(ctx context.Context, req *pb.TransferConsumerRequest) (*pb.TransferConsumerResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "TransferConsumer")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := subject.Validate(req.Subject); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.DurableName == "" {
+		return nil, status.Error(codes.InvalidArgument, "durable_name is required")
+	}
+	if req.Owner == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner is required")
+	}
+	// Consumers of a tenant are only handed to clients of the same tenant
+	for _, clientID := range append([]string{req.Owner}, req.AllowedClients...) {
+		if clientID == "" {
+			return nil, status.Error(codes.InvalidArgument, "allowed client IDs cannot be empty")
+		}
+		if h.tenants == nil {
+			continue
+		}
+		if clientTenantID, err := clientTenant(clientID); err != nil || clientTenantID != tenant {
+			return nil, status.Errorf(codes.PermissionDenied, "client %q is not in the tenant of the caller", clientID)
+		}
+	}
+
+	storedSubject, storedDurable, err := h.tenants.scope(ctx, req.Subject, req.DurableName)
+	if err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+
+	if err := h.consumerUC.TransferConsumer(ctx, storedDurable, storedSubject, req.Owner, req.AllowedClients); err != nil {
+		if errors.Is(err, entity.ErrConsumerNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		h.logger.Error("Consumer transfer failed",
+			logger.String("subject", req.Subject),
+			logger.String("durable_name", req.DurableName),
+			logger.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "TransferConsumer failed")
+	}
+	return &pb.TransferConsumerResponse{}, nil
+}
+
+// requireAdmin rejects callers without the admin permission and returns their tenant
+// The Authorizer lets unauthenticated requests through when auth.require_auth
+// is off, consumer ownership must stay closed to them regardless
+func (h *ConsumerHandler) requireAdmin(ctx context.Context, method string) (string, error) {
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "%s requires an authenticated client", method)
+	}
+	if !claims.CheckPermission(authz.PermissionAdmin) {
+		h.logger.Warn("Consumer administration denied",
+			logger.String("method", method),
+			logger.String("client_id", claims.ClientID),
+		)
+		return "", status.Errorf(codes.PermissionDenied, "%s requires the %s permission", method, authz.PermissionAdmin)
+	}
+
+	if h.tenants == nil {
+		return "", nil
+	}
+	tenant, err := clientTenant(claims.ClientID)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return tenant, nil
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// memoryConsumerRepository keeps owners of consumers keyed by "durable subject"
+type memoryConsumerRepository struct {
+	owners  map[string]string
+	allowed map[string][]string
+}
+
+func (m *memoryConsumerRepository) ClaimConsumer(ctx context.Context, durableName, subject, clientID string) (bool, string, error) {
+	owner, ok := m.owners[durableName+" "+subject]
+	if !ok {
+		return true, clientID, nil
+	}
+	return owner == clientID, owner, nil
+}
+
+func (m *memoryConsumerRepository) TransferConsumer(ctx context.Context, durableName, subject, owner string, allowedClients []string) error {
+	key := durableName + " " + subject
+	if _, ok := m.owners[key]; !ok {
+		return entity.ErrConsumerNotFound
+	}
+	m.owners[key] = owner
+	m.allowed[key] = allowedClients
+	return nil
+}
+
+func newConsumerHandler(repo *memoryConsumerRepository) *ConsumerHandler {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	return NewConsumerHandler(usecase.NewConsumerUseCase(repo, log), log)
+}
+
+func TestConsumerHandler_TransferConsumer(t *testing.T) {
+	repo := &memoryConsumerRepository{
+		owners:  map[string]string{"billing orders": "billing-service"},
+		allowed: map[string][]string{},
+	}
+	handler := newConsumerHandler(repo)
+	req := &pb.TransferConsumerRequest{Subject: "orders", DurableName: "billing", Owner: "billing-v2", AllowedClients: []string{"billing-service"}}
+
+	// Ownership is closed to unauthenticated clients and clients without the admin permission
+	if _, err := handler.TransferConsumer(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without claims, got %v", err)
+	}
+	reader := withClaims(&auth.Claims{ClientID: "billing-service", Permissions: []string{"fetch"}, AllowedSubjects: []string{"*"}})
+	if _, err := handler.TransferConsumer(reader, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without the admin permission, got %v", err)
+	}
+
+	admin := withClaims(&auth.Claims{ClientID: "operator", Permissions: []string{"admin"}})
+	if _, err := handler.TransferConsumer(admin, req); err != nil {
+		t.Fatalf("TransferConsumer failed: %v", err)
+	}
+	if repo.owners["billing orders"] != "billing-v2" || len(repo.allowed["billing orders"]) != 1 {
+		t.Errorf("unexpected ownership: %v %v", repo.owners, repo.allowed)
+	}
+
+	if _, err := handler.TransferConsumer(admin, &pb.TransferConsumerRequest{Subject: "orders", DurableName: "missing", Owner: "billing-v2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown consumer, got %v", err)
+	}
+	if _, err := handler.TransferConsumer(admin, &pb.TransferConsumerRequest{Subject: "orders", DurableName: "billing"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without an owner, got %v", err)
+	}
+	if _, err := handler.TransferConsumer(admin, &pb.TransferConsumerRequest{Subject: "orders..eu", DurableName: "billing", Owner: "billing-v2"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an invalid subject, got %v", err)
+	}
+}
+
+func TestConsumerHandler_TransferConsumer_Tenant(t *testing.T) {
+	repo := &memoryConsumerRepository{
+		owners:  map[string]string{"$TENANT.acme.billing $TENANT.acme.orders": "acme/billing"},
+		allowed: map[string][]string{},
+	}
+	handler := newConsumerHandler(repo)
+	handler.SetTenants(NewTenants(nil))
+	tenantAdmin := withClaims(&auth.Claims{ClientID: "acme/ops", Permissions: []string{"admin"}})
+
+	if _, err := handler.TransferConsumer(tenantAdmin, &pb.TransferConsumerRequest{Subject: "orders", DurableName: "billing", Owner: "acme/billing-v2"}); err != nil {
+		t.Fatalf("TransferConsumer failed: %v", err)
+	}
+	if repo.owners["$TENANT.acme.billing $TENANT.acme.orders"] != "acme/billing-v2" {
+		t.Errorf("expected the consumer of the tenant to be transferred, got %v", repo.owners)
+	}
+
+	// A consumer must not be handed to clients of another tenant
+	for _, req := range []*pb.TransferConsumerRequest{
+		{Subject: "orders", DurableName: "billing", Owner: "globex/reader"},
+		{Subject: "orders", DurableName: "billing", Owner: "acme/billing-v2", AllowedClients: []string{"reader"}},
+	} {
+		if _, err := handler.TransferConsumer(tenantAdmin, req); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("expected PermissionDenied for %+v, got %v", req, err)
+		}
+	}
+}
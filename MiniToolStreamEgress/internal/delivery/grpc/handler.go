@@ -2,24 +2,36 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 	"github.com/moroshma/MiniToolStreamConnector/auth"
 )
 
+const (
+	// metadataAcceptContentEncoding lists payload encodings the client decodes itself
+	metadataAcceptContentEncoding = "accept-content-encoding"
+)
+
 // EgressHandler implements the gRPC EgressService
 type EgressHandler struct {
 	pb.UnimplementedEgressServiceServer
 	messageUC *usecase.MessageUseCase
 	logger    *logger.Logger
+	tenants   *Tenants
 }
 
 // NewEgressHandler creates a new gRPC handler
@@ -30,8 +42,18 @@ func NewEgressHandler(messageUC *usecase.MessageUseCase, logger *logger.Logger)
 	}
 }
 
+// SetTenants scopes subjects and consumers per tenant of the client, nil disables it
+func (h *EgressHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
 // Subscribe implements the Subscribe RPC method
 func (h *EgressHandler) Subscribe(req *pb.SubscribeRequest, stream pb.EgressService_SubscribeServer) error {
+	// Subjects outside the grammar could be mistaken for claim patterns, so check before access
+	if err := subject.Validate(req.Subject); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Check authorization if claims are present in context
 	if claims, ok := auth.GetClaimsFromContext(stream.Context()); ok {
 		h.logger.Info("Authenticated Subscribe request",
@@ -56,14 +78,28 @@ func (h *EgressHandler) Subscribe(req *pb.SubscribeRequest, stream pb.EgressServ
 		)
 	}
 
-	if req.Subject == "" {
-		return fmt.Errorf("subject cannot be empty")
-	}
-
 	if req.DurableName == "" {
 		return fmt.Errorf("durable_name cannot be empty")
 	}
 
+	storedSubject, durableName, err := h.tenants.scope(stream.Context(), req.Subject, req.DurableName)
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+
+	bySubject := req.SequenceKind == pb.SequenceKind_SEQUENCE_KIND_SUBJECT
+	startSequence := req.StartSequence
+	if bySubject && startSequence != nil && *startSequence > 0 {
+		sequence, err := h.messageUC.ResolveSubjectSequence(stream.Context(), storedSubject, *startSequence)
+		if errors.Is(err, entity.ErrMessageNotFound) {
+			return status.Errorf(codes.NotFound, "start_sequence: %v", err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve start sequence: %w", err)
+		}
+		startSequence = &sequence
+	}
+
 	// Create notification channel
 	notificationChan := make(chan *entity.Notification, 100)
 	defer close(notificationChan)
@@ -74,7 +110,7 @@ func (h *EgressHandler) Subscribe(req *pb.SubscribeRequest, stream pb.EgressServ
 
 	errChan := make(chan error, 1)
 	go func() {
-		err := h.messageUC.Subscribe(ctx, req.Subject, req.DurableName, req.StartSequence, notificationChan)
+		err := h.messageUC.Subscribe(ctx, storedSubject, durableName, startSequence, notificationChan)
 		if err != nil && err != context.Canceled {
 			errChan <- err
 		}
@@ -89,9 +125,23 @@ func (h *EgressHandler) Subscribe(req *pb.SubscribeRequest, stream pb.EgressServ
 				return nil
 			}
 
+			sequence := notification.Sequence
+			if bySubject {
+				if sequence, err = h.messageUC.SubjectSequence(ctx, notification.Sequence); err != nil {
+					// Notifications only signal new messages, the next one supersedes a skipped one
+					h.logger.Warn("Failed to resolve subject sequence of notification",
+						logger.String("subject", req.Subject),
+						logger.Uint64("sequence", notification.Sequence),
+						logger.Error(err),
+					)
+					continue
+				}
+			}
+
+			// Clients see subjects by the name they subscribed with
 			err := stream.Send(&pb.Notification{
-				Subject:  notification.Subject,
-				Sequence: notification.Sequence,
+				Subject:  req.Subject,
+				Sequence: sequence,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to send notification: %w", err)
@@ -111,6 +161,11 @@ func (h *EgressHandler) Subscribe(req *pb.SubscribeRequest, stream pb.EgressServ
 
 // Fetch implements the Fetch RPC method
 func (h *EgressHandler) Fetch(req *pb.FetchRequest, stream pb.EgressService_FetchServer) error {
+	// Subjects outside the grammar could be mistaken for claim patterns, so check before access
+	if err := subject.Validate(req.Subject); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Check authorization if claims are present in context
 	if claims, ok := auth.GetClaimsFromContext(stream.Context()); ok {
 		h.logger.Info("Authenticated Fetch request",
@@ -137,33 +192,45 @@ func (h *EgressHandler) Fetch(req *pb.FetchRequest, stream pb.EgressService_Fetc
 		)
 	}
 
-	if req.Subject == "" {
-		return fmt.Errorf("subject cannot be empty")
-	}
-
 	if req.DurableName == "" {
 		return fmt.Errorf("durable_name cannot be empty")
 	}
 
+	storedSubject, durableName, err := h.tenants.scope(stream.Context(), req.Subject, req.DurableName)
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+
 	// Fetch messages
 	messages, err := h.messageUC.FetchMessages(
 		stream.Context(),
-		req.Subject,
-		req.DurableName,
+		storedSubject,
+		durableName,
 		int(req.BatchSize),
 	)
+	if errors.Is(err, entity.ErrPayloadCorrupted) {
+		return status.Errorf(codes.DataLoss, "failed to fetch messages: %v", err)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch messages: %w", err)
 	}
 
 	// Send each message
+	_, authenticated := auth.GetClaimsFromContext(stream.Context())
+	accepted := acceptedEncodings(stream.Context())
 	for _, msg := range messages {
+		// Encrypted payloads are opened only for consumers whose fetch access was checked above
+		if err := h.preparePayload(stream.Context(), msg, authenticated, accepted); err != nil {
+			return err
+		}
+
 		pbMsg := &pb.Message{
-			Subject:   msg.Subject,
-			Sequence:  msg.Sequence,
-			Data:      msg.Data,
-			Headers:   msg.Headers,
-			Timestamp: timestamppb.New(msg.Timestamp),
+			Subject:         req.Subject,
+			Sequence:        msg.Sequence,
+			Data:            msg.Data,
+			Headers:         msg.Headers,
+			Timestamp:       timestamppb.New(msg.Timestamp),
+			SubjectSequence: msg.SubjectSequence,
 		}
 
 		err = stream.Send(pbMsg)
@@ -184,11 +251,16 @@ func (h *EgressHandler) Fetch(req *pb.FetchRequest, stream pb.EgressService_Fetc
 func (h *EgressHandler) GetLastSequence(ctx context.Context, req *pb.GetLastSequenceRequest) (*pb.GetLastSequenceResponse, error) {
 	h.logger.Info("GetLastSequence request", logger.String("subject", req.Subject))
 
-	if req.Subject == "" {
-		return nil, fmt.Errorf("subject cannot be empty")
+	if err := subject.Validate(req.Subject); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	storedSubject, _, err := h.tenants.scope(ctx, req.Subject, "")
+	if err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
 	}
 
-	lastSeq, err := h.messageUC.GetLastSequence(ctx, req.Subject)
+	lastSeq, err := h.messageUC.GetLastSequence(ctx, storedSubject)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last sequence: %w", err)
 	}
@@ -200,6 +272,13 @@ func (h *EgressHandler) GetLastSequence(ctx context.Context, req *pb.GetLastSequ
 
 // AckMessage implements the AckMessage RPC method for manual message acknowledgment
 func (h *EgressHandler) AckMessage(ctx context.Context, req *pb.AckRequest) (*pb.AckResponse, error) {
+	if err := subject.Validate(req.Subject); err != nil {
+		return &pb.AckResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
 	// Check authorization if claims are present in context
 	if claims, ok := auth.GetClaimsFromContext(ctx); ok {
 		h.logger.Debug("Authenticated AckMessage request",
@@ -216,13 +295,6 @@ func (h *EgressHandler) AckMessage(ctx context.Context, req *pb.AckRequest) (*pb
 		)
 	}
 
-	if req.Subject == "" {
-		return &pb.AckResponse{
-			Success:      false,
-			ErrorMessage: "subject cannot be empty",
-		}, nil
-	}
-
 	if req.DurableName == "" {
 		return &pb.AckResponse{
 			Success:      false,
@@ -230,8 +302,28 @@ func (h *EgressHandler) AckMessage(ctx context.Context, req *pb.AckRequest) (*pb
 		}, nil
 	}
 
+	storedSubject, durableName, imported, err := h.tenants.scopeImport(ctx, req.Subject, req.DurableName)
+	if err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+
 	// Update consumer position
-	err := h.messageUC.AckMessage(ctx, req.DurableName, req.Subject, req.Sequence)
+	bySubject := req.SequenceKind == pb.SequenceKind_SEQUENCE_KIND_SUBJECT
+	switch {
+	case imported:
+		// Imported subjects are read-only, the ack must not delete messages the exporter retains
+		sequence := req.Sequence
+		if bySubject {
+			sequence, err = h.messageUC.ResolveSubjectSequence(ctx, storedSubject, req.Sequence)
+		}
+		if err == nil {
+			err = h.messageUC.MoveConsumer(ctx, durableName, storedSubject, sequence)
+		}
+	case bySubject:
+		err = h.messageUC.AckMessageBySubjectSequence(ctx, durableName, storedSubject, req.Sequence)
+	default:
+		err = h.messageUC.AckMessage(ctx, durableName, storedSubject, req.Sequence)
+	}
 	if err != nil {
 		h.logger.Warn("Failed to acknowledge message",
 			logger.String("durable_name", req.DurableName),
@@ -255,3 +347,50 @@ func (h *EgressHandler) AckMessage(ctx context.Context, req *pb.AckRequest) (*pb
 		Success: true,
 	}, nil
 }
+
+// preparePayload turns a loaded and verified payload into what the client receives:
+// encrypted payloads are opened for authenticated clients only, others get the
+// message without its payload, and encodings the client did not accept are decoded
+func (h *EgressHandler) preparePayload(ctx context.Context, msg *entity.Message, authenticated bool, accepted map[string]bool) error {
+	if encryption.IsEncrypted(msg.Headers) {
+		if !authenticated {
+			// Metadata only, so a consumer without credentials still moves past the message
+			msg.Data = nil
+			return nil
+		}
+		if err := h.messageUC.DecryptPayload(ctx, msg); err != nil {
+			h.logger.Error("Failed to decrypt message",
+				logger.String("subject", msg.Subject),
+				logger.Uint64("sequence", msg.Sequence),
+				logger.Error(err),
+			)
+			return status.Errorf(codes.Internal, "failed to decrypt message %d", msg.Sequence)
+		}
+	}
+
+	if encoding := msg.Headers[compression.HeaderContentEncoding]; encoding != "" && !accepted[encoding] {
+		if err := h.messageUC.DecodePayload(msg); err != nil {
+			return status.Errorf(codes.DataLoss, "failed to decode message: %v", err)
+		}
+	}
+	return nil
+}
+
+// acceptedEncodings returns payload encodings the client advertised in request metadata
+// Payloads in any other encoding are decoded before they are sent
+func acceptedEncodings(ctx context.Context) map[string]bool {
+	accepted := make(map[string]bool)
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return accepted
+	}
+
+	for _, value := range md.Get(metadataAcceptContentEncoding) {
+		for _, encoding := range strings.Split(value, ",") {
+			if encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding != "" {
+				accepted[encoding] = true
+			}
+		}
+	}
+	return accepted
+}
@@ -1,17 +1,25 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
 )
 
 type mockMessageRepository struct {
@@ -19,7 +27,25 @@ type mockMessageRepository struct {
 	getLatestSequenceForSubjectFunc func(ctx context.Context, subject string) (uint64, error)
 	getMessagesBySubjectFunc       func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error)
 	updateConsumerPositionFunc     func(ctx context.Context, durableName, subject string, sequence uint64) error
+	getMessagesRangeFunc           func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error)
 	getMessageBySequenceFunc       func(ctx context.Context, sequence uint64) (*entity.Message, error)
+	ackMessageFunc                 func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error)
+	getMessagesBySubjectSeqFunc    func(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error)
+	resolveSubjectSequenceFunc     func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error)
+}
+
+func (m *mockMessageRepository) GetMessagesBySubjectSequence(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error) {
+	if m.getMessagesBySubjectSeqFunc != nil {
+		return m.getMessagesBySubjectSeqFunc(ctx, subject, startSubjectSeq, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockMessageRepository) ResolveSubjectSequence(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+	if m.resolveSubjectSequenceFunc != nil {
+		return m.resolveSubjectSequenceFunc(ctx, subject, subjectSeq)
+	}
+	return 0, nil
 }
 
 func (m *mockMessageRepository) GetConsumerPosition(ctx context.Context, durableName, subject string) (uint64, error) {
@@ -50,6 +76,13 @@ func (m *mockMessageRepository) UpdateConsumerPosition(ctx context.Context, dura
 	return nil
 }
 
+func (m *mockMessageRepository) GetMessagesRange(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+	if m.getMessagesRangeFunc != nil {
+		return m.getMessagesRangeFunc(ctx, subject, fromSeq, toSeq, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockMessageRepository) GetMessageBySequence(ctx context.Context, sequence uint64) (*entity.Message, error) {
 	if m.getMessageBySequenceFunc != nil {
 		return m.getMessageBySequenceFunc(ctx, sequence)
@@ -57,9 +90,25 @@ func (m *mockMessageRepository) GetMessageBySequence(ctx context.Context, sequen
 	return nil, nil
 }
 
+func (m *mockMessageRepository) AckMessage(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+	if m.ackMessageFunc != nil {
+		return m.ackMessageFunc(ctx, durableName, subject, sequence)
+	}
+	return nil, nil
+}
+
 type mockStorageRepository struct {
-	getObjectFunc    func(ctx context.Context, subject, objectName string) ([]byte, error)
-	getObjectURLFunc func(objectName string) string
+	getObjectFunc      func(ctx context.Context, subject, objectName string) ([]byte, error)
+	getObjectRangeFunc func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error)
+	deleteObjectFunc   func(ctx context.Context, subject, objectName string) error
+	getObjectURLFunc   func(objectName string) string
+}
+
+func (m *mockStorageRepository) DeleteObject(ctx context.Context, subject, objectName string) error {
+	if m.deleteObjectFunc != nil {
+		return m.deleteObjectFunc(ctx, subject, objectName)
+	}
+	return nil
 }
 
 func (m *mockStorageRepository) GetObject(ctx context.Context, subject, objectName string) ([]byte, error) {
@@ -69,6 +118,13 @@ func (m *mockStorageRepository) GetObject(ctx context.Context, subject, objectNa
 	return nil, nil
 }
 
+func (m *mockStorageRepository) GetObjectRange(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+	if m.getObjectRangeFunc != nil {
+		return m.getObjectRangeFunc(ctx, subject, objectName, offset, length)
+	}
+	return nil, 0, nil
+}
+
 func (m *mockStorageRepository) GetObjectURL(subject, objectName string) string {
 	if m.getObjectURLFunc != nil {
 		return m.getObjectURLFunc(objectName)
@@ -419,3 +475,309 @@ func TestEgressHandler_GetLastSequence_Error(t *testing.T) {
 		t.Fatal("expected error from use case")
 	}
 }
+
+func TestEgressHandler_Fetch_ContentEncodingNegotiation(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"level":"info"}`, 64))
+	compressed, err := compression.Compress(compression.Zstd, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{{
+				Sequence:   1,
+				Subject:    subject,
+				ObjectName: "logs.app_1",
+				Headers: map[string]string{
+					"content-encoding": "zstd",
+					"original-size":    strconv.Itoa(len(payload)),
+					"data-size":        strconv.Itoa(len(compressed)),
+				},
+				Timestamp: time.Now(),
+			}}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return compressed, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, storageRepo, log, time.Second), log)
+	req := &pb.FetchRequest{Subject: "logs.app", DurableName: "reader", BatchSize: 10}
+
+	// Client without advertised support gets the original payload
+	stream := &mockFetchStream{ctx: context.Background()}
+	if err := handler.Fetch(req, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := stream.sentMsgs[0]
+	if !bytes.Equal(msg.Data, payload) {
+		t.Error("expected decompressed payload")
+	}
+	if _, ok := msg.Headers["content-encoding"]; ok {
+		t.Error("content-encoding must be dropped for decoded payloads")
+	}
+	if msg.Headers["data-size"] != strconv.Itoa(len(payload)) {
+		t.Errorf("expected data-size of the decoded payload, got %q", msg.Headers["data-size"])
+	}
+
+	// Client that decodes zstd itself gets the stored bytes
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-content-encoding", "gzip, zstd"))
+	stream = &mockFetchStream{ctx: ctx}
+	if err := handler.Fetch(req, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg = stream.sentMsgs[0]
+	if !bytes.Equal(msg.Data, compressed) {
+		t.Error("expected compressed payload to be passed through")
+	}
+	if msg.Headers["content-encoding"] != "zstd" {
+		t.Errorf("expected content-encoding zstd, got %q", msg.Headers["content-encoding"])
+	}
+}
+
+func TestEgressHandler_Fetch_EncryptedWithoutAuthentication(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{{
+				Sequence:   1,
+				Subject:    subject,
+				ObjectName: "payments_1",
+				Headers: map[string]string{
+					"encryption":          "aes-256-gcm",
+					"encryption-key":      "payments",
+					"encryption-data-key": "vault:v1:wrapped",
+				},
+				Timestamp: time.Now(),
+			}}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("ciphertext"), nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, storageRepo, log, time.Second), log)
+	stream := &mockFetchStream{ctx: context.Background()}
+
+	err := handler.Fetch(&pb.FetchRequest{Subject: "payments", DurableName: "reader", BatchSize: 10}, stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stream.sentMsgs) != 1 {
+		t.Fatalf("expected the message to be sent, got %d", len(stream.sentMsgs))
+	}
+	if sent := stream.sentMsgs[0]; sent.Data != nil || sent.Headers["encryption"] == "" {
+		t.Errorf("expected metadata only for an unauthenticated consumer, got %q", sent.Data)
+	}
+}
+
+func TestEgressHandler_Fetch_CorruptedPayload(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{{
+				Sequence:   1,
+				Subject:    subject,
+				ObjectName: "sensors_1",
+				Headers:    map[string]string{"payload-sha256": strings.Repeat("0", 64)},
+				Timestamp:  time.Now(),
+			}}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("damaged"), nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, storageRepo, log, time.Second), log)
+	stream := &mockFetchStream{ctx: context.Background()}
+
+	err := handler.Fetch(&pb.FetchRequest{Subject: "sensors", DurableName: "reader", BatchSize: 10}, stream)
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("expected DataLoss, got %v", err)
+	}
+	if len(stream.sentMsgs) != 0 {
+		t.Error("corrupted message must not be sent")
+	}
+}
+
+func TestEgressHandler_Fetch_InvalidSubject(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := usecase.NewMessageUseCase(&mockMessageRepository{}, &mockStorageRepository{}, log, time.Second)
+	handler := NewEgressHandler(uc, log)
+
+	for _, subject := range []string{"orders.*", "orders/eu", "$SYS.health"} {
+		stream := &mockFetchStream{ctx: context.Background()}
+		err := handler.Fetch(&pb.FetchRequest{Subject: subject, DurableName: "reader", BatchSize: 10}, stream)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for %q, got %v", subject, err)
+		}
+	}
+}
+
+func TestEgressHandler_Fetch_SubjectSequences(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{{Sequence: 57, SubjectSequence: 3, Subject: subject, Timestamp: time.Now()}}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second), log)
+	req := &pb.FetchRequest{Subject: "test.single", DurableName: "reader", BatchSize: 10}
+
+	stream := &mockFetchStream{ctx: context.Background()}
+	if err := handler.Fetch(req, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := stream.sentMsgs[0]; msg.Sequence != 57 || msg.SubjectSequence != 3 {
+		t.Errorf("expected global sequence 57 and subject sequence 3, got %d and %d", msg.Sequence, msg.SubjectSequence)
+	}
+}
+
+func TestEgressHandler_AckMessage_SubjectSequence(t *testing.T) {
+	var ackedSequence uint64
+	msgRepo := &mockMessageRepository{
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			if subjectSeq == 3 {
+				return 57, nil
+			}
+			return 0, nil
+		},
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			ackedSequence = sequence
+			return nil, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second), log)
+	ctx := context.Background()
+	bySubject := pb.SequenceKind_SEQUENCE_KIND_SUBJECT
+
+	resp, err := handler.AckMessage(ctx, &pb.AckRequest{Subject: "test.single", DurableName: "worker", Sequence: 3, SequenceKind: bySubject})
+	if err != nil || !resp.Success {
+		t.Fatalf("unexpected result %+v, %v", resp, err)
+	}
+	if ackedSequence != 57 {
+		t.Errorf("expected global sequence 57 to be acked, got %d", ackedSequence)
+	}
+
+	resp, err = handler.AckMessage(ctx, &pb.AckRequest{Subject: "test.single", DurableName: "worker", Sequence: 4, SequenceKind: bySubject})
+	if err != nil || resp.Success {
+		t.Errorf("expected ack of an unknown subject sequence to fail, got %+v, %v", resp, err)
+	}
+
+	// By default the sequence is global
+	if _, err := handler.AckMessage(ctx, &pb.AckRequest{Subject: "test.single", DurableName: "worker", Sequence: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ackedSequence != 3 {
+		t.Errorf("expected global sequence 3 to be acked, got %d", ackedSequence)
+	}
+}
+
+func TestEgressHandler_AckMessage_ImportedSubject(t *testing.T) {
+	var moved, acked []string
+	msgRepo := &mockMessageRepository{
+		updateConsumerPositionFunc: func(ctx context.Context, durableName, subject string, sequence uint64) error {
+			moved = append(moved, fmt.Sprintf("%s %s %d", durableName, subject, sequence))
+			return nil
+		},
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			acked = append(acked, fmt.Sprintf("%s %s %d", durableName, subject, sequence))
+			return nil, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second), log)
+	handler.SetTenants(NewTenants([]TenantImport{{Tenant: "globex", From: "acme", Subject: "orders.*", Prefix: "acme"}}))
+	ctx := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "globex/worker"})
+
+	// Acks of an imported subject move the position without applying the exporter's retention
+	resp, err := handler.AckMessage(ctx, &pb.AckRequest{Subject: "acme.orders.42", DurableName: "billing", Sequence: 5})
+	if err != nil || !resp.Success {
+		t.Fatalf("unexpected result %+v, %v", resp, err)
+	}
+	if len(moved) != 1 || moved[0] != "$TENANT.globex.billing $TENANT.acme.orders.42 5" || len(acked) != 0 {
+		t.Errorf("expected only the position to move, moved %v, acked %v", moved, acked)
+	}
+
+	if _, err := handler.AckMessage(ctx, &pb.AckRequest{Subject: "orders.42", DurableName: "billing", Sequence: 7}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(acked) != 1 || acked[0] != "$TENANT.globex.billing $TENANT.globex.orders.42 7" {
+		t.Errorf("expected own subjects to be acked, got %v", acked)
+	}
+}
+
+// notifyingStream cancels the subscription after the first notification
+type notifyingStream struct {
+	mockSubscribeStream
+	cancel context.CancelFunc
+}
+
+func (s *notifyingStream) Send(notif *pb.Notification) error {
+	s.sentNotifs = append(s.sentNotifs, notif)
+	s.cancel()
+	return nil
+}
+
+func TestEgressHandler_Subscribe_SubjectSequences(t *testing.T) {
+	var resolved uint64
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			resolved = subjectSeq
+			return 40, nil
+		},
+		getLatestSequenceForSubjectFunc: func(ctx context.Context, subject string) (uint64, error) {
+			return 57, nil
+		},
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, SubjectSequence: 3, Subject: "test.single"}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := NewEgressHandler(usecase.NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second), log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &notifyingStream{mockSubscribeStream: mockSubscribeStream{ctx: ctx}, cancel: cancel}
+	start := uint64(2)
+
+	req := &pb.SubscribeRequest{Subject: "test.single", DurableName: "worker", StartSequence: &start, SequenceKind: pb.SequenceKind_SEQUENCE_KIND_SUBJECT}
+	err := handler.Subscribe(req, stream)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved != 2 {
+		t.Errorf("expected start sequence 2 to be resolved, got %d", resolved)
+	}
+	if len(stream.sentNotifs) != 1 || stream.sentNotifs[0].Sequence != 3 {
+		t.Errorf("expected a notification with subject sequence 3, got %+v", stream.sentNotifs)
+	}
+}
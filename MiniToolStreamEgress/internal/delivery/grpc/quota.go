@@ -0,0 +1,199 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strconv"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// metadataRetryAfter tells a rejected client how many seconds to wait
+const metadataRetryAfter = "retry-after"
+
+// QuotaStreamInterceptor enforces fetch quotas ahead of EgressHandler.Fetch
+// The size of a batch is only known once it is sent, so a Fetch is admitted
+// while the client's buckets are not in debt and the delivered messages are
+// charged afterwards. It must run after authentication so the client ID is known.
+// Limits are matched against the subject as stored, so tenant patterns apply
+func QuotaStreamInterceptor(limiter *quota.Limiter, tenants *Tenants, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var clientID string
+		if claims, ok := auth.GetClaimsFromContext(stream.Context()); ok {
+			clientID = claims.ClientID
+		}
+
+		qs := &quotaStream{ServerStream: stream, limiter: limiter, tenants: tenants, logger: log, clientID: clientID}
+		err := handler(srv, qs)
+
+		if qs.messages > 0 {
+			if chargeErr := limiter.Charge(clientID, qs.subject, qs.messages, qs.bytes); chargeErr != nil {
+				log.Error("Failed to charge fetch quotas",
+					logger.String("client_id", clientID),
+					logger.String("subject", qs.subject),
+					logger.Error(chargeErr),
+				)
+			}
+		}
+		return err
+	}
+}
+
+// quotaStream admits Fetch requests and counts the messages sent in reply
+type quotaStream struct {
+	grpc.ServerStream
+	limiter  *quota.Limiter
+	tenants  *Tenants
+	logger   *logger.Logger
+	clientID string
+
+	subject  string
+	messages int64
+	bytes    int64
+}
+
+// RecvMsg checks the quotas of a Fetch request before the handler sees it
+func (s *quotaStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	fetch, ok := m.(*pb.FetchRequest)
+	if !ok {
+		return nil
+	}
+	// An invalid tenant is rejected by the handler
+	subj, _, err := s.tenants.scope(s.Context(), fetch.Subject, "")
+	if err != nil {
+		return nil
+	}
+	s.subject = subj
+
+	if err := s.limiter.Acquire(s.clientID, subj, 0, 0); err != nil {
+		return s.quotaError(err)
+	}
+	return nil
+}
+
+// SendMsg counts delivered messages
+func (s *quotaStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(*pb.Message); ok && s.subject != "" {
+		s.messages++
+		s.bytes += int64(len(msg.Data))
+	}
+	return nil
+}
+
+// quotaError converts a limiter error to a gRPC status
+func (s *quotaStream) quotaError(err error) error {
+	return quotaError(err, "Fetch", s.clientID, s.subject, s.logger, s.SetTrailer)
+}
+
+// quotaReadMethods are the stateless reads whose payloads count against fetch quotas
+var quotaReadMethods = map[string]bool{
+	pb.EgressService_GetMessage_FullMethodName: true,
+	pb.EgressService_ReadRange_FullMethodName:  true,
+	pb.EgressService_FetchRange_FullMethodName: true,
+}
+
+// QuotaUnaryInterceptor enforces fetch quotas on the stateless reads
+// Like Fetch, a read is admitted while the client's buckets are not in debt and
+// the payload it returned is charged afterwards. It must run after authentication
+func QuotaUnaryInterceptor(limiter *quota.Limiter, tenants *Tenants, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		read, ok := req.(subjectRequest)
+		if !ok || !quotaReadMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		// An invalid tenant is rejected by the handler
+		subj, _, err := tenants.scope(ctx, read.GetSubject(), "")
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		var clientID string
+		if claims, ok := auth.GetClaimsFromContext(ctx); ok {
+			clientID = claims.ClientID
+		}
+		method := path.Base(info.FullMethod)
+		if err := limiter.Acquire(clientID, subj, 0, 0); err != nil {
+			return nil, quotaError(err, method, clientID, subj, log, func(md metadata.MD) {
+				_ = grpc.SetTrailer(ctx, md)
+			})
+		}
+
+		resp, err := handler(ctx, req)
+		if messages, bytes := readUsage(resp); messages > 0 {
+			if chargeErr := limiter.Charge(clientID, subj, messages, bytes); chargeErr != nil {
+				log.Error("Failed to charge fetch quotas",
+					logger.String("client_id", clientID),
+					logger.String("subject", subj),
+					logger.Error(chargeErr),
+				)
+			}
+		}
+		return resp, err
+	}
+}
+
+// readUsage returns the messages and payload bytes a stateless read delivers
+func readUsage(resp interface{}) (int64, int64) {
+	switch r := resp.(type) {
+	case *pb.Message:
+		return 1, int64(len(r.Data))
+	case *pb.ReadRangeResponse:
+		var size int64
+		for _, msg := range r.Messages {
+			size += int64(len(msg.Data))
+		}
+		return int64(len(r.Messages)), size
+	case *pb.FetchRangeResponse:
+		return 1, int64(len(r.Data))
+	}
+	return 0, 0
+}
+
+// quotaError converts a limiter error to a gRPC status
+// Rejections become RESOURCE_EXHAUSTED with RetryInfo details and a retry-after trailer
+func quotaError(err error, method, clientID, subj string, log *logger.Logger, setTrailer func(metadata.MD)) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		log.Error("Failed to check quotas",
+			logger.String("client_id", clientID),
+			logger.String("subject", subj),
+			logger.Error(err),
+		)
+		return status.Error(codes.Unavailable, "failed to check quotas")
+	}
+
+	log.Warn(method+" rejected by quota",
+		logger.String("client_id", clientID),
+		logger.String("subject", subj),
+		logger.String("reason", exceeded.Reason),
+		logger.String("retry_after", exceeded.RetryAfter.String()),
+	)
+
+	st := status.New(codes.ResourceExhausted, exceeded.Error())
+	if exceeded.RetryAfter > 0 {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(exceeded.RetryAfter)}); err == nil {
+			st = detailed
+		}
+		setTrailer(metadata.Pairs(metadataRetryAfter, strconv.FormatInt(quota.RetryAfterSeconds(exceeded.RetryAfter), 10)))
+	}
+	return st.Err()
+}
@@ -0,0 +1,163 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+)
+
+type mockQuotaStore struct {
+	consumeFunc func(buckets []quota.Bucket, force bool) (time.Duration, error)
+}
+
+func (m *mockQuotaStore) ConsumeQuota(buckets []quota.Bucket, force bool) (time.Duration, error) {
+	if m.consumeFunc != nil {
+		return m.consumeFunc(buckets, force)
+	}
+	return 0, nil
+}
+
+// mockServerStream receives one FetchRequest
+type mockServerStream struct {
+	req     *pb.FetchRequest
+	trailer metadata.MD
+}
+
+func (m *mockServerStream) Context() context.Context        { return context.Background() }
+func (m *mockServerStream) SetHeader(md metadata.MD) error  { return nil }
+func (m *mockServerStream) SendHeader(md metadata.MD) error { return nil }
+func (m *mockServerStream) SetTrailer(md metadata.MD)       { m.trailer = md }
+func (m *mockServerStream) SendMsg(msg interface{}) error   { return nil }
+func (m *mockServerStream) RecvMsg(msg interface{}) error {
+	// Protobuf messages must not be copied by value
+	req := msg.(*pb.FetchRequest)
+	req.Subject = m.req.Subject
+	req.DurableName = m.req.DurableName
+	req.BatchSize = m.req.BatchSize
+	return nil
+}
+
+func TestQuotaStreamInterceptor(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	wait := time.Duration(0)
+	var charged []quota.Bucket
+	store := &mockQuotaStore{
+		consumeFunc: func(buckets []quota.Bucket, force bool) (time.Duration, error) {
+			if force {
+				charged = buckets
+				return 0, nil
+			}
+			return wait, nil
+		},
+	}
+	limiter := quota.NewLimiter(&quota.Policy{Default: quota.Limit{MessagesPerSecond: 10, BytesPerSecond: 1000}}, store, "fetch")
+	interceptor := QuotaStreamInterceptor(limiter, nil, log)
+
+	called := false
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		called = true
+		req := &pb.FetchRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		for i := 0; i < 3; i++ {
+			if err := stream.SendMsg(&pb.Message{Subject: req.Subject, Data: make([]byte, 100)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/EgressService/Fetch", IsServerStream: true}
+
+	stream := &mockServerStream{req: &pb.FetchRequest{Subject: "orders", DurableName: "billing", BatchSize: 10}}
+	if err := interceptor(nil, stream, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatal("expected the handler to be called")
+	}
+	if len(charged) != 2 || charged[0].Cost != 3 || charged[1].Cost != 300 {
+		t.Errorf("expected 3 messages and 300 bytes to be charged, got %+v", charged)
+	}
+
+	charged = nil
+	wait = 1500 * time.Millisecond
+	err := interceptor(nil, stream, info, handler)
+	st, _ := status.FromError(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if charged != nil {
+		t.Error("expected nothing to be charged for a rejected fetch")
+	}
+	if got := stream.trailer.Get("retry-after"); len(got) != 1 || got[0] != "2" {
+		t.Errorf("expected retry-after trailer of 2 seconds, got %v", got)
+	}
+
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if r, ok := d.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != wait {
+		t.Errorf("expected RetryInfo of %s, got %v", wait, st.Details())
+	}
+}
+
+func TestQuotaUnaryInterceptor(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	wait := time.Duration(0)
+	var charged []quota.Bucket
+	store := &mockQuotaStore{
+		consumeFunc: func(buckets []quota.Bucket, force bool) (time.Duration, error) {
+			if force {
+				charged = buckets
+				return 0, nil
+			}
+			return wait, nil
+		},
+	}
+	limiter := quota.NewLimiter(&quota.Policy{Default: quota.Limit{MessagesPerSecond: 10, BytesPerSecond: 1000}}, store, "fetch")
+	interceptor := QuotaUnaryInterceptor(limiter, nil, log)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.FetchRangeResponse{Subject: "logs", Data: make([]byte, 64)}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.EgressService_FetchRange_FullMethodName}
+	req := &pb.FetchRangeRequest{Subject: "logs", Sequence: 7}
+
+	if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(charged) != 2 || charged[0].Cost != 1 || charged[1].Cost != 64 {
+		t.Errorf("expected 1 message and 64 bytes to be charged, got %+v", charged)
+	}
+
+	// Other unary methods are not quota'd
+	charged = nil
+	wait = time.Second
+	ack := &grpc.UnaryServerInfo{FullMethod: "/EgressService/AckMessage"}
+	if _, err := interceptor(context.Background(), &pb.AckRequest{Subject: "logs"}, ack, handler); err != nil {
+		t.Fatalf("unexpected error for AckMessage: %v", err)
+	}
+
+	if _, err := interceptor(context.Background(), req, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if charged != nil {
+		t.Error("expected nothing to be charged for a rejected read")
+	}
+}
@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// GetMessage implements the GetMessage RPC method
+func (h *EgressHandler) GetMessage(ctx context.Context, req *pb.GetMessageRequest) (*pb.Message, error) {
+	storedSubject, authenticated, err := h.readAccess(ctx, "GetMessage", req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *entity.Message
+	if req.SequenceKind == pb.SequenceKind_SEQUENCE_KIND_SUBJECT {
+		msg, err = h.messageUC.GetMessageBySubjectSequence(ctx, storedSubject, req.Sequence)
+	} else {
+		msg, err = h.messageUC.GetMessage(ctx, storedSubject, req.Sequence)
+	}
+	if err != nil {
+		return nil, h.readError("GetMessage", req.Subject, err)
+	}
+	if err := h.preparePayload(ctx, msg, authenticated, acceptedEncodings(ctx)); err != nil {
+		return nil, err
+	}
+
+	return readMessage(req.Subject, msg), nil
+}
+
+// ReadRange implements the ReadRange RPC method
+func (h *EgressHandler) ReadRange(ctx context.Context, req *pb.ReadRangeRequest) (*pb.ReadRangeResponse, error) {
+	if req.ToSequence > 0 && req.ToSequence < req.FromSequence {
+		return nil, status.Errorf(codes.InvalidArgument, "to_sequence %d is before from_sequence %d", req.ToSequence, req.FromSequence)
+	}
+
+	storedSubject, authenticated, err := h.readAccess(ctx, "ReadRange", req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*entity.Message
+	if req.SequenceKind == pb.SequenceKind_SEQUENCE_KIND_SUBJECT {
+		messages, err = h.messageUC.ReadBySubjectSequence(ctx, storedSubject, req.FromSequence, req.ToSequence, int(req.Limit))
+	} else {
+		messages, err = h.messageUC.ReadRange(ctx, storedSubject, req.FromSequence, req.ToSequence, int(req.Limit))
+	}
+	if err != nil {
+		return nil, h.readError("ReadRange", req.Subject, err)
+	}
+
+	// The whole range is prepared before anything is returned, so a client never gets a partial range
+	accepted := acceptedEncodings(ctx)
+	resp := &pb.ReadRangeResponse{Messages: make([]*pb.Message, 0, len(messages))}
+	for _, msg := range messages {
+		if err := h.preparePayload(ctx, msg, authenticated, accepted); err != nil {
+			return nil, err
+		}
+		resp.Messages = append(resp.Messages, readMessage(req.Subject, msg))
+	}
+	return resp, nil
+}
+
+// FetchRange implements the FetchRange RPC method
+func (h *EgressHandler) FetchRange(ctx context.Context, req *pb.FetchRangeRequest) (*pb.FetchRangeResponse, error) {
+	storedSubject, _, err := h.readAccess(ctx, "FetchRange", req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence := req.Sequence
+	if req.SequenceKind == pb.SequenceKind_SEQUENCE_KIND_SUBJECT {
+		if sequence, err = h.messageUC.ResolveSubjectSequence(ctx, storedSubject, req.Sequence); err != nil {
+			return nil, h.readError("FetchRange", req.Subject, err)
+		}
+	}
+
+	result, err := h.messageUC.FetchRange(ctx, storedSubject, sequence, req.Offset, req.Length)
+	if err != nil {
+		return nil, h.readError("FetchRange", req.Subject, err)
+	}
+
+	return &pb.FetchRangeResponse{
+		Subject:   req.Subject,
+		Sequence:  result.Sequence,
+		Offset:    result.Offset,
+		Data:      result.Data,
+		TotalSize: result.TotalSize,
+	}, nil
+}
+
+// readAccess checks a stateless read of subj like Fetch does and returns the stored subject
+// and whether the client is authenticated, which encrypted payloads require
+func (h *EgressHandler) readAccess(ctx context.Context, method, subj string) (string, bool, error) {
+	// Subjects outside the grammar could be mistaken for claim patterns, so check before access
+	if err := subject.Validate(subj); err != nil {
+		return "", false, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	claims, authenticated := auth.GetClaimsFromContext(ctx)
+	if authenticated {
+		if err := claims.ValidateFetchAccess(subj); err != nil {
+			h.logger.Warn("Read permission denied",
+				logger.String("method", method),
+				logger.String("subject", subj),
+				logger.String("client_id", claims.ClientID),
+				logger.Error(err),
+			)
+			return "", false, status.Errorf(codes.PermissionDenied, "fetch permission denied")
+		}
+	}
+
+	storedSubject, _, err := h.tenants.scope(ctx, subj, "")
+	if err != nil {
+		return "", false, status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return storedSubject, authenticated, nil
+}
+
+// readError maps use case errors of stateless reads to gRPC statuses
+func (h *EgressHandler) readError(method, subj string, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrMessageNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidRange):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, entity.ErrPayloadCorrupted):
+		return status.Errorf(codes.DataLoss, "%s failed: %v", method, err)
+	case errors.Is(err, entity.ErrPayloadEncoded):
+		return status.Errorf(codes.FailedPrecondition, "%v, read the message with GetMessage", err)
+	}
+	h.logger.Error("Read failed",
+		logger.String("method", method),
+		logger.String("subject", subj),
+		logger.Error(err),
+	)
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
+
+// readMessage converts a prepared message, named by the subject the client asked for
+func readMessage(subj string, msg *entity.Message) *pb.Message {
+	return &pb.Message{
+		Subject:         subj,
+		Sequence:        msg.Sequence,
+		Data:            msg.Data,
+		Headers:         msg.Headers,
+		Timestamp:       timestamppb.New(msg.Timestamp),
+		SubjectSequence: msg.SubjectSequence,
+	}
+}
@@ -0,0 +1,402 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// staticKeyManager hands out one fixed data key, standing in for Vault transit
+type staticKeyManager struct{}
+
+func (staticKeyManager) GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error) {
+	return bytes.Repeat([]byte{7}, 32), "vault:v1:wrapped", nil
+}
+
+func (staticKeyManager) DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error) {
+	return bytes.Repeat([]byte{7}, 32), nil
+}
+
+func newReadHandler(t *testing.T, msgRepo *mockMessageRepository, storageRepo *mockStorageRepository) *EgressHandler {
+	t.Helper()
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := usecase.NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+	uc.SetEnvelope(encryption.NewEnvelope(staticKeyManager{}, "minitoolstream", nil))
+	return NewEgressHandler(uc, log)
+}
+
+func withClaims(claims *auth.Claims) context.Context {
+	return context.WithValue(context.Background(), auth.ClaimsContextKey{}, claims)
+}
+
+func TestEgressHandler_FetchRange(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			if sequence != 7 {
+				return nil, entity.ErrMessageNotFound
+			}
+			return &entity.Message{Sequence: 7, Subject: "videos", ObjectName: "videos_7", Timestamp: time.Now()}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			return []byte("0123456789")[offset : offset+length], 10, nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+
+	resp, err := handler.FetchRange(context.Background(), &pb.FetchRangeRequest{Subject: "videos", Sequence: 7, Offset: 2, Length: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Data) != "234" || resp.TotalSize != 10 || resp.Offset != 2 || resp.Subject != "videos" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	// Reading the sequence through another subject must not reveal it
+	_, err = handler.FetchRange(context.Background(), &pb.FetchRangeRequest{Subject: "audio", Sequence: 7, Length: 1})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for another subject, got %v", err)
+	}
+	_, err = handler.FetchRange(context.Background(), &pb.FetchRangeRequest{Subject: "videos", Sequence: 7, Offset: -1})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected OutOfRange for a negative offset, got %v", err)
+	}
+	_, err = handler.FetchRange(context.Background(), &pb.FetchRangeRequest{Subject: "videos.*", Sequence: 7})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a wildcard subject, got %v", err)
+	}
+}
+
+func TestEgressHandler_FetchRange_Access(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "$TENANT.acme.videos", ObjectName: "videos_1", Timestamp: time.Now()}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			return []byte("x"), 1, nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+	handler.SetTenants(NewTenants(nil))
+	req := &pb.FetchRangeRequest{Subject: "videos", Sequence: 1}
+
+	denied := withClaims(&auth.Claims{ClientID: "acme/player", Permissions: []string{"fetch"}, AllowedSubjects: []string{"audio.*"}})
+	if _, err := handler.FetchRange(denied, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied outside the allowed subjects, got %v", err)
+	}
+
+	// The client reads its tenant's subject under the name it published with
+	allowed := withClaims(&auth.Claims{ClientID: "acme/player", Permissions: []string{"fetch"}, AllowedSubjects: []string{"videos"}})
+	resp, err := handler.FetchRange(allowed, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Subject != "videos" {
+		t.Errorf("expected the client's subject in the response, got %q", resp.Subject)
+	}
+
+	// Without a tenant the stored subject is a different one
+	other := withClaims(&auth.Claims{ClientID: "player", Permissions: []string{"fetch"}, AllowedSubjects: []string{"videos"}})
+	if _, err := handler.FetchRange(other, req); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for another tenant, got %v", err)
+	}
+}
+
+func TestEgressHandler_FetchRange_EncodedPayload(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{
+				Sequence:   sequence,
+				Subject:    "payments",
+				ObjectName: "payments_1",
+				Headers: map[string]string{
+					"encryption":          "aes-256-gcm",
+					"encryption-key":      "payments",
+					"encryption-data-key": "vault:v1:wrapped",
+				},
+				Timestamp: time.Now(),
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			t.Error("an encoded payload must not be downloaded to serve a range")
+			return []byte("ciphertext"), nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+
+	ctx := withClaims(&auth.Claims{ClientID: "ledger", Permissions: []string{"fetch"}, AllowedSubjects: []string{"payments"}})
+	_, err := handler.FetchRange(ctx, &pb.FetchRangeRequest{Subject: "payments", Sequence: 1})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestEgressHandler_GetMessage(t *testing.T) {
+	payload := []byte("0123456789abcdefghij0123456789abcdefghij")
+	compressed, err := compression.Compress(compression.Gzip, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	envelope := encryption.NewEnvelope(staticKeyManager{}, "minitoolstream", nil)
+	sealed, headers, err := envelope.Seal(context.Background(), "payments", []byte("payments/9"), compressed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	headers["content-encoding"] = "gzip"
+
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			if sequence != 9 {
+				return nil, entity.ErrMessageNotFound
+			}
+			return &entity.Message{
+				Sequence:        9,
+				SubjectSequence: 3,
+				Subject:         "payments",
+				ObjectName:      "payments/9",
+				Headers:         headers,
+				Timestamp:       time.Now(),
+			}, nil
+		},
+		updateConsumerPositionFunc: func(ctx context.Context, durableName, subject string, sequence uint64) error {
+			t.Fatal("GetMessage must not move consumer cursors")
+			return nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return sealed, nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+	req := &pb.GetMessageRequest{Subject: "payments", Sequence: 9}
+
+	msg, err := handler.GetMessage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Data != nil || msg.Headers["encryption"] == "" {
+		t.Errorf("expected an unauthenticated client to get metadata only, got %q", msg.Data)
+	}
+
+	ctx := withClaims(&auth.Claims{ClientID: "ledger", Permissions: []string{"fetch"}, AllowedSubjects: []string{"payments"}})
+	if msg, err = handler.GetMessage(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(msg.Data, payload) {
+		t.Errorf("expected the decrypted and decoded payload, got %q", msg.Data)
+	}
+	if _, ok := msg.Headers["encryption"]; ok {
+		t.Error("encryption headers must be dropped for opened payloads")
+	}
+	if msg.SubjectSequence != 3 {
+		t.Errorf("expected subject sequence 3, got %d", msg.SubjectSequence)
+	}
+
+	// A client decoding gzip itself gets the decrypted but still compressed payload
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("accept-content-encoding", "gzip"))
+	if msg, err = handler.GetMessage(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(msg.Data, compressed) || msg.Headers["content-encoding"] != "gzip" {
+		t.Error("expected the compressed payload to be passed through")
+	}
+
+	if _, err := handler.GetMessage(ctx, &pb.GetMessageRequest{Subject: "payments", Sequence: 10}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	denied := withClaims(&auth.Claims{ClientID: "ledger", Permissions: []string{"fetch"}, AllowedSubjects: []string{"orders"}})
+	if _, err := handler.GetMessage(denied, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied outside the allowed subjects, got %v", err)
+	}
+}
+
+func TestEgressHandler_GetMessage_CorruptedPayload(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{
+				Sequence:   sequence,
+				Subject:    "sensors",
+				ObjectName: "sensors_1",
+				Headers:    map[string]string{"payload-sha256": strings.Repeat("0", 64)},
+				Timestamp:  time.Now(),
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("damaged"), nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+
+	_, err := handler.GetMessage(context.Background(), &pb.GetMessageRequest{Subject: "sensors", Sequence: 1})
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("expected DataLoss, got %v", err)
+	}
+}
+
+func TestEgressHandler_ReadRange(t *testing.T) {
+	payload := []byte("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	compressed, err := compression.Compress(compression.Zstd, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var gotSubject string
+	var gotFrom, gotTo uint64
+	msgRepo := &mockMessageRepository{
+		getMessagesRangeFunc: func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+			gotSubject, gotFrom, gotTo = subject, fromSeq, toSeq
+			return []*entity.Message{
+				{Sequence: 5, Subject: subject, ObjectName: "logs_5", Headers: map[string]string{"content-encoding": "zstd"}, Timestamp: time.Now()},
+				{Sequence: 8, Subject: subject, Timestamp: time.Now()},
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return compressed, nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+	handler.SetTenants(NewTenants(nil))
+
+	ctx := withClaims(&auth.Claims{ClientID: "acme/reader", Permissions: []string{"fetch"}, AllowedSubjects: []string{"logs"}})
+	resp, err := handler.ReadRange(ctx, &pb.ReadRangeRequest{Subject: "logs", FromSequence: 5, ToSequence: 9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSubject != "$TENANT.acme.logs" || gotFrom != 5 || gotTo != 9 {
+		t.Errorf("unexpected range read: %s %d-%d", gotSubject, gotFrom, gotTo)
+	}
+	if len(resp.Messages) != 2 || resp.Messages[0].Subject != "logs" || resp.Messages[1].Sequence != 8 {
+		t.Fatalf("unexpected messages: %+v", resp.Messages)
+	}
+	if !bytes.Equal(resp.Messages[0].Data, payload) {
+		t.Error("expected the decoded payload")
+	}
+
+	if _, err := handler.ReadRange(ctx, &pb.ReadRangeRequest{Subject: "logs", FromSequence: 9, ToSequence: 5}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an inverted range, got %v", err)
+	}
+}
+
+func TestEgressHandler_ReadRange_EncryptedWithoutAuthentication(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessagesRangeFunc: func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{
+				{Sequence: 1, Subject: subject, Timestamp: time.Now()},
+				{Sequence: 2, Subject: subject, ObjectName: "payments_2", Headers: map[string]string{
+					"encryption":          "aes-256-gcm",
+					"encryption-key":      "payments",
+					"encryption-data-key": "vault:v1:wrapped",
+				}, Timestamp: time.Now()},
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("ciphertext"), nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+
+	resp, err := handler.ReadRange(context.Background(), &pb.ReadRangeRequest{Subject: "payments", FromSequence: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Messages) != 2 {
+		t.Fatalf("expected the whole range, got %d messages", len(resp.Messages))
+	}
+	if encrypted := resp.Messages[1]; encrypted.Data != nil || encrypted.Sequence != 2 {
+		t.Errorf("expected the encrypted message without its payload, got %q", encrypted.Data)
+	}
+}
+
+func TestEgressHandler_Reads_SubjectSequences(t *testing.T) {
+	stored := map[uint64]*entity.Message{
+		40: {Sequence: 40, SubjectSequence: 2, Subject: "test.single", Timestamp: time.Now()},
+		57: {Sequence: 57, SubjectSequence: 3, Subject: "test.single", ObjectName: "test.single_57", Timestamp: time.Now()},
+	}
+	var gotStart uint64
+	msgRepo := &mockMessageRepository{
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			for _, msg := range stored {
+				if msg.SubjectSequence == subjectSeq {
+					return msg.Sequence, nil
+				}
+			}
+			return 0, nil
+		},
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			if msg, ok := stored[sequence]; ok {
+				copied := *msg
+				return &copied, nil
+			}
+			return nil, entity.ErrMessageNotFound
+		},
+		getMessagesBySubjectSeqFunc: func(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error) {
+			gotStart = startSubjectSeq
+			first, second := *stored[40], *stored[57]
+			return []*entity.Message{&first, &second}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("0123456789"), nil
+		},
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			return []byte("0123456789")[offset : offset+length], 10, nil
+		},
+	}
+	handler := newReadHandler(t, msgRepo, storageRepo)
+	ctx := context.Background()
+	bySubject := pb.SequenceKind_SEQUENCE_KIND_SUBJECT
+
+	msg, err := handler.GetMessage(ctx, &pb.GetMessageRequest{Subject: "test.single", Sequence: 3, SequenceKind: bySubject})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Sequence != 57 || msg.SubjectSequence != 3 {
+		t.Errorf("expected the message with subject sequence 3, got %+v", msg)
+	}
+	if _, err := handler.GetMessage(ctx, &pb.GetMessageRequest{Subject: "test.single", Sequence: 4, SequenceKind: bySubject}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown subject sequence, got %v", err)
+	}
+
+	resp, err := handler.ReadRange(ctx, &pb.ReadRangeRequest{Subject: "test.single", FromSequence: 2, ToSequence: 2, SequenceKind: bySubject})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotStart != 2 || len(resp.Messages) != 1 || resp.Messages[0].SubjectSequence != 2 {
+		t.Errorf("expected the range to stop at subject sequence 2, got %+v from %d", resp.Messages, gotStart)
+	}
+
+	part, err := handler.FetchRange(ctx, &pb.FetchRangeRequest{Subject: "test.single", Sequence: 3, Offset: 4, Length: 2, SequenceKind: bySubject})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.Sequence != 57 || string(part.Data) != "45" {
+		t.Errorf("unexpected range: %+v", part)
+	}
+}
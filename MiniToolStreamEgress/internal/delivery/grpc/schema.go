@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// SchemaHandler implements the lookups of the gRPC SchemaService
+// Schemas are registered through ingress, the Authorizer checks fetch access to the subject
+type SchemaHandler struct {
+	pb.UnimplementedSchemaServiceServer
+	schemaUC *usecase.SchemaUseCase
+	logger   *logger.Logger
+	tenants  *Tenants
+}
+
+// NewSchemaHandler creates a new SchemaService handler
+func NewSchemaHandler(schemaUC *usecase.SchemaUseCase, log *logger.Logger) *SchemaHandler {
+	return &SchemaHandler{
+		schemaUC: schemaUC,
+		logger:   log,
+	}
+}
+
+// SetTenants scopes subjects per tenant of the client, nil disables it
+// Schemas of imported subjects are read from the exporting tenant
+func (h *SchemaHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// GetSchema implements the GetSchema RPC method
+func (h *SchemaHandler) GetSchema(ctx context.Context, req *pb.GetSchemaRequest) (*pb.Schema, error) {
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *entity.Schema
+	if req.Id != 0 {
+		s, err = h.schemaUC.GetSchemaByID(ctx, req.Id)
+		// IDs are global, a schema of another subject is not revealed
+		if err == nil && s.Subject != storedSubject {
+			err = entity.ErrSchemaNotFound
+		}
+	} else {
+		s, err = h.schemaUC.GetSchema(ctx, storedSubject, req.Version)
+	}
+	if err != nil {
+		return nil, h.toStatus("GetSchema", err)
+	}
+
+	return &pb.Schema{
+		Id:          s.ID,
+		Subject:     req.Subject,
+		Version:     s.Version,
+		Format:      s.Format,
+		Definition:  s.Definition,
+		MessageType: s.MessageType,
+		CreatedAt:   timestamppb.New(s.CreatedAt),
+	}, nil
+}
+
+// ListSchemaVersions implements the ListSchemaVersions RPC method
+func (h *SchemaHandler) ListSchemaVersions(ctx context.Context, req *pb.ListSchemaVersionsRequest) (*pb.ListSchemaVersionsResponse, error) {
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := h.schemaUC.ListSchemaVersions(ctx, storedSubject)
+	if err != nil {
+		return nil, h.toStatus("ListSchemaVersions", err)
+	}
+	return &pb.ListSchemaVersionsResponse{Versions: versions}, nil
+}
+
+// scope validates the subject a client named and returns its stored name
+func (h *SchemaHandler) scope(ctx context.Context, subj string) (string, error) {
+	// Subjects outside the grammar could name subjects of other tenants
+	if err := subject.Validate(subj); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	storedSubject, _, err := h.tenants.scope(ctx, subj, "")
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return storedSubject, nil
+}
+
+// toStatus maps use case errors to gRPC statuses
+func (h *SchemaHandler) toStatus(method string, err error) error {
+	switch {
+	case errors.Is(err, subject.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entity.ErrSchemaNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	h.logger.Error("Schema request failed", logger.String("method", method), logger.Error(err))
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// memorySchemaRepository keeps schema versions in memory
+type memorySchemaRepository struct {
+	schemas []*entity.Schema
+}
+
+func (m *memorySchemaRepository) GetSchemaByID(ctx context.Context, id uint64) (*entity.Schema, error) {
+	for _, s := range m.schemas {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func (m *memorySchemaRepository) GetSchema(ctx context.Context, subject string, version uint64) (*entity.Schema, error) {
+	var found *entity.Schema
+	for _, s := range m.schemas {
+		if s.Subject == subject && (version == 0 || s.Version == version) {
+			found = s
+		}
+	}
+	if found == nil {
+		return nil, entity.ErrSchemaNotFound
+	}
+	return found, nil
+}
+
+func (m *memorySchemaRepository) ListSchemaVersions(ctx context.Context, subject string) ([]uint64, error) {
+	versions := []uint64{}
+	for _, s := range m.schemas {
+		if s.Subject == subject {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions, nil
+}
+
+func newSchemaHandler(repo *memorySchemaRepository) *SchemaHandler {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	return NewSchemaHandler(usecase.NewSchemaUseCase(repo, log), log)
+}
+
+func TestSchemaHandler(t *testing.T) {
+	repo := &memorySchemaRepository{schemas: []*entity.Schema{
+		{ID: 3, Subject: "$TENANT.acme.orders", Version: 1, Format: "json", Definition: []byte(`{}`), CreatedAt: time.Unix(1_700_000_000, 0)},
+		{ID: 5, Subject: "$TENANT.acme.orders", Version: 2, Format: "json", Definition: []byte(`{"type":"object"}`)},
+		{ID: 6, Subject: "$TENANT.globex.orders", Version: 1, Format: "json"},
+	}}
+	handler := newSchemaHandler(repo)
+	handler.SetTenants(NewTenants(nil))
+	reader := withClaims(&auth.Claims{ClientID: "acme/reader", Permissions: []string{"fetch"}, AllowedSubjects: []string{"*"}})
+
+	latest, err := handler.GetSchema(reader, &pb.GetSchemaRequest{Subject: "orders"})
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if latest.Id != 5 || latest.Version != 2 || latest.Subject != "orders" || string(latest.Definition) != `{"type":"object"}` {
+		t.Errorf("unexpected schema: %+v", latest)
+	}
+
+	first, err := handler.GetSchema(reader, &pb.GetSchemaRequest{Subject: "orders", Id: 3})
+	if err != nil || first.Version != 1 || !first.CreatedAt.AsTime().Equal(time.Unix(1_700_000_000, 0)) {
+		t.Fatalf("unexpected schema %+v, %v", first, err)
+	}
+	// IDs are global, they must not reveal schemas of other tenants
+	if _, err := handler.GetSchema(reader, &pb.GetSchemaRequest{Subject: "orders", Id: 6}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a schema of another tenant, got %v", err)
+	}
+	if _, err := handler.GetSchema(reader, &pb.GetSchemaRequest{Subject: "orders", Version: 9}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a missing version, got %v", err)
+	}
+	if _, err := handler.GetSchema(reader, &pb.GetSchemaRequest{Subject: "$TENANT.globex.orders"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a stored name, got %v", err)
+	}
+
+	listed, err := handler.ListSchemaVersions(reader, &pb.ListSchemaVersionsRequest{Subject: "orders"})
+	if err != nil || len(listed.Versions) != 2 || listed.Versions[0] != 1 || listed.Versions[1] != 2 {
+		t.Fatalf("unexpected versions %+v, %v", listed, err)
+	}
+
+	if _, err := handler.RegisterSchema(reader, &pb.RegisterSchemaRequest{Subject: "orders"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected schemas to be registered through ingress, got %v", err)
+	}
+}
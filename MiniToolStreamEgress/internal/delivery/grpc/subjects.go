@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// SubjectHandler implements the gRPC SubjectService
+// Every method requires the admin permission
+type SubjectHandler struct {
+	pb.UnimplementedSubjectServiceServer
+	subjectUC *usecase.SubjectUseCase
+	logger    *logger.Logger
+	tenants   *Tenants
+}
+
+// NewSubjectHandler creates a new SubjectService handler
+func NewSubjectHandler(subjectUC *usecase.SubjectUseCase, log *logger.Logger) *SubjectHandler {
+	return &SubjectHandler{
+		subjectUC: subjectUC,
+		logger:    log,
+	}
+}
+
+// SetTenants limits admins of a tenant to the subjects of their tenant, nil disables it
+// Admins of the default tenant see stored names, including those of other tenants
+func (h *SubjectHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// ListSubjects implements the ListSubjects RPC method
+func (h *SubjectHandler) ListSubjects(ctx context.Context, req *pb.ListSubjectsRequest) (*pb.ListSubjectsResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "ListSubjects")
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, after := req.Pattern, req.After
+	if tenant != "" {
+		if pattern == "" {
+			pattern = subject.Wildcard
+		}
+		if err := subject.ValidatePattern(pattern); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		pattern = subject.Qualify(tenant, pattern)
+		if after != "" {
+			after = subject.Qualify(tenant, after)
+		}
+	}
+
+	page, err := h.subjectUC.ListSubjects(ctx, pattern, after, int(req.Limit))
+	if err != nil {
+		return nil, h.toStatus("ListSubjects", err)
+	}
+
+	resp := &pb.ListSubjectsResponse{Subjects: make([]*pb.SubjectInfo, 0, len(page.Subjects))}
+	for _, info := range page.Subjects {
+		resp.Subjects = append(resp.Subjects, toSubjectInfo(info, clientName(tenant, info.Subject)))
+	}
+	if page.Next != "" {
+		resp.Next = clientName(tenant, page.Next)
+	}
+	return resp, nil
+}
+
+// GetSubjectInfo implements the GetSubjectInfo RPC method
+func (h *SubjectHandler) GetSubjectInfo(ctx context.Context, req *pb.GetSubjectInfoRequest) (*pb.SubjectInfo, error) {
+	tenant, err := h.requireAdmin(ctx, "GetSubjectInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	name := req.Subject
+	if tenant != "" {
+		if err := subject.Validate(name); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		name = subject.Qualify(tenant, name)
+	}
+
+	info, err := h.subjectUC.GetSubjectInfo(ctx, name)
+	if err != nil {
+		return nil, h.toStatus("GetSubjectInfo", err)
+	}
+	return toSubjectInfo(info, req.Subject), nil
+}
+
+// requireAdmin rejects callers without the admin permission and returns their tenant
+// The Authorizer lets unauthenticated requests through when auth.require_auth
+// is off, the catalogue must stay closed to them regardless
+func (h *SubjectHandler) requireAdmin(ctx context.Context, method string) (string, error) {
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "%s requires an authenticated client", method)
+	}
+	if !claims.CheckPermission(authz.PermissionAdmin) {
+		h.logger.Warn("Subject catalogue access denied",
+			logger.String("method", method),
+			logger.String("client_id", claims.ClientID),
+		)
+		return "", status.Errorf(codes.PermissionDenied, "%s requires the %s permission", method, authz.PermissionAdmin)
+	}
+
+	if h.tenants == nil {
+		return "", nil
+	}
+	tenant, err := clientTenant(claims.ClientID)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return tenant, nil
+}
+
+// toStatus maps use case errors to gRPC statuses
+func (h *SubjectHandler) toStatus(method string, err error) error {
+	switch {
+	case errors.Is(err, subject.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entity.ErrSubjectNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	h.logger.Error("Subject service request failed", logger.String("method", method), logger.Error(err))
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
+
+// clientName returns the name a client of tenant uses for a stored subject
+func clientName(tenant, stored string) string {
+	if tenant == "" {
+		return stored
+	}
+	_, name := subject.Unqualify(stored)
+	return name
+}
+
+// toSubjectInfo describes info under the name the client uses
+func toSubjectInfo(info *entity.SubjectInfo, name string) *pb.SubjectInfo {
+	return &pb.SubjectInfo{
+		Subject:             name,
+		FirstSequence:       info.FirstSequence,
+		LastSequence:        info.LastSequence,
+		MessageCount:        info.MessageCount,
+		TotalBytes:          info.TotalBytes,
+		FirstPublishAt:      timestamppb.New(info.FirstPublishAt),
+		LastPublishAt:       timestamppb.New(info.LastPublishAt),
+		LastSubjectSequence: info.LastSubjectSequence,
+	}
+}
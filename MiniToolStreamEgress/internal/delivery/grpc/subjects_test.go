@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+type mockSubjectRepository struct {
+	listSubjectsFunc   func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error)
+	getSubjectInfoFunc func(ctx context.Context, subject string) (*entity.SubjectInfo, error)
+}
+
+func (m *mockSubjectRepository) ListSubjects(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+	if m.listSubjectsFunc != nil {
+		return m.listSubjectsFunc(ctx, pattern, after, limit)
+	}
+	return &entity.SubjectPage{}, nil
+}
+
+func (m *mockSubjectRepository) GetSubjectInfo(ctx context.Context, subject string) (*entity.SubjectInfo, error) {
+	if m.getSubjectInfoFunc != nil {
+		return m.getSubjectInfoFunc(ctx, subject)
+	}
+	return nil, entity.ErrSubjectNotFound
+}
+
+func newSubjectHandler(repo *mockSubjectRepository) *SubjectHandler {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	return NewSubjectHandler(usecase.NewSubjectUseCase(repo, log), log)
+}
+
+func TestSubjectHandler_RequiresAdmin(t *testing.T) {
+	handler := newSubjectHandler(&mockSubjectRepository{})
+
+	if _, err := handler.ListSubjects(context.Background(), &pb.ListSubjectsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without claims, got %v", err)
+	}
+
+	reader := withClaims(&auth.Claims{ClientID: "reader", Permissions: []string{"fetch"}, AllowedSubjects: []string{"*"}})
+	if _, err := handler.ListSubjects(reader, &pb.ListSubjectsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without the admin permission, got %v", err)
+	}
+	if _, err := handler.GetSubjectInfo(reader, &pb.GetSubjectInfoRequest{Subject: "orders"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without the admin permission, got %v", err)
+	}
+}
+
+func TestSubjectHandler_ListSubjects(t *testing.T) {
+	var gotPattern, gotAfter string
+	repo := &mockSubjectRepository{
+		listSubjectsFunc: func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+			gotPattern, gotAfter = pattern, after
+			return &entity.SubjectPage{
+				Subjects: []*entity.SubjectInfo{{Subject: "orders.eu", MessageCount: 3, LastSequence: 42}},
+				Next:     "orders.eu",
+			}, nil
+		},
+	}
+	handler := newSubjectHandler(repo)
+	admin := withClaims(&auth.Claims{ClientID: "operator", Permissions: []string{"admin"}})
+
+	resp, err := handler.ListSubjects(admin, &pb.ListSubjectsRequest{Pattern: "orders.*", Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPattern != "orders.*" || gotAfter != "" {
+		t.Errorf("unexpected listing of %q after %q", gotPattern, gotAfter)
+	}
+	if len(resp.Subjects) != 1 || resp.Subjects[0].Subject != "orders.eu" || resp.Subjects[0].MessageCount != 3 || resp.Next != "orders.eu" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if _, err := handler.ListSubjects(admin, &pb.ListSubjectsRequest{Pattern: "orders*"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a malformed pattern, got %v", err)
+	}
+}
+
+func TestSubjectHandler_ListSubjects_Tenant(t *testing.T) {
+	var gotPattern, gotAfter string
+	repo := &mockSubjectRepository{
+		listSubjectsFunc: func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+			gotPattern, gotAfter = pattern, after
+			return &entity.SubjectPage{
+				Subjects: []*entity.SubjectInfo{{Subject: "$TENANT.acme.orders"}},
+				Next:     "$TENANT.acme.orders",
+			}, nil
+		},
+	}
+	handler := newSubjectHandler(repo)
+	handler.SetTenants(NewTenants(nil))
+	admin := withClaims(&auth.Claims{ClientID: "acme/ops", Permissions: []string{"admin"}})
+
+	resp, err := handler.ListSubjects(admin, &pb.ListSubjectsRequest{After: "invoices"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPattern != "$TENANT.acme.*" || gotAfter != "$TENANT.acme.invoices" {
+		t.Errorf("expected the listing to stay in the tenant, got %q after %q", gotPattern, gotAfter)
+	}
+	if resp.Subjects[0].Subject != "orders" || resp.Next != "orders" {
+		t.Errorf("expected names as the tenant uses them, got %+v", resp)
+	}
+
+	// Tenant admins cannot name stored subjects of other tenants
+	if _, err := handler.ListSubjects(admin, &pb.ListSubjectsRequest{Pattern: "$TENANT.globex.*"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestSubjectHandler_GetSubjectInfo(t *testing.T) {
+	published := time.Unix(1_700_000_000, 0).UTC()
+	repo := &mockSubjectRepository{
+		getSubjectInfoFunc: func(ctx context.Context, subject string) (*entity.SubjectInfo, error) {
+			if subject != "$TENANT.acme.orders" {
+				return nil, entity.ErrSubjectNotFound
+			}
+			return &entity.SubjectInfo{Subject: subject, MessageCount: 7, LastPublishAt: published, LastSubjectSequence: 7}, nil
+		},
+	}
+	handler := newSubjectHandler(repo)
+	handler.SetTenants(NewTenants(nil))
+
+	tenantAdmin := withClaims(&auth.Claims{ClientID: "acme/ops", Permissions: []string{"admin"}})
+	info, err := handler.GetSubjectInfo(tenantAdmin, &pb.GetSubjectInfoRequest{Subject: "orders"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Subject != "orders" || info.MessageCount != 7 || !info.LastPublishAt.AsTime().Equal(published) || info.LastSubjectSequence != 7 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	// Admins of the default tenant use stored names
+	operator := withClaims(&auth.Claims{ClientID: "operator", Permissions: []string{"admin"}})
+	if info, err = handler.GetSubjectInfo(operator, &pb.GetSubjectInfoRequest{Subject: "$TENANT.acme.orders"}); err != nil || info.MessageCount != 7 {
+		t.Errorf("unexpected info %+v, %v", info, err)
+	}
+
+	if _, err := handler.GetSubjectInfo(operator, &pb.GetSubjectInfoRequest{Subject: "orders"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	if _, err := handler.GetSubjectInfo(operator, &pb.GetSubjectInfoRequest{Subject: "orders.*"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
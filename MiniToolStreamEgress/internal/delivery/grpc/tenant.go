@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// tenantSeparator ends the tenant part of a JWT client_id, e.g. "acme/loader"
+const tenantSeparator = "/"
+
+// TenantImport lets clients of Tenant read subjects of From matching Subject
+// as "{Prefix}.{subject}". An empty tenant is the default tenant
+type TenantImport struct {
+	Tenant  string
+	From    string
+	Subject string
+	Prefix  string
+}
+
+// Tenants scopes subjects and durable names of authenticated clients per tenant
+// Clients without a tenant in their client_id and unauthenticated clients
+// belong to the default tenant, whose names are stored unqualified
+type Tenants struct {
+	imports []TenantImport
+}
+
+// NewTenants creates tenant scoping with the given imports
+func NewTenants(imports []TenantImport) *Tenants {
+	return &Tenants{imports: imports}
+}
+
+// scope returns the stored names of subj and durable for the client in ctx
+// Imported subjects resolve to the exporting tenant, the consumer stays with the client's tenant.
+// A nil Tenants keeps every name as it is
+func (t *Tenants) scope(ctx context.Context, subj, durable string) (string, string, error) {
+	storedSubject, durable, _, err := t.scopeImport(ctx, subj, durable)
+	return storedSubject, durable, err
+}
+
+// scopeImport is scope that also reports whether subj is imported from another tenant
+// Clients only read imported subjects, they must not change what the exporter retains
+func (t *Tenants) scopeImport(ctx context.Context, subj, durable string) (string, string, bool, error) {
+	if t == nil {
+		return subj, durable, false, nil
+	}
+	// Durable names are not bound by the subject grammar, so one could name another tenant's consumer
+	if strings.HasPrefix(durable, subject.TenantPrefix) {
+		return "", "", false, fmt.Errorf("durable_name cannot start with %q", subject.TenantPrefix)
+	}
+
+	var tenant string
+	if claims, ok := auth.GetClaimsFromContext(ctx); ok {
+		var err error
+		if tenant, err = clientTenant(claims.ClientID); err != nil {
+			return "", "", false, err
+		}
+	}
+
+	if durable != "" {
+		durable = subject.Qualify(tenant, durable)
+	}
+	storedSubject, imported := t.resolve(tenant, subj)
+	return storedSubject, durable, imported, nil
+}
+
+// resolve returns the stored name of a subject as seen by tenant and whether it is imported
+func (t *Tenants) resolve(tenant, subj string) (string, bool) {
+	for _, imp := range t.imports {
+		if imp.Tenant != tenant {
+			continue
+		}
+		rest, ok := strings.CutPrefix(subj, imp.Prefix+".")
+		if ok && subject.Match(imp.Subject, rest) {
+			return subject.Qualify(imp.From, rest), true
+		}
+	}
+	return subject.Qualify(tenant, subj), false
+}
+
+// clientTenant returns the tenant part of a client_id, "" for the default tenant
+func clientTenant(clientID string) (string, error) {
+	tenant, _, ok := strings.Cut(clientID, tenantSeparator)
+	if !ok {
+		return "", nil
+	}
+	if err := subject.ValidateTenant(tenant); err != nil {
+		return "", err
+	}
+	return tenant, nil
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClientTenant(t *testing.T) {
+	tests := []struct {
+		clientID string
+		want     string
+		wantErr  bool
+	}{
+		{"loader", "", false},
+		{"acme/loader", "acme", false},
+		{"/loader", "", true},
+		{"Acme/loader", "", true},
+	}
+	for _, tt := range tests {
+		got, err := clientTenant(tt.clientID)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("clientTenant(%q) = %q, %v; want %q, error %v", tt.clientID, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTenants_Resolve(t *testing.T) {
+	tenants := NewTenants([]TenantImport{
+		{Tenant: "globex", From: "acme", Subject: "orders.*", Prefix: "acme"},
+		{Tenant: "", From: "acme", Subject: "*", Prefix: "partners.acme"},
+	})
+
+	tests := []struct {
+		tenant, subject, want string
+		imported              bool
+	}{
+		{"acme", "orders.42", "$TENANT.acme.orders.42", false},
+		{"globex", "orders.42", "$TENANT.globex.orders.42", false},
+		{"globex", "acme.orders.42", "$TENANT.acme.orders.42", true},
+		{"globex", "acme.invoices.42", "$TENANT.globex.acme.invoices.42", false},
+		{"initech", "acme.orders.42", "$TENANT.initech.acme.orders.42", false},
+		{"", "orders.42", "orders.42", false},
+		{"", "partners.acme.invoices", "$TENANT.acme.invoices", true},
+	}
+	for _, tt := range tests {
+		if got, imported := tenants.resolve(tt.tenant, tt.subject); got != tt.want || imported != tt.imported {
+			t.Errorf("resolve(%q, %q) = %q, %v; want %q, %v", tt.tenant, tt.subject, got, imported, tt.want, tt.imported)
+		}
+	}
+}
+
+func TestTenants_Scope_Durable(t *testing.T) {
+	tenants := NewTenants(nil)
+
+	subj, durable, err := tenants.scope(context.Background(), "orders", "billing")
+	if err != nil || subj != "orders" || durable != "billing" {
+		t.Errorf("default tenant: got %q, %q, %v", subj, durable, err)
+	}
+	if _, _, err := tenants.scope(context.Background(), "orders", "$TENANT.acme.billing"); err == nil {
+		t.Error("expected error for a durable name of another tenant")
+	}
+
+	var disabled *Tenants
+	if _, durable, err := disabled.scope(context.Background(), "orders", "$TENANT.acme.billing"); err != nil || durable != "$TENANT.acme.billing" {
+		t.Errorf("nil Tenants: got %q, %v", durable, err)
+	}
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "errors"
+
+var (
+	// ErrMessageNotFound is returned when a message does not exist in the requested subject
+	ErrMessageNotFound = errors.New("message not found")
+
+	// ErrInvalidRange is returned when a payload range cannot be satisfied
+	ErrInvalidRange = errors.New("invalid payload range")
+
+	// ErrSubjectNotFound is returned when a subject is not in the catalogue
+	ErrSubjectNotFound = errors.New("subject not found")
+
+	// ErrSchemaNotFound is returned when a schema is not in the registry
+	ErrSchemaNotFound = errors.New("schema not found")
+
+	// ErrPayloadCorrupted is returned when a stored payload does not match its checksum
+	ErrPayloadCorrupted = errors.New("payload corrupted")
+
+	// ErrPayloadEncoded is returned when a byte range is requested of a payload stored compressed or encrypted
+	ErrPayloadEncoded = errors.New("payload is stored compressed or encrypted, byte ranges are not supported")
+
+	// ErrConsumerNotFound is returned when a durable consumer does not exist
+	ErrConsumerNotFound = errors.New("consumer not found")
+
+	// ErrNotConsumerOwner is returned when a durable consumer is owned by another client
+	ErrNotConsumerOwner = errors.New("durable consumer is owned by another client")
+)
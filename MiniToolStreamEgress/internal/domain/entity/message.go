@@ -10,6 +10,17 @@ type Message struct {
 	Headers    map[string]string
 	ObjectName string
 	Timestamp  time.Time
+	// SubjectSequence is the dense per-subject sequence (1, 2, 3, ...)
+	SubjectSequence uint64
+	// ExpiresAt is the per-message expiry time, zero if the message does not expire
+	ExpiresAt time.Time
+	// Inline is set when the payload is stored in Tarantool and Data is already loaded
+	Inline bool
+}
+
+// Expired reports whether the message outlived its own expiry time
+func (m *Message) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
 }
 
 // Consumer represents a durable consumer entity
@@ -24,3 +35,32 @@ type Notification struct {
 	Subject  string
 	Sequence uint64
 }
+
+// PayloadRange represents a slice of a message payload read from storage
+type PayloadRange struct {
+	Subject   string
+	Sequence  uint64
+	Offset    int64
+	Data      []byte
+	TotalSize int64
+}
+
+// SubjectInfo represents a subject catalogue entry with its statistics
+type SubjectInfo struct {
+	Subject        string
+	FirstSequence  uint64
+	LastSequence   uint64
+	MessageCount   uint64
+	TotalBytes     uint64
+	FirstPublishAt time.Time
+	LastPublishAt  time.Time
+	// LastSubjectSequence is the last allocated per-subject sequence
+	LastSubjectSequence uint64
+}
+
+// SubjectPage represents a page of subjects returned by a listing
+type SubjectPage struct {
+	Subjects []*SubjectInfo
+	// Next is the cursor for the following page, empty on the last page
+	Next string
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Schema is a registered payload schema version of a subject
+// Messages validated against it carry its ID and Version in the
+// schema-id and schema-version headers
+type Schema struct {
+	ID      uint64
+	Subject string
+	Version uint64
+	// Format is "json" (JSON Schema) or "protobuf" (serialized FileDescriptorSet)
+	Format     string
+	Definition []byte
+	// MessageType is the fully-qualified protobuf message name, empty for JSON
+	MessageType string
+	CreatedAt   time.Time
+}
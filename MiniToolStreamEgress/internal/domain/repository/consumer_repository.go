@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+)
+
+// ConsumerRepository defines the interface for durable consumer ownership
+type ConsumerRepository interface {
+	// ClaimConsumer reports whether clientID may use a consumer and returns its owner
+	// A consumer without an owner, including a new one, becomes owned by clientID
+	ClaimConsumer(ctx context.Context, durableName, subject, clientID string) (bool, string, error)
+
+	// TransferConsumer replaces the owner and the other allowed clients of a consumer
+	TransferConsumer(ctx context.Context, durableName, subject, owner string, allowedClients []string) error
+}
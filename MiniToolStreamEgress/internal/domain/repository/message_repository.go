@@ -14,12 +14,25 @@ type MessageRepository interface {
 	// UpdateConsumerPosition updates the last read sequence for a durable consumer
 	UpdateConsumerPosition(ctx context.Context, durableName, subject string, lastSequence uint64) error
 
+	// AckMessage moves a durable consumer position and applies the subject retention mode
+	// Returns messages deleted by interest or work-queue retention so their payloads can be removed
+	AckMessage(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error)
+
 	// GetLatestSequenceForSubject returns the latest sequence number for a subject
 	GetLatestSequenceForSubject(ctx context.Context, subject string) (uint64, error)
 
 	// GetMessagesBySubject fetches messages for a subject starting from a sequence
 	GetMessagesBySubject(ctx context.Context, subject string, startSequence uint64, limit int) ([]*entity.Message, error)
 
+	// GetMessagesRange fetches messages of a subject between two sequences (inclusive, toSequence 0 = unbounded)
+	GetMessagesRange(ctx context.Context, subject string, fromSequence, toSequence uint64, limit int) ([]*entity.Message, error)
+
+	// GetMessagesBySubjectSequence fetches messages of a subject using the per-subject sequence as cursor
+	GetMessagesBySubjectSequence(ctx context.Context, subject string, startSubjectSequence uint64, limit int) ([]*entity.Message, error)
+
+	// ResolveSubjectSequence translates a per-subject sequence to the global sequence (0 if not found)
+	ResolveSubjectSequence(ctx context.Context, subject string, subjectSequence uint64) (uint64, error)
+
 	// GetMessageBySequence gets a single message by its sequence number
 	GetMessageBySequence(ctx context.Context, sequence uint64) (*entity.Message, error)
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+)
+
+// SchemaRepository defines the interface for schema registry lookups
+type SchemaRepository interface {
+	// GetSchemaByID returns the schema a message was validated against
+	GetSchemaByID(ctx context.Context, id uint64) (*entity.Schema, error)
+
+	// GetSchema returns a schema version of a subject, version 0 means the latest
+	GetSchema(ctx context.Context, subject string, version uint64) (*entity.Schema, error)
+
+	// ListSchemaVersions returns the registered versions of a subject, oldest first
+	ListSchemaVersions(ctx context.Context, subject string) ([]uint64, error)
+}
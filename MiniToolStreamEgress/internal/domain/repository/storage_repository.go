@@ -7,6 +7,13 @@ type StorageRepository interface {
 	// GetObject downloads data from object storage
 	GetObject(ctx context.Context, subject string, objectName string) ([]byte, error)
 
+	// GetObjectRange downloads length bytes starting at offset (length 0 reads to the end)
+	// and returns them together with the total object size
+	GetObjectRange(ctx context.Context, subject string, objectName string, offset, length int64) ([]byte, int64, error)
+
+	// DeleteObject removes an object from storage
+	DeleteObject(ctx context.Context, subject string, objectName string) error
+
 	// GetObjectURL returns the URL for accessing an object
 	GetObjectURL(subject string, objectName string) string
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+)
+
+// SubjectRepository defines the interface for subject catalogue operations
+type SubjectRepository interface {
+	// ListSubjects returns subjects matching pattern in name order, starting after the given cursor
+	ListSubjects(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error)
+
+	// GetSubjectInfo returns statistics for a single subject
+	GetSubjectInfo(ctx context.Context, subject string) (*entity.SubjectInfo, error)
+}
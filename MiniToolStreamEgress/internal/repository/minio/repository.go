@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 
-	pkglogger "github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	pkglogger "github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 )
 
 // Repository implements domain.StorageRepository using MinIO
@@ -26,6 +29,8 @@ type Config struct {
 	SecretAccessKey string
 	UseSSL          bool
 	BucketName      string
+	// TenantBuckets lists tenants whose payloads are in a bucket of their own
+	TenantBuckets []string
 }
 
 // NewRepository creates a new MinIO repository
@@ -59,9 +64,19 @@ func normalizeBucketName(subject string) string {
 	return bucketName
 }
 
+// bucketFor returns the bucket an object is stored in
+// Object names of a tenant are qualified, so the name alone selects the bucket
+func (r *Repository) bucketFor(objectName string) string {
+	tenant, _ := subject.Unqualify(objectName)
+	if tenant != "" && slices.Contains(r.config.TenantBuckets, tenant) {
+		return normalizeBucketName(r.config.BucketName + "." + tenant)
+	}
+	return r.config.BucketName
+}
+
 // GetObject downloads data from MinIO
 func (r *Repository) GetObject(ctx context.Context, subject string, objectName string) ([]byte, error) {
-	bucketName := r.config.BucketName
+	bucketName := r.bucketFor(objectName)
 
 	r.logger.Debug("Getting object from MinIO",
 		pkglogger.String("bucket", bucketName),
@@ -90,9 +105,84 @@ func (r *Repository) GetObject(ctx context.Context, subject string, objectName s
 	return data, nil
 }
 
+// GetObjectRange downloads a byte range of an object from MinIO
+// length == 0 reads from offset to the end of the object
+func (r *Repository) GetObjectRange(ctx context.Context, subject string, objectName string, offset, length int64) ([]byte, int64, error) {
+	bucketName := r.bucketFor(objectName)
+
+	if offset < 0 || length < 0 {
+		return nil, 0, fmt.Errorf("%w: offset=%d length=%d", entity.ErrInvalidRange, offset, length)
+	}
+
+	// Stat first: ranged GET responses only carry the size of the slice
+	info, err := r.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat object: %w", err)
+	}
+	totalSize := info.Size
+
+	if offset > totalSize {
+		return nil, totalSize, fmt.Errorf("%w: offset %d exceeds object size %d", entity.ErrInvalidRange, offset, totalSize)
+	}
+	if offset == totalSize {
+		// Nothing left to read (e.g. resuming a completed download)
+		return []byte{}, totalSize, nil
+	}
+
+	end := totalSize - 1
+	if length > 0 && offset+length-1 < end {
+		end = offset + length - 1
+	}
+
+	r.logger.Debug("Getting object range from MinIO",
+		pkglogger.String("bucket", bucketName),
+		pkglogger.String("object", objectName),
+		pkglogger.Int64("offset", offset),
+		pkglogger.Int64("end", end),
+	)
+
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(offset, end); err != nil {
+		return nil, totalSize, fmt.Errorf("%w: %v", entity.ErrInvalidRange, err)
+	}
+	// Guard against the object being replaced between Stat and Get
+	if err := opts.SetMatchETag(info.ETag); err != nil {
+		return nil, totalSize, fmt.Errorf("failed to set etag condition: %w", err)
+	}
+
+	obj, err := r.client.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, totalSize, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, totalSize, fmt.Errorf("failed to read object data: %w", err)
+	}
+
+	return data, totalSize, nil
+}
+
+// DeleteObject removes an object from MinIO
+func (r *Repository) DeleteObject(ctx context.Context, subject string, objectName string) error {
+	bucketName := r.bucketFor(objectName)
+
+	r.logger.Debug("Deleting object from MinIO",
+		pkglogger.String("bucket", bucketName),
+		pkglogger.String("object", objectName),
+	)
+
+	if err := r.client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+
+	return nil
+}
+
 // GetObjectURL returns the URL for accessing an object
 func (r *Repository) GetObjectURL(subject string, objectName string) string {
-	bucketName := r.config.BucketName
+	bucketName := r.bucketFor(objectName)
 	protocol := "http"
 	if r.config.UseSSL {
 		protocol = "https"
@@ -9,7 +9,9 @@ import (
 	"github.com/tarantool/go-tarantool/v2"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStream/pkg/revocation"
 )
 
 // Repository implements domain.MessageRepository using Tarantool
@@ -131,6 +133,64 @@ func (r *Repository) UpdateConsumerPosition(ctx context.Context, durableName, su
 	return nil
 }
 
+// AckMessage moves a durable consumer position and applies the subject retention mode
+// Returns messages deleted by interest or work-queue retention
+func (r *Repository) AckMessage(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+	resp, err := r.call("ack_message", []interface{}{durableName, subject, sequence})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ack message: %w", err)
+	}
+
+	deleted := []*entity.Message{}
+	if len(resp) == 0 {
+		return deleted, nil
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		infoMap, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		deleted = append(deleted, &entity.Message{
+			Sequence:   toUint64(infoMap["sequence"]),
+			Subject:    toString(infoMap["subject"]),
+			ObjectName: toString(infoMap["object_name"]),
+		})
+	}
+
+	return deleted, nil
+}
+
+// ClaimConsumer reports whether clientID may use a durable consumer and returns its owner
+func (r *Repository) ClaimConsumer(ctx context.Context, durableName, subject, clientID string) (bool, string, error) {
+	resp, err := r.call("claim_consumer", []interface{}{durableName, subject, clientID})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to claim consumer: %w", err)
+	}
+	if len(resp) < 2 {
+		return false, "", fmt.Errorf("invalid response format")
+	}
+
+	allowed, _ := resp[0].(bool)
+	return allowed, toString(resp[1]), nil
+}
+
+// TransferConsumer replaces the owner and the other allowed clients of a durable consumer
+func (r *Repository) TransferConsumer(ctx context.Context, durableName, subject, owner string, allowedClients []string) error {
+	if allowedClients == nil {
+		allowedClients = []string{}
+	}
+	resp, err := r.call("transfer_consumer", []interface{}{durableName, subject, owner, allowedClients})
+	if err != nil {
+		return fmt.Errorf("failed to transfer consumer: %w", err)
+	}
+	if len(resp) == 0 || resp[0] == nil {
+		return entity.ErrConsumerNotFound
+	}
+	return nil
+}
+
 // GetLatestSequenceForSubject returns the latest sequence number for a subject
 func (r *Repository) GetLatestSequenceForSubject(ctx context.Context, subject string) (uint64, error) {
 	resp, err := r.call("get_latest_sequence_for_subject", []interface{}{subject})
@@ -156,10 +216,255 @@ func (r *Repository) GetMessagesBySubject(ctx context.Context, subject string, s
 		return []*entity.Message{}, nil
 	}
 
-	// Parse response - it's an array of tuples
+	return parseMessageTuples(resp), nil
+}
+
+// GetMessagesRange reads messages of a subject between two sequences (inclusive)
+// toSequence == 0 means the range is bounded by limit only
+func (r *Repository) GetMessagesRange(ctx context.Context, subject string, fromSequence, toSequence uint64, limit int) ([]*entity.Message, error) {
+	resp, err := r.call("get_messages_range", []interface{}{subject, fromSequence, toSequence, limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages range: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return []*entity.Message{}, nil
+	}
+
+	return parseMessageTuples(resp), nil
+}
+
+// GetMessagesBySubjectSequence fetches messages of a subject using the per-subject sequence as cursor
+func (r *Repository) GetMessagesBySubjectSequence(ctx context.Context, subject string, startSubjectSequence uint64, limit int) ([]*entity.Message, error) {
+	resp, err := r.call("get_messages_by_subject_sequence", []interface{}{subject, startSubjectSequence, limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages by subject sequence: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return []*entity.Message{}, nil
+	}
+
+	return parseMessageTuples(resp), nil
+}
+
+// ResolveSubjectSequence translates a per-subject sequence to the global sequence
+// Returns 0 if the message does not exist
+func (r *Repository) ResolveSubjectSequence(ctx context.Context, subject string, subjectSequence uint64) (uint64, error) {
+	resp, err := r.call("resolve_subject_sequence", []interface{}{subject, subjectSequence})
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve subject sequence: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, nil
+	}
+
+	return toUint64(resp[0]), nil
+}
+
+// GetMessageBySequence gets a single message by its sequence number
+func (r *Repository) GetMessageBySequence(ctx context.Context, sequence uint64) (*entity.Message, error) {
+	resp, err := r.call("get_message_by_sequence_decoded", []interface{}{sequence})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrMessageNotFound
+	}
+
+	// Parse the response map
+	msgMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+
+	msg := &entity.Message{
+		Sequence:   toUint64(msgMap["sequence"]),
+		Headers:    parseHeaders(msgMap["headers"]),
+		ObjectName: toString(msgMap["object_name"]),
+		Subject:    toString(msgMap["subject"]),
+		Timestamp:  time.Unix(int64(toUint64(msgMap["create_at"])), 0),
+
+		SubjectSequence: toUint64(msgMap["subject_sequence"]),
+		ExpiresAt:       parseExpiresAt(msgMap["expires_at"]),
+	}
+	msg.Data, msg.Inline = parsePayload(msgMap["payload"])
+
+	return msg, nil
+}
+
+// ListSubjects returns subjects matching pattern in name order, starting after the given cursor
+func (r *Repository) ListSubjects(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+	resp, err := r.call("list_subjects", []interface{}{pattern, after, limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subjects: %w", err)
+	}
+
+	page := &entity.SubjectPage{Subjects: []*entity.SubjectInfo{}}
+	if len(resp) == 0 {
+		return page, nil
+	}
+
+	pageMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+
+	if items, ok := pageMap["subjects"].([]interface{}); ok {
+		for _, item := range items {
+			if infoMap, ok := item.(map[interface{}]interface{}); ok {
+				page.Subjects = append(page.Subjects, parseSubjectInfo(infoMap))
+			}
+		}
+	}
+	page.Next = toString(pageMap["next"])
+
+	return page, nil
+}
+
+// GetSubjectInfo returns statistics for a single subject
+func (r *Repository) GetSubjectInfo(ctx context.Context, subject string) (*entity.SubjectInfo, error) {
+	resp, err := r.call("get_subject_info", []interface{}{subject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject info: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrSubjectNotFound
+	}
+
+	infoMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+
+	return parseSubjectInfo(infoMap), nil
+}
+
+// GetSchemaByID returns the schema a message was validated against
+func (r *Repository) GetSchemaByID(ctx context.Context, id uint64) (*entity.Schema, error) {
+	resp, err := r.call("get_schema_by_id", []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return parseSchemaResponse(resp)
+}
+
+// GetSchema returns a schema version of a subject, version 0 means the latest
+func (r *Repository) GetSchema(ctx context.Context, subject string, version uint64) (*entity.Schema, error) {
+	resp, err := r.call("get_schema", []interface{}{subject, version})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return parseSchemaResponse(resp)
+}
+
+// ListSchemaVersions returns the registered versions of a subject, oldest first
+func (r *Repository) ListSchemaVersions(ctx context.Context, subject string) ([]uint64, error) {
+	resp, err := r.call("list_schema_versions", []interface{}{subject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema versions: %w", err)
+	}
+
+	versions := []uint64{}
+	if len(resp) == 0 {
+		return versions, nil
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		versions = append(versions, toUint64(item))
+	}
+
+	return versions, nil
+}
+
+// CheckRevocation looks up whether a token or its client is revoked
+func (r *Repository) CheckRevocation(jti, clientID string) (revocation.Status, error) {
+	resp, err := r.call("check_revocation", []interface{}{jti, clientID})
+	if err != nil {
+		return revocation.Status{}, fmt.Errorf("failed to check revocation: %w", err)
+	}
+
+	if len(resp) < 2 {
+		return revocation.Status{}, fmt.Errorf("invalid response format")
+	}
+
+	status := revocation.Status{}
+	status.TokenRevoked, _ = resp[0].(bool)
+	if notBefore := toUint64(resp[1]); notBefore > 0 {
+		status.ClientNotBefore = time.Unix(int64(notBefore), 0)
+	}
+	return status, nil
+}
+
+// ConsumeQuota draws from token buckets and returns how long to wait if any of them is short
+// With force the buckets are charged anyway
+func (r *Repository) ConsumeQuota(buckets []quota.Bucket, force bool) (time.Duration, error) {
+	args := make([]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		args = append(args, []interface{}{b.Key, b.Rate, b.Cost})
+	}
+
+	resp, err := r.call("consume_quota", []interface{}{args, force})
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, fmt.Errorf("empty response from Tarantool")
+	}
+
+	return time.Duration(toFloat64(resp[0]) * float64(time.Second)), nil
+}
+
+// parseSchemaResponse converts a get_schema / get_schema_by_id response
+func parseSchemaResponse(resp []interface{}) (*entity.Schema, error) {
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrSchemaNotFound
+	}
+
+	schemaMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+
+	definition, _ := parsePayload(schemaMap["definition"])
+	return &entity.Schema{
+		ID:          toUint64(schemaMap["id"]),
+		Subject:     toString(schemaMap["subject"]),
+		Version:     toUint64(schemaMap["version"]),
+		Format:      toString(schemaMap["format"]),
+		Definition:  definition,
+		MessageType: toString(schemaMap["message_type"]),
+		CreatedAt:   time.Unix(int64(toUint64(schemaMap["create_at"])), 0),
+	}, nil
+}
+
+// parseSubjectInfo converts a msgpack-decoded subject info map
+func parseSubjectInfo(infoMap map[interface{}]interface{}) *entity.SubjectInfo {
+	return &entity.SubjectInfo{
+		Subject:        toString(infoMap["subject"]),
+		FirstSequence:  toUint64(infoMap["first_sequence"]),
+		LastSequence:   toUint64(infoMap["last_sequence"]),
+		MessageCount:   toUint64(infoMap["message_count"]),
+		TotalBytes:     toUint64(infoMap["total_bytes"]),
+		FirstPublishAt: time.Unix(int64(toUint64(infoMap["first_publish_at"])), 0),
+		LastPublishAt:  time.Unix(int64(toUint64(infoMap["last_publish_at"])), 0),
+
+		LastSubjectSequence: toUint64(infoMap["last_subject_sequence"]),
+	}
+}
+
+// parseMessageTuples converts a Call17 response holding an array of message tuples
+func parseMessageTuples(resp []interface{}) []*entity.Message {
 	tuples, ok := resp[0].([]interface{})
 	if !ok {
-		return []*entity.Message{}, nil
+		return []*entity.Message{}
 	}
 
 	messages := make([]*entity.Message, 0, len(tuples))
@@ -169,51 +474,53 @@ func (r *Repository) GetMessagesBySubject(ctx context.Context, subject string, s
 			continue
 		}
 
-		// Parse headers
-		headers := make(map[string]string)
-		if headersRaw, ok := tuple[1].(map[interface{}]interface{}); ok {
-			for k, v := range headersRaw {
-				if keyStr, ok := k.(string); ok {
-					if valStr, ok := v.(string); ok {
-						headers[keyStr] = valStr
-					}
-				}
-			}
-		}
-
 		msg := &entity.Message{
 			Sequence:   toUint64(tuple[0]),
-			Headers:    headers,
+			Headers:    parseHeaders(tuple[1]),
 			ObjectName: toString(tuple[2]),
 			Subject:    toString(tuple[3]),
 			Timestamp:  time.Unix(int64(toUint64(tuple[4])), 0),
 		}
+		if len(tuple) > 5 {
+			msg.SubjectSequence = toUint64(tuple[5])
+		}
+		if len(tuple) > 6 {
+			msg.ExpiresAt = parseExpiresAt(tuple[6])
+		}
+		if len(tuple) > 7 {
+			msg.Data, msg.Inline = parsePayload(tuple[7])
+		}
 		messages = append(messages, msg)
 	}
 
-	return messages, nil
+	return messages
 }
 
-// GetMessageBySequence gets a single message by its sequence number
-func (r *Repository) GetMessageBySequence(ctx context.Context, sequence uint64) (*entity.Message, error) {
-	resp, err := r.call("get_message_by_sequence_decoded", []interface{}{sequence})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get message: %w", err)
-	}
-
-	if len(resp) == 0 {
-		return nil, fmt.Errorf("message not found")
+// parseExpiresAt converts the nullable expires_at field, zero time means no expiry
+func parseExpiresAt(val interface{}) time.Time {
+	if unix := toUint64(val); unix > 0 {
+		return time.Unix(int64(unix), 0)
 	}
+	return time.Time{}
+}
 
-	// Parse the response map
-	msgMap, ok := resp[0].(map[interface{}]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid response format")
+// parsePayload converts the nullable inline payload field
+// Binary call arguments reach Tarantool as strings, so both encodings are accepted
+func parsePayload(val interface{}) ([]byte, bool) {
+	switch v := val.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
 	}
+}
 
-	// Parse headers
+// parseHeaders converts a msgpack-decoded map into message headers
+func parseHeaders(val interface{}) map[string]string {
 	headers := make(map[string]string)
-	if headersRaw, ok := msgMap["headers"].(map[interface{}]interface{}); ok {
+	if headersRaw, ok := val.(map[interface{}]interface{}); ok {
 		for k, v := range headersRaw {
 			if keyStr, ok := k.(string); ok {
 				if valStr, ok := v.(string); ok {
@@ -222,16 +529,7 @@ func (r *Repository) GetMessageBySequence(ctx context.Context, sequence uint64)
 			}
 		}
 	}
-
-	msg := &entity.Message{
-		Sequence:   toUint64(msgMap["sequence"]),
-		Headers:    headers,
-		ObjectName: toString(msgMap["object_name"]),
-		Subject:    toString(msgMap["subject"]),
-		Timestamp:  time.Unix(int64(toUint64(msgMap["create_at"])), 0),
-	}
-
-	return msg, nil
+	return headers
 }
 
 // Helper function for type conversion to uint64
@@ -266,6 +564,18 @@ func toUint64(val interface{}) uint64 {
 	}
 }
 
+// Helper function for fractional numbers; whole numbers may be encoded as integers
+func toFloat64(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	default:
+		return float64(toUint64(v))
+	}
+}
+
 // Helper function for type conversion to string
 func toString(val interface{}) string {
 	if s, ok := val.(string); ok {
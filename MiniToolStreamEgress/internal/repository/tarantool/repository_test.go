@@ -3,7 +3,7 @@ package tarantool
 import (
 	"testing"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
 func TestNewRepository_NilConfig(t *testing.T) {
@@ -174,3 +174,39 @@ func TestRepository_Close_AlreadyClosed(t *testing.T) {
 		t.Errorf("expected no error when closing already closed repository, got: %v", err)
 	}
 }
+
+func TestParseMessageTuples_ExpiresAt(t *testing.T) {
+	resp := []interface{}{[]interface{}{
+		[]interface{}{uint64(1), map[interface{}]interface{}{}, "a_1", "a", uint64(100), uint64(1), nil},
+		[]interface{}{uint64(2), map[interface{}]interface{}{}, "a_2", "a", uint64(100), uint64(2), uint64(1772366405)},
+	}}
+
+	messages := parseMessageTuples(resp)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if !messages[0].ExpiresAt.IsZero() {
+		t.Errorf("expected no expiry for message 1, got %v", messages[0].ExpiresAt)
+	}
+	if messages[1].ExpiresAt.Unix() != 1772366405 {
+		t.Errorf("expected expiry 1772366405, got %v", messages[1].ExpiresAt.Unix())
+	}
+}
+
+func TestParseMessageTuples_InlinePayload(t *testing.T) {
+	resp := []interface{}{[]interface{}{
+		[]interface{}{uint64(1), map[interface{}]interface{}{}, "", "a", uint64(100), uint64(1), nil, `{"id":1}`},
+		[]interface{}{uint64(2), map[interface{}]interface{}{}, "a_2", "a", uint64(100), uint64(2), nil, nil},
+	}}
+
+	messages := parseMessageTuples(resp)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if !messages[0].Inline || string(messages[0].Data) != `{"id":1}` {
+		t.Errorf("expected inline payload for message 1, got %q", messages[0].Data)
+	}
+	if messages[1].Inline || messages[1].Data != nil {
+		t.Error("expected message 2 payload to stay in storage")
+	}
+}
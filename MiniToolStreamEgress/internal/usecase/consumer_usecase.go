@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/repository"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// ConsumerUseCase handles ownership of durable consumers
+type ConsumerUseCase struct {
+	consumerRepo repository.ConsumerRepository
+	logger       *logger.Logger
+}
+
+// NewConsumerUseCase creates a new consumer use case
+func NewConsumerUseCase(consumerRepo repository.ConsumerRepository, logger *logger.Logger) *ConsumerUseCase {
+	return &ConsumerUseCase{
+		consumerRepo: consumerRepo,
+		logger:       logger,
+	}
+}
+
+// CheckConsumerOwner returns entity.ErrNotConsumerOwner if clientID may not use a consumer
+// The first client to use a consumer becomes its owner
+func (uc *ConsumerUseCase) CheckConsumerOwner(ctx context.Context, durableName, subjectName, clientID string) error {
+	allowed, owner, err := uc.consumerRepo.ClaimConsumer(ctx, durableName, subjectName, clientID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", entity.ErrNotConsumerOwner, owner)
+	}
+	return nil
+}
+
+// TransferConsumer hands a consumer over to owner, other allowedClients may still use it
+// It is an administrative operation: callers must check the admin permission
+func (uc *ConsumerUseCase) TransferConsumer(ctx context.Context, durableName, subjectName, owner string, allowedClients []string) error {
+	if durableName == "" {
+		return fmt.Errorf("durable_name cannot be empty")
+	}
+	if err := subject.ValidateQualified(subjectName); err != nil {
+		return err
+	}
+	if owner == "" {
+		return fmt.Errorf("owner cannot be empty")
+	}
+	for _, clientID := range allowedClients {
+		if clientID == "" {
+			return fmt.Errorf("allowed client IDs cannot be empty")
+		}
+	}
+
+	if err := uc.consumerRepo.TransferConsumer(ctx, durableName, subjectName, owner, allowedClients); err != nil {
+		return fmt.Errorf("failed to transfer consumer: %w", err)
+	}
+
+	uc.logger.Info("Consumer ownership transferred",
+		logger.String("durable_name", durableName),
+		logger.String("subject", subjectName),
+		logger.String("owner", owner),
+		logger.Int("allowed_clients", len(allowedClients)),
+	)
+	return nil
+}
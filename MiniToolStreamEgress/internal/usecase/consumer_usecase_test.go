@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+type mockConsumerRepository struct {
+	claimFunc    func(ctx context.Context, durableName, subject, clientID string) (bool, string, error)
+	transferFunc func(ctx context.Context, durableName, subject, owner string, allowedClients []string) error
+}
+
+func (m *mockConsumerRepository) ClaimConsumer(ctx context.Context, durableName, subject, clientID string) (bool, string, error) {
+	if m.claimFunc != nil {
+		return m.claimFunc(ctx, durableName, subject, clientID)
+	}
+	return true, clientID, nil
+}
+
+func (m *mockConsumerRepository) TransferConsumer(ctx context.Context, durableName, subject, owner string, allowedClients []string) error {
+	if m.transferFunc != nil {
+		return m.transferFunc(ctx, durableName, subject, owner, allowedClients)
+	}
+	return nil
+}
+
+func TestConsumerUseCase_CheckConsumerOwner(t *testing.T) {
+	repo := &mockConsumerRepository{
+		claimFunc: func(ctx context.Context, durableName, subject, clientID string) (bool, string, error) {
+			return clientID == "billing-service" || clientID == "billing-backfill", "billing-service", nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := NewConsumerUseCase(repo, log)
+
+	if err := uc.CheckConsumerOwner(context.Background(), "billing", "orders", "billing-service"); err != nil {
+		t.Errorf("unexpected error for the owner: %v", err)
+	}
+	if err := uc.CheckConsumerOwner(context.Background(), "billing", "orders", "billing-backfill"); err != nil {
+		t.Errorf("unexpected error for an allowed client: %v", err)
+	}
+	if err := uc.CheckConsumerOwner(context.Background(), "billing", "orders", "reporting"); !errors.Is(err, entity.ErrNotConsumerOwner) {
+		t.Errorf("expected ErrNotConsumerOwner, got %v", err)
+	}
+}
+
+func TestConsumerUseCase_TransferConsumer(t *testing.T) {
+	var gotOwner string
+	var gotAllowed []string
+	repo := &mockConsumerRepository{
+		transferFunc: func(ctx context.Context, durableName, subject, owner string, allowedClients []string) error {
+			if durableName == "missing" {
+				return entity.ErrConsumerNotFound
+			}
+			gotOwner, gotAllowed = owner, allowedClients
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := NewConsumerUseCase(repo, log)
+
+	if err := uc.TransferConsumer(context.Background(), "billing", "$TENANT.acme.orders", "billing-v2", []string{"billing-service"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotOwner != "billing-v2" || len(gotAllowed) != 1 || gotAllowed[0] != "billing-service" {
+		t.Errorf("unexpected transfer: owner=%q allowed=%v", gotOwner, gotAllowed)
+	}
+
+	if err := uc.TransferConsumer(context.Background(), "missing", "orders", "billing-v2", nil); !errors.Is(err, entity.ErrConsumerNotFound) {
+		t.Errorf("expected ErrConsumerNotFound, got %v", err)
+	}
+
+	invalid := []struct {
+		durable, subject, owner string
+		allowed                 []string
+	}{
+		{"", "orders", "billing-v2", nil},
+		{"billing", "orders..eu", "billing-v2", nil},
+		{"billing", "orders", "", nil},
+		{"billing", "orders", "billing-v2", []string{""}},
+	}
+	for _, tt := range invalid {
+		if err := uc.TransferConsumer(context.Background(), tt.durable, tt.subject, tt.owner, tt.allowed); err == nil {
+			t.Errorf("expected error for %+v", tt)
+		}
+	}
+}
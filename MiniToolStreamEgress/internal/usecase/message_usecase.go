@@ -2,20 +2,34 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/repository"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 )
 
+// headerPayloadSHA256 carries the hex SHA-256 of the stored payload written by ingress
+const headerPayloadSHA256 = "payload-sha256"
+
+// maxReadRangeLimit caps the number of messages returned by a single ReadRange call
+const maxReadRangeLimit = 1000
+
 // MessageUseCase handles business logic for message operations
 type MessageUseCase struct {
 	messageRepo  repository.MessageRepository
 	storageRepo  repository.StorageRepository
 	logger       *logger.Logger
 	pollInterval time.Duration
+	envelope     *encryption.Envelope
 }
 
 // NewMessageUseCase creates a new message use case
@@ -33,6 +47,11 @@ func NewMessageUseCase(
 	}
 }
 
+// SetEnvelope enables decryption of payloads encrypted at rest, nil disables it
+func (uc *MessageUseCase) SetEnvelope(envelope *encryption.Envelope) {
+	uc.envelope = envelope
+}
+
 // Subscribe polls for new messages and sends notifications
 func (uc *MessageUseCase) Subscribe(
 	ctx context.Context,
@@ -138,7 +157,7 @@ func (uc *MessageUseCase) FetchMessages(
 	)
 
 	// Fetch messages from repository
-	messages, err := uc.messageRepo.GetMessagesBySubject(ctx, subject, lastSequence+1, batchSize)
+	messages, err := uc.fetchUnexpired(ctx, subject, lastSequence+1, batchSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch messages: %w", err)
 	}
@@ -146,6 +165,172 @@ func (uc *MessageUseCase) FetchMessages(
 	// Load data from storage for each message
 	// IMPORTANT: Position is NOT updated here - consumer must explicitly ACK
 	// This enables At-Least-Once delivery semantics
+	if err := uc.loadPayloads(ctx, messages); err != nil {
+		// Don't return messages - client hasn't processed anything yet
+		return nil, err
+	}
+
+	uc.logger.Info("Fetched messages",
+		logger.String("subject", subject),
+		logger.Int("count", len(messages)),
+	)
+
+	return messages, nil
+}
+
+// fetchUnexpired reads messages of a subject skipping those past their own expiry
+// that cleanup has not removed yet. If a whole batch has expired it reads on,
+// so a consumer is not handed an empty batch while newer messages exist
+func (uc *MessageUseCase) fetchUnexpired(ctx context.Context, subject string, startSequence uint64, batchSize int) ([]*entity.Message, error) {
+	now := time.Now()
+	for {
+		messages, err := uc.messageRepo.GetMessagesBySubject(ctx, subject, startSequence, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(messages)
+		live := uc.dropExpired(subject, messages, now)
+		if len(live) > 0 || last < batchSize {
+			return live, nil
+		}
+
+		startSequence = messages[last-1].Sequence + 1
+	}
+}
+
+// dropExpired removes messages past their own expiry that cleanup has not removed yet
+// The slice is filtered in place
+func (uc *MessageUseCase) dropExpired(subject string, messages []*entity.Message, now time.Time) []*entity.Message {
+	live := messages[:0]
+	for _, msg := range messages {
+		if !msg.Expired(now) {
+			live = append(live, msg)
+		}
+	}
+	if skipped := len(messages) - len(live); skipped > 0 {
+		uc.logger.Debug("Skipped expired messages",
+			logger.String("subject", subject),
+			logger.Int("count", skipped),
+		)
+	}
+	return live
+}
+
+// getUnexpired returns the message stored at a sequence of a subject
+// Messages of other subjects and messages past their expiry are not found
+func (uc *MessageUseCase) getUnexpired(ctx context.Context, subject string, sequence uint64) (*entity.Message, error) {
+	msg, err := uc.messageRepo.GetMessageBySequence(ctx, sequence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message %d: %w", sequence, err)
+	}
+
+	// Sequences are global, so make sure the caller is not reading another subject
+	if msg.Subject != subject {
+		return nil, fmt.Errorf("sequence %d in subject %s: %w", sequence, subject, entity.ErrMessageNotFound)
+	}
+	// Cleanup removes expired messages periodically, until then they must not be served
+	if msg.Expired(time.Now()) {
+		return nil, fmt.Errorf("sequence %d in subject %s has expired: %w", sequence, subject, entity.ErrMessageNotFound)
+	}
+	return msg, nil
+}
+
+// inlineRange serves a range of a payload stored inline in Tarantool, which comes with the message
+func (uc *MessageUseCase) inlineRange(msg *entity.Message, result *entity.PayloadRange, length int64) (*entity.PayloadRange, error) {
+	if err := uc.verifyPayload(msg); err != nil {
+		return nil, err
+	}
+
+	totalSize := int64(len(msg.Data))
+	if result.Offset > totalSize {
+		return nil, fmt.Errorf("%w: offset %d exceeds payload size %d", entity.ErrInvalidRange, result.Offset, totalSize)
+	}
+	end := totalSize
+	if length > 0 && result.Offset+length < end {
+		end = result.Offset + length
+	}
+
+	result.Data = msg.Data[result.Offset:end]
+	result.TotalSize = totalSize
+	return result, nil
+}
+
+// DecryptPayload opens a payload encrypted at rest with the data key wrapped in its headers
+// Callers are responsible for checking the consumer may read the subject
+// Headers are rewritten to describe the decrypted payload, which may still be compressed;
+// the stored payload checksum no longer applies and is dropped
+func (uc *MessageUseCase) DecryptPayload(ctx context.Context, msg *entity.Message) error {
+	if !encryption.IsEncrypted(msg.Headers) {
+		return nil
+	}
+	if uc.envelope == nil {
+		return fmt.Errorf("payload of sequence %d is encrypted but decryption is not enabled", msg.Sequence)
+	}
+
+	// The recorded context must be the object name, or a key of the message's subject for inline payloads
+	aad := string(encryption.Context(msg.Headers, msg.ObjectName))
+	if (msg.ObjectName != "" && aad != msg.ObjectName) || !strings.HasPrefix(aad, subject.ObjectPrefix(msg.Subject)) {
+		return fmt.Errorf("payload of sequence %d is bound to another message", msg.Sequence)
+	}
+
+	data, err := uc.envelope.Open(ctx, msg.Headers, []byte(aad), msg.Data)
+	if err != nil {
+		return fmt.Errorf("payload of sequence %d: %w", msg.Sequence, err)
+	}
+
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	delete(headers, encryption.HeaderEncryption)
+	delete(headers, encryption.HeaderKeyName)
+	delete(headers, encryption.HeaderDataKey)
+	delete(headers, encryption.HeaderContext)
+	delete(headers, headerPayloadSHA256)
+	headers["data-size"] = strconv.Itoa(len(data))
+
+	msg.Data = data
+	msg.Headers = headers
+	return nil
+}
+
+// DecodePayload decompresses a payload stored with a content-encoding
+// Headers are rewritten to describe the decoded payload, without the stored payload checksum
+func (uc *MessageUseCase) DecodePayload(msg *entity.Message) error {
+	encoding := msg.Headers[compression.HeaderContentEncoding]
+	if encoding == "" {
+		return nil
+	}
+
+	alg, err := compression.ParseAlgorithm(encoding)
+	if err != nil {
+		return fmt.Errorf("payload of sequence %d: %w", msg.Sequence, err)
+	}
+	data, err := compression.Decompress(alg, msg.Data)
+	if err != nil {
+		return fmt.Errorf("payload of sequence %d: %w", msg.Sequence, err)
+	}
+
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	delete(headers, compression.HeaderContentEncoding)
+	delete(headers, compression.HeaderOriginalSize)
+	delete(headers, headerPayloadSHA256)
+	headers["data-size"] = strconv.Itoa(len(data))
+
+	msg.Data = data
+	msg.Headers = headers
+	return nil
+}
+
+// loadPayloads downloads the payload of every message that references an object
+// and verifies it against the stored checksum
+// Inline payloads come with the message and have no object
+// It stops at the first failure so that callers never hand out a partial batch
+func (uc *MessageUseCase) loadPayloads(ctx context.Context, messages []*entity.Message) error {
 	for _, msg := range messages {
 		if msg.ObjectName != "" {
 			data, err := uc.storageRepo.GetObject(ctx, msg.Subject, msg.ObjectName)
@@ -155,19 +340,192 @@ func (uc *MessageUseCase) FetchMessages(
 					logger.Uint64("sequence", msg.Sequence),
 					logger.Error(err),
 				)
-				// Don't return messages - client hasn't processed anything yet
-				return nil, fmt.Errorf("failed to fetch payload for sequence %d: %w", msg.Sequence, err)
+				return fmt.Errorf("failed to fetch payload for sequence %d: %w", msg.Sequence, err)
 			}
 			msg.Data = data
 		}
 
-		// NOTE: Position is NOT updated automatically anymore!
-		// Consumer must call AckMessage after successful processing
-		// This prevents message loss when consumer crashes during processing
+		if err := uc.verifyPayload(msg); err != nil {
+			return err
+		}
 	}
 
-	uc.logger.Info("Fetched messages",
+	return nil
+}
+
+// verifyPayload checks the stored payload against the checksum recorded by ingress
+// Messages published before checksums were introduced have none and are not checked
+func (uc *MessageUseCase) verifyPayload(msg *entity.Message) error {
+	want, ok := msg.Headers[headerPayloadSHA256]
+	if !ok {
+		return nil
+	}
+
+	sum := sha256.Sum256(msg.Data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, want) {
+		uc.logger.Error("Payload checksum mismatch",
+			logger.String("subject", msg.Subject),
+			logger.Uint64("sequence", msg.Sequence),
+			logger.String("object_name", msg.ObjectName),
+			logger.String("expected", want),
+			logger.String("actual", got),
+		)
+		return fmt.Errorf("%w: sequence %d does not match its sha256", entity.ErrPayloadCorrupted, msg.Sequence)
+	}
+	return nil
+}
+
+// GetMessage returns a single message of a subject with its payload
+// It is a stateless read: no consumer cursor is created or moved
+func (uc *MessageUseCase) GetMessage(ctx context.Context, subject string, sequence uint64) (*entity.Message, error) {
+	msg, err := uc.getUnexpired(ctx, subject, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.loadPayloads(ctx, []*entity.Message{msg}); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// ReadBySubjectSequence returns messages of a subject between two per-subject sequences (inclusive)
+// toSubjectSequence == 0 leaves the range open and only limit bounds it
+// It is a stateless read: no consumer cursor is created or moved
+func (uc *MessageUseCase) ReadBySubjectSequence(
+	ctx context.Context,
+	subject string,
+	startSubjectSequence uint64,
+	toSubjectSequence uint64,
+	limit int,
+) ([]*entity.Message, error) {
+	if toSubjectSequence > 0 && toSubjectSequence < startSubjectSequence {
+		return nil, fmt.Errorf("to_sequence %d is before from_sequence %d", toSubjectSequence, startSubjectSequence)
+	}
+
+	if limit <= 0 {
+		limit = 10 // Default batch size
+	}
+	if limit > maxReadRangeLimit {
+		limit = maxReadRangeLimit
+	}
+
+	now := time.Now()
+	var messages []*entity.Message
+	for {
+		batch, err := uc.messageRepo.GetMessagesBySubjectSequence(ctx, subject, startSubjectSequence, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read by subject sequence: %w", err)
+		}
+		last := len(batch)
+
+		// Subject sequences are dense, but retention may have removed messages inside the range
+		if toSubjectSequence > 0 {
+			for i, msg := range batch {
+				if msg.SubjectSequence > toSubjectSequence {
+					batch = batch[:i]
+					break
+				}
+			}
+		}
+
+		// A batch that expired as a whole is read past, so the range does not end early
+		messages = uc.dropExpired(subject, batch, now)
+		if len(messages) > 0 || len(batch) < last || last < limit {
+			break
+		}
+		startSubjectSequence = batch[last-1].SubjectSequence + 1
+	}
+
+	if err := uc.loadPayloads(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// ResolveSubjectSequence translates a per-subject sequence to the global sequence
+// so it can be used as a start position for Subscribe
+func (uc *MessageUseCase) ResolveSubjectSequence(ctx context.Context, subject string, subjectSequence uint64) (uint64, error) {
+	sequence, err := uc.messageRepo.ResolveSubjectSequence(ctx, subject, subjectSequence)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve subject sequence: %w", err)
+	}
+	if sequence == 0 {
+		return 0, fmt.Errorf("%w: subject %s has no subject sequence %d", entity.ErrMessageNotFound, subject, subjectSequence)
+	}
+	return sequence, nil
+}
+
+// GetMessageBySubjectSequence returns a single message of a subject identified by its per-subject sequence
+// It is a stateless read: no consumer cursor is created or moved
+func (uc *MessageUseCase) GetMessageBySubjectSequence(ctx context.Context, subject string, subjectSequence uint64) (*entity.Message, error) {
+	sequence, err := uc.ResolveSubjectSequence(ctx, subject, subjectSequence)
+	if err != nil {
+		return nil, err
+	}
+	return uc.GetMessage(ctx, subject, sequence)
+}
+
+// SubjectSequence returns the per-subject sequence of the message stored at a global sequence
+func (uc *MessageUseCase) SubjectSequence(ctx context.Context, sequence uint64) (uint64, error) {
+	msg, err := uc.messageRepo.GetMessageBySequence(ctx, sequence)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get message %d: %w", sequence, err)
+	}
+	return msg.SubjectSequence, nil
+}
+
+// ReadRange returns messages of a subject between fromSequence and toSequence (inclusive)
+// toSequence == 0 leaves the range open and only limit bounds it
+// It is a stateless read: no consumer cursor is created or moved
+func (uc *MessageUseCase) ReadRange(
+	ctx context.Context,
+	subject string,
+	fromSequence uint64,
+	toSequence uint64,
+	limit int,
+) ([]*entity.Message, error) {
+	if toSequence > 0 && toSequence < fromSequence {
+		return nil, fmt.Errorf("to_sequence %d is before from_sequence %d", toSequence, fromSequence)
+	}
+
+	if limit <= 0 {
+		limit = 10 // Default batch size
+	}
+	if limit > maxReadRangeLimit {
+		limit = maxReadRangeLimit
+	}
+
+	now := time.Now()
+	var messages []*entity.Message
+	for start := fromSequence; ; {
+		batch, err := uc.messageRepo.GetMessagesRange(ctx, subject, start, toSequence, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read range: %w", err)
+		}
+		last := len(batch)
+
+		// A batch that expired as a whole is read past, so the range does not end early
+		messages = uc.dropExpired(subject, batch, now)
+		if len(messages) > 0 || last < limit {
+			break
+		}
+		start = batch[last-1].Sequence + 1
+		if toSequence > 0 && start > toSequence {
+			break
+		}
+	}
+
+	if err := uc.loadPayloads(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Read range",
 		logger.String("subject", subject),
+		logger.Uint64("from_sequence", fromSequence),
+		logger.Uint64("to_sequence", toSequence),
 		logger.Int("count", len(messages)),
 	)
 
@@ -189,19 +547,118 @@ func (uc *MessageUseCase) GetLastSequence(ctx context.Context, subject string) (
 	return latestSeq, nil
 }
 
+// FetchRange reads a byte range of a message payload without touching any consumer cursor
+// length == 0 reads from offset to the end of the payload.
+// Offsets address the stored bytes, so payloads stored compressed or encrypted
+// are rejected with entity.ErrPayloadEncoded: a range of them could only be served
+// by downloading and decoding the whole object. Such payloads are read with GetMessage
+func (uc *MessageUseCase) FetchRange(
+	ctx context.Context,
+	subject string,
+	sequence uint64,
+	offset int64,
+	length int64,
+) (*entity.PayloadRange, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("%w: offset and length must not be negative", entity.ErrInvalidRange)
+	}
+
+	msg, err := uc.getUnexpired(ctx, subject, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.PayloadRange{
+		Subject:  msg.Subject,
+		Sequence: msg.Sequence,
+		Offset:   offset,
+		Data:     []byte{},
+	}
+
+	// Message without payload
+	if msg.ObjectName == "" && !msg.Inline {
+		if offset > 0 {
+			return nil, fmt.Errorf("%w: offset %d exceeds payload size 0", entity.ErrInvalidRange, offset)
+		}
+		return result, nil
+	}
+
+	if encryption.IsEncrypted(msg.Headers) || msg.Headers[compression.HeaderContentEncoding] != "" {
+		return nil, fmt.Errorf("sequence %d: %w", sequence, entity.ErrPayloadEncoded)
+	}
+
+	if msg.Inline {
+		return uc.inlineRange(msg, result, length)
+	}
+
+	// A partial read cannot be checked against the whole-payload checksum,
+	// the header is passed on so consumers reading every range can verify it
+	data, totalSize, err := uc.storageRepo.GetObjectRange(ctx, msg.Subject, msg.ObjectName, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payload range for sequence %d: %w", sequence, err)
+	}
+	result.Data = data
+	result.TotalSize = totalSize
+
+	uc.logger.Debug("Fetched payload range",
+		logger.String("subject", subject),
+		logger.Uint64("sequence", sequence),
+		logger.Int64("offset", offset),
+		logger.Int("size", len(data)),
+		logger.Int64("total_size", totalSize),
+	)
+
+	return result, nil
+}
+
 // AckMessage acknowledges a message by updating the consumer position
 // This enables At-Least-Once delivery semantics
+// For interest and work-queue subjects acked messages are deleted together with their payloads
 func (uc *MessageUseCase) AckMessage(ctx context.Context, durableName, subject string, sequence uint64) error {
-	err := uc.messageRepo.UpdateConsumerPosition(ctx, durableName, subject, sequence)
+	deleted, err := uc.messageRepo.AckMessage(ctx, durableName, subject, sequence)
 	if err != nil {
 		return fmt.Errorf("failed to update consumer position: %w", err)
 	}
 
+	// Metadata is already gone, a failed object delete only leaves garbage behind
+	for _, msg := range deleted {
+		if msg.ObjectName == "" {
+			continue
+		}
+		if err := uc.storageRepo.DeleteObject(ctx, msg.Subject, msg.ObjectName); err != nil {
+			uc.logger.Error("Failed to delete acked object",
+				logger.String("subject", msg.Subject),
+				logger.Uint64("sequence", msg.Sequence),
+				logger.String("object_name", msg.ObjectName),
+				logger.Error(err),
+			)
+		}
+	}
+
 	uc.logger.Debug("Message acknowledged",
 		logger.String("durable_name", durableName),
 		logger.String("subject", subject),
 		logger.Uint64("sequence", sequence),
+		logger.Int("deleted", len(deleted)),
 	)
 
 	return nil
 }
+
+// MoveConsumer moves a durable consumer position without applying the subject retention mode
+// Consumers of subjects imported from another tenant use it, so they cannot delete the exporter's messages
+func (uc *MessageUseCase) MoveConsumer(ctx context.Context, durableName, subject string, sequence uint64) error {
+	if err := uc.messageRepo.UpdateConsumerPosition(ctx, durableName, subject, sequence); err != nil {
+		return fmt.Errorf("failed to update consumer position: %w", err)
+	}
+	return nil
+}
+
+// AckMessageBySubjectSequence acknowledges a message identified by its per-subject sequence
+func (uc *MessageUseCase) AckMessageBySubjectSequence(ctx context.Context, durableName, subject string, subjectSequence uint64) error {
+	sequence, err := uc.ResolveSubjectSequence(ctx, subject, subjectSequence)
+	if err != nil {
+		return err
+	}
+	return uc.AckMessage(ctx, durableName, subject, sequence)
+}
@@ -1,21 +1,44 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
 type mockMessageRepository struct {
-	getConsumerPositionFunc        func(ctx context.Context, durableName, subject string) (uint64, error)
+	getConsumerPositionFunc         func(ctx context.Context, durableName, subject string) (uint64, error)
 	getLatestSequenceForSubjectFunc func(ctx context.Context, subject string) (uint64, error)
-	getMessagesBySubjectFunc       func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error)
-	updateConsumerPositionFunc     func(ctx context.Context, durableName, subject string, sequence uint64) error
-	getMessageBySequenceFunc       func(ctx context.Context, sequence uint64) (*entity.Message, error)
+	getMessagesBySubjectFunc        func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error)
+	updateConsumerPositionFunc      func(ctx context.Context, durableName, subject string, sequence uint64) error
+	getMessagesRangeFunc            func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error)
+	getMessageBySequenceFunc        func(ctx context.Context, sequence uint64) (*entity.Message, error)
+	ackMessageFunc                  func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error)
+	getMessagesBySubjectSeqFunc     func(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error)
+	resolveSubjectSequenceFunc      func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error)
+}
+
+func (m *mockMessageRepository) GetMessagesBySubjectSequence(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error) {
+	if m.getMessagesBySubjectSeqFunc != nil {
+		return m.getMessagesBySubjectSeqFunc(ctx, subject, startSubjectSeq, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockMessageRepository) ResolveSubjectSequence(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+	if m.resolveSubjectSequenceFunc != nil {
+		return m.resolveSubjectSequenceFunc(ctx, subject, subjectSeq)
+	}
+	return 0, nil
 }
 
 func (m *mockMessageRepository) GetConsumerPosition(ctx context.Context, durableName, subject string) (uint64, error) {
@@ -46,6 +69,13 @@ func (m *mockMessageRepository) UpdateConsumerPosition(ctx context.Context, dura
 	return nil
 }
 
+func (m *mockMessageRepository) GetMessagesRange(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+	if m.getMessagesRangeFunc != nil {
+		return m.getMessagesRangeFunc(ctx, subject, fromSeq, toSeq, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockMessageRepository) GetMessageBySequence(ctx context.Context, sequence uint64) (*entity.Message, error) {
 	if m.getMessageBySequenceFunc != nil {
 		return m.getMessageBySequenceFunc(ctx, sequence)
@@ -53,9 +83,25 @@ func (m *mockMessageRepository) GetMessageBySequence(ctx context.Context, sequen
 	return nil, nil
 }
 
+func (m *mockMessageRepository) AckMessage(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+	if m.ackMessageFunc != nil {
+		return m.ackMessageFunc(ctx, durableName, subject, sequence)
+	}
+	return nil, nil
+}
+
 type mockStorageRepository struct {
-	getObjectFunc    func(ctx context.Context, subject, objectName string) ([]byte, error)
-	getObjectURLFunc func(objectName string) string
+	getObjectFunc      func(ctx context.Context, subject, objectName string) ([]byte, error)
+	getObjectRangeFunc func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error)
+	deleteObjectFunc   func(ctx context.Context, subject, objectName string) error
+	getObjectURLFunc   func(objectName string) string
+}
+
+func (m *mockStorageRepository) DeleteObject(ctx context.Context, subject, objectName string) error {
+	if m.deleteObjectFunc != nil {
+		return m.deleteObjectFunc(ctx, subject, objectName)
+	}
+	return nil
 }
 
 func (m *mockStorageRepository) GetObject(ctx context.Context, subject, objectName string) ([]byte, error) {
@@ -65,6 +111,13 @@ func (m *mockStorageRepository) GetObject(ctx context.Context, subject, objectNa
 	return nil, nil
 }
 
+func (m *mockStorageRepository) GetObjectRange(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+	if m.getObjectRangeFunc != nil {
+		return m.getObjectRangeFunc(ctx, subject, objectName, offset, length)
+	}
+	return nil, 0, nil
+}
+
 func (m *mockStorageRepository) GetObjectURL(subject, objectName string) string {
 	if m.getObjectURLFunc != nil {
 		return m.getObjectURLFunc(objectName)
@@ -388,3 +441,731 @@ func TestMessageUseCase_Subscribe_CancelContext(t *testing.T) {
 		t.Fatal("timeout waiting for subscription to cancel")
 	}
 }
+
+func TestMessageUseCase_FetchRange_Success(t *testing.T) {
+	var gotOffset, gotLength int64
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{
+				Sequence:   sequence,
+				Subject:    "logs.archive",
+				ObjectName: "logs.archive_7",
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			gotOffset, gotLength = offset, length
+			return []byte("tail"), 1024, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	result, err := uc.FetchRange(context.Background(), "logs.archive", 7, 1020, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotOffset != 1020 || gotLength != 4 {
+		t.Errorf("expected range 1020+4, got %d+%d", gotOffset, gotLength)
+	}
+	if string(result.Data) != "tail" {
+		t.Errorf("expected data 'tail', got '%s'", string(result.Data))
+	}
+	if result.TotalSize != 1024 {
+		t.Errorf("expected total size 1024, got %d", result.TotalSize)
+	}
+	if result.Offset != 1020 {
+		t.Errorf("expected offset 1020, got %d", result.Offset)
+	}
+}
+
+func TestMessageUseCase_FetchRange_SubjectMismatch(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "other.subject", ObjectName: "other.subject_7"}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			t.Fatal("storage must not be read for a foreign subject")
+			return nil, 0, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	_, err := uc.FetchRange(context.Background(), "logs.archive", 7, 0, 0)
+	if !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_FetchRange_NegativeOffset(t *testing.T) {
+	msgRepo := &mockMessageRepository{}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	_, err := uc.FetchRange(context.Background(), "logs.archive", 7, -1, 0)
+	if !errors.Is(err, entity.ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange, got %v", err)
+	}
+}
+
+func TestMessageUseCase_FetchRange_NoPayload(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "events"}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	result, err := uc.FetchRange(context.Background(), "events", 3, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Data) != 0 || result.TotalSize != 0 {
+		t.Errorf("expected empty payload, got %d bytes of %d", len(result.Data), result.TotalSize)
+	}
+
+	if _, err := uc.FetchRange(context.Background(), "events", 3, 1, 0); !errors.Is(err, entity.ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange for offset past empty payload, got %v", err)
+	}
+}
+
+func TestMessageUseCase_GetMessage_Success(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "audit", ObjectName: "audit_12"}, nil
+		},
+		updateConsumerPositionFunc: func(ctx context.Context, durableName, subject string, sequence uint64) error {
+			t.Fatal("GetMessage must not move consumer cursors")
+			return nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte("payload"), nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	msg, err := uc.GetMessage(context.Background(), "audit", 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Sequence != 12 || string(msg.Data) != "payload" {
+		t.Errorf("unexpected message: sequence=%d data=%q", msg.Sequence, string(msg.Data))
+	}
+}
+
+func TestMessageUseCase_GetMessage_OtherSubject(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "audit", ObjectName: "audit_12"}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			t.Fatal("payload of another subject must not be loaded")
+			return nil, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	_, err := uc.GetMessage(context.Background(), "billing", 12)
+	if !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_GetMessage_NotFound(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return nil, entity.ErrMessageNotFound
+		},
+	}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	_, err := uc.GetMessage(context.Background(), "audit", 99)
+	if !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_ReadRange_Success(t *testing.T) {
+	var gotFrom, gotTo uint64
+	var gotLimit int
+	msgRepo := &mockMessageRepository{
+		getMessagesRangeFunc: func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+			gotFrom, gotTo, gotLimit = fromSeq, toSeq, limit
+			return []*entity.Message{
+				{Sequence: 5, Subject: subject, ObjectName: "audit_5"},
+				{Sequence: 8, Subject: subject},
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return []byte(objectName), nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	messages, err := uc.ReadRange(context.Background(), "audit", 5, 8, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotFrom != 5 || gotTo != 8 {
+		t.Errorf("expected range 5..8, got %d..%d", gotFrom, gotTo)
+	}
+	if gotLimit != 10 {
+		t.Errorf("expected default limit 10, got %d", gotLimit)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if string(messages[0].Data) != "audit_5" {
+		t.Errorf("expected payload of first message to be loaded, got %q", string(messages[0].Data))
+	}
+	if messages[1].Data != nil {
+		t.Errorf("expected no payload for message without object, got %q", string(messages[1].Data))
+	}
+}
+
+func TestMessageUseCase_ReadRange_LimitCapped(t *testing.T) {
+	var gotLimit int
+	msgRepo := &mockMessageRepository{
+		getMessagesRangeFunc: func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+			gotLimit = limit
+			return []*entity.Message{}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	if _, err := uc.ReadRange(context.Background(), "audit", 1, 0, 1000000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != maxReadRangeLimit {
+		t.Errorf("expected limit capped at %d, got %d", maxReadRangeLimit, gotLimit)
+	}
+}
+
+func TestMessageUseCase_ReadRange_InvertedBounds(t *testing.T) {
+	msgRepo := &mockMessageRepository{}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	if _, err := uc.ReadRange(context.Background(), "audit", 10, 5, 0); err == nil {
+		t.Fatal("expected error for to_sequence before from_sequence")
+	}
+}
+
+func TestMessageUseCase_AckMessage_DeletesRetainedObjects(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			if durableName != "worker" || subject != "jobs" || sequence != 3 {
+				t.Errorf("unexpected ack arguments: %s %s %d", durableName, subject, sequence)
+			}
+			return []*entity.Message{
+				{Sequence: 2, Subject: "jobs", ObjectName: "jobs_2"},
+				{Sequence: 3, Subject: "jobs", ObjectName: ""},
+			}, nil
+		},
+	}
+	var deleted []string
+	storageRepo := &mockStorageRepository{
+		deleteObjectFunc: func(ctx context.Context, subject, objectName string) error {
+			deleted = append(deleted, objectName)
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	if err := uc.AckMessage(context.Background(), "worker", "jobs", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "jobs_2" {
+		t.Errorf("expected only jobs_2 to be deleted, got %v", deleted)
+	}
+}
+
+func TestMessageUseCase_AckMessage_StorageErrorIgnored(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			return []*entity.Message{{Sequence: 1, Subject: "jobs", ObjectName: "jobs_1"}}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		deleteObjectFunc: func(ctx context.Context, subject, objectName string) error {
+			return errors.New("minio error")
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	if err := uc.AckMessage(context.Background(), "worker", "jobs", 1); err != nil {
+		t.Fatalf("ack should succeed once the position is stored, got %v", err)
+	}
+}
+
+func TestMessageUseCase_AckMessage_RepoError(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			return nil, errors.New("tarantool error")
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	if err := uc.AckMessage(context.Background(), "worker", "jobs", 1); err == nil {
+		t.Fatal("expected error from message repository")
+	}
+}
+
+func TestMessageUseCase_ReadBySubjectSequence(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getMessagesBySubjectSeqFunc: func(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error) {
+			if startSubjectSeq != 2 || limit != 10 {
+				t.Errorf("unexpected arguments: start=%d limit=%d", startSubjectSeq, limit)
+			}
+			return []*entity.Message{
+				{Sequence: 40, SubjectSequence: 2, Subject: subject},
+				{Sequence: 57, SubjectSequence: 3, Subject: subject},
+			}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	messages, err := uc.ReadBySubjectSequence(context.Background(), "test.single", 2, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 2 || messages[1].SubjectSequence != 3 {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+
+	// The upper bound is inclusive
+	messages, err = uc.ReadBySubjectSequence(context.Background(), "test.single", 2, 2, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 1 || messages[0].SubjectSequence != 2 {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+
+	if _, err := uc.ReadBySubjectSequence(context.Background(), "test.single", 3, 2, 0); err == nil {
+		t.Error("expected error for an inverted range")
+	}
+}
+
+func TestMessageUseCase_GetMessageBySubjectSequence(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			if subject == "test.single" && subjectSeq == 3 {
+				return 57, nil
+			}
+			return 0, nil
+		},
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, SubjectSequence: 3, Subject: "test.single"}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	msg, err := uc.GetMessageBySubjectSequence(context.Background(), "test.single", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Sequence != 57 {
+		t.Errorf("expected global sequence 57, got %d", msg.Sequence)
+	}
+
+	if _, err := uc.GetMessageBySubjectSequence(context.Background(), "test.single", 4); !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Errorf("expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_AckMessageBySubjectSequence(t *testing.T) {
+	var ackedSequence uint64
+	msgRepo := &mockMessageRepository{
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			if subjectSeq == 3 {
+				return 57, nil
+			}
+			return 0, nil
+		},
+		ackMessageFunc: func(ctx context.Context, durableName, subject string, sequence uint64) ([]*entity.Message, error) {
+			ackedSequence = sequence
+			return nil, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	if err := uc.AckMessageBySubjectSequence(context.Background(), "worker", "test.single", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ackedSequence != 57 {
+		t.Errorf("expected global sequence 57 to be acked, got %d", ackedSequence)
+	}
+
+	err := uc.AckMessageBySubjectSequence(context.Background(), "worker", "test.single", 99)
+	if !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Errorf("expected ErrMessageNotFound for unknown subject sequence, got %v", err)
+	}
+}
+
+func TestMessageUseCase_FetchMessages_SkipsExpired(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	var starts []uint64
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			starts = append(starts, startSeq)
+			if startSeq == 1 {
+				// A whole batch that expired but is not cleaned up yet
+				return []*entity.Message{
+					{Sequence: 1, Subject: subject, ExpiresAt: expired},
+					{Sequence: 2, Subject: subject, ExpiresAt: expired},
+				}, nil
+			}
+			return []*entity.Message{
+				{Sequence: 3, Subject: subject, ExpiresAt: expired},
+				{Sequence: 4, Subject: subject, ExpiresAt: time.Now().Add(time.Hour)},
+			}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	messages, err := uc.FetchMessages(context.Background(), "sensors.frames", "reader", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Sequence != 4 {
+		t.Fatalf("expected only message 4, got %+v", messages)
+	}
+	if len(starts) != 2 || starts[1] != 3 {
+		t.Errorf("expected to read on after the expired batch, got starts %v", starts)
+	}
+}
+
+func TestMessageUseCase_GetMessage_Expired(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		resolveSubjectSequenceFunc: func(ctx context.Context, subject string, subjectSeq uint64) (uint64, error) {
+			return 12, nil
+		},
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "sensors.frames", ObjectName: "frames_12", ExpiresAt: time.Now().Add(-time.Second)}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			t.Fatal("payload of an expired message must not be loaded")
+			return nil, nil
+		},
+		getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+			t.Fatal("payload of an expired message must not be loaded")
+			return nil, 0, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	if _, err := uc.GetMessage(context.Background(), "sensors.frames", 12); !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Errorf("GetMessage: expected ErrMessageNotFound, got %v", err)
+	}
+	if _, err := uc.GetMessageBySubjectSequence(context.Background(), "sensors.frames", 3); !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Errorf("GetMessageBySubjectSequence: expected ErrMessageNotFound, got %v", err)
+	}
+	if _, err := uc.FetchRange(context.Background(), "sensors.frames", 12, 0, 0); !errors.Is(err, entity.ErrMessageNotFound) {
+		t.Errorf("FetchRange: expected ErrMessageNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_ReadRange_SkipsExpired(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	var starts []uint64
+	msgRepo := &mockMessageRepository{
+		getMessagesRangeFunc: func(ctx context.Context, subject string, fromSeq, toSeq uint64, limit int) ([]*entity.Message, error) {
+			starts = append(starts, fromSeq)
+			if fromSeq == 1 {
+				// A whole batch that expired but is not cleaned up yet
+				return []*entity.Message{
+					{Sequence: 1, Subject: subject, ExpiresAt: expired},
+					{Sequence: 2, Subject: subject, ExpiresAt: expired},
+				}, nil
+			}
+			return []*entity.Message{
+				{Sequence: 3, Subject: subject, ExpiresAt: expired},
+				{Sequence: 4, Subject: subject},
+			}, nil
+		},
+		getMessagesBySubjectSeqFunc: func(ctx context.Context, subject string, startSubjectSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{
+				{Sequence: 3, SubjectSequence: 1, Subject: subject, ExpiresAt: expired},
+				{Sequence: 4, SubjectSequence: 2, Subject: subject},
+			}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, &mockStorageRepository{}, log, time.Second)
+
+	messages, err := uc.ReadRange(context.Background(), "sensors.frames", 1, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Sequence != 4 {
+		t.Fatalf("expected only message 4, got %+v", messages)
+	}
+	if len(starts) != 2 || starts[1] != 3 {
+		t.Errorf("expected to read on after the expired batch, got starts %v", starts)
+	}
+
+	messages, err = uc.ReadBySubjectSequence(context.Background(), "sensors.frames", 1, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 1 || messages[0].SubjectSequence != 2 {
+		t.Errorf("expected only subject sequence 2, got %+v", messages)
+	}
+}
+
+func TestMessageUseCase_FetchRange_EncodedPayload(t *testing.T) {
+	for name, headers := range map[string]map[string]string{
+		"compressed": {"content-encoding": "gzip"},
+		"encrypted":  {"encryption": "aes-256-gcm", "encryption-key": "payments", "encryption-data-key": "vault:v1:wrapped"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			msgRepo := &mockMessageRepository{
+				getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+					return &entity.Message{Sequence: sequence, Subject: "logs.app", ObjectName: "logs.app/9", Headers: headers}, nil
+				},
+			}
+			storageRepo := &mockStorageRepository{
+				getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+					t.Error("the whole object must not be downloaded to serve a range")
+					return nil, nil
+				},
+				getObjectRangeFunc: func(ctx context.Context, subject, objectName string, offset, length int64) ([]byte, int64, error) {
+					t.Error("offsets of an encoded payload do not address the original bytes")
+					return nil, 0, nil
+				},
+			}
+			log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+			uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+			if _, err := uc.FetchRange(context.Background(), "logs.app", 9, 10, 10); !errors.Is(err, entity.ErrPayloadEncoded) {
+				t.Fatalf("expected ErrPayloadEncoded, got %v", err)
+			}
+		})
+	}
+}
+
+// staticKeyManager hands out one fixed data key, standing in for Vault transit
+type staticKeyManager struct{}
+
+func (staticKeyManager) GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error) {
+	return bytes.Repeat([]byte{7}, 32), "vault:v1:wrapped", nil
+}
+
+func (staticKeyManager) DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error) {
+	return bytes.Repeat([]byte{7}, 32), nil
+}
+
+func TestMessageUseCase_DecryptPayload(t *testing.T) {
+	envelope := encryption.NewEnvelope(staticKeyManager{}, "minitoolstream", nil)
+	sealed, headers, err := envelope.Seal(context.Background(), "payments", []byte("payments/3"), []byte("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	headers["trace-id"] = "abc"
+
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := NewMessageUseCase(&mockMessageRepository{}, &mockStorageRepository{}, log, time.Second)
+	uc.SetEnvelope(envelope)
+
+	msg := &entity.Message{Sequence: 3, Subject: "payments", ObjectName: "payments/3", Headers: headers, Data: sealed}
+	if err := uc.DecryptPayload(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(msg.Data) != "secret" {
+		t.Errorf("expected decrypted payload, got %q", msg.Data)
+	}
+	if encryption.IsEncrypted(msg.Headers) || msg.Headers[encryption.HeaderDataKey] != "" {
+		t.Errorf("expected encryption headers to be dropped, got %v", msg.Headers)
+	}
+	if msg.Headers["trace-id"] != "abc" || msg.Headers["data-size"] != "6" {
+		t.Errorf("unexpected headers: %v", msg.Headers)
+	}
+	if headers[encryption.HeaderEncryption] == "" {
+		t.Error("stored headers must not be modified")
+	}
+
+	// A payload moved to another object is rejected
+	msg = &entity.Message{Sequence: 4, Subject: "payments", ObjectName: "payments/4", Headers: headers, Data: sealed}
+	if err := uc.DecryptPayload(context.Background(), msg); err == nil {
+		t.Error("expected error for payload bound to another object")
+	}
+
+	// Inline payloads are opened with the recorded context, within their own subject only
+	msg = &entity.Message{Sequence: 5, Subject: "payments", Headers: headers, Data: sealed, Inline: true}
+	if err := uc.DecryptPayload(context.Background(), msg); err != nil || string(msg.Data) != "secret" {
+		t.Errorf("expected inline payload to decrypt, got %q: %v", msg.Data, err)
+	}
+	msg = &entity.Message{Sequence: 6, Subject: "refunds", Headers: headers, Data: sealed, Inline: true}
+	if err := uc.DecryptPayload(context.Background(), msg); err == nil {
+		t.Error("expected error for inline payload moved to another subject")
+	}
+}
+
+func TestMessageUseCase_FetchMessages_InlinePayload(t *testing.T) {
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{
+				{Sequence: 1, Subject: subject, Data: []byte(`{"id":1}`), Inline: true},
+				{Sequence: 2, Subject: subject, ObjectName: "events_2"},
+			}, nil
+		},
+		getMessageBySequenceFunc: func(ctx context.Context, sequence uint64) (*entity.Message, error) {
+			return &entity.Message{Sequence: sequence, Subject: "events", Data: []byte(`{"id":1}`), Inline: true}, nil
+		},
+	}
+	var fetched []string
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			fetched = append(fetched, objectName)
+			return []byte("from minio"), nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	messages, err := uc.FetchMessages(context.Background(), "events", "reader", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(messages[0].Data) != `{"id":1}` || string(messages[1].Data) != "from minio" {
+		t.Errorf("unexpected payloads: %q, %q", messages[0].Data, messages[1].Data)
+	}
+	if len(fetched) != 1 || fetched[0] != "events_2" {
+		t.Errorf("expected only the MinIO-backed message to hit storage, got %v", fetched)
+	}
+
+	result, err := uc.FetchRange(context.Background(), "events", 1, 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != "id" || result.TotalSize != 8 {
+		t.Errorf("unexpected range of inline payload: %q of %d", result.Data, result.TotalSize)
+	}
+}
+
+func TestMessageUseCase_FetchMessages_VerifiesChecksum(t *testing.T) {
+	payload := []byte(`{"reading":42}`)
+	sum := sha256.Sum256(payload)
+	checksum := hex.EncodeToString(sum[:])
+
+	stored := payload
+	msgRepo := &mockMessageRepository{
+		getConsumerPositionFunc: func(ctx context.Context, durableName, subject string) (uint64, error) {
+			return 0, nil
+		},
+		getMessagesBySubjectFunc: func(ctx context.Context, subject string, startSeq uint64, limit int) ([]*entity.Message, error) {
+			return []*entity.Message{
+				{Sequence: 1, Subject: subject, ObjectName: "sensors_1", Headers: map[string]string{"payload-sha256": checksum}},
+				{Sequence: 2, Subject: subject, ObjectName: "sensors_2"},
+			}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		getObjectFunc: func(ctx context.Context, subject, objectName string) ([]byte, error) {
+			return stored, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewMessageUseCase(msgRepo, storageRepo, log, time.Second)
+
+	messages, err := uc.FetchMessages(context.Background(), "sensors", "reader", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	// A flipped bit in storage is reported instead of delivered
+	stored = []byte(`{"reading":43}`)
+	_, err = uc.FetchMessages(context.Background(), "sensors", "reader", 10)
+	if !errors.Is(err, entity.ErrPayloadCorrupted) {
+		t.Fatalf("expected ErrPayloadCorrupted, got %v", err)
+	}
+}
+
+func TestMessageUseCase_DecodePayload_DropsChecksum(t *testing.T) {
+	payload := bytes.Repeat([]byte("reading=42;"), 20)
+	compressed, _ := compression.Compress(compression.Gzip, payload)
+	sum := sha256.Sum256(compressed)
+
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := NewMessageUseCase(&mockMessageRepository{}, &mockStorageRepository{}, log, time.Second)
+
+	msg := &entity.Message{
+		Sequence: 1,
+		Data:     compressed,
+		Headers: map[string]string{
+			"content-encoding": "gzip",
+			"payload-sha256":   hex.EncodeToString(sum[:]),
+		},
+	}
+	if err := uc.DecodePayload(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := msg.Headers["payload-sha256"]; ok {
+		t.Error("expected checksum of the stored payload to be dropped after decoding")
+	}
+}
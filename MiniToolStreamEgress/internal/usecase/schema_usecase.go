@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/repository"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// SchemaUseCase lets consumers look up payload schemas registered by ingress
+type SchemaUseCase struct {
+	schemaRepo repository.SchemaRepository
+	logger     *logger.Logger
+}
+
+// NewSchemaUseCase creates a new schema use case
+func NewSchemaUseCase(schemaRepo repository.SchemaRepository, logger *logger.Logger) *SchemaUseCase {
+	return &SchemaUseCase{
+		schemaRepo: schemaRepo,
+		logger:     logger,
+	}
+}
+
+// GetSchemaByID returns the schema named by the schema-id header of a message
+func (uc *SchemaUseCase) GetSchemaByID(ctx context.Context, id uint64) (*entity.Schema, error) {
+	if id == 0 {
+		return nil, entity.ErrSchemaNotFound
+	}
+
+	s, err := uc.schemaRepo.GetSchemaByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema %d: %w", id, err)
+	}
+
+	return s, nil
+}
+
+// GetSchema returns a schema version of a subject, version 0 means the latest
+func (uc *SchemaUseCase) GetSchema(ctx context.Context, name string, version uint64) (*entity.Schema, error) {
+	if err := subject.ValidateQualified(name); err != nil {
+		return nil, err
+	}
+
+	s, err := uc.schemaRepo.GetSchema(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema of %s: %w", name, err)
+	}
+
+	return s, nil
+}
+
+// ListSchemaVersions returns the registered schema versions of a subject, oldest first
+func (uc *SchemaUseCase) ListSchemaVersions(ctx context.Context, name string) ([]uint64, error) {
+	if err := subject.ValidateQualified(name); err != nil {
+		return nil, err
+	}
+
+	versions, err := uc.schemaRepo.ListSchemaVersions(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema versions: %w", err)
+	}
+
+	uc.logger.Debug("Listed schema versions",
+		logger.String("subject", name),
+		logger.Int("count", len(versions)),
+	)
+
+	return versions, nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+type mockSchemaRepository struct {
+	getSchemaByIDFunc      func(ctx context.Context, id uint64) (*entity.Schema, error)
+	getSchemaFunc          func(ctx context.Context, subject string, version uint64) (*entity.Schema, error)
+	listSchemaVersionsFunc func(ctx context.Context, subject string) ([]uint64, error)
+}
+
+func (m *mockSchemaRepository) GetSchemaByID(ctx context.Context, id uint64) (*entity.Schema, error) {
+	if m.getSchemaByIDFunc != nil {
+		return m.getSchemaByIDFunc(ctx, id)
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func (m *mockSchemaRepository) GetSchema(ctx context.Context, subject string, version uint64) (*entity.Schema, error) {
+	if m.getSchemaFunc != nil {
+		return m.getSchemaFunc(ctx, subject, version)
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func (m *mockSchemaRepository) ListSchemaVersions(ctx context.Context, subject string) ([]uint64, error) {
+	if m.listSchemaVersionsFunc != nil {
+		return m.listSchemaVersionsFunc(ctx, subject)
+	}
+	return []uint64{}, nil
+}
+
+func TestSchemaUseCase_GetSchema(t *testing.T) {
+	repo := &mockSchemaRepository{
+		getSchemaByIDFunc: func(ctx context.Context, id uint64) (*entity.Schema, error) {
+			if id != 7 {
+				return nil, entity.ErrSchemaNotFound
+			}
+			return &entity.Schema{ID: 7, Subject: "documents.json", Version: 2, Format: "json"}, nil
+		},
+		getSchemaFunc: func(ctx context.Context, subject string, version uint64) (*entity.Schema, error) {
+			if version != 0 {
+				t.Errorf("expected latest version to be requested, got %d", version)
+			}
+			return &entity.Schema{ID: 7, Subject: subject, Version: 2, Format: "json"}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewSchemaUseCase(repo, log)
+
+	s, err := uc.GetSchemaByID(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Subject != "documents.json" || s.Version != 2 {
+		t.Errorf("unexpected schema: %+v", s)
+	}
+
+	if _, err := uc.GetSchemaByID(context.Background(), 8); !errors.Is(err, entity.ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound, got %v", err)
+	}
+	if _, err := uc.GetSchemaByID(context.Background(), 0); !errors.Is(err, entity.ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound for id 0, got %v", err)
+	}
+
+	latest, err := uc.GetSchema(context.Background(), "documents.json", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest.ID != 7 {
+		t.Errorf("expected schema 7, got %d", latest.ID)
+	}
+
+	if _, err := uc.GetSchema(context.Background(), "documents/json", 0); err == nil {
+		t.Error("expected error for an invalid subject")
+	}
+	if _, err := uc.ListSchemaVersions(context.Background(), ""); err == nil {
+		t.Error("expected error for an empty subject")
+	}
+}
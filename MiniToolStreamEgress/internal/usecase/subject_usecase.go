@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/repository"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+const (
+	// defaultListSubjectsLimit is the page size used when the caller does not set one
+	defaultListSubjectsLimit = 100
+	// maxListSubjectsLimit caps the page size of a single ListSubjects call
+	maxListSubjectsLimit = 1000
+)
+
+// SubjectUseCase handles business logic for the subject catalogue
+type SubjectUseCase struct {
+	subjectRepo repository.SubjectRepository
+	logger      *logger.Logger
+}
+
+// NewSubjectUseCase creates a new subject use case
+func NewSubjectUseCase(subjectRepo repository.SubjectRepository, logger *logger.Logger) *SubjectUseCase {
+	return &SubjectUseCase{
+		subjectRepo: subjectRepo,
+		logger:      logger,
+	}
+}
+
+// ListSubjects returns a page of subjects matching pattern
+// pattern follows JWT subject semantics: "" or "*" for all, "prefix.*" for a subtree, otherwise exact;
+// names are stored names, "$TENANT.<tenant>.*" lists the subjects of a tenant
+// after is the cursor returned as Next by the previous page
+func (uc *SubjectUseCase) ListSubjects(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+	if pattern != "" {
+		if err := subject.ValidateQualifiedPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 {
+		limit = defaultListSubjectsLimit
+	}
+	if limit > maxListSubjectsLimit {
+		limit = maxListSubjectsLimit
+	}
+
+	page, err := uc.subjectRepo.ListSubjects(ctx, pattern, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subjects: %w", err)
+	}
+
+	uc.logger.Debug("Listed subjects",
+		logger.String("pattern", pattern),
+		logger.String("after", after),
+		logger.Int("count", len(page.Subjects)),
+	)
+
+	return page, nil
+}
+
+// GetSubjectInfo returns statistics for a single subject
+func (uc *SubjectUseCase) GetSubjectInfo(ctx context.Context, name string) (*entity.SubjectInfo, error) {
+	if err := subject.ValidateQualified(name); err != nil {
+		return nil, err
+	}
+
+	info, err := uc.subjectRepo.GetSubjectInfo(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject info: %w", err)
+	}
+
+	return info, nil
+}
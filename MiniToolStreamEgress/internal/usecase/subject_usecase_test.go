@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamEgress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+type mockSubjectRepository struct {
+	listSubjectsFunc   func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error)
+	getSubjectInfoFunc func(ctx context.Context, subject string) (*entity.SubjectInfo, error)
+}
+
+func (m *mockSubjectRepository) ListSubjects(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+	if m.listSubjectsFunc != nil {
+		return m.listSubjectsFunc(ctx, pattern, after, limit)
+	}
+	return &entity.SubjectPage{}, nil
+}
+
+func (m *mockSubjectRepository) GetSubjectInfo(ctx context.Context, subject string) (*entity.SubjectInfo, error) {
+	if m.getSubjectInfoFunc != nil {
+		return m.getSubjectInfoFunc(ctx, subject)
+	}
+	return nil, nil
+}
+
+func TestSubjectUseCase_ListSubjects_Success(t *testing.T) {
+	repo := &mockSubjectRepository{
+		listSubjectsFunc: func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+			if pattern != "orders.*" || after != "orders.eu" {
+				t.Errorf("unexpected arguments: pattern=%s after=%s", pattern, after)
+			}
+			return &entity.SubjectPage{
+				Subjects: []*entity.SubjectInfo{{Subject: "orders.us", MessageCount: 3}},
+				Next:     "orders.us",
+			}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewSubjectUseCase(repo, log)
+
+	page, err := uc.ListSubjects(context.Background(), "orders.*", "orders.eu", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Subjects) != 1 || page.Subjects[0].Subject != "orders.us" {
+		t.Errorf("unexpected subjects: %+v", page.Subjects)
+	}
+	if page.Next != "orders.us" {
+		t.Errorf("expected next cursor orders.us, got %s", page.Next)
+	}
+}
+
+func TestSubjectUseCase_ListSubjects_Limits(t *testing.T) {
+	var gotLimit int
+	repo := &mockSubjectRepository{
+		listSubjectsFunc: func(ctx context.Context, pattern, after string, limit int) (*entity.SubjectPage, error) {
+			gotLimit = limit
+			return &entity.SubjectPage{}, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewSubjectUseCase(repo, log)
+
+	if _, err := uc.ListSubjects(context.Background(), "", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != defaultListSubjectsLimit {
+		t.Errorf("expected default limit %d, got %d", defaultListSubjectsLimit, gotLimit)
+	}
+
+	if _, err := uc.ListSubjects(context.Background(), "", "", 1000000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != maxListSubjectsLimit {
+		t.Errorf("expected limit capped at %d, got %d", maxListSubjectsLimit, gotLimit)
+	}
+}
+
+func TestSubjectUseCase_GetSubjectInfo_NotFound(t *testing.T) {
+	repo := &mockSubjectRepository{
+		getSubjectInfoFunc: func(ctx context.Context, subject string) (*entity.SubjectInfo, error) {
+			return nil, entity.ErrSubjectNotFound
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewSubjectUseCase(repo, log)
+
+	_, err := uc.GetSubjectInfo(context.Background(), "missing")
+	if !errors.Is(err, entity.ErrSubjectNotFound) {
+		t.Fatalf("expected ErrSubjectNotFound, got %v", err)
+	}
+}
+
+func TestSubjectUseCase_GetSubjectInfo_EmptySubject(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewSubjectUseCase(&mockSubjectRepository{}, log)
+
+	if _, err := uc.GetSubjectInfo(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty subject")
+	}
+}
@@ -38,7 +38,7 @@ docker build \
   --tag "${IMAGE_NAME}:latest" \
   --tag "${FULL_IMAGE}" \
   -f Dockerfile \
-  .
+  ..
 
 echo -e "${GREEN}✓ Image built successfully${NC}"
 
@@ -2,18 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
-	vault "github.com/hashicorp/vault/api"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
@@ -22,8 +25,18 @@ import (
 	grpcHandler "github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/delivery/grpc"
 	minioRepo "github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/repository/minio"
 	tarantoolRepo "github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/repository/tarantool"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/service/retention"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/service/scheduler"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/service/ttl"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/jwtkeys"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/mtls"
+	"github.com/moroshma/MiniToolStream/pkg/oidc"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStream/pkg/revocation"
 	"github.com/moroshma/MiniToolStreamConnector/auth"
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
 )
@@ -113,6 +126,9 @@ func main() {
 		UseSSL:          cfg.MinIO.UseSSL,
 		BucketName:      cfg.MinIO.BucketName,
 	}
+	if cfg.Tenancy.Enabled {
+		minioCfg.TenantBuckets = cfg.Tenancy.SeparateBuckets()
+	}
 
 	storageRepo, err := minioRepo.NewRepository(minioCfg, appLogger)
 	if err != nil {
@@ -132,33 +148,148 @@ func main() {
 		appLogger,
 	)
 
+	compressionPolicy, err := cfg.Compression.Policy()
+	if err != nil {
+		appLogger.Fatal("Invalid compression configuration", logger.Error(err))
+	}
+	publishUC.SetCompressionPolicy(compressionPolicy)
+	if compressionPolicy != nil {
+		appLogger.Info("Payload compression enabled",
+			logger.String("algorithm", string(compressionPolicy.Default)),
+			logger.Int("min_size", compressionPolicy.MinSize),
+		)
+	}
+
+	if cfg.Inline.Enabled {
+		publishUC.SetInlinePolicy(&usecase.InlinePolicy{
+			MaxSize:        cfg.Inline.MaxSize,
+			MaxMemoryUsage: cfg.Inline.MaxMemoryUsage,
+		})
+		appLogger.Info("Inline payload storage enabled",
+			logger.Int("max_size", cfg.Inline.MaxSize),
+			logger.Any("max_memory_usage", cfg.Inline.MaxMemoryUsage),
+		)
+	}
+
+	if cfg.Deduplication.Enabled {
+		publishUC.SetDeduplication(messageRepo)
+		appLogger.Info("Payload deduplication enabled")
+	}
+
+	if cfg.SchemaRegistry.Enabled {
+		publishUC.SetSchemaRegistry(messageRepo)
+		appLogger.Info("Schema validation enabled")
+	}
+
+	if cfg.Encryption.Enabled {
+		publishUC.SetEnvelope(encryption.NewEnvelope(vaultClient, cfg.Encryption.DefaultKey, cfg.Encryption.SubjectKeys()))
+		appLogger.Info("Payload encryption enabled",
+			logger.String("transit_mount", cfg.Vault.TransitMount),
+			logger.String("default_key", cfg.Encryption.DefaultKey),
+		)
+	}
+
 	// Initialize gRPC handler
 	ingressHandler := grpcHandler.NewIngressHandler(publishUC, appLogger)
 
+	var tenants *grpcHandler.Tenants
+	var tenantConfigs []config.TenantConfig
+	if cfg.Tenancy.Enabled {
+		settings := make(map[string]grpcHandler.TenantSettings, len(cfg.Tenancy.Tenants))
+		for _, t := range cfg.Tenancy.Tenants {
+			settings[t.Name] = grpcHandler.TenantSettings{TTL: t.TTL}
+		}
+		tenants = grpcHandler.NewTenants(settings)
+		tenantConfigs = cfg.Tenancy.Tenants
+		ingressHandler.SetTenants(tenants)
+		appLogger.Info("Tenant namespaces enabled",
+			logger.Int("tenants", len(cfg.Tenancy.Tenants)),
+			logger.Int("separate_buckets", len(minioCfg.TenantBuckets)),
+		)
+	}
+
 	// Setup MinIO lifecycle policies for TTL if enabled
 	if cfg.TTL.Enabled {
 		appLogger.Info("Setting up MinIO TTL policies")
-		if err := storageRepo.SetupTTLPolicies(ctx, cfg.TTL); err != nil {
+		if err := storageRepo.SetupTTLPolicies(ctx, cfg.TTL, tenantConfigs); err != nil {
 			appLogger.Error("Failed to setup MinIO TTL policies", logger.Error(err))
 		} else {
 			appLogger.Info("MinIO TTL policies configured successfully")
 		}
 	}
 
-	// Initialize Tarantool TTL background process
+	// Start TTL cleanup, Tarantool only keeps the per-channel TTLs so that
+	// the objects of deleted messages are removed from MinIO here
 	if cfg.TTL.Enabled {
-		appLogger.Info("Starting Tarantool TTL cleanup fiber")
-		if err := messageRepo.StartTTLCleanup(cfg.TTL); err != nil {
-			appLogger.Error("Failed to start Tarantool TTL cleanup", logger.Error(err))
-		} else {
-			appLogger.Info("Tarantool TTL cleanup fiber started")
+		if err := messageRepo.ConfigureTTL(cfg.TTL); err != nil {
+			appLogger.Error("Failed to configure Tarantool TTL", logger.Error(err))
 		}
 	}
+	ttlInterval := cfg.TTL.Default / 24 // Run 24 times during TTL period
+	if ttlInterval < time.Second {
+		ttlInterval = time.Second
+	}
+	ttlService := ttl.NewService(messageRepo, storageRepo, ttl.Config{
+		Enabled:     cfg.TTL.Enabled,
+		TTLDuration: cfg.TTL.Default,
+		Interval:    ttlInterval,
+	}, appLogger)
+	if err := ttlService.Start(ctx); err != nil {
+		appLogger.Error("Failed to start TTL cleanup", logger.Error(err))
+	}
+	defer ttlService.Stop()
+
+	// Start subject limits enforcer
+	retentionService := retention.NewService(messageRepo, storageRepo, retention.Config{
+		Enabled:  cfg.Retention.Enabled,
+		Interval: cfg.Retention.Interval,
+	}, appLogger)
+	if err := retentionService.Start(ctx); err != nil {
+		appLogger.Error("Failed to start retention enforcer", logger.Error(err))
+	}
+	defer retentionService.Stop()
+
+	// Delayed messages are delivered here so their dropped and trimmed payloads leave MinIO too
+	schedulerService := scheduler.NewService(messageRepo, storageRepo, scheduler.Config{
+		Enabled:   cfg.Scheduler.Enabled,
+		Interval:  cfg.Scheduler.Interval,
+		BatchSize: cfg.Scheduler.BatchSize,
+	}, appLogger)
+	if err := schedulerService.Start(ctx); err != nil {
+		appLogger.Error("Failed to start scheduler of delayed messages", logger.Error(err))
+	}
+	defer schedulerService.Stop()
 
 	// Initialize JWT authentication if enabled
-	var grpcServer *grpc.Server
 	// Set max message size to 1GB (for large file transfers)
 	maxMsgSize := 1024 * 1024 * 1024 // 1GB
+	serverOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxMsgSize),
+		grpc.MaxSendMsgSize(maxMsgSize),
+	}
+
+	// Client certificates are mapped to claims only when authentication is enabled
+	var identities mtls.Identities
+	if cfg.Server.TLS.Enabled {
+		reloader, err := mtls.NewReloader(cfg.Server.TLS.Files())
+		if err != nil {
+			appLogger.Fatal("Failed to load TLS certificates", logger.Error(err))
+		}
+		logCertificate(reloader, appLogger)
+		go watchCertificates(ctx, reloader, cfg.Server.TLS.ReloadInterval, appLogger)
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+		identities = cfg.Server.TLS.Identities()
+		appLogger.Info("✓ TLS enabled",
+			logger.Bool("client_ca", cfg.Server.TLS.ClientCAFile != ""),
+			logger.Bool("require_client_cert", cfg.Server.TLS.RequireClientCert),
+			logger.Int("client_identities", len(identities)),
+		)
+	}
+
+	// Interceptors run in order: authentication, authorization, then quotas, which need the client
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+	var tokenHandler *grpcHandler.TokenHandler
 
 	if cfg.Auth.Enabled {
 		appLogger.Info("JWT authentication enabled",
@@ -166,33 +297,103 @@ func main() {
 			logger.String("vault_path", cfg.Auth.JWTVaultPath),
 		)
 
-		// Import auth package
-		jwtManager, err := initJWTManager(ctx, vaultClient.Client(), &cfg.Auth, appLogger)
-		if err != nil {
-			appLogger.Fatal("Failed to initialize JWT manager", logger.Error(err))
+		// With an external provider or client certificates the Vault keys are optional
+		var validator tokenValidator
+		var jwtKeys *jwtkeys.Verifier
+		if needJWTKeys(vaultClient, &cfg.Auth, identities) {
+			jwtKeys, err = initJWTKeys(ctx, vaultClient, &cfg.Auth, appLogger)
+			if err != nil {
+				appLogger.Fatal("Failed to initialize JWT keys", logger.Error(err))
+			}
+			go watchJWTKeys(ctx, jwtKeys, cfg.Auth.KeyReloadInterval, appLogger)
+			validator = jwtKeys
 		}
 
-		// Create gRPC server with JWT interceptors and increased message size limits
-		grpcServer = grpc.NewServer(
-			grpc.UnaryInterceptor(conditionalAuthInterceptor(jwtManager, cfg.Auth.RequireAuth)),
-			grpc.MaxRecvMsgSize(maxMsgSize),
-			grpc.MaxSendMsgSize(maxMsgSize),
-		)
+		if cfg.Auth.OIDC.Enabled {
+			provider, err := initOIDC(ctx, &cfg.Auth.OIDC, appLogger)
+			if err != nil {
+				appLogger.Fatal("Failed to initialize OIDC provider", logger.Error(err))
+			}
+			go watchJWKS(ctx, provider, cfg.Auth.OIDC.RefreshInterval, appLogger)
+			validator = &issuerRouter{provider: provider, fallback: validator}
+		}
+
+		if validator == nil {
+			appLogger.Warn("No JWT keys or OIDC provider configured, only client certificates are accepted")
+		} else if cfg.Auth.CheckRevocation {
+			validator = &revocationFilter{
+				tokenValidator: validator,
+				checker:        revocation.NewChecker(messageRepo, cfg.Auth.RevocationCacheTTL),
+				logger:         appLogger,
+			}
+			appLogger.Info("Token revocation check enabled", logger.Duration("cache_ttl", cfg.Auth.RevocationCacheTTL))
+		}
+
+		// Clients exchange their API key for a token, so the exchange itself needs none
+		var public map[string]bool
+		if cfg.Auth.APIKeys.Enabled {
+			if jwtKeys == nil {
+				appLogger.Fatal("API keys require the JWT keys from Vault")
+			}
+			if jwtKeys.Keys().SigningKey == nil {
+				appLogger.Warn("JWT key set holds no signing key, API key exchanges fail until it does")
+			}
+			tokenUC := usecase.NewTokenUseCase(messageRepo, jwtKeys, cfg.Auth.APIKeys.DefaultTokenTTL, cfg.Auth.APIKeys.MaxTokenTTL, appLogger)
+			tokenHandler = grpcHandler.NewTokenHandler(tokenUC, appLogger)
+			tokenHandler.SetTenants(tenants)
+			public = map[string]bool{pb.TokenService_ExchangeAPIKey_FullMethodName: true}
+			appLogger.Info("API key authentication enabled",
+				logger.Duration("default_token_ttl", cfg.Auth.APIKeys.DefaultTokenTTL),
+				logger.Duration("max_token_ttl", cfg.Auth.APIKeys.MaxTokenTTL),
+			)
+		}
+
+		unaryInterceptors = append(unaryInterceptors, conditionalAuthInterceptor(validator, identities, cfg.Auth.RequireAuth, public))
+		unaryInterceptors = append(unaryInterceptors, authz.NewAuthorizer(grpcHandler.IngressPolicy, appLogger).UnaryInterceptor())
 		appLogger.Info("✓ JWT authentication configured")
 	} else {
 		appLogger.Info("JWT authentication disabled")
-		grpcServer = grpc.NewServer(
-			grpc.MaxRecvMsgSize(maxMsgSize),
-			grpc.MaxSendMsgSize(maxMsgSize),
+	}
+
+	if policy := cfg.Quotas.Policy(); policy != nil {
+		if cfg.Tenancy.Enabled {
+			policy.Subjects = append(policy.Subjects, cfg.Tenancy.SubjectLimits()...)
+		}
+		limiter := quota.NewLimiter(policy, messageRepo, "publish")
+		limiter.SetUsageStore(messageRepo)
+		unaryInterceptors = append(unaryInterceptors, grpcHandler.QuotaUnaryInterceptor(limiter, tenants, appLogger))
+		appLogger.Info("Publish quotas enabled",
+			logger.Int("client_overrides", len(policy.Clients)),
+			logger.Int("subject_limits", len(policy.Subjects)),
 		)
 	}
+
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(unaryInterceptors...))
+	grpcServer := grpc.NewServer(serverOpts...)
 	appLogger.Info("gRPC max message size configured", logger.Int("max_mb", maxMsgSize/(1024*1024)))
 
 	pb.RegisterIngressServiceServer(grpcServer, ingressHandler)
+	if tokenHandler != nil {
+		pb.RegisterTokenServiceServer(grpcServer, tokenHandler)
+	}
+
+	subjectConfigHandler := grpcHandler.NewSubjectConfigHandler(usecase.NewSubjectConfigUseCase(messageRepo, appLogger), appLogger)
+	subjectConfigHandler.SetTenants(tenants)
+	pb.RegisterSubjectConfigServiceServer(grpcServer, subjectConfigHandler)
+
+	// Schemas can be registered before schema_registry.enabled turns on validation
+	schemaHandler := grpcHandler.NewSchemaHandler(usecase.NewSchemaUseCase(messageRepo, appLogger), appLogger)
+	schemaHandler.SetTenants(tenants)
+	pb.RegisterSchemaServiceServer(grpcServer, schemaHandler)
 
 	// Register reflection for grpcurl
 	reflection.Register(grpcServer)
 
+	// The Authorizer denies methods without a rule, a service added without one is a bug
+	if missing := grpcHandler.IngressPolicy.Missing(grpcServer.GetServiceInfo()); len(missing) > 0 {
+		appLogger.Fatal("gRPC methods have no authorization rule", logger.Any("methods", missing))
+	}
+
 	// Start listening
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
 	if err != nil {
@@ -218,50 +419,254 @@ func main() {
 	}
 }
 
-// initJWTManager initializes JWT manager from Vault
-func initJWTManager(ctx context.Context, vaultClient *vault.Client, cfg *config.AuthConfig, log *logger.Logger) (*auth.JWTManager, error) {
+// tokenValidator validates bearer tokens
+type tokenValidator interface {
+	ValidateToken(token string) (*auth.Claims, error)
+}
+
+// revocationFilter rejects revoked tokens after validating them
+type revocationFilter struct {
+	tokenValidator
+	checker *revocation.Checker
+	logger  *logger.Logger
+}
+
+// ValidateToken validates a token and checks it was not revoked
+func (f *revocationFilter) ValidateToken(token string) (*auth.Claims, error) {
+	claims, err := f.tokenValidator.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checker.Check(claims); err != nil {
+		if errors.Is(err, revocation.ErrRevoked) {
+			f.logger.Warn("Revoked token rejected",
+				logger.String("client_id", claims.ClientID),
+				logger.String("jti", claims.ID),
+			)
+			return nil, err
+		}
+		f.logger.Error("Failed to check token revocation", logger.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to check token revocation")
+	}
+	return claims, nil
+}
+
+// needJWTKeys reports whether the JWT key set must be loaded from Vault
+// Without Vault an OIDC provider or client certificates mapped to identities authenticate clients instead
+func needJWTKeys(vaultClient *config.VaultClient, cfg *config.AuthConfig, identities mtls.Identities) bool {
+	return vaultClient != nil || (!cfg.OIDC.Enabled && len(identities) == 0)
+}
+
+// initJWTKeys loads the JWT key set from Vault
+func initJWTKeys(ctx context.Context, vaultClient *config.VaultClient, cfg *config.AuthConfig, log *logger.Logger) (*jwtkeys.Verifier, error) {
 	if vaultClient == nil {
 		return nil, fmt.Errorf("vault client is required for JWT authentication")
 	}
 
 	log.Info("Loading JWT keys from Vault", logger.String("path", cfg.JWTVaultPath))
-	jwtManager, err := auth.NewJWTManagerFromVault(ctx, vaultClient, cfg.JWTVaultPath, cfg.JWTIssuer)
+	verifier := jwtkeys.NewVerifier(vaultClient, cfg.JWTVaultPath, cfg.JWTIssuer)
+	if err := verifier.Reload(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	logJWTKeys(verifier.Keys(), log)
+
+	return verifier, nil
+}
+
+// watchJWTKeys reloads the JWT key set every interval and on SIGHUP
+// A failed reload keeps the previous keys
+func watchJWTKeys(ctx context.Context, verifier *jwtkeys.Verifier, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, reloading JWT keys")
+		case <-tick:
+		}
+
+		if err := verifier.Reload(ctx); err != nil {
+			log.Error("Failed to reload JWT keys", logger.Error(err))
+			continue
+		}
+		logJWTKeys(verifier.Keys(), log)
+	}
+}
+
+// logJWTKeys logs the keys tokens are accepted from
+func logJWTKeys(keys *jwtkeys.KeySet, log *logger.Logger) {
+	accepted := 0
+	for _, key := range keys.Keys {
+		if !key.Retired() {
+			accepted++
+		}
+	}
+	log.Debug("JWT keys loaded",
+		logger.String("active_kid", keys.ActiveID),
+		logger.Int("accepted", accepted),
+		logger.Int("retired", len(keys.Keys)-accepted),
+	)
+}
+
+// issuerRouter validates tokens of the external provider with its JWKS and other tokens with the Vault keys
+type issuerRouter struct {
+	provider *oidc.Verifier
+	fallback tokenValidator // nil when the Vault keys are not loaded
+}
+
+// ValidateToken validates a token with the verifier of its issuer
+func (r *issuerRouter) ValidateToken(token string) (*auth.Claims, error) {
+	if r.fallback == nil || r.provider.Issued(token) {
+		return r.provider.ValidateToken(token)
+	}
+	return r.fallback.ValidateToken(token)
+}
+
+// initOIDC loads the rules and the JWKS of the external provider
+func initOIDC(ctx context.Context, cfg *config.OIDCConfig, log *logger.Logger) (*oidc.Verifier, error) {
+	rules, err := oidc.LoadRules(cfg.RulesFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create JWT manager: %w", err)
+		return nil, err
 	}
 
-	return jwtManager, nil
+	var source oidc.Source = oidc.FileSource(cfg.JWKSFile)
+	if cfg.JWKSURL != "" {
+		source = &oidc.URLSource{URL: cfg.JWKSURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	}
+
+	log.Info("OIDC provider enabled",
+		logger.String("issuer", cfg.Issuer),
+		logger.String("audience", cfg.Audience),
+		logger.Int("rules", len(rules.Rules)),
+		logger.Duration("refresh_interval", cfg.RefreshInterval),
+	)
+	verifier := oidc.NewVerifier(source, cfg.Issuer, cfg.Audience, rules)
+	if err := verifier.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+	log.Debug("JWKS loaded", logger.String("kids", strings.Join(verifier.KeyIDs(), ",")))
+
+	return verifier, nil
+}
+
+// watchJWKS refreshes the provider's JWKS every interval and on SIGHUP
+// Tokens naming an unknown key also trigger a refresh, at most every 30s
+func watchJWKS(ctx context.Context, verifier *oidc.Verifier, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, refreshing JWKS")
+		case <-tick:
+		}
+
+		if err := verifier.Refresh(ctx); err != nil {
+			log.Error("Failed to refresh JWKS", logger.Error(err))
+			continue
+		}
+		log.Debug("JWKS loaded", logger.String("kids", strings.Join(verifier.KeyIDs(), ",")))
+	}
+}
+
+// watchCertificates reloads the TLS certificates every interval and on SIGHUP
+// A failed reload keeps the previous certificates
+func watchCertificates(ctx context.Context, reloader *mtls.Reloader, interval time.Duration, log *logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, reloading TLS certificates")
+		case <-tick:
+		}
+
+		if err := reloader.Reload(); err != nil {
+			log.Error("Failed to reload TLS certificates", logger.Error(err))
+			continue
+		}
+		logCertificate(reloader, log)
+	}
 }
 
-// conditionalAuthInterceptor creates an interceptor that conditionally requires authentication
-func conditionalAuthInterceptor(jwtManager *auth.JWTManager, requireAuth bool) grpc.UnaryServerInterceptor {
-	if requireAuth {
-		// Require authentication for all requests
-		return auth.UnaryServerInterceptor(jwtManager)
+// logCertificate logs the server certificate in use
+func logCertificate(reloader *mtls.Reloader, log *logger.Logger) {
+	cert, err := reloader.Certificate()
+	if err != nil {
+		log.Error("Failed to parse server certificate", logger.Error(err))
+		return
 	}
+	log.Debug("TLS certificate loaded",
+		logger.String("subject", cert.Subject.CommonName),
+		logger.String("expires_at", cert.NotAfter.Format(time.RFC3339)),
+	)
+}
 
-	// Optional authentication - validate if present, allow if not
+// conditionalAuthInterceptor creates an interceptor that validates bearer tokens
+// Requests without a token may authenticate with a client certificate mapped by identities,
+// otherwise they are rejected when requireAuth is set, unless their method is public, and pass unauthenticated
+// A nil validator rejects every bearer token
+func conditionalAuthInterceptor(validator tokenValidator, identities mtls.Identities, requireAuth bool, public map[string]bool) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
 		req interface{},
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		// Try to get token from metadata
-		claims, err := tryAuthenticate(ctx, jwtManager)
+		claims, err := tryAuthenticate(ctx, validator)
 		if err != nil {
 			// Token was provided but invalid - reject the request
 			return nil, err
 		}
-		if claims != nil {
-			ctx = context.WithValue(ctx, auth.ClaimsContextKey{}, claims)
+		if claims == nil {
+			claims, _ = identities.PeerClaims(ctx)
 		}
+		if claims == nil {
+			if requireAuth && !public[info.FullMethod] {
+				return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+			}
+			return handler(ctx, req)
+		}
+		ctx = context.WithValue(ctx, auth.ClaimsContextKey{}, claims)
 		return handler(ctx, req)
 	}
 }
 
 // tryAuthenticate attempts to authenticate but doesn't fail if no token present
-func tryAuthenticate(ctx context.Context, jwtManager *auth.JWTManager) (*auth.Claims, error) {
+func tryAuthenticate(ctx context.Context, validator tokenValidator) (*auth.Claims, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, nil
@@ -277,9 +682,18 @@ func tryAuthenticate(ctx context.Context, jwtManager *auth.JWTManager) (*auth.Cl
 		return nil, nil
 	}
 
+	// Without a validator only client certificates authenticate
+	if validator == nil {
+		return nil, status.Error(codes.Unauthenticated, "bearer tokens are not accepted, authenticate with a client certificate")
+	}
+
 	token = strings.TrimPrefix(token, "Bearer ")
-	claims, err := jwtManager.ValidateToken(token)
+	claims, err := validator.ValidateToken(token)
 	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			// Already a status, e.g. the revocation store is unavailable
+			return nil, err
+		}
 		// Token was provided but invalid - return the error
 		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
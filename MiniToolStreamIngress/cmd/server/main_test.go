@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/config"
+	"github.com/moroshma/MiniToolStream/pkg/mtls"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// withPeerCertificate returns a context of a connection that presented a verified certificate with the common name
+func withPeerCertificate(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestNeedJWTKeys(t *testing.T) {
+	identities := mtls.Identities{{CommonName: "archiver", ClientID: "archiver"}}
+
+	tests := []struct {
+		name       string
+		vault      *config.VaultClient
+		oidc       bool
+		identities mtls.Identities
+		want       bool
+	}{
+		{"vault", &config.VaultClient{}, false, nil, true},
+		{"vault with client certificates", &config.VaultClient{}, false, identities, true},
+		{"no vault", nil, false, nil, true},
+		{"oidc only", nil, true, nil, false},
+		{"client certificates only", nil, false, identities, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.AuthConfig{}
+			cfg.OIDC.Enabled = tt.oidc
+			if got := needJWTKeys(tt.vault, cfg, tt.identities); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConditionalAuthInterceptor_CertificatesOnly(t *testing.T) {
+	identities := mtls.Identities{{CommonName: "archiver", ClientID: "archiver", Permissions: []string{"publish"}}}
+	interceptor := conditionalAuthInterceptor(nil, identities, true, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/minitoolstream.IngressService/Publish"}
+
+	var claims *auth.Claims
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, _ = auth.GetClaimsFromContext(ctx)
+		return nil, nil
+	}
+
+	if _, err := interceptor(withPeerCertificate(context.Background(), "archiver"), nil, info, handler); err != nil {
+		t.Fatalf("expected the client certificate to authenticate, got %v", err)
+	}
+	if claims == nil || claims.ClientID != "archiver" {
+		t.Errorf("expected the claims of archiver, got %+v", claims)
+	}
+
+	bearer := metadata.NewIncomingContext(withPeerCertificate(context.Background(), "archiver"), metadata.Pairs("authorization", "Bearer token"))
+	if _, err := interceptor(bearer, nil, info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for a bearer token, got %v", err)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"no certificate":      context.Background(),
+		"unknown certificate": withPeerCertificate(context.Background(), "unknown"),
+	} {
+		if _, err := interceptor(ctx, nil, info, handler); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected Unauthenticated, got %v", name, err)
+		}
+	}
+}
@@ -68,7 +68,7 @@ func (s *IngressServer) Publish(ctx context.Context, req *pb.PublishRequest) (*p
 			contentType = "application/octet-stream"
 		}
 
-		err = s.minioClient.UploadData(ctx, objectName, req.Data, contentType)
+		err = s.minioClient.UploadData(ctx, objectName, req.Data, contentType, "")
 		if err != nil {
 			return &pb.PublishResponse{
 				Sequence:     sequence,
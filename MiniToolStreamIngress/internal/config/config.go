@@ -7,6 +7,11 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"gopkg.in/yaml.v3"
+
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/mtls"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 )
 
 // Config represents the application configuration
@@ -17,12 +22,112 @@ type Config struct {
 	Vault     VaultConfig     `yaml:"vault"`
 	Logger    LoggerConfig    `yaml:"logger"`
 	TTL       TTLConfig       `yaml:"ttl"`
+	Retention RetentionConfig `yaml:"retention"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
 	Auth      AuthConfig      `yaml:"auth"`
+
+	Compression    CompressionConfig    `yaml:"compression"`
+	Encryption     EncryptionConfig     `yaml:"encryption"`
+	Inline         InlineConfig         `yaml:"inline"`
+	Deduplication  DeduplicationConfig  `yaml:"deduplication"`
+	SchemaRegistry SchemaRegistryConfig `yaml:"schema_registry"`
+	Quotas         QuotaConfig          `yaml:"quotas"`
+	Tenancy        TenancyConfig        `yaml:"tenancy"`
 }
 
 // ServerConfig represents gRPC server configuration
 type ServerConfig struct {
 	Port int `yaml:"port" envconfig:"SERVER_PORT" default:"50051"`
+
+	TLS TLSConfig `yaml:"tls"`
+}
+
+// ClientIdentityConfig grants the permissions of a JWT to a client certificate
+// The certificate must carry the common name or the SAN (DNS name, URI or email)
+type ClientIdentityConfig struct {
+	CommonName  string   `yaml:"common_name"`
+	SAN         string   `yaml:"san"`
+	ClientID    string   `yaml:"client_id"`
+	Subjects    []string `yaml:"subjects"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// TLSConfig represents TLS of the gRPC server
+// Certificate files are read again every ReloadInterval and on SIGHUP, 0 only reloads on SIGHUP
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" envconfig:"SERVER_TLS_ENABLED"`
+	CertFile string `yaml:"cert_file" envconfig:"SERVER_TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" envconfig:"SERVER_TLS_KEY_FILE"`
+
+	// ClientCAFile enables client certificate verification
+	ClientCAFile      string        `yaml:"client_ca_file" envconfig:"SERVER_TLS_CLIENT_CA_FILE"`
+	RequireClientCert bool          `yaml:"require_client_cert" envconfig:"SERVER_TLS_REQUIRE_CLIENT_CERT"`
+	ReloadInterval    time.Duration `yaml:"reload_interval" envconfig:"SERVER_TLS_RELOAD_INTERVAL"`
+
+	// ClientIdentities authenticate internal services by certificate instead of a token
+	ClientIdentities []ClientIdentityConfig `yaml:"client_identities"`
+}
+
+// Files returns the certificate files in their mtls form
+func (c *TLSConfig) Files() mtls.Files {
+	return mtls.Files{
+		CertFile:          c.CertFile,
+		KeyFile:           c.KeyFile,
+		ClientCAFile:      c.ClientCAFile,
+		RequireClientCert: c.RequireClientCert,
+	}
+}
+
+// Identities returns the client identities in their mtls form
+func (c *TLSConfig) Identities() mtls.Identities {
+	ids := make(mtls.Identities, 0, len(c.ClientIdentities))
+	for _, id := range c.ClientIdentities {
+		ids = append(ids, mtls.Identity{
+			CommonName:  id.CommonName,
+			SAN:         id.SAN,
+			ClientID:    id.ClientID,
+			Subjects:    id.Subjects,
+			Permissions: id.Permissions,
+		})
+	}
+	return ids
+}
+
+// validate checks the TLS settings, identities need authentication to mean anything
+func (c *TLSConfig) validate(authEnabled bool) error {
+	if !c.Enabled {
+		if len(c.ClientIdentities) > 0 {
+			return fmt.Errorf("client identities require tls to be enabled")
+		}
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("tls requires cert_file and key_file")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return fmt.Errorf("require_client_cert requires client_ca_file")
+	}
+	if c.ReloadInterval < 0 {
+		return fmt.Errorf("tls reload interval cannot be negative")
+	}
+	if len(c.ClientIdentities) == 0 {
+		return nil
+	}
+	if c.ClientCAFile == "" {
+		return fmt.Errorf("client identities require client_ca_file")
+	}
+	if !authEnabled {
+		return fmt.Errorf("client identities require auth to be enabled")
+	}
+	for _, id := range c.ClientIdentities {
+		if id.CommonName == "" && id.SAN == "" {
+			return fmt.Errorf("client identity %s needs a common_name or san", id.ClientID)
+		}
+		if id.ClientID == "" {
+			return fmt.Errorf("client identity %s needs a client_id", id.CommonName+id.SAN)
+		}
+	}
+	return nil
 }
 
 // TarantoolConfig represents Tarantool connection configuration
@@ -61,13 +166,215 @@ type TTLConfig struct {
 	Channels []ChannelTTLConfig `yaml:"channels"`
 }
 
+// RetentionConfig represents the subject limits enforcer configuration
+type RetentionConfig struct {
+	Enabled  bool          `yaml:"enabled" envconfig:"RETENTION_ENABLED" default:"true"`
+	Interval time.Duration `yaml:"interval" envconfig:"RETENTION_INTERVAL" default:"1m"`
+}
+
+// SchedulerConfig represents the delivery of delayed messages
+type SchedulerConfig struct {
+	Enabled   bool          `yaml:"enabled" envconfig:"SCHEDULER_ENABLED" default:"true"`
+	Interval  time.Duration `yaml:"interval" envconfig:"SCHEDULER_INTERVAL" default:"1s"`
+	BatchSize int           `yaml:"batch_size" envconfig:"SCHEDULER_BATCH_SIZE" default:"1000"`
+}
+
+// SubjectCompressionConfig overrides the compression algorithm for a specific subject
+type SubjectCompressionConfig struct {
+	Subject   string `yaml:"subject"`
+	Algorithm string `yaml:"algorithm"` // zstd, gzip or none
+}
+
+// CompressionConfig represents payload compression configuration
+type CompressionConfig struct {
+	Enabled   bool                       `yaml:"enabled" envconfig:"COMPRESSION_ENABLED" default:"false"`
+	Algorithm string                     `yaml:"algorithm" envconfig:"COMPRESSION_ALGORITHM" default:"zstd"`
+	MinSize   int                        `yaml:"min_size" envconfig:"COMPRESSION_MIN_SIZE" default:"1024"`
+	Subjects  []SubjectCompressionConfig `yaml:"subjects"`
+}
+
+// Policy builds the compression policy, nil if compression is disabled
+func (c *CompressionConfig) Policy() (*compression.Policy, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	def, err := compression.ParseAlgorithm(c.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &compression.Policy{
+		Default:  def,
+		MinSize:  c.MinSize,
+		Subjects: make(map[string]compression.Algorithm, len(c.Subjects)),
+	}
+	for _, s := range c.Subjects {
+		alg, err := compression.ParseAlgorithm(s.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("subject %s: %w", s.Subject, err)
+		}
+		policy.Subjects[s.Subject] = alg
+	}
+
+	return policy, nil
+}
+
+// SubjectEncryptionConfig selects the key-encryption key for a subject or "prefix.*" pattern
+type SubjectEncryptionConfig struct {
+	Subject string `yaml:"subject"`
+	Key     string `yaml:"key"`
+}
+
+// EncryptionConfig represents payload encryption at rest
+// Data keys are generated and wrapped by the Vault transit engine
+type EncryptionConfig struct {
+	Enabled    bool                      `yaml:"enabled" envconfig:"ENCRYPTION_ENABLED" default:"false"`
+	DefaultKey string                    `yaml:"default_key" envconfig:"ENCRYPTION_DEFAULT_KEY" default:"minitoolstream"`
+	Subjects   []SubjectEncryptionConfig `yaml:"subjects"`
+}
+
+// SubjectKeys returns the key name configured for each subject pattern
+func (c *EncryptionConfig) SubjectKeys() map[string]string {
+	keys := make(map[string]string, len(c.Subjects))
+	for _, s := range c.Subjects {
+		keys[s.Subject] = s.Key
+	}
+	return keys
+}
+
+// InlineConfig represents storage of small payloads inside Tarantool tuples
+type InlineConfig struct {
+	Enabled        bool    `yaml:"enabled" envconfig:"INLINE_ENABLED" default:"false"`
+	MaxSize        int     `yaml:"max_size" envconfig:"INLINE_MAX_SIZE" default:"4096"`                // Largest payload stored inline, bytes
+	MaxMemoryUsage float64 `yaml:"max_memory_usage" envconfig:"INLINE_MAX_MEMORY_USAGE" default:"0.8"` // Fraction of memtx_memory
+}
+
+// DeduplicationConfig represents content-addressed payload storage
+// Identical payloads are stored once under their SHA-256 and reference counted in Tarantool
+type DeduplicationConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"DEDUPLICATION_ENABLED" default:"false"`
+}
+
+// SchemaRegistryConfig represents publish-time payload validation
+// Payloads of subjects with a registered schema are validated against its latest version
+type SchemaRegistryConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"SCHEMA_REGISTRY_ENABLED" default:"false"`
+}
+
+// QuotaLimitConfig represents one set of publish quotas, 0 means unlimited
+type QuotaLimitConfig struct {
+	MessagesPerSecond float64 `yaml:"messages_per_second"`
+	BytesPerSecond    float64 `yaml:"bytes_per_second"`
+	MaxStoredBytes    uint64  `yaml:"max_stored_bytes"`
+}
+
+// ClientQuotaConfig overrides the default quotas of a JWT client_id
+type ClientQuotaConfig struct {
+	ClientID         string `yaml:"client_id"`
+	QuotaLimitConfig `yaml:",inline"`
+}
+
+// SubjectQuotaConfig limits a subject or "prefix.*" pattern across all clients
+type SubjectQuotaConfig struct {
+	Subject          string `yaml:"subject"`
+	QuotaLimitConfig `yaml:",inline"`
+}
+
+// QuotaConfig represents publish rate limits and storage quotas
+// Every publish must fit both its client limits and all matching subject limits
+type QuotaConfig struct {
+	Enabled  bool                 `yaml:"enabled" envconfig:"QUOTAS_ENABLED" default:"false"`
+	Default  QuotaLimitConfig     `yaml:"default"`
+	Clients  []ClientQuotaConfig  `yaml:"clients"`
+	Subjects []SubjectQuotaConfig `yaml:"subjects"`
+}
+
+// limit converts a quota set to its policy form
+func (c QuotaLimitConfig) limit() quota.Limit {
+	return quota.Limit{
+		MessagesPerSecond: c.MessagesPerSecond,
+		BytesPerSecond:    c.BytesPerSecond,
+		MaxStoredBytes:    c.MaxStoredBytes,
+	}
+}
+
+// Policy builds the quota policy, nil if quotas are disabled
+func (c *QuotaConfig) Policy() *quota.Policy {
+	if !c.Enabled {
+		return nil
+	}
+
+	policy := &quota.Policy{
+		Default: c.Default.limit(),
+		Clients: make(map[string]quota.Limit, len(c.Clients)),
+	}
+	for _, cl := range c.Clients {
+		policy.Clients[cl.ClientID] = cl.limit()
+	}
+	for _, s := range c.Subjects {
+		policy.Subjects = append(policy.Subjects, quota.SubjectLimit{Pattern: s.Subject, Limit: s.limit()})
+	}
+	return policy
+}
+
+// validate checks a quota set
+func (c QuotaLimitConfig) validate() error {
+	if c.MessagesPerSecond < 0 || c.BytesPerSecond < 0 {
+		return fmt.Errorf("rates cannot be negative")
+	}
+	return nil
+}
+
+// TenantConfig represents settings of one tenant
+// Tenants that are not listed get the defaults
+type TenantConfig struct {
+	Name string `yaml:"name"`
+	// SeparateBucket keeps payloads of the tenant in a bucket of its own
+	SeparateBucket bool `yaml:"separate_bucket"`
+	// TTL is the lifetime of messages published without expires-at or ttl headers
+	TTL time.Duration `yaml:"ttl"`
+	// Limits are shared by all clients of the tenant (requires quotas.enabled)
+	Limits QuotaLimitConfig `yaml:"limits"`
+}
+
+// TenancyConfig represents isolation of tenants sharing the deployment
+// The tenant of a client is the part of its JWT client_id before "/", e.g. "acme/loader"
+type TenancyConfig struct {
+	Enabled bool           `yaml:"enabled" envconfig:"TENANCY_ENABLED" default:"false"`
+	Tenants []TenantConfig `yaml:"tenants"`
+}
+
+// SeparateBuckets returns the tenants with a bucket of their own
+func (c *TenancyConfig) SeparateBuckets() []string {
+	var tenants []string
+	for _, t := range c.Tenants {
+		if t.SeparateBucket {
+			tenants = append(tenants, t.Name)
+		}
+	}
+	return tenants
+}
+
+// SubjectLimits returns tenant limits as quotas on all subjects of each tenant
+func (c *TenancyConfig) SubjectLimits() []quota.SubjectLimit {
+	var limits []quota.SubjectLimit
+	for _, t := range c.Tenants {
+		if t.Limits != (QuotaLimitConfig{}) {
+			limits = append(limits, quota.SubjectLimit{Pattern: subject.Qualify(t.Name, subject.Wildcard), Limit: t.Limits.limit()})
+		}
+	}
+	return limits
+}
+
 // VaultConfig represents HashiCorp Vault configuration
 type VaultConfig struct {
-	Enabled   bool   `yaml:"enabled" envconfig:"VAULT_ENABLED" default:"false"`
-	Address   string `yaml:"address" envconfig:"VAULT_ADDR" default:"http://localhost:8200"`
-	Token     string `yaml:"token" envconfig:"VAULT_TOKEN"`
-	TokenPath string `yaml:"token_path" envconfig:"VAULT_TOKEN_PATH"`
-	Namespace string `yaml:"namespace" envconfig:"VAULT_NAMESPACE"`
+	Enabled      bool   `yaml:"enabled" envconfig:"VAULT_ENABLED" default:"false"`
+	Address      string `yaml:"address" envconfig:"VAULT_ADDR" default:"http://localhost:8200"`
+	Token        string `yaml:"token" envconfig:"VAULT_TOKEN"`
+	TokenPath    string `yaml:"token_path" envconfig:"VAULT_TOKEN_PATH"`
+	Namespace    string `yaml:"namespace" envconfig:"VAULT_NAMESPACE"`
+	TransitMount string `yaml:"transit_mount" envconfig:"VAULT_TRANSIT_MOUNT" default:"transit"`
 }
 
 // LoggerConfig represents logger configuration
@@ -83,6 +390,87 @@ type AuthConfig struct {
 	JWTVaultPath   string `yaml:"jwt_vault_path" envconfig:"JWT_VAULT_PATH" default:"secret/data/minitoolstream/jwt"`
 	JWTIssuer      string `yaml:"jwt_issuer" envconfig:"JWT_ISSUER" default:"minitoolstream"`
 	RequireAuth    bool   `yaml:"require_auth" envconfig:"REQUIRE_AUTH" default:"true"` // If false, allow unauthenticated requests
+
+	// KeyReloadInterval is how often the JWT key set is read again from Vault, 0 only reloads on SIGHUP
+	KeyReloadInterval time.Duration `yaml:"key_reload_interval" envconfig:"JWT_KEY_RELOAD_INTERVAL" default:"5m"`
+
+	// CheckRevocation rejects tokens revoked with jwt-gen -revoke
+	CheckRevocation bool `yaml:"check_revocation" envconfig:"AUTH_CHECK_REVOCATION" default:"false"`
+	// RevocationCacheTTL is how long a lookup is reused, so how late a revocation may take effect
+	RevocationCacheTTL time.Duration `yaml:"revocation_cache_ttl" envconfig:"AUTH_REVOCATION_CACHE_TTL" default:"10s"`
+
+	// OIDC accepts tokens of an external identity provider next to the tokens signed with the Vault keys
+	OIDC OIDCConfig `yaml:"oidc"`
+
+	// APIKeys serves TokenService, which exchanges API keys stored in Tarantool for tokens
+	APIKeys APIKeysConfig `yaml:"api_keys"`
+}
+
+// APIKeysConfig represents API key authentication
+// Tokens are signed with the active Vault key, so the key set must hold its private key
+type APIKeysConfig struct {
+	Enabled bool `yaml:"enabled" envconfig:"AUTH_API_KEYS_ENABLED"`
+	// DefaultTokenTTL is the lifetime of tokens of keys created without one
+	DefaultTokenTTL time.Duration `yaml:"default_token_ttl" envconfig:"AUTH_API_KEYS_DEFAULT_TOKEN_TTL" default:"15m"`
+	// MaxTokenTTL caps the lifetime of every exchanged token
+	MaxTokenTTL time.Duration `yaml:"max_token_ttl" envconfig:"AUTH_API_KEYS_MAX_TOKEN_TTL" default:"1h"`
+}
+
+// validate checks the API key settings
+func (c *APIKeysConfig) validate(auth *AuthConfig, vault *VaultConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if !auth.Enabled || !vault.Enabled {
+		return fmt.Errorf("api keys require auth and vault to be enabled")
+	}
+	if c.DefaultTokenTTL <= 0 || c.MaxTokenTTL <= 0 {
+		return fmt.Errorf("api key token ttls must be positive")
+	}
+	if c.DefaultTokenTTL > c.MaxTokenTTL {
+		return fmt.Errorf("api key default token ttl exceeds max token ttl %s", c.MaxTokenTTL)
+	}
+	return nil
+}
+
+// OIDCConfig represents an external OpenID Connect provider
+// Its tokens are told apart by their issuer, the rules file maps their claims to subjects and permissions
+type OIDCConfig struct {
+	Enabled   bool   `yaml:"enabled" envconfig:"AUTH_OIDC_ENABLED"`
+	Issuer    string `yaml:"issuer" envconfig:"AUTH_OIDC_ISSUER"`
+	Audience  string `yaml:"audience" envconfig:"AUTH_OIDC_AUDIENCE"`
+	JWKSURL   string `yaml:"jwks_url" envconfig:"AUTH_OIDC_JWKS_URL"`
+	JWKSFile  string `yaml:"jwks_file" envconfig:"AUTH_OIDC_JWKS_FILE"` // Static key set, e.g. for local testing
+	RulesFile string `yaml:"rules_file" envconfig:"AUTH_OIDC_RULES_FILE"`
+
+	// RefreshInterval is how often the JWKS is fetched again, 0 only refreshes on SIGHUP and unknown keys
+	RefreshInterval time.Duration `yaml:"refresh_interval" envconfig:"AUTH_OIDC_REFRESH_INTERVAL"`
+}
+
+// validate checks the provider settings
+func (c *OIDCConfig) validate(auth *AuthConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if !auth.Enabled {
+		return fmt.Errorf("oidc requires auth to be enabled")
+	}
+	if c.Issuer == "" || c.Audience == "" {
+		return fmt.Errorf("oidc requires issuer and audience")
+	}
+	if c.Issuer == auth.JWTIssuer {
+		return fmt.Errorf("oidc issuer must differ from jwt_issuer")
+	}
+	if (c.JWKSURL == "") == (c.JWKSFile == "") {
+		return fmt.Errorf("oidc requires exactly one of jwks_url and jwks_file")
+	}
+	if c.RulesFile == "" {
+		return fmt.Errorf("oidc requires rules_file")
+	}
+	if c.RefreshInterval < 0 {
+		return fmt.Errorf("oidc refresh interval cannot be negative")
+	}
+	return nil
 }
 
 // Load loads configuration from file and environment variables
@@ -145,6 +533,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 
+	if err := c.Server.TLS.validate(c.Auth.Enabled); err != nil {
+		return err
+	}
+
 	if c.Tarantool.Address == "" {
 		return fmt.Errorf("tarantool address is required")
 	}
@@ -157,6 +549,131 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("minio bucket name is required")
 	}
 
+	if c.Retention.Enabled && c.Retention.Interval <= 0 {
+		return fmt.Errorf("retention interval must be positive")
+	}
+
+	if c.Scheduler.Enabled && (c.Scheduler.Interval <= 0 || c.Scheduler.BatchSize <= 0) {
+		return fmt.Errorf("scheduler interval and batch size must be positive")
+	}
+
+	if c.Auth.KeyReloadInterval < 0 {
+		return fmt.Errorf("jwt key reload interval cannot be negative")
+	}
+
+	if c.Auth.CheckRevocation && !c.Auth.Enabled {
+		return fmt.Errorf("revocation check requires auth to be enabled")
+	}
+
+	if c.Auth.RevocationCacheTTL < 0 {
+		return fmt.Errorf("revocation cache ttl cannot be negative")
+	}
+
+	if err := c.Auth.OIDC.validate(&c.Auth); err != nil {
+		return err
+	}
+
+	if err := c.Auth.APIKeys.validate(&c.Auth, &c.Vault); err != nil {
+		return err
+	}
+
+	if c.Compression.MinSize < 0 {
+		return fmt.Errorf("compression min size cannot be negative")
+	}
+
+	if _, err := c.Compression.Policy(); err != nil {
+		return fmt.Errorf("invalid compression config: %w", err)
+	}
+	for _, s := range c.Compression.Subjects {
+		if err := subject.Validate(s.Subject); err != nil {
+			return fmt.Errorf("invalid compression config: %w", err)
+		}
+	}
+
+	for _, ch := range c.TTL.Channels {
+		if err := subject.Validate(ch.Channel); err != nil {
+			return fmt.Errorf("invalid ttl channel: %w", err)
+		}
+	}
+
+	if c.Inline.Enabled {
+		if c.Inline.MaxSize <= 0 {
+			return fmt.Errorf("inline max size must be positive")
+		}
+		if c.Inline.MaxMemoryUsage <= 0 || c.Inline.MaxMemoryUsage > 1 {
+			return fmt.Errorf("inline max memory usage must be in (0, 1]")
+		}
+	}
+
+	if c.Encryption.Enabled {
+		if !c.Vault.Enabled {
+			return fmt.Errorf("encryption requires vault to be enabled")
+		}
+		if c.Encryption.DefaultKey == "" {
+			return fmt.Errorf("encryption default key is required when encryption is enabled")
+		}
+		for _, s := range c.Encryption.Subjects {
+			if s.Subject == "" || s.Key == "" {
+				return fmt.Errorf("encryption subject overrides need both subject and key")
+			}
+			if err := subject.ValidatePattern(s.Subject); err != nil {
+				return fmt.Errorf("invalid encryption config: %w", err)
+			}
+		}
+	}
+
+	if c.Quotas.Enabled {
+		if err := c.Quotas.Default.validate(); err != nil {
+			return fmt.Errorf("invalid default quotas: %w", err)
+		}
+		for _, cl := range c.Quotas.Clients {
+			if cl.ClientID == "" {
+				return fmt.Errorf("client quotas need a client_id")
+			}
+			if err := cl.validate(); err != nil {
+				return fmt.Errorf("invalid quotas of client %s: %w", cl.ClientID, err)
+			}
+		}
+		for _, s := range c.Quotas.Subjects {
+			if err := subject.ValidatePattern(s.Subject); err != nil {
+				return fmt.Errorf("invalid subject quotas: %w", err)
+			}
+			if err := s.validate(); err != nil {
+				return fmt.Errorf("invalid quotas of subject %s: %w", s.Subject, err)
+			}
+		}
+	}
+
+	if c.Tenancy.Enabled {
+		seen := make(map[string]bool, len(c.Tenancy.Tenants))
+		for _, t := range c.Tenancy.Tenants {
+			if err := subject.ValidateTenant(t.Name); err != nil {
+				return fmt.Errorf("invalid tenancy config: %w", err)
+			}
+			if seen[t.Name] {
+				return fmt.Errorf("tenant %s is configured twice", t.Name)
+			}
+			seen[t.Name] = true
+
+			if t.TTL < 0 {
+				return fmt.Errorf("ttl of tenant %s cannot be negative", t.Name)
+			}
+			// Messages older than the global TTL are removed regardless of their tenant
+			if c.TTL.Enabled && t.TTL > c.TTL.Default {
+				return fmt.Errorf("ttl of tenant %s exceeds the default ttl %s", t.Name, c.TTL.Default)
+			}
+			if t.SeparateBucket && len(c.MinIO.BucketName)+1+len(t.Name) > 63 {
+				return fmt.Errorf("bucket name of tenant %s would be longer than 63 characters", t.Name)
+			}
+			if t.Limits != (QuotaLimitConfig{}) && !c.Quotas.Enabled {
+				return fmt.Errorf("limits of tenant %s require quotas to be enabled", t.Name)
+			}
+			if err := t.Limits.validate(); err != nil {
+				return fmt.Errorf("invalid limits of tenant %s: %w", t.Name, err)
+			}
+		}
+	}
+
 	if c.Vault.Enabled && c.Vault.Address == "" {
 		return fmt.Errorf("vault address is required when vault is enabled")
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestConfig_Validate_Success(t *testing.T) {
@@ -329,3 +330,404 @@ func TestVaultConfig_GetVaultToken_TokenPrecedence(t *testing.T) {
 		t.Errorf("expected direct token to take precedence, got '%s'", token)
 	}
 }
+
+func TestConfig_Validate_InvalidCompressionAlgorithm(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Compression: CompressionConfig{
+			Enabled:   true,
+			Algorithm: "zstd",
+			Subjects:  []SubjectCompressionConfig{{Subject: "logs", Algorithm: "lz4"}},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for unsupported compression algorithm")
+	}
+}
+
+func TestConfig_Validate_EncryptionWithoutVault(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Encryption: EncryptionConfig{
+			Enabled:    true,
+			DefaultKey: "minitoolstream",
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error when encryption is enabled without vault")
+	}
+}
+
+func TestConfig_Validate_InvalidInlineMemoryUsage(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Inline: InlineConfig{
+			Enabled:        true,
+			MaxSize:        4096,
+			MaxMemoryUsage: 1.5,
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for inline memory usage above 1")
+	}
+}
+
+func TestConfig_Validate_InvalidTTLChannel(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		TTL: TTLConfig{
+			Channels: []ChannelTTLConfig{{Channel: "images/raw", Duration: time.Hour}},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for ttl channel outside the subject grammar")
+	}
+}
+
+func TestConfig_Validate_Quotas(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Quotas: QuotaConfig{
+			Enabled:  true,
+			Default:  QuotaLimitConfig{MessagesPerSecond: 100},
+			Clients:  []ClientQuotaConfig{{ClientID: "loader", QuotaLimitConfig: QuotaLimitConfig{BytesPerSecond: 1 << 20}}},
+			Subjects: []SubjectQuotaConfig{{Subject: "logs.*", QuotaLimitConfig: QuotaLimitConfig{MaxStoredBytes: 1 << 30}}},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policy := cfg.Quotas.Policy()
+	if policy.Clients["loader"].BytesPerSecond != 1<<20 || policy.Subjects[0].Pattern != "logs.*" {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	cfg.Quotas.Subjects[0].Subject = "logs*"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for an invalid subject pattern")
+	}
+
+	cfg.Quotas.Subjects[0].Subject = "logs.*"
+	cfg.Quotas.Default.BytesPerSecond = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for a negative rate")
+	}
+}
+
+func TestConfig_Validate_Tenancy(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		TTL: TTLConfig{
+			Enabled: true,
+			Default: 72 * time.Hour,
+		},
+		Quotas: QuotaConfig{
+			Enabled: true,
+		},
+		Tenancy: TenancyConfig{
+			Enabled: true,
+			Tenants: []TenantConfig{
+				{Name: "acme", SeparateBucket: true, TTL: 24 * time.Hour},
+				{Name: "globex", Limits: QuotaLimitConfig{BytesPerSecond: 1 << 20}},
+			},
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if buckets := cfg.Tenancy.SeparateBuckets(); len(buckets) != 1 || buckets[0] != "acme" {
+		t.Errorf("unexpected separate buckets: %v", buckets)
+	}
+	limits := cfg.Tenancy.SubjectLimits()
+	if len(limits) != 1 || limits[0].Pattern != "$TENANT.globex.*" || limits[0].BytesPerSecond != 1<<20 {
+		t.Errorf("unexpected tenant limits: %+v", limits)
+	}
+
+	cfg.Tenancy.Tenants[0].TTL = 96 * time.Hour
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for a tenant ttl above the default ttl")
+	}
+
+	cfg.Tenancy.Tenants[0].TTL = 0
+	cfg.Tenancy.Tenants[0].Name = "Acme"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for an invalid tenant name")
+	}
+
+	cfg.Tenancy.Tenants[0].Name = "acme"
+	cfg.Quotas.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for tenant limits without quotas")
+	}
+}
+
+func TestConfig_Validate_NegativeKeyReloadInterval(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			Enabled:           true,
+			KeyReloadInterval: -time.Minute,
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for negative key reload interval")
+	}
+}
+
+func TestConfig_Validate_RevocationWithoutAuth(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			CheckRevocation: true,
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when revocation check is enabled without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Auth.RevocationCacheTTL = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for negative revocation cache ttl")
+	}
+}
+
+func TestConfig_Validate_TLS(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+			TLS: TLSConfig{
+				Enabled:  true,
+				CertFile: "/etc/tls/tls.crt",
+			},
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for tls without a key file")
+	}
+
+	cfg.Server.TLS.KeyFile = "/etc/tls/tls.key"
+	cfg.Server.TLS.RequireClientCert = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when requiring client certificates without a CA")
+	}
+
+	cfg.Server.TLS.ClientCAFile = "/etc/tls/ca.crt"
+	cfg.Server.TLS.ClientIdentities = []ClientIdentityConfig{
+		{SAN: "archiver.stream.svc", ClientID: "archiver", Permissions: []string{"subscribe"}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for client identities without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := cfg.Server.TLS.Identities(); ids[0].ClientID != "archiver" || ids[0].SAN != "archiver.stream.svc" {
+		t.Errorf("unexpected identities: %+v", ids)
+	}
+
+	cfg.Server.TLS.ClientIdentities[0].SAN = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an identity without a name")
+	}
+
+	cfg.Server.TLS.ClientIdentities[0].SAN = "archiver.stream.svc"
+	cfg.Server.TLS.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for client identities without tls")
+	}
+}
+
+func TestConfig_Validate_OIDC(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			JWTIssuer: "minitoolstream",
+			OIDC: OIDCConfig{
+				Enabled:   true,
+				Issuer:    "https://idp.example.com/realms/stream",
+				Audience:  "minitoolstream",
+				JWKSFile:  "/etc/minitoolstream/jwks.json",
+				RulesFile: "/etc/minitoolstream/oidc-rules.yaml",
+			},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for oidc without auth")
+	}
+
+	cfg.Auth.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Auth.OIDC.JWKSURL = "https://idp.example.com/realms/stream/protocol/openid-connect/certs"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for both jwks_url and jwks_file")
+	}
+
+	cfg.Auth.OIDC.JWKSFile = ""
+	cfg.Auth.OIDC.Issuer = "minitoolstream"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for an issuer shared with the vault keys")
+	}
+
+	cfg.Auth.OIDC.Issuer = "https://idp.example.com/realms/stream"
+	cfg.Auth.OIDC.Audience = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for a missing audience")
+	}
+}
+
+func TestConfig_Validate_APIKeys(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port: 50051,
+		},
+		Tarantool: TarantoolConfig{
+			Address: "localhost:3301",
+		},
+		MinIO: MinIOConfig{
+			Endpoint:   "localhost:9000",
+			BucketName: "test-bucket",
+		},
+		Auth: AuthConfig{
+			Enabled: true,
+			APIKeys: APIKeysConfig{
+				Enabled:         true,
+				DefaultTokenTTL: 15 * time.Minute,
+				MaxTokenTTL:     time.Hour,
+			},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for api keys without vault")
+	}
+
+	cfg.Vault = VaultConfig{Enabled: true, Address: "http://localhost:8200"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Auth.APIKeys.DefaultTokenTTL = 2 * time.Hour
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for a default token ttl above the max")
+	}
+
+	cfg.Auth.APIKeys.DefaultTokenTTL = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for a zero default token ttl")
+	}
+}
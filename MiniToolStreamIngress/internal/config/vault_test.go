@@ -66,3 +66,23 @@ func TestApplyVaultSecrets_NilClient(t *testing.T) {
 		t.Error("expected original password to remain unchanged")
 	}
 }
+
+func TestVaultClient_DataKeys_NilClient(t *testing.T) {
+	var vc *VaultClient
+	ctx := context.Background()
+
+	if _, _, err := vc.GenerateDataKey(ctx, "events"); err == nil {
+		t.Error("expected error generating data key with nil client")
+	}
+	if _, err := vc.DecryptDataKey(ctx, "events", "vault:v1:abc"); err == nil {
+		t.Error("expected error decrypting data key with nil client")
+	}
+}
+
+func TestVaultClient_ReadKeySet_NilClient(t *testing.T) {
+	var vc *VaultClient
+
+	if _, err := vc.ReadKeySet(context.Background(), "secret/data/minitoolstream/jwt"); err == nil {
+		t.Error("expected error reading JWT keys with nil client")
+	}
+}
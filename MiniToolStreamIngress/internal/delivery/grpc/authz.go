@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// IngressPolicy lists the rules of every method the ingress server registers
+// A method without a rule is denied, see TestIngressPolicy_CoversEveryMethod
+var IngressPolicy = authz.Policy{
+	"Publish": {Permission: auth.PermissionPublish, Subject: true},
+
+	"DeclareSubject":      {Permission: authz.PermissionAdmin},
+	"GetSubjectConfig":    {Permission: authz.PermissionAdmin},
+	"DeleteSubjectConfig": {Permission: authz.PermissionAdmin},
+	"ListSubjectConfigs":  {Permission: authz.PermissionAdmin},
+
+	"RegisterSchema":         {Permission: auth.PermissionPublish, Subject: true},
+	"GetSchema":              {Permission: auth.PermissionPublish, Subject: true},
+	"ListSchemaVersions":     {Permission: auth.PermissionPublish, Subject: true},
+	"SetSchemaCompatibility": {Permission: authz.PermissionAdmin, Subject: true},
+
+	// Clients exchange their API key for a token, so the exchange itself needs none
+	"ExchangeAPIKey": {Public: true},
+	"CreateAPIKey":   {Permission: authz.PermissionAdmin},
+	"ListAPIKeys":    {Permission: authz.PermissionAdmin},
+	"RevokeAPIKey":   {Permission: authz.PermissionAdmin},
+
+	// Reflection for grpcurl describes the API to any authenticated client
+	"ServerReflectionInfo": {},
+}
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"testing"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+func TestIngressPolicy_CoversEveryMethod(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	// Every service the server can register, the Authorizer denies methods without a rule
+	server := grpc.NewServer()
+	pb.RegisterIngressServiceServer(server, NewIngressHandler(nil, log))
+	pb.RegisterTokenServiceServer(server, NewTokenHandler(nil, log))
+	pb.RegisterSubjectConfigServiceServer(server, NewSubjectConfigHandler(nil, log))
+	pb.RegisterSchemaServiceServer(server, NewSchemaHandler(nil, log))
+	reflection.Register(server)
+
+	for _, method := range IngressPolicy.Missing(server.GetServiceInfo()) {
+		t.Errorf("%s has no authorization rule", method)
+	}
+}
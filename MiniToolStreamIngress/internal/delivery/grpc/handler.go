@@ -2,22 +2,56 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 	"github.com/moroshma/MiniToolStreamConnector/auth"
 )
 
+const (
+	// headerExpectedLastSequence makes a publish conditional on the newest published sequence
+	headerExpectedLastSequence = "expected-last-sequence"
+	// headerExpectedLastSubjectSequence makes a publish conditional on the newest sequence of the subject
+	headerExpectedLastSubjectSequence = "expected-last-subject-sequence"
+
+	// headerDeliverAt delays delivery until an RFC 3339 time or Unix timestamp
+	headerDeliverAt = "deliver-at"
+	// headerDelay delays delivery by a duration such as "90s" or "10m"
+	headerDelay = "delay"
+
+	// headerExpiresAt limits the lifetime of a message to an RFC 3339 time or Unix timestamp
+	headerExpiresAt = "expires-at"
+	// headerTTL limits the lifetime of a message to a duration such as "5s"
+	headerTTL = "ttl"
+
+	// headerPublisherID carries the client_id of the publisher, used for per-client storage quotas
+	headerPublisherID = "publisher-id"
+
+	// statusCodeSequenceConflict is returned when publish expectations do not hold
+	statusCodeSequenceConflict = 2
+	// statusCodeSchemaViolation is returned when the payload does not match the subject schema
+	statusCodeSchemaViolation = 3
+)
+
 // IngressHandler implements the gRPC IngressService
 type IngressHandler struct {
 	pb.UnimplementedIngressServiceServer
 	publishUC *usecase.PublishUseCase
 	logger    *logger.Logger
+	tenants   *Tenants
 }
 
 // NewIngressHandler creates a new gRPC handler instance
@@ -28,6 +62,11 @@ func NewIngressHandler(publishUC *usecase.PublishUseCase, log *logger.Logger) *I
 	}
 }
 
+// SetTenants scopes subjects per tenant of the client, nil disables it
+func (h *IngressHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
 // Publish implements the Publish RPC method
 func (h *IngressHandler) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
 	// Validate request is not nil
@@ -36,6 +75,31 @@ func (h *IngressHandler) Publish(ctx context.Context, req *pb.PublishRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
 	}
 
+	// Validate request
+	if req.Subject == "" {
+		h.logger.Warn("Publish request rejected: empty subject")
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   1,
+			ErrorMessage: "subject cannot be empty",
+		}, nil
+	}
+
+	// Subjects outside the grammar could be mistaken for claim patterns, so check before access
+	if err := subject.Validate(req.Subject); err != nil {
+		h.logger.Warn("Publish request rejected: invalid subject",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   1,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
 	// Check authorization if claims are present in context
 	if claims, ok := auth.GetClaimsFromContext(ctx); ok {
 		h.logger.Info("Received authenticated Publish request",
@@ -62,36 +126,113 @@ func (h *IngressHandler) Publish(ctx context.Context, req *pb.PublishRequest) (*
 		)
 	}
 
-	// Validate request
-	if req.Subject == "" {
-		h.logger.Warn("Publish request rejected: empty subject")
+	// Access was checked against the name the client uses, storage uses the tenant's name
+	tenant, storedSubject, err := h.tenants.scope(ctx, req.Subject)
+	if err != nil {
+		h.logger.Warn("Publish rejected: invalid tenant",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return nil, status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+
+	// Convert headers from proto map to Go map
+	headers := make(map[string]string)
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+
+	// Headers describing how the payload is stored must come from the server only
+	if err := checkReservedHeaders(headers); err != nil {
+		h.logger.Warn("Publish request rejected: reserved header",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
 		return &pb.PublishResponse{
 			Sequence:     0,
 			ObjectName:   "",
 			StatusCode:   1,
-			ErrorMessage: "subject cannot be empty",
+			ErrorMessage: err.Error(),
 		}, nil
 	}
+	// Storage usage is counted per publisher-id
+	if claims, ok := auth.GetClaimsFromContext(ctx); ok && claims.ClientID != "" {
+		headers[headerPublisherID] = claims.ClientID
+	}
 
-	// Convert headers from proto map to Go map
-	headers := make(map[string]string)
-	for k, v := range req.Headers {
-		headers[k] = v
+	// Optimistic-concurrency conditions are not stored with the message
+	expect, err := parseExpectations(headers)
+	if err != nil {
+		h.logger.Warn("Publish request rejected: invalid expectation header",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   1,
+			ErrorMessage: err.Error(),
+		}, nil
 	}
 
-	// Add data size to headers if data is provided
-	if len(req.Data) > 0 {
-		headers["data-size"] = fmt.Sprintf("%d", len(req.Data))
+	deliverAt, err := parseDeliverAt(headers, time.Now())
+	if err != nil {
+		h.logger.Warn("Publish request rejected: invalid delivery time",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   1,
+			ErrorMessage: err.Error(),
+		}, nil
 	}
 
+	if err := parseExpiresAt(headers, time.Now()); err != nil {
+		h.logger.Warn("Publish request rejected: invalid expiry",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   1,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+	h.tenants.applyDefaults(tenant, headers, time.Now(), deliverAt)
+
 	// Call use case
 	ucReq := &usecase.PublishRequest{
-		Subject: req.Subject,
-		Data:    req.Data,
-		Headers: headers,
+		Subject:   storedSubject,
+		Data:      req.Data,
+		Headers:   headers,
+		Expect:    expect,
+		DeliverAt: deliverAt,
 	}
 
 	resp, err := h.publishUC.Publish(ctx, ucReq)
+	if errors.Is(err, entity.ErrSequenceConflict) {
+		h.logger.Info("Publish rejected: sequence conflict",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   statusCodeSequenceConflict,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+	if errors.Is(err, entity.ErrSchemaViolation) {
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   "",
+			StatusCode:   statusCodeSchemaViolation,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
 	if err != nil {
 		h.logger.Error("Publish use case failed",
 			logger.String("subject", req.Subject),
@@ -105,17 +246,178 @@ func (h *IngressHandler) Publish(ctx context.Context, req *pb.PublishRequest) (*
 		}, nil
 	}
 
+	if resp.ScheduleID > 0 {
+		h.logger.Info("Publish request scheduled",
+			logger.String("subject", req.Subject),
+			logger.Uint64("schedule_id", resp.ScheduleID),
+			logger.String("deliver_at", headers[headerDeliverAt]),
+		)
+
+		// The sequence is assigned on delivery, so only the schedule id can be returned
+		return &pb.PublishResponse{
+			Sequence:     0,
+			ObjectName:   resp.ObjectName,
+			StatusCode:   0,
+			ErrorMessage: "",
+			ScheduleId:   resp.ScheduleID,
+			DeliverAt:    timestamppb.New(deliverAt),
+		}, nil
+	}
+
 	h.logger.Info("Publish request completed successfully",
 		logger.String("subject", req.Subject),
 		logger.Uint64("sequence", resp.Sequence),
+		logger.Uint64("subject_sequence", resp.SubjectSequence),
 		logger.String("object_name", resp.ObjectName),
 	)
 
 	// Return response
 	return &pb.PublishResponse{
-		Sequence:     resp.Sequence,
-		ObjectName:   resp.ObjectName,
-		StatusCode:   0,
-		ErrorMessage: "",
+		Sequence:        resp.Sequence,
+		ObjectName:      resp.ObjectName,
+		StatusCode:      0,
+		ErrorMessage:    "",
+		SubjectSequence: resp.SubjectSequence,
 	}, nil
 }
+
+// reservedHeaders describe the stored payload and are written by the publish use case
+// A forged value would make egress decrypt or decode the payload wrongly,
+// claim a schema the payload was never validated against, or charge the
+// stored bytes to another client
+var reservedHeaders = []string{
+	encryption.HeaderEncryption,
+	encryption.HeaderKeyName,
+	encryption.HeaderDataKey,
+	encryption.HeaderContext,
+	compression.HeaderContentEncoding,
+	compression.HeaderOriginalSize,
+	usecase.HeaderSchemaID,
+	usecase.HeaderSchemaVersion,
+	headerPublisherID,
+}
+
+// checkReservedHeaders rejects headers only the server may set
+func checkReservedHeaders(headers map[string]string) error {
+	for _, name := range reservedHeaders {
+		if _, ok := headers[name]; ok {
+			return fmt.Errorf("%s header is reserved and set by the server", name)
+		}
+	}
+	return nil
+}
+
+// parseExpectations extracts optimistic-concurrency headers and removes them from headers
+// Returns nil if the publish is unconditional
+func parseExpectations(headers map[string]string) (*entity.PublishExpectations, error) {
+	var expect *entity.PublishExpectations
+
+	for _, name := range []string{headerExpectedLastSequence, headerExpectedLastSubjectSequence} {
+		raw, ok := headers[name]
+		if !ok {
+			continue
+		}
+		delete(headers, name)
+
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: must be an unsigned integer", name, raw)
+		}
+
+		if expect == nil {
+			expect = &entity.PublishExpectations{}
+		}
+		if name == headerExpectedLastSequence {
+			expect.LastSequence = &value
+		} else {
+			expect.LastSubjectSequence = &value
+		}
+	}
+
+	return expect, nil
+}
+
+// parseDeliverAt extracts the delivery time from deliver-at or delay headers
+// The delay header is removed and deliver-at is rewritten in RFC 3339 (UTC)
+// so the stored message records when it was due
+// Returns the zero time if delivery is not delayed
+func parseDeliverAt(headers map[string]string, now time.Time) (time.Time, error) {
+	rawAt, hasAt := headers[headerDeliverAt]
+	rawDelay, hasDelay := headers[headerDelay]
+
+	var deliverAt time.Time
+	switch {
+	case hasAt && hasDelay:
+		return time.Time{}, fmt.Errorf("%s and %s headers are mutually exclusive", headerDeliverAt, headerDelay)
+	case hasAt:
+		t, ok := parseTimestamp(rawAt)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid %s header %q: must be an RFC 3339 time or Unix timestamp", headerDeliverAt, rawAt)
+		}
+		deliverAt = t
+	case hasDelay:
+		delete(headers, headerDelay)
+		delay, err := time.ParseDuration(rawDelay)
+		if err != nil || delay < 0 {
+			return time.Time{}, fmt.Errorf("invalid %s header %q: must be a non-negative duration such as 90s or 10m", headerDelay, rawDelay)
+		}
+		deliverAt = now.Add(delay)
+	default:
+		return time.Time{}, nil
+	}
+
+	headers[headerDeliverAt] = deliverAt.UTC().Format(time.RFC3339)
+	return deliverAt, nil
+}
+
+// parseExpiresAt validates expires-at or ttl headers of a message
+// The ttl header is removed and expires-at is rewritten as a Unix timestamp,
+// which is what Tarantool indexes for cleanup
+func parseExpiresAt(headers map[string]string, now time.Time) error {
+	rawAt, hasAt := headers[headerExpiresAt]
+	rawTTL, hasTTL := headers[headerTTL]
+
+	var expiresAt time.Time
+	switch {
+	case hasAt && hasTTL:
+		return fmt.Errorf("%s and %s headers are mutually exclusive", headerExpiresAt, headerTTL)
+	case hasAt:
+		t, ok := parseTimestamp(rawAt)
+		if !ok {
+			return fmt.Errorf("invalid %s header %q: must be an RFC 3339 time or Unix timestamp", headerExpiresAt, rawAt)
+		}
+		expiresAt = t
+	case hasTTL:
+		delete(headers, headerTTL)
+		ttl, err := time.ParseDuration(rawTTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid %s header %q: must be a positive duration such as 5s or 1m", headerTTL, rawTTL)
+		}
+		expiresAt = now.Add(ttl)
+	default:
+		return nil
+	}
+
+	if !expiresAt.After(now) {
+		return fmt.Errorf("message already expired at %s", expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	// Round up so a message never expires before the requested time
+	unix := expiresAt.Unix()
+	if expiresAt.Nanosecond() > 0 {
+		unix++
+	}
+	headers[headerExpiresAt] = strconv.FormatInt(unix, 10)
+	return nil
+}
+
+// parseTimestamp parses an RFC 3339 time or a Unix timestamp in seconds
+func parseTimestamp(raw string) (time.Time, bool) {
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0), true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
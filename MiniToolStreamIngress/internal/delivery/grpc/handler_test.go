@@ -2,12 +2,17 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/moroshma/MiniToolStreamConnector/model"
 
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
 )
 
 type mockPublishUseCase struct {
@@ -44,6 +49,55 @@ func TestNewIngressHandler(t *testing.T) {
 	}
 }
 
+func TestIngressHandler_Publish_InvalidSubject(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	handler := &IngressHandler{
+		publishUC: &usecase.PublishUseCase{},
+		logger:    log,
+	}
+
+	for _, subject := range []string{"orders/eu", "orders.*", "$SYS.health", "orders..eu"} {
+		resp, err := handler.Publish(context.Background(), &pb.PublishRequest{Subject: subject, Data: []byte("x")})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != 1 || !strings.Contains(resp.ErrorMessage, "invalid subject") {
+			t.Errorf("expected %q to be rejected, got status %d: %s", subject, resp.StatusCode, resp.ErrorMessage)
+		}
+	}
+}
+
+func TestIngressHandler_Publish_ReservedHeaders(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	allocated := false
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			allocated = true
+			return 1, nil
+		},
+	}
+	handler := NewIngressHandler(usecase.NewPublishUseCase(msgRepo, &mockStorageRepository{}, log), log)
+
+	for _, name := range []string{"encryption", "encryption-key", "encryption-data-key", "encryption-context", "content-encoding", "original-size", "schema-id", "schema-version", "publisher-id"} {
+		resp, err := handler.Publish(context.Background(), &pb.PublishRequest{
+			Subject: "orders",
+			Data:    []byte("plain"),
+			Headers: map[string]string{name: "forged"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != 1 || !strings.Contains(resp.ErrorMessage, name) {
+			t.Errorf("expected %s to be rejected, got status %d: %s", name, resp.StatusCode, resp.ErrorMessage)
+		}
+	}
+	if allocated {
+		t.Error("expected no sequence allocated for a rejected publish")
+	}
+}
+
 func TestIngressHandler_Publish_EmptySubject(t *testing.T) {
 	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
 
@@ -80,9 +134,14 @@ func TestIngressHandler_Publish_EmptySubject(t *testing.T) {
 
 type mockMessageRepository struct {
 	getNextSeqFunc    func() (uint64, error)
-	insertMessageFunc func(sequence uint64, subject string, headers map[string]string, objectName string) error
+	insertMessageFunc func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error)
+	insertIfFunc      func(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error)
+	checkLimitsFunc   func(subject string, size int) (*entity.PublishLimits, error)
+	enforceLimitsFunc func(subject string) ([]entity.MessageInfo, error)
 	pingFunc          func() error
 	closeFunc         func() error
+	scheduleFunc      func(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error
+	memtxUsageFunc    func() (float64, error)
 }
 
 func (m *mockMessageRepository) PublishMessage(subject string, headers map[string]string) (uint64, error) {
@@ -111,22 +170,58 @@ func (m *mockMessageRepository) GetNextSequence() (uint64, error) {
 	return 0, nil
 }
 
-func (m *mockMessageRepository) InsertMessage(sequence uint64, subject string, headers map[string]string, objectName string) error {
+func (m *mockMessageRepository) InsertMessage(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
 	if m.insertMessageFunc != nil {
-		return m.insertMessageFunc(sequence, subject, headers, objectName)
+		return m.insertMessageFunc(subject, headers, objectName, payload)
+	}
+	return 0, 0, nil
+}
+
+func (m *mockMessageRepository) InsertMessageIf(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error) {
+	if m.insertIfFunc != nil {
+		return m.insertIfFunc(subject, headers, objectName, expect, payload)
+	}
+	return 0, 0, nil
+}
+
+func (m *mockMessageRepository) ScheduleMessage(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error {
+	if m.scheduleFunc != nil {
+		return m.scheduleFunc(sequence, subject, headers, objectName, deliverAt, payload)
 	}
 	return nil
 }
 
+func (m *mockMessageRepository) CheckPublishLimits(subject string, size int) (*entity.PublishLimits, error) {
+	if m.checkLimitsFunc != nil {
+		return m.checkLimitsFunc(subject, size)
+	}
+	return &entity.PublishLimits{Allowed: true}, nil
+}
+
+func (m *mockMessageRepository) GetMemtxUsage() (float64, error) {
+	if m.memtxUsageFunc != nil {
+		return m.memtxUsageFunc()
+	}
+	return 0, nil
+}
+
+func (m *mockMessageRepository) EnforceSubjectLimits(subject string) ([]entity.MessageInfo, error) {
+	if m.enforceLimitsFunc != nil {
+		return m.enforceLimitsFunc(subject)
+	}
+	return nil, nil
+}
+
 type mockStorageRepository struct {
-	uploadFunc       func(ctx context.Context, objectName string, data []byte, contentType string) error
+	uploadFunc       func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error
 	getURLFunc       func(objectName string) string
 	ensureBucketFunc func(ctx context.Context) error
+	deleteObjectFunc func(ctx context.Context, objectName string) error
 }
 
-func (m *mockStorageRepository) UploadData(ctx context.Context, objectName string, data []byte, contentType string) error {
+func (m *mockStorageRepository) UploadData(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 	if m.uploadFunc != nil {
-		return m.uploadFunc(ctx, objectName, data, contentType)
+		return m.uploadFunc(ctx, objectName, data, contentType, checksum)
 	}
 	return nil
 }
@@ -144,3 +239,273 @@ func (m *mockStorageRepository) EnsureBucket(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (m *mockStorageRepository) DeleteObject(ctx context.Context, objectName string) error {
+	if m.deleteObjectFunc != nil {
+		return m.deleteObjectFunc(ctx, objectName)
+	}
+	return nil
+}
+
+func TestParseExpectations(t *testing.T) {
+	headers := map[string]string{
+		"content-type":                   "application/json",
+		"expected-last-subject-sequence": "42",
+	}
+
+	expect, err := parseExpectations(headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expect == nil || expect.LastSubjectSequence == nil || *expect.LastSubjectSequence != 42 {
+		t.Fatalf("expected last subject sequence 42, got %+v", expect)
+	}
+	if expect.LastSequence != nil {
+		t.Errorf("expected no last sequence condition, got %d", *expect.LastSequence)
+	}
+	if _, ok := headers["expected-last-subject-sequence"]; ok {
+		t.Error("expectation header should not be stored with the message")
+	}
+	if headers["content-type"] != "application/json" {
+		t.Error("unrelated headers must be kept")
+	}
+
+	if expect, err := parseExpectations(map[string]string{}); err != nil || expect != nil {
+		t.Errorf("expected unconditional publish, got %+v, %v", expect, err)
+	}
+
+	if _, err := parseExpectations(map[string]string{"expected-last-sequence": "-1"}); err == nil {
+		t.Error("expected error for invalid header value")
+	}
+}
+
+func TestIngressHandler_Publish_SequenceConflict(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 8, nil
+		},
+		insertIfFunc: func(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error) {
+			return 0, 0, fmt.Errorf("failed to insert message: %w: expected last subject sequence 5, actual 7", entity.ErrSequenceConflict)
+		},
+	}
+	handler := NewIngressHandler(usecase.NewPublishUseCase(msgRepo, &mockStorageRepository{}, log), log)
+
+	resp, err := handler.Publish(context.Background(), &pb.PublishRequest{
+		Subject: "orders.42",
+		Headers: map[string]string{"expected-last-subject-sequence": "5"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != statusCodeSequenceConflict {
+		t.Errorf("expected status code %d, got %d", statusCodeSequenceConflict, resp.StatusCode)
+	}
+}
+
+func TestIngressHandler_Publish_SubjectSequence(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 8, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 9, 3, nil
+		},
+	}
+	handler := NewIngressHandler(usecase.NewPublishUseCase(msgRepo, &mockStorageRepository{}, log), log)
+
+	resp, err := handler.Publish(context.Background(), &pb.PublishRequest{Subject: "orders.42", Data: []byte("{}")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Sequence != 9 || resp.SubjectSequence != 3 {
+		t.Errorf("expected sequence 9 and subject sequence 3, got %d and %d", resp.Sequence, resp.SubjectSequence)
+	}
+}
+
+func TestIngressHandler_Publish_Scheduled(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 12, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			t.Error("a delayed message must not be inserted into its subject")
+			return 0, 0, nil
+		},
+	}
+	handler := NewIngressHandler(usecase.NewPublishUseCase(msgRepo, &mockStorageRepository{}, log), log)
+
+	deliverAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	resp, err := handler.Publish(context.Background(), &pb.PublishRequest{
+		Subject: "orders.42",
+		Data:    []byte("{}"),
+		Headers: map[string]string{"deliver-at": deliverAt.Format(time.RFC3339)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 0 || resp.Sequence != 0 {
+		t.Fatalf("expected a scheduled message without a sequence, got %+v", resp)
+	}
+	if resp.ScheduleId != 12 {
+		t.Errorf("expected schedule id 12, got %d", resp.ScheduleId)
+	}
+	if !resp.DeliverAt.AsTime().Equal(deliverAt) {
+		t.Errorf("expected deliver_at %v, got %v", deliverAt, resp.DeliverAt.AsTime())
+	}
+}
+
+func TestIngressHandler_Publish_PublisherID(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	var stored map[string]string
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 1, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	handler := NewIngressHandler(usecase.NewPublishUseCase(msgRepo, &mockStorageRepository{}, log), log)
+
+	// Stamped by the handler, whether or not quotas are enabled
+	ctx := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "loader", Permissions: []string{"publish"}})
+	if _, err := handler.Publish(ctx, &pb.PublishRequest{Subject: "orders", Data: []byte("{}")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored["publisher-id"] != "loader" {
+		t.Errorf("expected publisher-id loader, got %v", stored)
+	}
+
+	stored = nil
+	if _, err := handler.Publish(context.Background(), &pb.PublishRequest{Subject: "orders", Data: []byte("{}")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := stored["publisher-id"]; ok {
+		t.Errorf("expected no publisher-id for an unauthenticated publish, got %v", stored)
+	}
+}
+
+type mockSchemaLookup struct {
+	getSchemaFunc func(subject string, version uint64) (*entity.Schema, error)
+}
+
+func (m *mockSchemaLookup) GetSchema(subject string, version uint64) (*entity.Schema, error) {
+	if m.getSchemaFunc != nil {
+		return m.getSchemaFunc(subject, version)
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func TestIngressHandler_Publish_SchemaViolation(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	publishUC := usecase.NewPublishUseCase(&mockMessageRepository{}, &mockStorageRepository{}, log)
+	publishUC.SetSchemaRegistry(&mockSchemaLookup{
+		getSchemaFunc: func(subject string, version uint64) (*entity.Schema, error) {
+			return &entity.Schema{ID: 1, Subject: subject, Version: 1, Format: "json", Definition: []byte(`{"type":"object"}`)}, nil
+		},
+	})
+	handler := NewIngressHandler(publishUC, log)
+
+	resp, err := handler.Publish(context.Background(), &pb.PublishRequest{
+		Subject: "documents.json",
+		Data:    []byte(`["not", "an", "object"]`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != statusCodeSchemaViolation {
+		t.Errorf("expected status code %d, got %d", statusCodeSchemaViolation, resp.StatusCode)
+	}
+}
+
+func TestParseDeliverAt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		want      time.Time
+		wantError bool
+	}{
+		{name: "not delayed", headers: map[string]string{}},
+		{name: "delay", headers: map[string]string{"delay": "10m"}, want: now.Add(10 * time.Minute)},
+		{name: "rfc3339", headers: map[string]string{"deliver-at": "2026-03-01T15:00:00+03:00"}, want: now},
+		{name: "unix timestamp", headers: map[string]string{"deliver-at": "1772370000"}, want: now.Add(time.Hour)},
+		{name: "both headers", headers: map[string]string{"deliver-at": "1772370000", "delay": "1m"}, wantError: true},
+		{name: "negative delay", headers: map[string]string{"delay": "-1m"}, wantError: true},
+		{name: "malformed time", headers: map[string]string{"deliver-at": "tomorrow"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeliverAt(tt.headers, now)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if _, ok := tt.headers["delay"]; ok {
+				t.Error("delay header should not be stored with the message")
+			}
+			if !got.IsZero() && tt.headers["deliver-at"] != got.UTC().Format(time.RFC3339) {
+				t.Errorf("expected normalized deliver-at header, got %q", tt.headers["deliver-at"])
+			}
+		})
+	}
+}
+
+func TestParseExpiresAt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	headers := map[string]string{"ttl": "5s"}
+	if err := parseExpiresAt(headers, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if headers["expires-at"] != "1772366405" {
+		t.Errorf("expected expires-at 1772366405, got %q", headers["expires-at"])
+	}
+	if _, ok := headers["ttl"]; ok {
+		t.Error("ttl header should not be stored with the message")
+	}
+
+	headers = map[string]string{"expires-at": "2026-03-01T12:00:30Z"}
+	if err := parseExpiresAt(headers, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if headers["expires-at"] != "1772366430" {
+		t.Errorf("expected expires-at 1772366430, got %q", headers["expires-at"])
+	}
+
+	headers = map[string]string{}
+	if err := parseExpiresAt(headers, now); err != nil || len(headers) != 0 {
+		t.Errorf("expected message without expiry, got %v, %v", headers, err)
+	}
+
+	invalid := []map[string]string{
+		{"ttl": "5s", "expires-at": "1772366430"},
+		{"ttl": "0s"},
+		{"ttl": "soon"},
+		{"expires-at": "1772366399"},
+	}
+	for _, h := range invalid {
+		if err := parseExpiresAt(h, now); err == nil {
+			t.Errorf("expected error for %v", h)
+		}
+	}
+}
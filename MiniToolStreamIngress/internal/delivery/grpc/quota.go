@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// metadataRetryAfter tells a rejected client how many seconds to wait
+const metadataRetryAfter = "retry-after"
+
+// QuotaUnaryInterceptor enforces publish quotas ahead of IngressHandler.Publish
+// It must run after authentication so the client ID is known. Limits are
+// matched against the subject as stored, so tenant patterns apply
+func QuotaUnaryInterceptor(limiter *quota.Limiter, tenants *Tenants, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		publish, ok := req.(*pb.PublishRequest)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		var clientID string
+		if claims, ok := auth.GetClaimsFromContext(ctx); ok {
+			clientID = claims.ClientID
+		}
+
+		// An invalid tenant is rejected by the handler
+		_, subj, err := tenants.scope(ctx, publish.Subject)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		size := int64(len(publish.Data))
+		err = limiter.CheckStorage(clientID, subj, size)
+		if err == nil {
+			err = limiter.Acquire(clientID, subj, 1, size)
+		}
+		if err != nil {
+			return nil, quotaError(ctx, log, clientID, subj, err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// quotaError converts a limiter error to a gRPC status
+// Rejections become RESOURCE_EXHAUSTED with RetryInfo details and a retry-after trailer
+func quotaError(ctx context.Context, log *logger.Logger, clientID, subject string, err error) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		log.Error("Failed to check quotas",
+			logger.String("client_id", clientID),
+			logger.String("subject", subject),
+			logger.Error(err),
+		)
+		return status.Error(codes.Unavailable, "failed to check quotas")
+	}
+
+	log.Warn("Publish rejected by quota",
+		logger.String("client_id", clientID),
+		logger.String("subject", subject),
+		logger.String("reason", exceeded.Reason),
+		logger.Duration("retry_after", exceeded.RetryAfter),
+	)
+
+	st := status.New(codes.ResourceExhausted, exceeded.Error())
+	if exceeded.RetryAfter > 0 {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(exceeded.RetryAfter)}); err == nil {
+			st = detailed
+		}
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(metadataRetryAfter, strconv.FormatInt(quota.RetryAfterSeconds(exceeded.RetryAfter), 10)))
+	}
+	return st.Err()
+}
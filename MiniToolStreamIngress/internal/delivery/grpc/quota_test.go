@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+)
+
+type mockQuotaStore struct {
+	consumeFunc func(buckets []quota.Bucket, force bool) (time.Duration, error)
+}
+
+func (m *mockQuotaStore) ConsumeQuota(buckets []quota.Bucket, force bool) (time.Duration, error) {
+	if m.consumeFunc != nil {
+		return m.consumeFunc(buckets, force)
+	}
+	return 0, nil
+}
+
+func TestQuotaUnaryInterceptor(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	wait := time.Duration(0)
+	var drawn []quota.Bucket
+	store := &mockQuotaStore{
+		consumeFunc: func(buckets []quota.Bucket, force bool) (time.Duration, error) {
+			drawn = buckets
+			return wait, nil
+		},
+	}
+	limiter := quota.NewLimiter(&quota.Policy{Default: quota.Limit{MessagesPerSecond: 10, BytesPerSecond: 1000}}, store, "publish")
+	interceptor := QuotaUnaryInterceptor(limiter, nil, log)
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return &pb.PublishResponse{}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/IngressService/Publish"}
+
+	req := &pb.PublishRequest{
+		Subject: "orders",
+		Data:    make([]byte, 300),
+	}
+	if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatal("expected the handler to be called")
+	}
+	if len(drawn) != 2 || drawn[0].Cost != 1 || drawn[1].Cost != 300 {
+		t.Errorf("unexpected buckets: %+v", drawn)
+	}
+
+	called = false
+	wait = 1500 * time.Millisecond
+	_, err := interceptor(context.Background(), req, info, handler)
+	st, _ := status.FromError(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if called {
+		t.Error("expected the handler not to be called")
+	}
+
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if r, ok := d.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != wait {
+		t.Errorf("expected RetryInfo of %s, got %v", wait, st.Details())
+	}
+}
@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// SchemaHandler implements the gRPC SchemaService
+// The Authorizer checks publish access to the subject, changing the
+// compatibility mode also requires the admin permission
+type SchemaHandler struct {
+	pb.UnimplementedSchemaServiceServer
+	schemaUC *usecase.SchemaUseCase
+	logger   *logger.Logger
+	tenants  *Tenants
+}
+
+// NewSchemaHandler creates a new SchemaService handler
+func NewSchemaHandler(schemaUC *usecase.SchemaUseCase, log *logger.Logger) *SchemaHandler {
+	return &SchemaHandler{
+		schemaUC: schemaUC,
+		logger:   log,
+	}
+}
+
+// SetTenants scopes subjects per tenant of the client, nil disables it
+func (h *SchemaHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// RegisterSchema implements the RegisterSchema RPC method
+func (h *SchemaHandler) RegisterSchema(ctx context.Context, req *pb.RegisterSchemaRequest) (*pb.Schema, error) {
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+	format, err := schema.ParseFormat(req.Format)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	stored, err := h.schemaUC.RegisterSchema(&entity.Schema{
+		Subject:     storedSubject,
+		Format:      format,
+		Definition:  req.Definition,
+		MessageType: req.MessageType,
+	})
+	if err != nil {
+		return nil, h.toStatus("RegisterSchema", err)
+	}
+	return toSchema(stored, req.Subject), nil
+}
+
+// GetSchema implements the GetSchema RPC method
+func (h *SchemaHandler) GetSchema(ctx context.Context, req *pb.GetSchemaRequest) (*pb.Schema, error) {
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *entity.Schema
+	if req.Id != 0 {
+		s, err = h.schemaUC.GetSchemaByID(req.Id)
+		// IDs are global, a schema of another subject is not revealed
+		if err == nil && s.Subject != storedSubject {
+			err = entity.ErrSchemaNotFound
+		}
+	} else {
+		s, err = h.schemaUC.GetSchema(storedSubject, req.Version)
+	}
+	if err != nil {
+		return nil, h.toStatus("GetSchema", err)
+	}
+	return toSchema(s, req.Subject), nil
+}
+
+// ListSchemaVersions implements the ListSchemaVersions RPC method
+func (h *SchemaHandler) ListSchemaVersions(ctx context.Context, req *pb.ListSchemaVersionsRequest) (*pb.ListSchemaVersionsResponse, error) {
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := h.schemaUC.ListSchemaVersions(storedSubject)
+	if err != nil {
+		return nil, h.toStatus("ListSchemaVersions", err)
+	}
+	return &pb.ListSchemaVersionsResponse{Versions: versions}, nil
+}
+
+// SetSchemaCompatibility implements the SetSchemaCompatibility RPC method
+func (h *SchemaHandler) SetSchemaCompatibility(ctx context.Context, req *pb.SetSchemaCompatibilityRequest) (*pb.SetSchemaCompatibilityResponse, error) {
+	// The Authorizer lets unauthenticated requests through when auth.require_auth
+	// is off, compatibility modes must stay closed to them regardless
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "SetSchemaCompatibility requires an authenticated client")
+	}
+	if !claims.CheckPermission(authz.PermissionAdmin) {
+		h.logger.Warn("Schema compatibility change denied",
+			logger.String("subject", req.Subject),
+			logger.String("client_id", claims.ClientID),
+		)
+		return nil, status.Errorf(codes.PermissionDenied, "SetSchemaCompatibility requires the %s permission", authz.PermissionAdmin)
+	}
+
+	storedSubject, err := h.scope(ctx, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.schemaUC.SetCompatibility(storedSubject, req.Compatibility); err != nil {
+		return nil, h.toStatus("SetSchemaCompatibility", err)
+	}
+	return &pb.SetSchemaCompatibilityResponse{}, nil
+}
+
+// scope validates the subject a client named and returns its stored name
+func (h *SchemaHandler) scope(ctx context.Context, subj string) (string, error) {
+	// Subjects outside the grammar could name subjects of other tenants
+	if err := subject.Validate(subj); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	_, storedSubject, err := h.tenants.scope(ctx, subj)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return storedSubject, nil
+}
+
+// toStatus maps use case errors to gRPC statuses
+func (h *SchemaHandler) toStatus(method string, err error) error {
+	switch {
+	case errors.Is(err, subject.ErrInvalid), errors.Is(err, entity.ErrInvalidSchema):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entity.ErrSchemaNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrIncompatibleSchema):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, entity.ErrSchemaConflict):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	h.logger.Error("Schema request failed", logger.String("method", method), logger.Error(err))
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
+
+// toSchema describes s under the subject name the client uses
+func toSchema(s *entity.Schema, name string) *pb.Schema {
+	return &pb.Schema{
+		Id:          s.ID,
+		Subject:     name,
+		Version:     s.Version,
+		Format:      string(s.Format),
+		Definition:  s.Definition,
+		MessageType: s.MessageType,
+		CreatedAt:   timestamppb.New(s.CreatedAt),
+	}
+}
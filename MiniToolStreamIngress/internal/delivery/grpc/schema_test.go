@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// memorySchemaRepository keeps schema versions in memory
+type memorySchemaRepository struct {
+	versions      map[string][]*entity.Schema
+	compatibility map[string]schema.Compatibility
+	nextID        uint64
+}
+
+func (m *memorySchemaRepository) RegisterSchema(s *entity.Schema, expectedVersion uint64) (*entity.Schema, error) {
+	versions := m.versions[s.Subject]
+	if uint64(len(versions)) != expectedVersion {
+		return nil, entity.ErrSchemaConflict
+	}
+	m.nextID++
+	stored := *s
+	stored.ID = m.nextID
+	stored.Version = expectedVersion + 1
+	m.versions[s.Subject] = append(versions, &stored)
+	return &stored, nil
+}
+
+func (m *memorySchemaRepository) GetSchema(subject string, version uint64) (*entity.Schema, error) {
+	versions := m.versions[subject]
+	if version == 0 {
+		version = uint64(len(versions))
+	}
+	if version == 0 || version > uint64(len(versions)) {
+		return nil, entity.ErrSchemaNotFound
+	}
+	return versions[version-1], nil
+}
+
+func (m *memorySchemaRepository) GetSchemaByID(id uint64) (*entity.Schema, error) {
+	for _, versions := range m.versions {
+		for _, s := range versions {
+			if s.ID == id {
+				return s, nil
+			}
+		}
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func (m *memorySchemaRepository) ListSchemaVersions(subject string) ([]uint64, error) {
+	versions := []uint64{}
+	for _, s := range m.versions[subject] {
+		versions = append(versions, s.Version)
+	}
+	return versions, nil
+}
+
+func (m *memorySchemaRepository) GetSchemaCompatibility(subject string) (schema.Compatibility, error) {
+	if mode, ok := m.compatibility[subject]; ok {
+		return mode, nil
+	}
+	return schema.CompatibilityBackward, nil
+}
+
+func (m *memorySchemaRepository) SetSchemaCompatibility(subject string, mode schema.Compatibility) error {
+	m.compatibility[subject] = mode
+	return nil
+}
+
+func newSchemaHandler() (*SchemaHandler, *memorySchemaRepository) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := &memorySchemaRepository{
+		versions:      make(map[string][]*entity.Schema),
+		compatibility: make(map[string]schema.Compatibility),
+	}
+	return NewSchemaHandler(usecase.NewSchemaUseCase(repo, log), log), repo
+}
+
+const orderSchema = `{"type":"object","properties":{"id":{"type":"string"}}}`
+
+func TestSchemaHandler(t *testing.T) {
+	h, repo := newSchemaHandler()
+	h.SetTenants(NewTenants(nil))
+
+	publisher := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "acme/loader", Permissions: []string{auth.PermissionPublish}, AllowedSubjects: []string{"*"}})
+
+	registered, err := h.RegisterSchema(publisher, &pb.RegisterSchemaRequest{Subject: "orders", Format: "json", Definition: []byte(orderSchema)})
+	if err != nil {
+		t.Fatalf("RegisterSchema failed: %v", err)
+	}
+	if registered.Subject != "orders" || registered.Version != 1 || registered.Id == 0 {
+		t.Errorf("unexpected schema: %+v", registered)
+	}
+	if _, ok := repo.versions["$TENANT.acme.orders"]; !ok {
+		t.Errorf("expected the schema to be stored under the tenant, got %v", repo.versions)
+	}
+
+	got, err := h.GetSchema(publisher, &pb.GetSchemaRequest{Subject: "orders"})
+	if err != nil || got.Version != 1 || string(got.Definition) != orderSchema {
+		t.Fatalf("unexpected schema %+v, %v", got, err)
+	}
+	byID, err := h.GetSchema(publisher, &pb.GetSchemaRequest{Subject: "orders", Id: registered.Id})
+	if err != nil || byID.Version != 1 {
+		t.Fatalf("unexpected schema %+v, %v", byID, err)
+	}
+	// IDs are global, they must not reveal schemas of other subjects
+	if _, err := h.GetSchema(publisher, &pb.GetSchemaRequest{Subject: "invoices", Id: registered.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a schema of another subject, got %v", err)
+	}
+
+	listed, err := h.ListSchemaVersions(publisher, &pb.ListSchemaVersionsRequest{Subject: "orders"})
+	if err != nil || len(listed.Versions) != 1 || listed.Versions[0] != 1 {
+		t.Fatalf("unexpected versions %+v, %v", listed, err)
+	}
+
+	// Adding a required field breaks backward compatibility
+	breaking := `{"type":"object","required":["total"],"properties":{"id":{"type":"string"},"total":{"type":"number"}}}`
+	if _, err := h.RegisterSchema(publisher, &pb.RegisterSchemaRequest{Subject: "orders", Format: "json", Definition: []byte(breaking)}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+	if _, err := h.RegisterSchema(publisher, &pb.RegisterSchemaRequest{Subject: "orders", Format: "avro", Definition: []byte(orderSchema)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown format, got %v", err)
+	}
+	if _, err := h.RegisterSchema(publisher, &pb.RegisterSchemaRequest{Subject: "orders", Format: "json", Definition: []byte("{")}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a malformed definition, got %v", err)
+	}
+	if _, err := h.GetSchema(publisher, &pb.GetSchemaRequest{Subject: "$TENANT.globex.orders"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a stored name, got %v", err)
+	}
+}
+
+func TestSchemaHandler_SetSchemaCompatibility(t *testing.T) {
+	h, repo := newSchemaHandler()
+
+	publisher := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "loader", Permissions: []string{auth.PermissionPublish}})
+	admin := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "ops", Permissions: []string{authz.PermissionAdmin}})
+	req := &pb.SetSchemaCompatibilityRequest{Subject: "orders", Compatibility: "full"}
+
+	if _, err := h.SetSchemaCompatibility(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+	if _, err := h.SetSchemaCompatibility(publisher, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+	if _, err := h.SetSchemaCompatibility(admin, &pb.SetSchemaCompatibilityRequest{Subject: "orders", Compatibility: "sideways"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	if _, err := h.SetSchemaCompatibility(admin, req); err != nil {
+		t.Fatalf("SetSchemaCompatibility failed: %v", err)
+	}
+	if repo.compatibility["orders"] != schema.CompatibilityFull {
+		t.Errorf("expected full compatibility, got %q", repo.compatibility["orders"])
+	}
+}
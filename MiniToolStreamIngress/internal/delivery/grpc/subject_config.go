@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// SubjectConfigHandler implements the gRPC SubjectConfigService
+// Every method requires the admin permission
+type SubjectConfigHandler struct {
+	pb.UnimplementedSubjectConfigServiceServer
+	configUC *usecase.SubjectConfigUseCase
+	logger   *logger.Logger
+	tenants  *Tenants
+}
+
+// NewSubjectConfigHandler creates a new SubjectConfigService handler
+func NewSubjectConfigHandler(configUC *usecase.SubjectConfigUseCase, log *logger.Logger) *SubjectConfigHandler {
+	return &SubjectConfigHandler{
+		configUC: configUC,
+		logger:   log,
+	}
+}
+
+// SetTenants limits admins of a tenant to the subjects of their tenant, nil disables it
+// Admins of the default tenant use stored names, including those of other tenants
+func (h *SubjectConfigHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// DeclareSubject implements the DeclareSubject RPC method
+func (h *SubjectConfigHandler) DeclareSubject(ctx context.Context, req *pb.DeclareSubjectRequest) (*pb.SubjectConfig, error) {
+	tenant, storedSubject, err := h.access(ctx, "DeclareSubject", req.GetConfig().GetSubject())
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := h.configUC.DeclareSubject(&entity.SubjectConfig{
+		Subject:    storedSubject,
+		MaxMsgs:    req.Config.MaxMsgs,
+		MaxBytes:   req.Config.MaxBytes,
+		MaxAge:     time.Duration(req.Config.MaxAgeSeconds) * time.Second,
+		MaxMsgSize: req.Config.MaxMsgSize,
+		Discard:    entity.DiscardPolicy(req.Config.Discard),
+		Retention:  entity.RetentionPolicy(req.Config.Retention),
+	})
+	if err != nil {
+		return nil, h.toStatus("DeclareSubject", err)
+	}
+	return toSubjectConfig(stored, tenant), nil
+}
+
+// GetSubjectConfig implements the GetSubjectConfig RPC method
+func (h *SubjectConfigHandler) GetSubjectConfig(ctx context.Context, req *pb.GetSubjectConfigRequest) (*pb.SubjectConfig, error) {
+	tenant, storedSubject, err := h.access(ctx, "GetSubjectConfig", req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := h.configUC.GetSubject(storedSubject)
+	if err != nil {
+		return nil, h.toStatus("GetSubjectConfig", err)
+	}
+	return toSubjectConfig(cfg, tenant), nil
+}
+
+// DeleteSubjectConfig implements the DeleteSubjectConfig RPC method
+func (h *SubjectConfigHandler) DeleteSubjectConfig(ctx context.Context, req *pb.DeleteSubjectConfigRequest) (*pb.DeleteSubjectConfigResponse, error) {
+	_, storedSubject, err := h.access(ctx, "DeleteSubjectConfig", req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.configUC.DeleteSubject(storedSubject); err != nil {
+		return nil, h.toStatus("DeleteSubjectConfig", err)
+	}
+	return &pb.DeleteSubjectConfigResponse{}, nil
+}
+
+// ListSubjectConfigs implements the ListSubjectConfigs RPC method
+func (h *SubjectConfigHandler) ListSubjectConfigs(ctx context.Context, req *pb.ListSubjectConfigsRequest) (*pb.ListSubjectConfigsResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "ListSubjectConfigs")
+	if err != nil {
+		return nil, err
+	}
+
+	configs, err := h.configUC.ListSubjects()
+	if err != nil {
+		return nil, h.toStatus("ListSubjectConfigs", err)
+	}
+
+	resp := &pb.ListSubjectConfigsResponse{Configs: make([]*pb.SubjectConfig, 0, len(configs))}
+	prefix := subject.Qualify(tenant, "")
+	for _, cfg := range configs {
+		if tenant != "" && !strings.HasPrefix(cfg.Subject, prefix) {
+			continue
+		}
+		resp.Configs = append(resp.Configs, toSubjectConfig(cfg, tenant))
+	}
+	return resp, nil
+}
+
+// access checks the caller may configure subj and returns its tenant and the stored name of subj
+func (h *SubjectConfigHandler) access(ctx context.Context, method, subj string) (string, string, error) {
+	tenant, err := h.requireAdmin(ctx, method)
+	if err != nil {
+		return "", "", err
+	}
+	if subj == "" {
+		return "", "", status.Error(codes.InvalidArgument, "subject is required")
+	}
+	if tenant == "" {
+		return "", subj, nil
+	}
+
+	// Stored names of other tenants are not subjects a tenant can name
+	if err := subject.Validate(subj); err != nil {
+		return "", "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return tenant, subject.Qualify(tenant, subj), nil
+}
+
+// requireAdmin rejects callers without the admin permission and returns their tenant
+// The Authorizer lets unauthenticated requests through when auth.require_auth
+// is off, subject limits must stay closed to them regardless
+func (h *SubjectConfigHandler) requireAdmin(ctx context.Context, method string) (string, error) {
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "%s requires an authenticated client", method)
+	}
+	if !claims.CheckPermission(authz.PermissionAdmin) {
+		h.logger.Warn("Subject configuration denied",
+			logger.String("method", method),
+			logger.String("client_id", claims.ClientID),
+		)
+		return "", status.Errorf(codes.PermissionDenied, "%s requires the %s permission", method, authz.PermissionAdmin)
+	}
+
+	if h.tenants == nil {
+		return "", nil
+	}
+	tenant, err := clientTenant(claims.ClientID)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return tenant, nil
+}
+
+// toStatus maps use case errors to gRPC statuses
+func (h *SubjectConfigHandler) toStatus(method string, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidSubjectConfig):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entity.ErrSubjectConfigNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	h.logger.Error("Subject config request failed", logger.String("method", method), logger.Error(err))
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
+
+// toSubjectConfig describes cfg under the name a client of tenant uses
+func toSubjectConfig(cfg *entity.SubjectConfig, tenant string) *pb.SubjectConfig {
+	name := cfg.Subject
+	if tenant != "" {
+		_, name = subject.Unqualify(cfg.Subject)
+	}
+	return &pb.SubjectConfig{
+		Subject:       name,
+		MaxMsgs:       cfg.MaxMsgs,
+		MaxBytes:      cfg.MaxBytes,
+		MaxAgeSeconds: int64(cfg.MaxAge / time.Second),
+		MaxMsgSize:    cfg.MaxMsgSize,
+		Discard:       string(cfg.Discard),
+		Retention:     string(cfg.Retention),
+		UpdatedAt:     timestamppb.New(cfg.UpdatedAt),
+	}
+}
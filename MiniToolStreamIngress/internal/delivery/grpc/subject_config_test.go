@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// memoryConfigRepository keeps subject configs in memory
+type memoryConfigRepository struct {
+	configs map[string]*entity.SubjectConfig
+}
+
+func (m *memoryConfigRepository) PutSubjectConfig(cfg *entity.SubjectConfig) (*entity.SubjectConfig, error) {
+	stored := *cfg
+	stored.UpdatedAt = time.Unix(1_700_000_000, 0).UTC()
+	m.configs[cfg.Subject] = &stored
+	return &stored, nil
+}
+
+func (m *memoryConfigRepository) GetSubjectConfig(subject string) (*entity.SubjectConfig, error) {
+	if cfg, ok := m.configs[subject]; ok {
+		return cfg, nil
+	}
+	return nil, entity.ErrSubjectConfigNotFound
+}
+
+func (m *memoryConfigRepository) DeleteSubjectConfig(subject string) error {
+	if _, ok := m.configs[subject]; !ok {
+		return entity.ErrSubjectConfigNotFound
+	}
+	delete(m.configs, subject)
+	return nil
+}
+
+func (m *memoryConfigRepository) ListSubjectConfigs() ([]*entity.SubjectConfig, error) {
+	configs := make([]*entity.SubjectConfig, 0, len(m.configs))
+	for _, cfg := range m.configs {
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+func newSubjectConfigHandler() (*SubjectConfigHandler, *memoryConfigRepository) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := &memoryConfigRepository{configs: make(map[string]*entity.SubjectConfig)}
+	return NewSubjectConfigHandler(usecase.NewSubjectConfigUseCase(repo, log), log), repo
+}
+
+func TestSubjectConfigHandler(t *testing.T) {
+	h, repo := newSubjectConfigHandler()
+
+	admin := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "ops", Permissions: []string{authz.PermissionAdmin}})
+	publisher := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "loader", Permissions: []string{auth.PermissionPublish}})
+	declare := &pb.DeclareSubjectRequest{Config: &pb.SubjectConfig{Subject: "orders", MaxMsgs: 100, MaxAgeSeconds: 3600}}
+
+	// Subject limits are closed to unauthenticated clients and clients without the admin permission
+	if _, err := h.DeclareSubject(context.Background(), declare); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+	if _, err := h.ListSubjectConfigs(publisher, &pb.ListSubjectConfigsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+	if _, err := h.DeclareSubject(admin, &pb.DeclareSubjectRequest{Config: &pb.SubjectConfig{Subject: "orders", Discard: "oldest"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	declared, err := h.DeclareSubject(admin, declare)
+	if err != nil {
+		t.Fatalf("DeclareSubject failed: %v", err)
+	}
+	if declared.MaxMsgs != 100 || declared.MaxAgeSeconds != 3600 || declared.Discard != "old" || declared.Retention != "limits" || declared.UpdatedAt.AsTime().IsZero() {
+		t.Errorf("unexpected config: %+v", declared)
+	}
+	if repo.configs["orders"].MaxAge != time.Hour {
+		t.Errorf("expected max age to be stored as 1h, got %v", repo.configs["orders"].MaxAge)
+	}
+
+	got, err := h.GetSubjectConfig(admin, &pb.GetSubjectConfigRequest{Subject: "orders"})
+	if err != nil || got.MaxMsgs != 100 {
+		t.Fatalf("unexpected config %+v, %v", got, err)
+	}
+	listed, err := h.ListSubjectConfigs(admin, &pb.ListSubjectConfigsRequest{})
+	if err != nil || len(listed.Configs) != 1 || listed.Configs[0].Subject != "orders" {
+		t.Fatalf("unexpected listing %+v, %v", listed, err)
+	}
+
+	if _, err := h.DeleteSubjectConfig(admin, &pb.DeleteSubjectConfigRequest{Subject: "orders"}); err != nil {
+		t.Fatalf("DeleteSubjectConfig failed: %v", err)
+	}
+	if _, err := h.GetSubjectConfig(admin, &pb.GetSubjectConfigRequest{Subject: "orders"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	if _, err := h.GetSubjectConfig(admin, &pb.GetSubjectConfigRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestSubjectConfigHandler_Tenant(t *testing.T) {
+	h, repo := newSubjectConfigHandler()
+	h.SetTenants(NewTenants(nil))
+	repo.configs["$TENANT.globex.orders"] = &entity.SubjectConfig{Subject: "$TENANT.globex.orders"}
+
+	tenantAdmin := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "acme/ops", Permissions: []string{authz.PermissionAdmin}})
+	declared, err := h.DeclareSubject(tenantAdmin, &pb.DeclareSubjectRequest{Config: &pb.SubjectConfig{Subject: "orders", MaxBytes: 1 << 20}})
+	if err != nil {
+		t.Fatalf("DeclareSubject failed: %v", err)
+	}
+	if declared.Subject != "orders" {
+		t.Errorf("expected the name the tenant uses, got %q", declared.Subject)
+	}
+	if _, ok := repo.configs["$TENANT.acme.orders"]; !ok {
+		t.Errorf("expected the config to be stored under the tenant, got %v", repo.configs)
+	}
+
+	listed, err := h.ListSubjectConfigs(tenantAdmin, &pb.ListSubjectConfigsRequest{})
+	if err != nil || len(listed.Configs) != 1 || listed.Configs[0].Subject != "orders" {
+		t.Fatalf("expected only the subjects of the tenant, got %+v, %v", listed, err)
+	}
+
+	// Tenant admins cannot name stored subjects of other tenants
+	if _, err := h.DeleteSubjectConfig(tenantAdmin, &pb.DeleteSubjectConfigRequest{Subject: "$TENANT.globex.orders"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	// Admins of the default tenant use stored names
+	operator := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "ops", Permissions: []string{authz.PermissionAdmin}})
+	listed, err = h.ListSubjectConfigs(operator, &pb.ListSubjectConfigsRequest{})
+	if err != nil || len(listed.Configs) != 2 {
+		t.Fatalf("expected every subject, got %+v, %v", listed, err)
+	}
+	if _, err := h.DeleteSubjectConfig(operator, &pb.DeleteSubjectConfigRequest{Subject: "$TENANT.globex.orders"}); err != nil {
+		t.Errorf("DeleteSubjectConfig failed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// tenantSeparator ends the tenant part of a JWT client_id, e.g. "acme/loader"
+const tenantSeparator = "/"
+
+// TenantSettings are defaults applied to publishes of a tenant
+type TenantSettings struct {
+	// TTL is the lifetime of messages published without expires-at or ttl headers
+	TTL time.Duration
+}
+
+// Tenants scopes subjects of authenticated clients per tenant
+// Clients without a tenant in their client_id and unauthenticated clients
+// belong to the default tenant, whose subjects are stored unqualified
+type Tenants struct {
+	settings map[string]TenantSettings
+}
+
+// NewTenants creates tenant scoping, tenants missing from settings get the defaults
+func NewTenants(settings map[string]TenantSettings) *Tenants {
+	return &Tenants{settings: settings}
+}
+
+// scope returns the tenant of the client in ctx and the stored name of subj
+// A nil Tenants keeps every subject as it is
+func (t *Tenants) scope(ctx context.Context, subj string) (string, string, error) {
+	if t == nil {
+		return "", subj, nil
+	}
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return "", subj, nil
+	}
+	tenant, err := clientTenant(claims.ClientID)
+	if err != nil {
+		return "", "", err
+	}
+	return tenant, subject.Qualify(tenant, subj), nil
+}
+
+// applyDefaults sets the expiry of a message published without one to the TTL of its tenant
+// The TTL of a delayed message starts at its delivery
+func (t *Tenants) applyDefaults(tenant string, headers map[string]string, now, deliverAt time.Time) {
+	if t == nil {
+		return
+	}
+	ttl := t.settings[tenant].TTL
+	if ttl <= 0 || headers[headerExpiresAt] != "" {
+		return
+	}
+	if deliverAt.After(now) {
+		now = deliverAt
+	}
+	headers[headerExpiresAt] = strconv.FormatInt(now.Add(ttl).Unix(), 10)
+}
+
+// clientTenant returns the tenant part of a client_id, "" for the default tenant
+func clientTenant(clientID string) (string, error) {
+	tenant, _, ok := strings.Cut(clientID, tenantSeparator)
+	if !ok {
+		return "", nil
+	}
+	if err := subject.ValidateTenant(tenant); err != nil {
+		return "", err
+	}
+	return tenant, nil
+}
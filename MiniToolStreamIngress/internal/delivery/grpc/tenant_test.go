@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClientTenant(t *testing.T) {
+	tests := []struct {
+		clientID string
+		want     string
+		wantErr  bool
+	}{
+		{"loader", "", false},
+		{"acme/loader", "acme", false},
+		{"acme-2/team/loader", "acme-2", false},
+		{"/loader", "", true},
+		{"Acme/loader", "", true},
+		{"ac.me/loader", "", true},
+	}
+	for _, tt := range tests {
+		got, err := clientTenant(tt.clientID)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("clientTenant(%q) = %q, %v; want %q, error %v", tt.clientID, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTenants_Scope_WithoutClaims(t *testing.T) {
+	var disabled *Tenants
+	if tenant, stored, err := disabled.scope(context.Background(), "orders"); err != nil || tenant != "" || stored != "orders" {
+		t.Errorf("nil Tenants: got %q, %q, %v", tenant, stored, err)
+	}
+
+	tenants := NewTenants(nil)
+	if tenant, stored, err := tenants.scope(context.Background(), "orders"); err != nil || tenant != "" || stored != "orders" {
+		t.Errorf("unauthenticated client: got %q, %q, %v", tenant, stored, err)
+	}
+}
+
+func TestTenants_ApplyDefaults(t *testing.T) {
+	tenants := NewTenants(map[string]TenantSettings{"acme": {TTL: time.Hour}})
+	now := time.Unix(1000, 0)
+
+	headers := map[string]string{}
+	tenants.applyDefaults("acme", headers, now, time.Time{})
+	if got := headers[headerExpiresAt]; got != strconv.FormatInt(now.Add(time.Hour).Unix(), 10) {
+		t.Errorf("expected expiry one hour from now, got %q", got)
+	}
+
+	deliverAt := now.Add(10 * time.Minute)
+	headers = map[string]string{}
+	tenants.applyDefaults("acme", headers, now, deliverAt)
+	if got := headers[headerExpiresAt]; got != strconv.FormatInt(deliverAt.Add(time.Hour).Unix(), 10) {
+		t.Errorf("expected expiry one hour after delivery, got %q", got)
+	}
+
+	headers = map[string]string{headerExpiresAt: "5000"}
+	tenants.applyDefaults("acme", headers, now, time.Time{})
+	if headers[headerExpiresAt] != "5000" {
+		t.Errorf("expected explicit expiry to be kept, got %q", headers[headerExpiresAt])
+	}
+
+	headers = map[string]string{}
+	tenants.applyDefaults("other", headers, now, time.Time{})
+	if _, ok := headers[headerExpiresAt]; ok {
+		t.Error("expected no expiry for a tenant without ttl")
+	}
+}
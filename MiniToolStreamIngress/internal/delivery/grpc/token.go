@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// TokenHandler implements the gRPC TokenService
+// ExchangeAPIKey is public, managing keys requires the admin permission
+type TokenHandler struct {
+	pb.UnimplementedTokenServiceServer
+	tokenUC *usecase.TokenUseCase
+	logger  *logger.Logger
+	tenants *Tenants
+}
+
+// NewTokenHandler creates a new TokenService handler
+func NewTokenHandler(tokenUC *usecase.TokenUseCase, log *logger.Logger) *TokenHandler {
+	return &TokenHandler{
+		tokenUC: tokenUC,
+		logger:  log,
+	}
+}
+
+// SetTenants limits admins of a tenant to the keys of clients of their tenant, nil disables it
+// Subjects of a key are names within the tenant of its client, so they need no qualifying
+func (h *TokenHandler) SetTenants(tenants *Tenants) {
+	h.tenants = tenants
+}
+
+// ExchangeAPIKey implements the ExchangeAPIKey RPC method
+func (h *TokenHandler) ExchangeAPIKey(ctx context.Context, req *pb.ExchangeAPIKeyRequest) (*pb.ExchangeAPIKeyResponse, error) {
+	if req.GetApiKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "api_key is required")
+	}
+
+	token, expiresAt, err := h.tokenUC.Exchange(req.ApiKey)
+	if err != nil {
+		return nil, h.toStatus("ExchangeAPIKey", err)
+	}
+	return &pb.ExchangeAPIKeyResponse{Token: token, ExpiresAt: timestamppb.New(expiresAt)}, nil
+}
+
+// CreateAPIKey implements the CreateAPIKey RPC method
+func (h *TokenHandler) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "CreateAPIKey")
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	clientID, err := tenantClient(tenant, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &entity.APIKey{
+		ClientID:    clientID,
+		Subjects:    req.Subjects,
+		Permissions: req.Permissions,
+		TokenTTL:    time.Duration(req.TokenTtlSeconds) * time.Second,
+		Description: req.Description,
+	}
+	if req.ExpiresAt != nil {
+		k.ExpiresAt = req.ExpiresAt.AsTime()
+	}
+	raw, err := h.tokenUC.CreateAPIKey(k)
+	if err != nil {
+		return nil, h.toStatus("CreateAPIKey", err)
+	}
+	return &pb.CreateAPIKeyResponse{ApiKey: raw, Key: toKeyInfo(k)}, nil
+}
+
+// ListAPIKeys implements the ListAPIKeys RPC method
+func (h *TokenHandler) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "ListAPIKeys")
+	if err != nil {
+		return nil, err
+	}
+	clientID, err := tenantClient(tenant, req.GetClientId())
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := h.tokenUC.ListAPIKeys(clientID)
+	if err != nil {
+		return nil, h.toStatus("ListAPIKeys", err)
+	}
+	resp := &pb.ListAPIKeysResponse{Keys: make([]*pb.APIKeyInfo, 0, len(keys))}
+	for _, k := range keys {
+		if !ownsClient(tenant, k.ClientID) {
+			continue
+		}
+		resp.Keys = append(resp.Keys, toKeyInfo(k))
+	}
+	return resp, nil
+}
+
+// RevokeAPIKey implements the RevokeAPIKey RPC method
+func (h *TokenHandler) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	tenant, err := h.requireAdmin(ctx, "RevokeAPIKey")
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if tenant != "" {
+		// Keys of other tenants are reported as missing, like unknown ones
+		k, err := h.tokenUC.GetAPIKey(req.Id)
+		if err == nil && !ownsClient(tenant, k.ClientID) {
+			err = entity.ErrAPIKeyNotFound
+		}
+		if err != nil {
+			return nil, h.toStatus("RevokeAPIKey", err)
+		}
+	}
+
+	if err := h.tokenUC.RevokeAPIKey(req.Id); err != nil {
+		return nil, h.toStatus("RevokeAPIKey", err)
+	}
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// requireAdmin rejects callers without the admin permission and returns their tenant
+// The Authorizer lets unauthenticated requests through when auth.require_auth
+// is off, key management must stay closed to them regardless
+func (h *TokenHandler) requireAdmin(ctx context.Context, method string) (string, error) {
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "%s requires an authenticated client", method)
+	}
+	if !claims.CheckPermission(authz.PermissionAdmin) {
+		h.logger.Warn("API key management denied",
+			logger.String("method", method),
+			logger.String("client_id", claims.ClientID),
+		)
+		return "", status.Errorf(codes.PermissionDenied, "%s requires the %s permission", method, authz.PermissionAdmin)
+	}
+
+	if h.tenants == nil {
+		return "", nil
+	}
+	tenant, err := clientTenant(claims.ClientID)
+	if err != nil {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant: %v", err)
+	}
+	return tenant, nil
+}
+
+// tenantClient returns the client_id an admin of tenant names
+// Admins of a tenant may omit the tenant part, but cannot name clients of other tenants
+func tenantClient(tenant, clientID string) (string, error) {
+	if tenant == "" || clientID == "" {
+		return clientID, nil
+	}
+	if !strings.Contains(clientID, tenantSeparator) {
+		return tenant + tenantSeparator + clientID, nil
+	}
+	if !ownsClient(tenant, clientID) {
+		return "", status.Errorf(codes.PermissionDenied, "client_id %q is outside tenant %s", clientID, tenant)
+	}
+	return clientID, nil
+}
+
+// ownsClient reports whether an admin of tenant manages the keys of clientID
+func ownsClient(tenant, clientID string) bool {
+	if tenant == "" {
+		return true
+	}
+	owner, err := clientTenant(clientID)
+	return err == nil && owner == tenant
+}
+
+// toStatus maps use case errors to gRPC statuses
+func (h *TokenHandler) toStatus(method string, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidAPIKey):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidAPIKeyRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	h.logger.Error("Token service request failed", logger.String("method", method), logger.Error(err))
+	return status.Errorf(codes.Internal, "%s failed", method)
+}
+
+// toKeyInfo describes k without its hash
+func toKeyInfo(k *entity.APIKey) *pb.APIKeyInfo {
+	return &pb.APIKeyInfo{
+		Id:              k.ID,
+		ClientId:        k.ClientID,
+		Subjects:        k.Subjects,
+		Permissions:     k.Permissions,
+		TokenTtlSeconds: int64(k.TokenTTL / time.Second),
+		Description:     k.Description,
+		CreatedAt:       timestamp(k.CreatedAt),
+		ExpiresAt:       timestamp(k.ExpiresAt),
+		RevokedAt:       timestamp(k.RevokedAt),
+		LastUsedAt:      timestamp(k.LastUsedAt),
+	}
+}
+
+// timestamp converts t, leaving the zero time unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
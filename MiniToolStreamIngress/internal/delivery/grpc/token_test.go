@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/usecase"
+	"github.com/moroshma/MiniToolStream/pkg/authz"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// memoryKeyRepository keeps API keys in memory
+type memoryKeyRepository struct {
+	keys map[string]*entity.APIKey
+}
+
+func (m *memoryKeyRepository) CreateAPIKey(k *entity.APIKey) error {
+	stored := *k
+	m.keys[k.ID] = &stored
+	return nil
+}
+
+func (m *memoryKeyRepository) GetAPIKey(id string) (*entity.APIKey, error) {
+	if k, ok := m.keys[id]; ok {
+		return k, nil
+	}
+	return nil, entity.ErrAPIKeyNotFound
+}
+
+func (m *memoryKeyRepository) TouchAPIKey(id string) error { return nil }
+
+func (m *memoryKeyRepository) ListAPIKeys(clientID string) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	for _, k := range m.keys {
+		if clientID == "" || k.ClientID == clientID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memoryKeyRepository) RevokeAPIKey(id string) error {
+	k, ok := m.keys[id]
+	if !ok {
+		return entity.ErrAPIKeyNotFound
+	}
+	k.RevokedAt = time.Now()
+	return nil
+}
+
+// fakeSigner returns the client id as the token
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(claims *auth.Claims) (string, error) {
+	return "token-for-" + claims.ClientID, nil
+}
+
+func TestTokenHandler(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := &memoryKeyRepository{keys: make(map[string]*entity.APIKey)}
+	h := NewTokenHandler(usecase.NewTokenUseCase(repo, fakeSigner{}, 15*time.Minute, time.Hour, log), log)
+
+	admin := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "ops", Permissions: []string{authz.PermissionAdmin}})
+	publisher := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "loader", Permissions: []string{auth.PermissionPublish}})
+	create := &pb.CreateAPIKeyRequest{ClientId: "edge-7", Subjects: []string{"telemetry.*"}, Permissions: []string{auth.PermissionPublish}}
+
+	// Key management is closed to unauthenticated clients and clients without the admin permission
+	if _, err := h.CreateAPIKey(context.Background(), create); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+	if _, err := h.ListAPIKeys(publisher, &pb.ListAPIKeysRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+	if _, err := h.CreateAPIKey(admin, &pb.CreateAPIKeyRequest{ClientId: "edge-7"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	created, err := h.CreateAPIKey(admin, create)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if created.Key.TokenTtlSeconds != 900 || created.Key.CreatedAt == nil || created.Key.RevokedAt != nil {
+		t.Errorf("unexpected key info: %+v", created.Key)
+	}
+
+	exchanged, err := h.ExchangeAPIKey(context.Background(), &pb.ExchangeAPIKeyRequest{ApiKey: created.ApiKey})
+	if err != nil {
+		t.Fatalf("ExchangeAPIKey failed: %v", err)
+	}
+	if exchanged.Token != "token-for-edge-7" || exchanged.ExpiresAt == nil {
+		t.Errorf("unexpected exchange: %+v", exchanged)
+	}
+
+	listed, err := h.ListAPIKeys(admin, &pb.ListAPIKeysRequest{ClientId: "edge-7"})
+	if err != nil || len(listed.Keys) != 1 || listed.Keys[0].Id != created.Key.Id {
+		t.Fatalf("unexpected listing %+v, %v", listed, err)
+	}
+
+	if _, err := h.RevokeAPIKey(admin, &pb.RevokeAPIKeyRequest{Id: "0000000000000000"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	if _, err := h.RevokeAPIKey(admin, &pb.RevokeAPIKeyRequest{Id: created.Key.Id}); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if _, err := h.ExchangeAPIKey(context.Background(), &pb.ExchangeAPIKeyRequest{ApiKey: created.ApiKey}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestTokenHandler_Tenants(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := &memoryKeyRepository{keys: make(map[string]*entity.APIKey)}
+	h := NewTokenHandler(usecase.NewTokenUseCase(repo, fakeSigner{}, 15*time.Minute, time.Hour, log), log)
+	h.SetTenants(NewTenants(nil))
+
+	tenantAdmin := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "acme/ops", Permissions: []string{authz.PermissionAdmin}})
+	operator := context.WithValue(context.Background(), auth.ClaimsContextKey{}, &auth.Claims{ClientID: "ops", Permissions: []string{authz.PermissionAdmin}})
+	subjects, permissions := []string{"*"}, []string{auth.PermissionPublish}
+
+	// Clients named without a tenant belong to the admin's tenant
+	created, err := h.CreateAPIKey(tenantAdmin, &pb.CreateAPIKeyRequest{ClientId: "ci", Subjects: subjects, Permissions: permissions})
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if created.Key.ClientId != "acme/ci" {
+		t.Errorf("expected client acme/ci, got %q", created.Key.ClientId)
+	}
+
+	// Clients of the default tenant and of other tenants are out of reach
+	for _, clientID := range []string{"globex/ci", "$TENANT/ci"} {
+		if _, err := h.CreateAPIKey(tenantAdmin, &pb.CreateAPIKeyRequest{ClientId: clientID, Subjects: subjects, Permissions: permissions}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("expected PermissionDenied for %q, got %v", clientID, err)
+		}
+	}
+	foreign, err := h.CreateAPIKey(operator, &pb.CreateAPIKeyRequest{ClientId: "loader", Subjects: subjects, Permissions: permissions})
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+
+	listed, err := h.ListAPIKeys(tenantAdmin, &pb.ListAPIKeysRequest{})
+	if err != nil || len(listed.Keys) != 1 || listed.Keys[0].Id != created.Key.Id {
+		t.Errorf("expected only the keys of the tenant, got %+v, %v", listed, err)
+	}
+	if _, err := h.ListAPIKeys(tenantAdmin, &pb.ListAPIKeysRequest{ClientId: "globex/ci"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+	if _, err := h.RevokeAPIKey(tenantAdmin, &pb.RevokeAPIKeyRequest{Id: foreign.Key.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a key of another tenant, got %v", err)
+	}
+	if _, err := h.RevokeAPIKey(tenantAdmin, &pb.RevokeAPIKeyRequest{Id: created.Key.Id}); err != nil {
+		t.Errorf("RevokeAPIKey failed: %v", err)
+	}
+
+	// Admins of the default tenant manage every key
+	if listed, err = h.ListAPIKeys(operator, &pb.ListAPIKeysRequest{}); err != nil || len(listed.Keys) != 2 {
+		t.Errorf("expected every key, got %+v, %v", listed, err)
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// APIKey is a long-lived credential exchanged for short-lived tokens
+// Only the hash of its secret is stored
+type APIKey struct {
+	ID          string
+	Hash        string
+	ClientID    string
+	Subjects    []string
+	Permissions []string
+	// TokenTTL is the lifetime of the tokens it is exchanged for
+	TokenTTL    time.Duration
+	Description string
+	CreatedAt   time.Time
+	// ExpiresAt, RevokedAt and LastUsedAt are zero if not set
+	ExpiresAt  time.Time
+	RevokedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// Active reports whether the key may be exchanged at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt))
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "errors"
+
+var (
+	// ErrSubjectLimitExceeded is returned when a publish would violate subject limits
+	ErrSubjectLimitExceeded = errors.New("subject limit exceeded")
+
+	// ErrSequenceConflict is returned when publish expectations do not match the stored sequences
+	ErrSequenceConflict = errors.New("sequence conflict")
+
+	// ErrScheduledWithExpectations is returned when a delayed publish carries sequence expectations
+	ErrScheduledWithExpectations = errors.New("sequence expectations cannot be combined with delayed delivery")
+
+	// ErrChecksumMismatch is returned when a payload does not match the checksum sent by the publisher
+	ErrChecksumMismatch = errors.New("payload checksum mismatch")
+
+	// ErrSchemaNotFound is returned when a subject has no registered schema
+	ErrSchemaNotFound = errors.New("schema not found")
+
+	// ErrSchemaViolation is returned when a payload does not match the active schema of its subject
+	ErrSchemaViolation = errors.New("payload violates subject schema")
+
+	// ErrInvalidSchema is returned when a schema definition or compatibility mode is malformed
+	ErrInvalidSchema = errors.New("invalid schema")
+
+	// ErrIncompatibleSchema is returned when a new schema version breaks the compatibility mode of its subject
+	ErrIncompatibleSchema = errors.New("incompatible schema")
+
+	// ErrSchemaConflict is returned when another schema version was registered concurrently
+	ErrSchemaConflict = errors.New("schema version conflict")
+
+	// ErrSubjectConfigNotFound is returned when a subject was not declared
+	ErrSubjectConfigNotFound = errors.New("subject config not found")
+
+	// ErrInvalidSubjectConfig is returned when subject limits are malformed
+	ErrInvalidSubjectConfig = errors.New("invalid subject config")
+
+	// ErrAPIKeyNotFound is returned when no API key has the given id
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrInvalidAPIKey is returned when an API key is unknown, wrong, expired or revoked
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrInvalidAPIKeyRequest is returned when a new API key is malformed
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+)
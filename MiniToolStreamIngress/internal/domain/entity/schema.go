@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+)
+
+// Schema is a registered payload schema version of a subject
+type Schema struct {
+	// ID is global across subjects and stamped on published messages
+	ID      uint64
+	Subject string
+	Version uint64
+	Format  schema.Format
+	// Definition is a JSON Schema document or a serialized FileDescriptorSet
+	Definition []byte
+	// MessageType is the fully-qualified protobuf message name, empty for JSON
+	MessageType string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// DiscardPolicy defines what happens when a subject reaches its limits
+type DiscardPolicy string
+
+const (
+	// DiscardOld drops the oldest messages to make room for new ones
+	DiscardOld DiscardPolicy = "old"
+	// DiscardNew rejects new publishes while the subject is full
+	DiscardNew DiscardPolicy = "new"
+)
+
+// RetentionPolicy defines when messages of a subject are removed
+type RetentionPolicy string
+
+const (
+	// RetentionLimits removes messages by TTL and subject limits only
+	RetentionLimits RetentionPolicy = "limits"
+	// RetentionInterest removes a message once every consumer of the subject has acked it
+	RetentionInterest RetentionPolicy = "interest"
+	// RetentionWorkQueue removes a message as soon as any consumer acks it
+	RetentionWorkQueue RetentionPolicy = "workqueue"
+)
+
+// SubjectConfig represents an explicitly declared subject with retention limits
+// Zero value of any limit means unlimited
+type SubjectConfig struct {
+	Subject    string
+	MaxMsgs    uint64
+	MaxBytes   uint64
+	MaxAge     time.Duration
+	MaxMsgSize uint64
+	Discard    DiscardPolicy
+	Retention  RetentionPolicy
+	UpdatedAt  time.Time
+}
+
+// Validate checks the subject configuration
+func (c *SubjectConfig) Validate() error {
+	if err := subject.ValidateQualified(c.Subject); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubjectConfig, err)
+	}
+	switch c.Discard {
+	case "", DiscardOld, DiscardNew:
+	default:
+		return fmt.Errorf("%w: unknown discard policy %q", ErrInvalidSubjectConfig, c.Discard)
+	}
+	switch c.Retention {
+	case "", RetentionLimits, RetentionInterest, RetentionWorkQueue:
+	default:
+		return fmt.Errorf("%w: unknown retention policy %q", ErrInvalidSubjectConfig, c.Retention)
+	}
+	if c.MaxAge < 0 || (c.MaxAge > 0 && c.MaxAge < time.Second) {
+		return fmt.Errorf("%w: max age must be at least 1s", ErrInvalidSubjectConfig)
+	}
+	return nil
+}
+
+// PublishLimits is the result of checking subject limits before a publish
+type PublishLimits struct {
+	Allowed bool
+	Reason  string
+	// Trim is set when the subject must be trimmed after the publish (discard old)
+	Trim bool
+}
+
+// MessageInfo describes a message removed from Tarantool whose payload must be deleted
+type MessageInfo struct {
+	Sequence   uint64
+	Subject    string
+	ObjectName string
+}
+
+// DeliveredMessage is a delayed message moved into its subject
+type DeliveredMessage struct {
+	ScheduleID uint64
+	Sequence   uint64
+	Subject    string
+}
+
+// PublishExpectations are optimistic-concurrency conditions of a publish
+// A nil field is not checked
+type PublishExpectations struct {
+	// LastSequence is the expected newest published sequence across all subjects, kept after its message is deleted
+	LastSequence *uint64
+	// LastSubjectSequence is the expected newest stored sequence of the subject (0 = empty subject)
+	LastSubjectSequence *uint64
+}
+
+// ObjectState is the result of taking a reference to a content-addressed object
+type ObjectState string
+
+const (
+	// ObjectStored means the object is already in storage and needs no upload
+	ObjectStored ObjectState = "stored"
+	// ObjectUpload means the caller must upload the object
+	ObjectUpload ObjectState = "upload"
+	// ObjectBusy means the object is being deleted and cannot be shared right now
+	ObjectBusy ObjectState = "busy"
+)
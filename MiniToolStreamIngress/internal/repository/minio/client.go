@@ -3,7 +3,10 @@ package minio
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
@@ -12,7 +15,8 @@ import (
 	"github.com/minio/minio-go/v7/pkg/lifecycle"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/config"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 )
 
 // Config represents MinIO repository configuration
@@ -22,6 +26,8 @@ type Config struct {
 	SecretAccessKey string
 	UseSSL          bool
 	BucketName      string
+	// TenantBuckets lists tenants whose payloads are kept in a bucket of their own
+	TenantBuckets []string
 }
 
 // Repository represents a MinIO repository
@@ -68,11 +74,29 @@ func normalizeBucketName(subject string) string {
 	return bucketName
 }
 
+// tenantBucketName returns the bucket of a tenant with a bucket of its own
+func (r *Repository) tenantBucketName(tenant string) string {
+	return normalizeBucketName(r.config.BucketName + "." + tenant)
+}
+
+// bucketFor returns the bucket an object is stored in
+// Object names of a tenant are qualified, so the name alone selects the bucket
+func (r *Repository) bucketFor(objectName string) string {
+	tenant, _ := subject.Unqualify(objectName)
+	if tenant != "" && slices.Contains(r.config.TenantBuckets, tenant) {
+		return r.tenantBucketName(tenant)
+	}
+	return r.config.BucketName
+}
+
 // EnsureBucket creates bucket if it doesn't exist
-// For Ingress, we use a single bucket configured in settings
+// Buckets of tenants are created on their first upload
 func (r *Repository) EnsureBucket(ctx context.Context) error {
-	bucketName := r.config.BucketName
+	return r.ensureBucket(ctx, r.config.BucketName)
+}
 
+// ensureBucket creates a bucket if it doesn't exist
+func (r *Repository) ensureBucket(ctx context.Context, bucketName string) error {
 	// Check cache first
 	r.bucketCacheMu.RLock()
 	if r.bucketCache[bucketName] {
@@ -118,13 +142,14 @@ func (r *Repository) EnsureBucket(ctx context.Context) error {
 }
 
 // UploadData uploads data to MinIO
-func (r *Repository) UploadData(ctx context.Context, objectName string, data []byte, contentType string) error {
+// A hex SHA-256 checksum, if given, is sent as x-amz-checksum-sha256 so MinIO rejects a damaged upload
+func (r *Repository) UploadData(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 	if len(data) == 0 {
 		// No data to upload
 		return nil
 	}
 
-	bucketName := r.config.BucketName
+	bucketName := r.bucketFor(objectName)
 
 	r.logger.Debug("Uploading data to MinIO",
 		logger.String("bucket", bucketName),
@@ -134,15 +159,26 @@ func (r *Repository) UploadData(ctx context.Context, objectName string, data []b
 	)
 
 	// Ensure bucket exists
-	if err := r.EnsureBucket(ctx); err != nil {
+	if err := r.ensureBucket(ctx, bucketName); err != nil {
 		return err
 	}
 
+	opts := minio.PutObjectOptions{
+		ContentType: contentType,
+	}
+	if checksum != "" {
+		sum, err := hex.DecodeString(checksum)
+		if err != nil {
+			return fmt.Errorf("invalid payload checksum: %w", err)
+		}
+		opts.UserMetadata = map[string]string{
+			"x-amz-checksum-sha256": base64.StdEncoding.EncodeToString(sum),
+		}
+	}
+
 	// Upload object
 	reader := bytes.NewReader(data)
-	_, err := r.client.PutObject(ctx, bucketName, objectName, reader, int64(len(data)), minio.PutObjectOptions{
-		ContentType: contentType,
-	})
+	_, err := r.client.PutObject(ctx, bucketName, objectName, reader, int64(len(data)), opts)
 	if err != nil {
 		r.logger.Error("Failed to upload object to MinIO",
 			logger.String("bucket", bucketName),
@@ -162,7 +198,7 @@ func (r *Repository) UploadData(ctx context.Context, objectName string, data []b
 
 // GetObjectURL returns the URL for accessing an object
 func (r *Repository) GetObjectURL(objectName string) string {
-	bucketName := r.config.BucketName
+	bucketName := r.bucketFor(objectName)
 	protocol := "http"
 	if r.config.UseSSL {
 		protocol = "https"
@@ -172,7 +208,7 @@ func (r *Repository) GetObjectURL(objectName string) string {
 
 // DeleteObject deletes an object from MinIO
 func (r *Repository) DeleteObject(ctx context.Context, objectName string) error {
-	bucketName := r.config.BucketName
+	bucketName := r.bucketFor(objectName)
 
 	r.logger.Debug("Deleting object from MinIO",
 		logger.String("bucket", bucketName),
@@ -199,7 +235,8 @@ func (r *Repository) DeleteObject(ctx context.Context, objectName string) error
 }
 
 // SetupTTLPolicies configures MinIO lifecycle policies for automatic expiration
-func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLConfig) error {
+// Tenants with their own TTL get a rule for their objects, in their own bucket if they have one
+func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLConfig, tenants []config.TenantConfig) error {
 	bucketName := r.config.BucketName
 
 	r.logger.Info("Setting up MinIO lifecycle policies",
@@ -233,7 +270,7 @@ func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLC
 			continue
 		}
 
-		// Objects are named as "{channel}_{sequence}"
+		// Objects are named as "{channel}/{sequence}"
 		// Use prefix filter to match channel
 		channelRule := lifecycle.Rule{
 			ID:     fmt.Sprintf("channel-%s-ttl", channelTTL.Channel),
@@ -243,7 +280,7 @@ func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLC
 			},
 			RuleFilter: lifecycle.Filter{
 				And: lifecycle.And{
-					Prefix: channelTTL.Channel + "_",
+					Prefix: subject.ObjectPrefix(channelTTL.Channel),
 				},
 			},
 		}
@@ -251,11 +288,53 @@ func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLC
 		r.logger.Info("Added channel-specific TTL rule",
 			logger.String("rule_id", channelRule.ID),
 			logger.String("channel", channelTTL.Channel),
-			logger.String("prefix", channelTTL.Channel+"_"),
+			logger.String("prefix", subject.ObjectPrefix(channelTTL.Channel)),
 			logger.Int("days", int(channelTTL.Duration.Hours()/24)),
 		)
 	}
 
+	// Add per-tenant rules, tenants with their own bucket get the default rule there
+	for _, tenant := range tenants {
+		ttl := tenant.TTL
+		if ttl <= 0 {
+			ttl = ttlConfig.Default
+		}
+		// Lifecycle rules count whole days, shorter TTLs are left to the retention service
+		days := int(ttl.Hours() / 24)
+		if days == 0 {
+			continue
+		}
+
+		if tenant.SeparateBucket {
+			if err := r.setupTenantBucketTTL(ctx, tenant.Name, days); err != nil {
+				return err
+			}
+			continue
+		}
+		if tenant.TTL <= 0 {
+			continue
+		}
+
+		prefix := subject.Qualify(tenant.Name, "")
+		rules = append(rules, lifecycle.Rule{
+			ID:     fmt.Sprintf("tenant-%s-ttl", tenant.Name),
+			Status: "Enabled",
+			Expiration: lifecycle.Expiration{
+				Days: lifecycle.ExpirationDays(days),
+			},
+			RuleFilter: lifecycle.Filter{
+				And: lifecycle.And{
+					Prefix: prefix,
+				},
+			},
+		})
+		r.logger.Info("Added tenant TTL rule",
+			logger.String("tenant", tenant.Name),
+			logger.String("prefix", prefix),
+			logger.Int("days", days),
+		)
+	}
+
 	if len(rules) == 0 {
 		r.logger.Warn("No TTL rules to apply")
 		return nil
@@ -280,3 +359,35 @@ func (r *Repository) SetupTTLPolicies(ctx context.Context, ttlConfig config.TTLC
 
 	return nil
 }
+
+// setupTenantBucketTTL expires all objects of a tenant bucket after the given number of days
+func (r *Repository) setupTenantBucketTTL(ctx context.Context, tenant string, days int) error {
+	bucketName := r.tenantBucketName(tenant)
+	if err := r.ensureBucket(ctx, bucketName); err != nil {
+		return err
+	}
+
+	config := lifecycle.NewConfiguration()
+	config.Rules = []lifecycle.Rule{{
+		ID:     "default-ttl",
+		Status: "Enabled",
+		Expiration: lifecycle.Expiration{
+			Days: lifecycle.ExpirationDays(days),
+		},
+	}}
+
+	if err := r.client.SetBucketLifecycle(ctx, bucketName, config); err != nil {
+		r.logger.Error("Failed to set bucket lifecycle",
+			logger.String("bucket", bucketName),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to set lifecycle of tenant %s: %w", tenant, err)
+	}
+
+	r.logger.Info("Configured tenant bucket lifecycle",
+		logger.String("tenant", tenant),
+		logger.String("bucket", bucketName),
+		logger.Int("days", days),
+	)
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
 func TestNormalizeBucketName(t *testing.T) {
@@ -99,6 +99,26 @@ func TestRepository_GetObjectURL(t *testing.T) {
 			objectName: "folder/subfolder/file.pdf",
 			expected:   "http://localhost:9000/mybucket/folder/subfolder/file.pdf",
 		},
+		{
+			name: "tenant with a bucket of its own",
+			config: &Config{
+				Endpoint:      "localhost:9000",
+				BucketName:    "minitoolstream",
+				TenantBuckets: []string{"acme"},
+			},
+			objectName: "$TENANT.acme.orders/5",
+			expected:   "http://localhost:9000/minitoolstream-acme/$TENANT.acme.orders/5",
+		},
+		{
+			name: "tenant in the shared bucket",
+			config: &Config{
+				Endpoint:      "localhost:9000",
+				BucketName:    "minitoolstream",
+				TenantBuckets: []string{"acme"},
+			},
+			objectName: "$TENANT.globex.orders/5",
+			expected:   "http://localhost:9000/minitoolstream/$TENANT.globex.orders/5",
+		},
 	}
 
 	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
@@ -132,7 +152,7 @@ func TestRepository_UploadData_EmptyData(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	err := repo.UploadData(ctx, "test-object", []byte{}, "text/plain")
+	err := repo.UploadData(ctx, "test-object", []byte{}, "text/plain", "")
 	if err != nil {
 		t.Errorf("expected no error for empty data, got: %v", err)
 	}
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/config"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
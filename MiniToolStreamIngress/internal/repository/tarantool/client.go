@@ -3,13 +3,18 @@ package tarantool
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/tarantool/go-tarantool/v2"
 
 	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/config"
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/quota"
+	"github.com/moroshma/MiniToolStream/pkg/revocation"
 )
 
 // Config represents configuration for Tarantool connection
@@ -111,7 +116,8 @@ func (r *Repository) call(functionName string, args []interface{}) ([]interface{
 	return resp, nil
 }
 
-// GetNextSequence allocates a new sequence number
+// GetNextSequence allocates a new id from the global sequence counter
+// Publishers use it to name payloads and delayed messages before the metadata is inserted
 func (r *Repository) GetNextSequence() (uint64, error) {
 	r.logger.Debug("Getting next sequence from Tarantool")
 
@@ -136,10 +142,32 @@ func (r *Repository) GetNextSequence() (uint64, error) {
 	return sequence, nil
 }
 
-// InsertMessage inserts a message with pre-allocated sequence
-func (r *Repository) InsertMessage(sequence uint64, subject string, headers map[string]string, objectName string) error {
+// InsertMessage inserts a message whose payload is already stored
+// A non-nil payload is stored in the tuple, objectName is then empty
+// Returns the global and per-subject sequences, both assigned by the same call
+func (r *Repository) InsertMessage(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+	return r.insertMessage(subject, headers, objectName, nil, payload)
+}
+
+// InsertMessageIf inserts a message only if the publish expectations hold
+// Returns entity.ErrSequenceConflict otherwise
+func (r *Repository) InsertMessageIf(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error) {
+	expected := make(map[string]interface{})
+	if expect != nil {
+		if expect.LastSequence != nil {
+			expected["last_sequence"] = *expect.LastSequence
+		}
+		if expect.LastSubjectSequence != nil {
+			expected["last_subject_sequence"] = *expect.LastSubjectSequence
+		}
+	}
+	return r.insertMessage(subject, headers, objectName, expected, payload)
+}
+
+// insertMessage calls insert_message with optional expectations and inline payload
+func (r *Repository) insertMessage(subject string, headers map[string]string, objectName string, expected map[string]interface{}, payload []byte) (uint64, uint64, error) {
 	if subject == "" {
-		return fmt.Errorf("subject cannot be empty")
+		return 0, 0, fmt.Errorf("subject cannot be empty")
 	}
 
 	if headers == nil {
@@ -147,39 +175,108 @@ func (r *Repository) InsertMessage(sequence uint64, subject string, headers map[
 	}
 
 	r.logger.Debug("Inserting message to Tarantool",
-		logger.Uint64("sequence", sequence),
 		logger.String("subject", subject),
 		logger.String("object_name", objectName),
+		logger.Int("inline_size", len(payload)),
 	)
 
-	// Call Tarantool function
-	resp, err := r.call("insert_message", []interface{}{
-		sequence,
+	args := []interface{}{
 		subject,
 		headers,
 		objectName,
-	})
+	}
+	if len(expected) > 0 || payload != nil {
+		var exp interface{}
+		if len(expected) > 0 {
+			exp = expected
+		}
+		args = append(args, exp)
+	}
+	if payload != nil {
+		args = append(args, payload)
+	}
+
+	// Call Tarantool function
+	resp, err := r.call("insert_message", args)
 	if err != nil {
 		r.logger.Error("Failed to insert message to Tarantool",
 			logger.String("subject", subject),
-			logger.Uint64("sequence", sequence),
+			logger.String("object_name", objectName),
 			logger.Error(err),
 		)
-		return fmt.Errorf("failed to insert message: %w", err)
+		return 0, 0, fmt.Errorf("failed to insert message: %w", classifyInsertError(err))
 	}
 
-	if len(resp) == 0 {
-		return fmt.Errorf("empty response from Tarantool")
+	if len(resp) < 2 {
+		return 0, 0, fmt.Errorf("unexpected response format from insert_message")
 	}
 
+	sequence := toUint64(resp[0])
+	subjectSeq := toUint64(resp[1])
+
 	r.logger.Debug("Message inserted successfully",
 		logger.String("subject", subject),
 		logger.Uint64("sequence", sequence),
+		logger.Uint64("subject_sequence", subjectSeq),
 	)
 
+	return sequence, subjectSeq, nil
+}
+
+// ScheduleMessage stores a message that becomes visible at deliverAt
+// The sequence only names the message until delivery; Tarantool assigns
+// the final sequence when the message is due. A non-nil payload is stored in the tuple
+func (r *Repository) ScheduleMessage(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error {
+	if subject == "" {
+		return fmt.Errorf("subject cannot be empty")
+	}
+
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+
+	r.logger.Debug("Scheduling message in Tarantool",
+		logger.Uint64("schedule_id", sequence),
+		logger.String("subject", subject),
+		logger.String("deliver_at", deliverAt.UTC().Format(time.RFC3339)),
+	)
+
+	args := []interface{}{
+		sequence,
+		subject,
+		headers,
+		objectName,
+		deliverAt.Unix(),
+	}
+	if payload != nil {
+		args = append(args, payload)
+	}
+
+	_, err := r.call("schedule_message", args)
+	if err != nil {
+		r.logger.Error("Failed to schedule message in Tarantool",
+			logger.String("subject", subject),
+			logger.Uint64("schedule_id", sequence),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to schedule message: %w", classifyInsertError(err))
+	}
+
 	return nil
 }
 
+// classifyInsertError maps rejections raised by insert_message to domain errors
+func classifyInsertError(err error) error {
+	msg := err.Error()
+	if idx := strings.Index(msg, "sequence conflict: "); idx >= 0 {
+		return fmt.Errorf("%w: %s", entity.ErrSequenceConflict, msg[idx+len("sequence conflict: "):])
+	}
+	if idx := strings.Index(msg, "subject limit exceeded: "); idx >= 0 {
+		return fmt.Errorf("%w: %s", entity.ErrSubjectLimitExceeded, msg[idx+len("subject limit exceeded: "):])
+	}
+	return err
+}
+
 // PublishMessage publishes a message to Tarantool (legacy method)
 // Returns sequence number
 func (r *Repository) PublishMessage(subject string, headers map[string]string) (uint64, error) {
@@ -225,9 +322,9 @@ func (r *Repository) PublishMessage(subject string, headers map[string]string) (
 	return sequence, nil
 }
 
-// StartTTLCleanup starts the TTL cleanup background fiber in Tarantool
-func (r *Repository) StartTTLCleanup(ttlConfig config.TTLConfig) error {
-	r.logger.Info("Configuring Tarantool TTL cleanup",
+// ConfigureTTL passes the per-channel TTLs to Tarantool, DeleteOldMessages applies them
+func (r *Repository) ConfigureTTL(ttlConfig config.TTLConfig) error {
+	r.logger.Info("Configuring Tarantool TTL",
 		logger.Any("default_ttl", ttlConfig.Default),
 		logger.Int("channels_count", len(ttlConfig.Channels)),
 	)
@@ -240,10 +337,9 @@ func (r *Repository) StartTTLCleanup(ttlConfig config.TTLConfig) error {
 
 	// Prepare configuration
 	ttlConfigMap := map[string]interface{}{
-		"enabled":        ttlConfig.Enabled,
-		"default_ttl":    int(ttlConfig.Default.Seconds()),
-		"check_interval": int(ttlConfig.Default.Seconds()) / 24, // Run 24 times during TTL period
-		"channels":       channelsMap,
+		"enabled":     ttlConfig.Enabled,
+		"default_ttl": int(ttlConfig.Default.Seconds()),
+		"channels":    channelsMap,
 	}
 
 	// Call configure_ttl function
@@ -257,7 +353,7 @@ func (r *Repository) StartTTLCleanup(ttlConfig config.TTLConfig) error {
 
 	if len(resp) > 0 {
 		if success, ok := resp[0].(bool); ok && success {
-			r.logger.Info("Tarantool TTL cleanup configured successfully")
+			r.logger.Info("Tarantool TTL configured successfully")
 			return nil
 		}
 	}
@@ -265,6 +361,21 @@ func (r *Repository) StartTTLCleanup(ttlConfig config.TTLConfig) error {
 	return fmt.Errorf("unexpected response from configure_ttl")
 }
 
+// DeleteOldMessages deletes messages older than their channel TTL, ttlSeconds for other channels
+// The caller must delete the returned objects from MinIO
+func (r *Repository) DeleteOldMessages(ttlSeconds int) (int, []entity.MessageInfo, error) {
+	resp, err := r.call("delete_old_messages", []interface{}{ttlSeconds})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to delete old messages: %w", err)
+	}
+	if len(resp) < 2 {
+		return 0, nil, nil
+	}
+
+	deleted := parseMessageInfos(resp[1:])
+	return len(deleted), deleted, nil
+}
+
 // GetTTLStatus returns the current TTL configuration status from Tarantool
 func (r *Repository) GetTTLStatus() (map[string]interface{}, error) {
 	resp, err := r.call("get_ttl_status", []interface{}{})
@@ -289,6 +400,580 @@ func (r *Repository) GetTTLStatus() (map[string]interface{}, error) {
 	return nil, fmt.Errorf("unexpected response format from get_ttl_status")
 }
 
+// PutSubjectConfig declares a subject or replaces its limits
+func (r *Repository) PutSubjectConfig(cfg *entity.SubjectConfig) (*entity.SubjectConfig, error) {
+	discard := string(cfg.Discard)
+	if discard == "" {
+		discard = string(entity.DiscardOld)
+	}
+	retention := string(cfg.Retention)
+	if retention == "" {
+		retention = string(entity.RetentionLimits)
+	}
+
+	resp, err := r.call("put_subject_config", []interface{}{
+		cfg.Subject,
+		map[string]interface{}{
+			"max_msgs":     cfg.MaxMsgs,
+			"max_bytes":    cfg.MaxBytes,
+			"max_age":      uint64(cfg.MaxAge.Seconds()),
+			"max_msg_size": cfg.MaxMsgSize,
+			"discard":      discard,
+			"retention":    retention,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put subject config: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response from Tarantool")
+	}
+
+	cfgMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from put_subject_config")
+	}
+
+	return parseSubjectConfig(cfgMap), nil
+}
+
+// GetSubjectConfig returns limits of a declared subject
+func (r *Repository) GetSubjectConfig(subject string) (*entity.SubjectConfig, error) {
+	resp, err := r.call("get_subject_config", []interface{}{subject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject config: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrSubjectConfigNotFound
+	}
+
+	cfgMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from get_subject_config")
+	}
+
+	return parseSubjectConfig(cfgMap), nil
+}
+
+// DeleteSubjectConfig removes limits of a subject, stored messages are kept
+func (r *Repository) DeleteSubjectConfig(subject string) error {
+	resp, err := r.call("delete_subject_config", []interface{}{subject})
+	if err != nil {
+		return fmt.Errorf("failed to delete subject config: %w", err)
+	}
+
+	if len(resp) > 0 {
+		if removed, ok := resp[0].(bool); ok && !removed {
+			return entity.ErrSubjectConfigNotFound
+		}
+	}
+
+	return nil
+}
+
+// ListSubjectConfigs returns all declared subjects
+func (r *Repository) ListSubjectConfigs() ([]*entity.SubjectConfig, error) {
+	resp, err := r.call("list_subject_configs", []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subject configs: %w", err)
+	}
+
+	configs := []*entity.SubjectConfig{}
+	if len(resp) == 0 {
+		return configs, nil
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		if cfgMap, ok := item.(map[interface{}]interface{}); ok {
+			configs = append(configs, parseSubjectConfig(cfgMap))
+		}
+	}
+
+	return configs, nil
+}
+
+// RegisterSchema stores the next schema version of a subject
+// Fails with ErrSchemaConflict if the latest version is no longer expectedVersion
+func (r *Repository) RegisterSchema(s *entity.Schema, expectedVersion uint64) (*entity.Schema, error) {
+	resp, err := r.call("register_schema", []interface{}{
+		s.Subject,
+		string(s.Format),
+		s.Definition,
+		s.MessageType,
+		expectedVersion,
+	})
+	if err != nil {
+		if idx := strings.Index(err.Error(), "schema version conflict: "); idx >= 0 {
+			return nil, fmt.Errorf("%w: %s", entity.ErrSchemaConflict, err.Error()[idx+len("schema version conflict: "):])
+		}
+		return nil, fmt.Errorf("failed to register schema: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response from Tarantool")
+	}
+
+	schemaMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from register_schema")
+	}
+
+	return parseSchema(schemaMap), nil
+}
+
+// GetSchema returns a schema version of a subject, 0 means the latest
+func (r *Repository) GetSchema(subject string, version uint64) (*entity.Schema, error) {
+	resp, err := r.call("get_schema", []interface{}{subject, version})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrSchemaNotFound
+	}
+
+	schemaMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from get_schema")
+	}
+
+	return parseSchema(schemaMap), nil
+}
+
+// GetSchemaByID returns a schema by its global ID
+func (r *Repository) GetSchemaByID(id uint64) (*entity.Schema, error) {
+	resp, err := r.call("get_schema_by_id", []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrSchemaNotFound
+	}
+
+	schemaMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from get_schema_by_id")
+	}
+
+	return parseSchema(schemaMap), nil
+}
+
+// ListSchemaVersions returns the registered versions of a subject, oldest first
+func (r *Repository) ListSchemaVersions(subject string) ([]uint64, error) {
+	resp, err := r.call("list_schema_versions", []interface{}{subject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema versions: %w", err)
+	}
+
+	versions := []uint64{}
+	if len(resp) == 0 {
+		return versions, nil
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		versions = append(versions, toUint64(item))
+	}
+	return versions, nil
+}
+
+// GetSchemaCompatibility returns the compatibility mode of a subject
+func (r *Repository) GetSchemaCompatibility(subject string) (schema.Compatibility, error) {
+	resp, err := r.call("get_schema_compatibility", []interface{}{subject})
+	if err != nil {
+		return "", fmt.Errorf("failed to get schema compatibility: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return "", fmt.Errorf("empty response from Tarantool")
+	}
+
+	return schema.ParseCompatibility(toString(resp[0]))
+}
+
+// SetSchemaCompatibility changes the compatibility mode of a subject
+func (r *Repository) SetSchemaCompatibility(subject string, mode schema.Compatibility) error {
+	if _, err := r.call("set_schema_compatibility", []interface{}{subject, string(mode)}); err != nil {
+		return fmt.Errorf("failed to set schema compatibility: %w", err)
+	}
+	return nil
+}
+
+// AcquireObject takes a reference to a content-addressed object before it is published
+func (r *Repository) AcquireObject(objectName string) (entity.ObjectState, error) {
+	resp, err := r.call("acquire_object", []interface{}{objectName})
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire object: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return "", fmt.Errorf("empty response from Tarantool")
+	}
+
+	state := entity.ObjectState(toString(resp[0]))
+	switch state {
+	case entity.ObjectStored, entity.ObjectUpload, entity.ObjectBusy:
+		return state, nil
+	default:
+		return "", fmt.Errorf("unexpected acquire_object result: %q", state)
+	}
+}
+
+// MarkObjectStored records that a content-addressed object was uploaded
+func (r *Repository) MarkObjectStored(objectName string) error {
+	if _, err := r.call("mark_object_stored", []interface{}{objectName}); err != nil {
+		return fmt.Errorf("failed to mark object stored: %w", err)
+	}
+	return nil
+}
+
+// ReleaseObjectRef gives back a reference taken by AcquireObject
+// Returns true if it was the last reference and the object must be deleted
+func (r *Repository) ReleaseObjectRef(objectName string) (bool, error) {
+	resp, err := r.call("release_object_ref", []interface{}{objectName})
+	if err != nil {
+		return false, fmt.Errorf("failed to release object: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return false, fmt.Errorf("empty response from Tarantool")
+	}
+
+	last, _ := resp[0].(bool)
+	return last, nil
+}
+
+// ConsumeQuota draws from token buckets and returns how long to wait if any of them is short
+// With force the buckets are charged anyway
+func (r *Repository) ConsumeQuota(buckets []quota.Bucket, force bool) (time.Duration, error) {
+	args := make([]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		args = append(args, []interface{}{b.Key, b.Rate, b.Cost})
+	}
+
+	resp, err := r.call("consume_quota", []interface{}{args, force})
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, fmt.Errorf("empty response from Tarantool")
+	}
+
+	return time.Duration(toFloat64(resp[0]) * float64(time.Second)), nil
+}
+
+// GetStoredBytes returns payload bytes stored in subjects matching pattern
+func (r *Repository) GetStoredBytes(pattern string) (uint64, error) {
+	resp, err := r.call("get_stored_bytes", []interface{}{pattern})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stored bytes: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, fmt.Errorf("empty response from Tarantool")
+	}
+
+	return toUint64(resp[0]), nil
+}
+
+// GetClientStoredBytes returns payload bytes stored by a publishing client
+func (r *Repository) GetClientStoredBytes(clientID string) (uint64, error) {
+	resp, err := r.call("get_client_stored_bytes", []interface{}{clientID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get client stored bytes: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, fmt.Errorf("empty response from Tarantool")
+	}
+
+	return toUint64(resp[0]), nil
+}
+
+// CheckRevocation looks up whether a token or its client is revoked
+func (r *Repository) CheckRevocation(jti, clientID string) (revocation.Status, error) {
+	resp, err := r.call("check_revocation", []interface{}{jti, clientID})
+	if err != nil {
+		return revocation.Status{}, fmt.Errorf("failed to check revocation: %w", err)
+	}
+
+	if len(resp) < 2 {
+		return revocation.Status{}, fmt.Errorf("invalid response format")
+	}
+
+	status := revocation.Status{}
+	status.TokenRevoked, _ = resp[0].(bool)
+	if notBefore := toUint64(resp[1]); notBefore > 0 {
+		status.ClientNotBefore = time.Unix(int64(notBefore), 0)
+	}
+	return status, nil
+}
+
+// CreateAPIKey stores a new API key
+func (r *Repository) CreateAPIKey(k *entity.APIKey) error {
+	var expiresAt uint64
+	if !k.ExpiresAt.IsZero() {
+		expiresAt = uint64(k.ExpiresAt.Unix())
+	}
+	_, err := r.call("create_api_key", []interface{}{
+		k.ID,
+		k.Hash,
+		k.ClientID,
+		k.Subjects,
+		k.Permissions,
+		uint64(k.TokenTTL / time.Second),
+		expiresAt,
+		k.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKey returns an API key by its id
+func (r *Repository) GetAPIKey(id string) (*entity.APIKey, error) {
+	resp, err := r.call("get_api_key", []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if len(resp) == 0 || resp[0] == nil {
+		return nil, entity.ErrAPIKeyNotFound
+	}
+
+	tuple, ok := resp[0].([]interface{})
+	if !ok || len(tuple) < 11 {
+		return nil, fmt.Errorf("unexpected response format from get_api_key")
+	}
+	return parseAPIKey(tuple), nil
+}
+
+// TouchAPIKey records a successful exchange of an API key
+func (r *Repository) TouchAPIKey(id string) error {
+	if _, err := r.call("touch_api_key", []interface{}{id}); err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+	return nil
+}
+
+// ListAPIKeys returns the API keys of a client, or all keys for an empty clientID
+func (r *Repository) ListAPIKeys(clientID string) ([]*entity.APIKey, error) {
+	resp, err := r.call("list_api_keys", []interface{}{clientID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, nil
+	}
+
+	list, ok := resp[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from list_api_keys")
+	}
+
+	keys := make([]*entity.APIKey, 0, len(list))
+	for _, item := range list {
+		if tuple, ok := item.([]interface{}); ok && len(tuple) >= 11 {
+			keys = append(keys, parseAPIKey(tuple))
+		}
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key
+func (r *Repository) RevokeAPIKey(id string) error {
+	resp, err := r.call("revoke_api_key", []interface{}{id})
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return fmt.Errorf("empty response from Tarantool")
+	}
+	if found, _ := resp[0].(bool); !found {
+		return entity.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// parseAPIKey converts an api_key tuple
+func parseAPIKey(tuple []interface{}) *entity.APIKey {
+	return &entity.APIKey{
+		ID:          toString(tuple[0]),
+		Hash:        toString(tuple[1]),
+		ClientID:    toString(tuple[2]),
+		Subjects:    toStrings(tuple[3]),
+		Permissions: toStrings(tuple[4]),
+		TokenTTL:    time.Duration(toUint64(tuple[5])) * time.Second,
+		CreatedAt:   unixTime(tuple[6]),
+		ExpiresAt:   unixTime(tuple[7]),
+		RevokedAt:   unixTime(tuple[8]),
+		LastUsedAt:  unixTime(tuple[9]),
+		Description: toString(tuple[10]),
+	}
+}
+
+// GetMemtxUsage returns the used fraction of Tarantool memtx_memory (0..1)
+func (r *Repository) GetMemtxUsage() (float64, error) {
+	resp, err := r.call("get_memtx_usage", []interface{}{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get memtx usage: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return 0, fmt.Errorf("empty response from Tarantool")
+	}
+
+	return toFloat64(resp[0]), nil
+}
+
+// CheckPublishLimits checks subject limits for a payload of the given size
+func (r *Repository) CheckPublishLimits(subject string, size int) (*entity.PublishLimits, error) {
+	resp, err := r.call("check_publish_limits", []interface{}{subject, size})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check publish limits: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response from Tarantool")
+	}
+
+	resMap, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format from check_publish_limits")
+	}
+
+	allowed, _ := resMap["allowed"].(bool)
+	trim, _ := resMap["trim"].(bool)
+	reason, _ := resMap["reason"].(string)
+
+	return &entity.PublishLimits{
+		Allowed: allowed,
+		Reason:  reason,
+		Trim:    trim,
+	}, nil
+}
+
+// EnforceSubjectLimits trims a subject down to its limits
+// Returns deleted messages so their payloads can be removed from storage
+func (r *Repository) EnforceSubjectLimits(subject string) ([]entity.MessageInfo, error) {
+	resp, err := r.call("enforce_subject_limits", []interface{}{subject})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enforce subject limits: %w", err)
+	}
+
+	return parseMessageInfos(resp), nil
+}
+
+// EnforceAllSubjectLimits trims every declared subject down to its limits
+// Returns deleted messages so their payloads can be removed from storage
+func (r *Repository) EnforceAllSubjectLimits() ([]entity.MessageInfo, error) {
+	resp, err := r.call("enforce_all_subject_limits", []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enforce subject limits: %w", err)
+	}
+
+	return parseMessageInfos(resp), nil
+}
+
+// DeleteExpiredMessages deletes messages whose expires-at time has passed
+// Returns deleted messages so their payloads can be removed from storage
+func (r *Repository) DeleteExpiredMessages() ([]entity.MessageInfo, error) {
+	resp, err := r.call("delete_expired_messages", []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired messages: %w", err)
+	}
+
+	return parseMessageInfos(resp), nil
+}
+
+// DeliverScheduledMessages moves due delayed messages into their subjects
+// It returns the delivered messages and the removed ones whose payloads must be deleted:
+// messages dropped at delivery and those trimmed from discard old subjects
+func (r *Repository) DeliverScheduledMessages(batchSize int) ([]entity.DeliveredMessage, []entity.MessageInfo, error) {
+	resp, err := r.call("deliver_scheduled_messages", []interface{}{batchSize})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to deliver scheduled messages: %w", err)
+	}
+
+	delivered := []entity.DeliveredMessage{}
+	if len(resp) == 0 {
+		return delivered, []entity.MessageInfo{}, nil
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		infoMap, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		delivered = append(delivered, entity.DeliveredMessage{
+			ScheduleID: toUint64(infoMap["schedule_id"]),
+			Sequence:   toUint64(infoMap["sequence"]),
+			Subject:    toString(infoMap["subject"]),
+		})
+	}
+
+	return delivered, parseMessageInfos(resp[1:]), nil
+}
+
+// parseSubjectConfig converts a msgpack-decoded subject config map
+func parseSubjectConfig(cfgMap map[interface{}]interface{}) *entity.SubjectConfig {
+	return &entity.SubjectConfig{
+		Subject:    toString(cfgMap["subject"]),
+		MaxMsgs:    toUint64(cfgMap["max_msgs"]),
+		MaxBytes:   toUint64(cfgMap["max_bytes"]),
+		MaxAge:     time.Duration(toUint64(cfgMap["max_age"])) * time.Second,
+		MaxMsgSize: toUint64(cfgMap["max_msg_size"]),
+		Discard:    entity.DiscardPolicy(toString(cfgMap["discard"])),
+		Retention:  entity.RetentionPolicy(toString(cfgMap["retention"])),
+		UpdatedAt:  time.Unix(int64(toUint64(cfgMap["updated_at"])), 0),
+	}
+}
+
+// parseSchema converts a msgpack-decoded schema map
+func parseSchema(schemaMap map[interface{}]interface{}) *entity.Schema {
+	return &entity.Schema{
+		ID:          toUint64(schemaMap["id"]),
+		Subject:     toString(schemaMap["subject"]),
+		Version:     toUint64(schemaMap["version"]),
+		Format:      schema.Format(toString(schemaMap["format"])),
+		Definition:  toBytes(schemaMap["definition"]),
+		MessageType: toString(schemaMap["message_type"]),
+		CreatedAt:   time.Unix(int64(toUint64(schemaMap["create_at"])), 0),
+	}
+}
+
+// parseMessageInfos converts an array of {sequence, subject, object_name} maps
+func parseMessageInfos(resp []interface{}) []entity.MessageInfo {
+	infos := []entity.MessageInfo{}
+	if len(resp) == 0 {
+		return infos
+	}
+
+	items, _ := resp[0].([]interface{})
+	for _, item := range items {
+		infoMap, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		infos = append(infos, entity.MessageInfo{
+			Sequence:   toUint64(infoMap["sequence"]),
+			Subject:    toString(infoMap["subject"]),
+			ObjectName: toString(infoMap["object_name"]),
+		})
+	}
+
+	return infos
+}
+
 // Helper function for type conversion
 func toUint64(val interface{}) uint64 {
 	switch v := val.(type) {
@@ -321,6 +1006,18 @@ func toUint64(val interface{}) uint64 {
 	}
 }
 
+// Helper function for fractional numbers; whole numbers may be encoded as integers
+func toFloat64(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	default:
+		return float64(toUint64(v))
+	}
+}
+
 // Helper function for string conversion
 func toString(val interface{}) string {
 	switch v := val.(type) {
@@ -332,3 +1029,34 @@ func toString(val interface{}) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// Helper function for Lua arrays of strings
+func toStrings(val interface{}) []string {
+	list, _ := val.([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		result = append(result, toString(item))
+	}
+	return result
+}
+
+// Helper function for Unix times, 0 meaning unset
+func unixTime(val interface{}) time.Time {
+	sec := toUint64(val)
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(sec), 0)
+}
+
+// Helper function for binary fields, which Lua returns as strings
+func toBytes(val interface{}) []byte {
+	switch v := val.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	default:
+		return nil
+	}
+}
@@ -1,10 +1,12 @@
 package tarantool
 
 import (
+	"errors"
 	"testing"
 	"time"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
 func TestNewRepository_NilConfig(t *testing.T) {
@@ -188,3 +190,35 @@ func TestRepository_Close_AlreadyClosed(t *testing.T) {
 		t.Errorf("expected no error when closing already closed repository, got: %v", err)
 	}
 }
+
+func TestClassifyInsertError(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  error
+		target error
+	}{
+		{
+			name:   "sequence conflict",
+			input:  errors.New("init.lua:400: sequence conflict: expected last subject sequence 5, actual 7"),
+			target: entity.ErrSequenceConflict,
+		},
+		{
+			name:   "subject limit",
+			input:  errors.New("init.lua:396: subject limit exceeded: subject has reached max_msgs 10"),
+			target: entity.ErrSubjectLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := classifyInsertError(tt.input); !errors.Is(err, tt.target) {
+				t.Errorf("classifyInsertError(%v) = %v, want %v", tt.input, err, tt.target)
+			}
+		})
+	}
+
+	other := errors.New("connection refused")
+	if err := classifyInsertError(other); err != other {
+		t.Errorf("unexpected wrapping of unrelated error: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// MessageRepository defines the interface for trimming subjects in Tarantool
+type MessageRepository interface {
+	EnforceAllSubjectLimits() ([]entity.MessageInfo, error)
+	DeleteExpiredMessages() ([]entity.MessageInfo, error)
+}
+
+// StorageRepository defines the interface for object storage operations
+type StorageRepository interface {
+	DeleteObject(ctx context.Context, objectName string) error
+}
+
+// Service periodically trims declared subjects down to their limits
+// and removes messages published with their own expiry time
+// Metadata is removed in Tarantool first, then the payloads are deleted from MinIO
+type Service struct {
+	messageRepo MessageRepository
+	storageRepo StorageRepository
+	logger      *logger.Logger
+	interval    time.Duration
+	enabled     bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// Config represents retention enforcer configuration
+type Config struct {
+	Enabled  bool
+	Interval time.Duration
+}
+
+// NewService creates a new retention enforcer
+func NewService(
+	messageRepo MessageRepository,
+	storageRepo StorageRepository,
+	cfg Config,
+	log *logger.Logger,
+) *Service {
+	return &Service{
+		messageRepo: messageRepo,
+		storageRepo: storageRepo,
+		logger:      log,
+		interval:    cfg.Interval,
+		enabled:     cfg.Enabled,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start starts the retention enforcer
+func (s *Service) Start(ctx context.Context) error {
+	if !s.enabled {
+		s.logger.Info("Retention enforcer is disabled")
+		return nil
+	}
+
+	s.logger.Info("Starting retention enforcer",
+		logger.Duration("interval", s.interval),
+	)
+
+	s.wg.Add(1)
+	go s.enforceLoop(ctx, s.stopCh)
+
+	return nil
+}
+
+// Stop stops the retention enforcer
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCh == nil {
+		return
+	}
+
+	s.logger.Info("Stopping retention enforcer...")
+	close(s.stopCh)
+	s.stopCh = nil
+	s.wg.Wait()
+	s.logger.Info("Retention enforcer stopped")
+}
+
+// enforceLoop runs the enforcement periodically
+func (s *Service) enforceLoop(ctx context.Context, stopCh <-chan struct{}) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	if err := s.enforce(ctx); err != nil {
+		s.logger.Error("Initial retention enforcement failed", logger.Error(err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Retention enforcer context cancelled")
+			return
+		case <-stopCh:
+			s.logger.Info("Retention enforcer received stop signal")
+			return
+		case <-ticker.C:
+			if err := s.enforce(ctx); err != nil {
+				s.logger.Error("Retention enforcement failed", logger.Error(err))
+			}
+		}
+	}
+}
+
+// enforce trims all declared subjects and drops expired messages once
+func (s *Service) enforce(ctx context.Context) error {
+	startTime := time.Now()
+
+	deleted, err := s.messageRepo.EnforceAllSubjectLimits()
+	if err != nil {
+		return fmt.Errorf("failed to enforce subject limits in Tarantool: %w", err)
+	}
+
+	expired, err := s.messageRepo.DeleteExpiredMessages()
+	if err != nil {
+		return fmt.Errorf("failed to delete expired messages in Tarantool: %w", err)
+	}
+	deleted = append(deleted, expired...)
+
+	if len(deleted) == 0 {
+		s.logger.Debug("No messages over subject limits or expired")
+		return nil
+	}
+
+	deletedFromMinIO := 0
+	failedDeletes := 0
+
+	for _, msg := range deleted {
+		if msg.ObjectName == "" {
+			continue
+		}
+		if err := s.storageRepo.DeleteObject(ctx, msg.ObjectName); err != nil {
+			s.logger.Error("Failed to delete object from MinIO",
+				logger.String("object_name", msg.ObjectName),
+				logger.Uint64("sequence", msg.Sequence),
+				logger.String("subject", msg.Subject),
+				logger.Error(err),
+			)
+			failedDeletes++
+			continue
+		}
+		deletedFromMinIO++
+	}
+
+	s.logger.Info("Retention enforcement completed",
+		logger.Int("tarantool_deleted", len(deleted)),
+		logger.Int("expired", len(expired)),
+		logger.Int("minio_deleted", deletedFromMinIO),
+		logger.Int("minio_failed", failedDeletes),
+		logger.Duration("duration", time.Since(startTime)),
+	)
+
+	return nil
+}
+
+// RunOnce runs the enforcement once (useful for testing)
+func (s *Service) RunOnce(ctx context.Context) error {
+	return s.enforce(ctx)
+}
@@ -0,0 +1,130 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMessageRepository is a mock implementation of MessageRepository
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) EnforceAllSubjectLimits() ([]entity.MessageInfo, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.MessageInfo), args.Error(1)
+}
+
+func (m *MockMessageRepository) DeleteExpiredMessages() ([]entity.MessageInfo, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.MessageInfo), args.Error(1)
+}
+
+// MockStorageRepository is a mock implementation of StorageRepository
+type MockStorageRepository struct {
+	mock.Mock
+}
+
+func (m *MockStorageRepository) DeleteObject(ctx context.Context, objectName string) error {
+	args := m.Called(ctx, objectName)
+	return args.Error(0)
+}
+
+func TestRunOnce_DeletesObjects(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Minute}, log)
+
+	deleted := []entity.MessageInfo{
+		{Sequence: 1, Subject: "orders", ObjectName: "orders_1"},
+		{Sequence: 2, Subject: "orders", ObjectName: ""}, // message without payload
+		{Sequence: 3, Subject: "orders", ObjectName: "orders_3"},
+	}
+
+	ctx := context.Background()
+
+	messageRepo.On("EnforceAllSubjectLimits").Return(deleted, nil)
+	messageRepo.On("DeleteExpiredMessages").Return([]entity.MessageInfo{}, nil)
+	storageRepo.On("DeleteObject", ctx, "orders_1").Return(nil)
+	storageRepo.On("DeleteObject", ctx, "orders_3").Return(errors.New("object not found"))
+
+	err := service.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+	storageRepo.AssertExpectations(t)
+	storageRepo.AssertNumberOfCalls(t, "DeleteObject", 2)
+}
+
+func TestRunOnce_MessageRepoError(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Minute}, log)
+
+	messageRepo.On("EnforceAllSubjectLimits").Return(nil, errors.New("connection error"))
+
+	err := service.RunOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection error")
+	storageRepo.AssertNotCalled(t, "DeleteObject")
+}
+
+func TestStart_Stop(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: 100 * time.Millisecond}, log)
+
+	messageRepo.On("EnforceAllSubjectLimits").Return([]entity.MessageInfo{}, nil).Maybe()
+	messageRepo.On("DeleteExpiredMessages").Return([]entity.MessageInfo{}, nil).Maybe()
+
+	err := service.Start(context.Background())
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	service.Stop()
+	// Second stop is a no-op
+	service.Stop()
+}
+
+func TestRunOnce_DeletesExpiredObjects(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Minute}, log)
+
+	ctx := context.Background()
+
+	messageRepo.On("EnforceAllSubjectLimits").Return([]entity.MessageInfo{}, nil)
+	messageRepo.On("DeleteExpiredMessages").Return([]entity.MessageInfo{
+		{Sequence: 7, Subject: "sensors.frames", ObjectName: "sensors.frames_7"},
+	}, nil)
+	storageRepo.On("DeleteObject", ctx, "sensors.frames_7").Return(nil)
+
+	err := service.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+	storageRepo.AssertExpectations(t)
+}
@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// MessageRepository defines the interface for delivering delayed messages in Tarantool
+type MessageRepository interface {
+	DeliverScheduledMessages(batchSize int) ([]entity.DeliveredMessage, []entity.MessageInfo, error)
+}
+
+// StorageRepository defines the interface for object storage operations
+type StorageRepository interface {
+	DeleteObject(ctx context.Context, objectName string) error
+}
+
+// Service periodically delivers delayed messages that are due
+// Tarantool moves them into their subjects, drops those that no longer fit the
+// subject limits or expired while waiting and trims discard old subjects,
+// then the payloads of removed messages are deleted from MinIO
+type Service struct {
+	messageRepo MessageRepository
+	storageRepo StorageRepository
+	logger      *logger.Logger
+	interval    time.Duration
+	batchSize   int
+	enabled     bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// Config represents scheduler configuration
+type Config struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+}
+
+// NewService creates a new scheduler of delayed messages
+func NewService(
+	messageRepo MessageRepository,
+	storageRepo StorageRepository,
+	cfg Config,
+	log *logger.Logger,
+) *Service {
+	return &Service{
+		messageRepo: messageRepo,
+		storageRepo: storageRepo,
+		logger:      log,
+		interval:    cfg.Interval,
+		batchSize:   cfg.BatchSize,
+		enabled:     cfg.Enabled,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start starts the scheduler
+func (s *Service) Start(ctx context.Context) error {
+	if !s.enabled {
+		s.logger.Info("Scheduler of delayed messages is disabled")
+		return nil
+	}
+
+	s.logger.Info("Starting scheduler of delayed messages",
+		logger.Duration("interval", s.interval),
+		logger.Int("batch_size", s.batchSize),
+	)
+
+	s.wg.Add(1)
+	go s.deliverLoop(ctx, s.stopCh)
+
+	return nil
+}
+
+// Stop stops the scheduler
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCh == nil {
+		return
+	}
+
+	s.logger.Info("Stopping scheduler of delayed messages...")
+	close(s.stopCh)
+	s.stopCh = nil
+	s.wg.Wait()
+	s.logger.Info("Scheduler of delayed messages stopped")
+}
+
+// deliverLoop runs the delivery periodically
+func (s *Service) deliverLoop(ctx context.Context, stopCh <-chan struct{}) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx, stopCh)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Scheduler context cancelled")
+			return
+		case <-stopCh:
+			s.logger.Info("Scheduler received stop signal")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue delivers batches until fewer messages than a full batch were due
+func (s *Service) deliverDue(ctx context.Context, stopCh <-chan struct{}) {
+	for {
+		processed, err := s.deliver(ctx)
+		if err != nil {
+			s.logger.Error("Delivery of scheduled messages failed", logger.Error(err))
+			return
+		}
+		if processed < s.batchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		default:
+		}
+	}
+}
+
+// deliver delivers one batch and returns how many scheduled messages it took
+func (s *Service) deliver(ctx context.Context) (int, error) {
+	startTime := time.Now()
+
+	delivered, deleted, err := s.messageRepo.DeliverScheduledMessages(s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deliver scheduled messages in Tarantool: %w", err)
+	}
+
+	// Dropped messages never got a sequence, the others were trimmed from their subjects
+	dropped := 0
+	for _, msg := range deleted {
+		if msg.Sequence == 0 {
+			dropped++
+		}
+	}
+	processed := len(delivered) + dropped
+	if processed == 0 && len(deleted) == 0 {
+		return 0, nil
+	}
+
+	for _, msg := range delivered {
+		s.logger.Debug("Scheduled message delivered",
+			logger.Uint64("schedule_id", msg.ScheduleID),
+			logger.Uint64("sequence", msg.Sequence),
+			logger.String("subject", msg.Subject),
+		)
+	}
+
+	deletedFromMinIO := 0
+	failedDeletes := 0
+
+	for _, msg := range deleted {
+		if msg.ObjectName == "" {
+			continue
+		}
+		if err := s.storageRepo.DeleteObject(ctx, msg.ObjectName); err != nil {
+			s.logger.Error("Failed to delete object from MinIO",
+				logger.String("object_name", msg.ObjectName),
+				logger.Uint64("sequence", msg.Sequence),
+				logger.String("subject", msg.Subject),
+				logger.Error(err),
+			)
+			failedDeletes++
+			continue
+		}
+		deletedFromMinIO++
+	}
+
+	s.logger.Info("Scheduled messages delivered",
+		logger.Int("delivered", len(delivered)),
+		logger.Int("dropped", dropped),
+		logger.Int("trimmed", len(deleted)-dropped),
+		logger.Int("minio_deleted", deletedFromMinIO),
+		logger.Int("minio_failed", failedDeletes),
+		logger.Duration("duration", time.Since(startTime)),
+	)
+
+	return processed, nil
+}
+
+// RunOnce delivers one batch (useful for testing)
+func (s *Service) RunOnce(ctx context.Context) error {
+	_, err := s.deliver(ctx)
+	return err
+}
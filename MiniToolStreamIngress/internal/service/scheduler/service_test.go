@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMessageRepository is a mock implementation of MessageRepository
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) DeliverScheduledMessages(batchSize int) ([]entity.DeliveredMessage, []entity.MessageInfo, error) {
+	args := m.Called(batchSize)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]entity.DeliveredMessage), args.Get(1).([]entity.MessageInfo), args.Error(2)
+}
+
+// MockStorageRepository is a mock implementation of StorageRepository
+type MockStorageRepository struct {
+	mock.Mock
+}
+
+func (m *MockStorageRepository) DeleteObject(ctx context.Context, objectName string) error {
+	args := m.Called(ctx, objectName)
+	return args.Error(0)
+}
+
+func TestRunOnce_DeletesDroppedAndTrimmedObjects(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Second, BatchSize: 100}, log)
+
+	delivered := []entity.DeliveredMessage{
+		{ScheduleID: 40, Sequence: 51, Subject: "orders"},
+	}
+	deleted := []entity.MessageInfo{
+		{Sequence: 0, Subject: "orders", ObjectName: "orders/41"},  // expired before delivery
+		{Sequence: 0, Subject: "orders", ObjectName: ""},           // dropped, payload still referenced
+		{Sequence: 12, Subject: "orders", ObjectName: "orders/12"}, // trimmed from a discard old subject
+	}
+
+	ctx := context.Background()
+
+	messageRepo.On("DeliverScheduledMessages", 100).Return(delivered, deleted, nil)
+	storageRepo.On("DeleteObject", ctx, "orders/41").Return(nil)
+	storageRepo.On("DeleteObject", ctx, "orders/12").Return(errors.New("object not found"))
+
+	err := service.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+	storageRepo.AssertExpectations(t)
+	storageRepo.AssertNumberOfCalls(t, "DeleteObject", 2)
+}
+
+func TestRunOnce_MessageRepoError(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Second, BatchSize: 100}, log)
+
+	messageRepo.On("DeliverScheduledMessages", 100).Return(nil, nil, errors.New("connection error"))
+
+	err := service.RunOnce(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "connection error")
+	storageRepo.AssertNotCalled(t, "DeleteObject")
+}
+
+func TestDeliverDue_ContinuesAfterFullBatch(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: time.Second, BatchSize: 2}, log)
+
+	// A delivered and a dropped message fill the batch, so more may be due
+	messageRepo.On("DeliverScheduledMessages", 2).Return(
+		[]entity.DeliveredMessage{{ScheduleID: 1, Sequence: 10, Subject: "orders"}},
+		[]entity.MessageInfo{{Subject: "orders"}},
+		nil,
+	).Once()
+	messageRepo.On("DeliverScheduledMessages", 2).Return(
+		[]entity.DeliveredMessage{{ScheduleID: 3, Sequence: 11, Subject: "orders"}},
+		[]entity.MessageInfo{},
+		nil,
+	).Once()
+
+	service.deliverDue(context.Background(), make(chan struct{}))
+
+	messageRepo.AssertExpectations(t)
+	messageRepo.AssertNumberOfCalls(t, "DeliverScheduledMessages", 2)
+}
+
+func TestStart_Stop(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	service := NewService(messageRepo, storageRepo, Config{Enabled: true, Interval: 100 * time.Millisecond, BatchSize: 100}, log)
+
+	messageRepo.On("DeliverScheduledMessages", 100).Return([]entity.DeliveredMessage{}, []entity.MessageInfo{}, nil).Maybe()
+
+	err := service.Start(context.Background())
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+
+	service.Stop()
+	// Second stop is a no-op
+	service.Stop()
+}
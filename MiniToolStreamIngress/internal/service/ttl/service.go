@@ -6,19 +6,13 @@ import (
 	"sync"
 	"time"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
-// MessageInfo represents information about a deleted message
-type MessageInfo struct {
-	Sequence   uint64
-	Subject    string
-	ObjectName string
-}
-
 // MessageRepository defines the interface for message storage operations
 type MessageRepository interface {
-	DeleteOldMessages(ttlSeconds int) (int, []MessageInfo, error)
+	DeleteOldMessages(ttlSeconds int) (int, []entity.MessageInfo, error)
 }
 
 // StorageRepository defines the interface for object storage operations
@@ -155,6 +149,10 @@ func (s *Service) runCleanup(ctx context.Context) error {
 	failedDeletes := 0
 
 	for _, msg := range deletedMessages {
+		// Messages without payload and inline payloads have no object
+		if msg.ObjectName == "" {
+			continue
+		}
 		if err := s.storageRepo.DeleteObject(ctx, msg.ObjectName); err != nil {
 			s.logger.Error("Failed to delete object from MinIO",
 				logger.String("object_name", msg.ObjectName),
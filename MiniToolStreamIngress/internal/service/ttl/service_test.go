@@ -6,7 +6,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,12 +17,12 @@ type MockMessageRepository struct {
 	mock.Mock
 }
 
-func (m *MockMessageRepository) DeleteOldMessages(ttlSeconds int) (int, []MessageInfo, error) {
+func (m *MockMessageRepository) DeleteOldMessages(ttlSeconds int) (int, []entity.MessageInfo, error) {
 	args := m.Called(ttlSeconds)
 	if args.Get(1) == nil {
 		return args.Int(0), nil, args.Error(2)
 	}
-	return args.Int(0), args.Get(1).([]MessageInfo), args.Error(2)
+	return args.Int(0), args.Get(1).([]entity.MessageInfo), args.Error(2)
 }
 
 // MockStorageRepository is a mock implementation of StorageRepository
@@ -67,7 +68,7 @@ func TestRunOnce_Success(t *testing.T) {
 	service := NewService(messageRepo, storageRepo, cfg, log)
 
 	// Mock deleted messages
-	deletedMessages := []MessageInfo{
+	deletedMessages := []entity.MessageInfo{
 		{Sequence: 1, Subject: "test", ObjectName: "test_1"},
 		{Sequence: 2, Subject: "test", ObjectName: "test_2"},
 	}
@@ -85,6 +86,38 @@ func TestRunOnce_Success(t *testing.T) {
 	storageRepo.AssertExpectations(t)
 }
 
+func TestRunOnce_SkipsMessagesWithoutObject(t *testing.T) {
+	messageRepo := &MockMessageRepository{}
+	storageRepo := &MockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "info", Format: "json"})
+
+	cfg := Config{
+		Enabled:     true,
+		TTLDuration: 24 * time.Hour,
+		Interval:    1 * time.Hour,
+	}
+
+	service := NewService(messageRepo, storageRepo, cfg, log)
+
+	// Inline payloads live in Tarantool and have no object in MinIO
+	deletedMessages := []entity.MessageInfo{
+		{Sequence: 1, Subject: "test", ObjectName: ""},
+		{Sequence: 2, Subject: "test", ObjectName: "test_2"},
+	}
+
+	ctx := context.Background()
+
+	messageRepo.On("DeleteOldMessages", 86400).Return(2, deletedMessages, nil)
+	storageRepo.On("DeleteObject", ctx, "test_2").Return(nil)
+
+	err := service.RunOnce(ctx)
+
+	assert.NoError(t, err)
+	messageRepo.AssertExpectations(t)
+	storageRepo.AssertExpectations(t)
+	storageRepo.AssertNumberOfCalls(t, "DeleteObject", 1)
+}
+
 func TestRunOnce_NoMessagesToDelete(t *testing.T) {
 	messageRepo := &MockMessageRepository{}
 	storageRepo := &MockStorageRepository{}
@@ -100,7 +133,7 @@ func TestRunOnce_NoMessagesToDelete(t *testing.T) {
 
 	ctx := context.Background()
 
-	messageRepo.On("DeleteOldMessages", 86400).Return(0, []MessageInfo{}, nil)
+	messageRepo.On("DeleteOldMessages", 86400).Return(0, []entity.MessageInfo{}, nil)
 
 	err := service.RunOnce(ctx)
 
@@ -148,7 +181,7 @@ func TestRunOnce_PartialStorageFailure(t *testing.T) {
 	service := NewService(messageRepo, storageRepo, cfg, log)
 
 	// Mock deleted messages
-	deletedMessages := []MessageInfo{
+	deletedMessages := []entity.MessageInfo{
 		{Sequence: 1, Subject: "test", ObjectName: "test_1"},
 		{Sequence: 2, Subject: "test", ObjectName: "test_2"},
 	}
@@ -204,7 +237,7 @@ func TestStart_Stop(t *testing.T) {
 	ctx := context.Background()
 
 	// Mock initial cleanup
-	messageRepo.On("DeleteOldMessages", 86400).Return(0, []MessageInfo{}, nil).Maybe()
+	messageRepo.On("DeleteOldMessages", 86400).Return(0, []entity.MessageInfo{}, nil).Maybe()
 
 	err := service.Start(ctx)
 	assert.NoError(t, err)
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+)
+
+// headerPayloadSHA256 carries the hex SHA-256 of the stored payload, verified by egress on read
+const headerPayloadSHA256 = "payload-sha256"
+
+// payloadChecksum returns the hex SHA-256 of data
+func payloadChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyPublisherChecksum checks the payload against a checksum sent by the publisher
+// Publishers that set the header get their payload rejected if it was damaged on the way in
+func verifyPublisherChecksum(req *PublishRequest) error {
+	want, ok := req.Headers[headerPayloadSHA256]
+	if !ok {
+		return nil
+	}
+	if got := payloadChecksum(req.Data); !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: payload sha256 is %s, header says %s", entity.ErrChecksumMismatch, got, want)
+	}
+	return nil
+}
+
+// recordStoredPayload stores the size and checksum of the bytes actually written to storage
+// Compressed or encrypted payloads get values that describe the stored form,
+// messages without a payload get a zero size and the checksum of no bytes
+func recordStoredPayload(req *PublishRequest, data []byte) {
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	req.Headers[headerDataSize] = strconv.Itoa(len(data))
+	req.Headers[headerPayloadSHA256] = payloadChecksum(data)
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// sharedObjectPrefix marks content-addressed object names
+// Objects are shared within a tenant only, so names of a tenant are qualified
+const sharedObjectPrefix = "sha256/"
+
+// isSharedObject reports whether objectName is a content-addressed name
+// Message keys of a subject called "sha256" have the same prefix but never a full digest
+func isSharedObject(objectName string) bool {
+	_, name := subject.Unqualify(objectName)
+	return len(name) == len(sharedObjectPrefix)+2*sha256.Size && strings.HasPrefix(name, sharedObjectPrefix)
+}
+
+// ObjectRefRepository keeps reference counts of content-addressed objects
+type ObjectRefRepository interface {
+	AcquireObject(objectName string) (entity.ObjectState, error)
+	MarkObjectStored(objectName string) error
+	ReleaseObjectRef(objectName string) (bool, error)
+}
+
+// SetDeduplication stores payloads under their SHA-256, nil disables it
+func (uc *PublishUseCase) SetDeduplication(refs ObjectRefRepository) {
+	uc.objectRefs = refs
+}
+
+// store uploads the payload and returns the object name the message must reference
+// With deduplication identical payloads share one sha256/<hex> object. Encrypted
+// payloads, which never match, and content that is being deleted right now are
+// stored under the per-message name instead
+func (uc *PublishUseCase) store(ctx context.Context, req *PublishRequest, payloadID uint64, objectName string, data []byte) (string, error) {
+	if uc.objectRefs == nil || encryption.IsEncrypted(req.Headers) {
+		return objectName, uc.upload(ctx, req, payloadID, objectName, data)
+	}
+
+	tenant, _ := subject.Unqualify(req.Subject)
+	shared := subject.Qualify(tenant, sharedObjectPrefix+req.Headers[headerPayloadSHA256])
+
+	state, err := uc.objectRefs.AcquireObject(shared)
+	if err != nil {
+		uc.logger.Warn("Failed to acquire shared object, storing payload per message",
+			logger.String("object_name", shared),
+			logger.Error(err),
+		)
+		return objectName, uc.upload(ctx, req, payloadID, objectName, data)
+	}
+
+	switch state {
+	case entity.ObjectStored:
+		uc.logger.Debug("Payload deduplicated",
+			logger.String("subject", req.Subject),
+			logger.String("object_name", shared),
+		)
+		return shared, nil
+	case entity.ObjectBusy:
+		return objectName, uc.upload(ctx, req, payloadID, objectName, data)
+	}
+
+	if err := uc.upload(ctx, req, payloadID, shared, data); err != nil {
+		uc.discardObject(ctx, shared)
+		return "", err
+	}
+	if err := uc.objectRefs.MarkObjectStored(shared); err != nil {
+		// The next publisher of the same content simply uploads it again
+		uc.logger.Warn("Failed to mark shared object as stored",
+			logger.String("object_name", shared),
+			logger.Error(err),
+		)
+	}
+	return shared, nil
+}
+
+// discardObject removes a payload the failed publish no longer references
+// A shared object is only deleted together with its last reference
+func (uc *PublishUseCase) discardObject(ctx context.Context, objectName string) {
+	if uc.objectRefs != nil && isSharedObject(objectName) {
+		last, err := uc.objectRefs.ReleaseObjectRef(objectName)
+		if err != nil {
+			uc.logger.Error("Failed to release shared object",
+				logger.String("object_name", objectName),
+				logger.Error(err),
+			)
+			return
+		}
+		if !last {
+			return
+		}
+	}
+	uc.deleteObject(ctx, objectName)
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// memtxUsageTTL bounds how often publishes ask Tarantool for its memory usage
+const memtxUsageTTL = time.Second
+
+// InlinePolicy decides which payloads are stored in the Tarantool tuple instead of MinIO
+type InlinePolicy struct {
+	// MaxSize is the largest stored payload kept inline, in bytes
+	MaxSize int
+	// MaxMemoryUsage is the used fraction of memtx_memory above which payloads go to MinIO
+	MaxMemoryUsage float64
+}
+
+// memtxUsage caches the last memory usage reading shared by concurrent publishes
+type memtxUsage struct {
+	mu        sync.Mutex
+	value     float64
+	checkedAt time.Time
+}
+
+// SetInlinePolicy enables inline storage of small payloads, nil disables it
+func (uc *PublishUseCase) SetInlinePolicy(policy *InlinePolicy) {
+	uc.inline = policy
+}
+
+// storeInline reports whether a payload of the given stored size goes into the tuple
+// Payloads fall back to MinIO while Tarantool is close to its memory limit
+func (uc *PublishUseCase) storeInline(size int) bool {
+	if uc.inline == nil || size == 0 || size > uc.inline.MaxSize {
+		return false
+	}
+	return uc.memtxUsage() < uc.inline.MaxMemoryUsage
+}
+
+// memtxUsage returns the cached memtx usage, refreshing it at most once per memtxUsageTTL
+// An unknown usage counts as full, so a failing check never grows Tarantool memory
+func (uc *PublishUseCase) memtxUsage() float64 {
+	uc.memtx.mu.Lock()
+	defer uc.memtx.mu.Unlock()
+
+	if time.Since(uc.memtx.checkedAt) < memtxUsageTTL {
+		return uc.memtx.value
+	}
+
+	usage, err := uc.messageRepo.GetMemtxUsage()
+	if err != nil {
+		uc.logger.Warn("Failed to get Tarantool memory usage, storing payloads in MinIO",
+			logger.Error(err),
+		)
+		usage = 1
+	}
+
+	uc.memtx.value = usage
+	uc.memtx.checkedAt = time.Now()
+	return usage
+}
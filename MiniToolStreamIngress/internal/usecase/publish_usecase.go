@@ -3,24 +3,39 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
 )
 
+// headerDataSize carries the stored payload size, used by Tarantool for subject statistics
+const headerDataSize = "data-size"
+
 // MessageRepository defines the interface for message storage
 type MessageRepository interface {
 	GetNextSequence() (uint64, error)
-	InsertMessage(sequence uint64, subject string, headers map[string]string, objectName string) error
+	InsertMessage(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error)
+	InsertMessageIf(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error)
+	ScheduleMessage(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error
 	PublishMessage(subject string, headers map[string]string) (uint64, error) // legacy
+	CheckPublishLimits(subject string, size int) (*entity.PublishLimits, error)
+	GetMemtxUsage() (float64, error)
+	EnforceSubjectLimits(subject string) ([]entity.MessageInfo, error)
 	Ping() error
 	Close() error
 }
 
 // StorageRepository defines the interface for object storage
 type StorageRepository interface {
-	UploadData(ctx context.Context, objectName string, data []byte, contentType string) error
+	UploadData(ctx context.Context, objectName string, data []byte, contentType, checksum string) error
 	GetObjectURL(objectName string) string
 	EnsureBucket(ctx context.Context) error
+	DeleteObject(ctx context.Context, objectName string) error
 }
 
 // PublishUseCase handles message publishing logic
@@ -28,6 +43,13 @@ type PublishUseCase struct {
 	messageRepo MessageRepository
 	storageRepo StorageRepository
 	logger      *logger.Logger
+	compression *compression.Policy
+	envelope    *encryption.Envelope
+	inline      *InlinePolicy
+	memtx       memtxUsage
+	objectRefs  ObjectRefRepository
+	schemas     SchemaLookup
+	schemaCache schemaCache
 }
 
 // NewPublishUseCase creates a new publish use case
@@ -43,24 +65,45 @@ func NewPublishUseCase(
 	}
 }
 
+// SetCompressionPolicy enables payload compression, nil disables it
+func (uc *PublishUseCase) SetCompressionPolicy(policy *compression.Policy) {
+	uc.compression = policy
+}
+
+// SetEnvelope enables payload encryption at rest, nil disables it
+func (uc *PublishUseCase) SetEnvelope(envelope *encryption.Envelope) {
+	uc.envelope = envelope
+}
+
 // PublishRequest represents a publish request
 type PublishRequest struct {
 	Subject string
 	Data    []byte
 	Headers map[string]string
+	// Expect makes the publish conditional on the stored sequences (optional)
+	Expect *entity.PublishExpectations
+	// DeliverAt delays visibility of the message until the given time (optional)
+	DeliverAt time.Time
 }
 
 // PublishResponse represents a publish response
 type PublishResponse struct {
 	Sequence   uint64
 	ObjectName string
+	// SubjectSequence is the dense per-subject position of the message
+	SubjectSequence uint64
+	// ScheduleID identifies a delayed message; Sequence and SubjectSequence
+	// are assigned only when it is delivered
+	ScheduleID uint64
 }
 
 // Publish publishes a message with optional data to storage
 // IMPORTANT: Order of operations to prevent race conditions:
-// 1. Allocate sequence number
-// 2. Upload payload to MinIO (if present)
-// 3. Insert metadata to Tarantool
+// 1. Check subject limits
+// 2. Allocate a payload id naming the stored payload
+// 3. Encrypt, checksum and upload payload to MinIO (if present and not stored inline)
+// 4. Insert metadata to Tarantool, which assigns the sequences, or schedule it if DeliverAt is in the future
+// 5. Trim the subject if its discard policy drops old messages
 // This ensures metadata only appears after payload is available
 func (uc *PublishUseCase) Publish(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
 	if req == nil {
@@ -70,71 +113,297 @@ func (uc *PublishUseCase) Publish(ctx context.Context, req *PublishRequest) (*Pu
 	if req.Subject == "" {
 		return nil, fmt.Errorf("subject cannot be empty")
 	}
+	if err := subject.ValidateQualified(req.Subject); err != nil {
+		return nil, err
+	}
+
+	// Expectations are checked against the stream at insert time, which for
+	// a delayed message is not the time the publisher observed it
+	scheduled := req.DeliverAt.After(time.Now())
+	if scheduled && req.Expect != nil {
+		return nil, entity.ErrScheduledWithExpectations
+	}
+
+	if err := verifyPublisherChecksum(req); err != nil {
+		uc.logger.Warn("Publish rejected: payload checksum mismatch",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return nil, err
+	}
+
+	if err := uc.validateSchema(req); err != nil {
+		uc.logger.Warn("Publish rejected: payload does not match subject schema",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return nil, err
+	}
 
 	uc.logger.Info("Publishing message",
 		logger.String("subject", req.Subject),
 		logger.Int("data_size", len(req.Data)),
 	)
 
-	// Step 1: Allocate sequence number from Tarantool
-	sequence, err := uc.messageRepo.GetNextSequence()
+	// Limits and statistics apply to the bytes actually stored
+	data, err := uc.compressPayload(req)
 	if err != nil {
-		uc.logger.Error("Failed to get next sequence",
+		return nil, err
+	}
+
+	storedSize := len(data)
+	if uc.envelope != nil && storedSize > 0 {
+		storedSize += encryption.Overhead
+	}
+
+	// Step 1: Reject early if the subject is declared with limits this publish would violate
+	limits, err := uc.messageRepo.CheckPublishLimits(req.Subject, storedSize)
+	if err != nil {
+		uc.logger.Error("Failed to check subject limits",
+			logger.String("subject", req.Subject),
+			logger.Error(err),
+		)
+		return nil, fmt.Errorf("failed to check subject limits: %w", err)
+	}
+	if !limits.Allowed {
+		uc.logger.Warn("Publish rejected by subject limits",
+			logger.String("subject", req.Subject),
+			logger.String("reason", limits.Reason),
+		)
+		return nil, fmt.Errorf("%w: %s", entity.ErrSubjectLimitExceeded, limits.Reason)
+	}
+
+	// Step 2: Allocate a payload id from the Tarantool sequence counter
+	// The message sequences are assigned later, by the metadata insert
+	payloadID, err := uc.messageRepo.GetNextSequence()
+	if err != nil {
+		uc.logger.Error("Failed to get payload id",
 			logger.String("subject", req.Subject),
 			logger.Error(err),
 		)
 		return nil, fmt.Errorf("failed to get next sequence: %w", err)
 	}
 
-	// Generate object name based on subject and sequence
-	objectName := fmt.Sprintf("%s_%d", req.Subject, sequence)
+	// Generate object name based on subject and payload id
+	// It also identifies the payload when encrypting, inline payloads included
+	messageKey := subject.ObjectKey(req.Subject, payloadID)
+	objectName := messageKey
 
-	// Step 2: Upload data to MinIO if present (BEFORE metadata insert)
-	if len(req.Data) > 0 {
-		contentType := "application/octet-stream"
-		if ct, ok := req.Headers["content-type"]; ok {
-			contentType = ct
-		}
+	// Small payloads are kept in the tuple; such messages have no object
+	inline := uc.storeInline(storedSize)
+	if inline {
+		objectName = ""
+	}
 
-		err = uc.storageRepo.UploadData(ctx, objectName, req.Data, contentType)
+	// Step 3: Upload data to MinIO if present (BEFORE metadata insert)
+	var payload []byte
+	if len(data) > 0 {
+		data, err = uc.sealPayload(ctx, req, messageKey, data)
 		if err != nil {
-			uc.logger.Error("Failed to upload data to storage",
+			uc.logger.Error("Failed to encrypt payload",
 				logger.String("subject", req.Subject),
-				logger.Uint64("sequence", sequence),
-				logger.String("object_name", objectName),
+				logger.Uint64("payload_id", payloadID),
 				logger.Error(err),
 			)
-			// NOTE: sequence is "burned" here (gap in sequence numbers)
+			return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+	}
+
+	// Size and checksum describe the stored bytes, values sent by the publisher never survive
+	recordStoredPayload(req, data)
+
+	if len(data) > 0 {
+		if inline {
+			payload = data
+		} else if objectName, err = uc.store(ctx, req, payloadID, objectName, data); err != nil {
+			// NOTE: payload id is "burned" here (gap in sequence numbers)
 			// This is acceptable to prevent race condition
 			return nil, fmt.Errorf("failed to upload data: %w", err)
 		}
 	}
 
-	// Step 3: Insert message metadata to Tarantool (AFTER payload is uploaded)
-	err = uc.messageRepo.InsertMessage(sequence, req.Subject, req.Headers, objectName)
+	if scheduled {
+		return uc.schedule(ctx, req, payloadID, objectName, payload)
+	}
+
+	// Step 4: Insert message metadata to Tarantool (AFTER payload is uploaded)
+	var sequence, subjectSeq uint64
+	if req.Expect != nil {
+		sequence, subjectSeq, err = uc.messageRepo.InsertMessageIf(req.Subject, req.Headers, objectName, req.Expect, payload)
+	} else {
+		sequence, subjectSeq, err = uc.messageRepo.InsertMessage(req.Subject, req.Headers, objectName, payload)
+	}
 	if err != nil {
 		uc.logger.Error("Failed to insert message metadata",
 			logger.String("subject", req.Subject),
-			logger.Uint64("sequence", sequence),
+			logger.String("object_name", objectName),
 			logger.Error(err),
 		)
-		// NOTE: MinIO object exists but metadata is missing
-		// TTL cleanup will eventually remove orphaned objects
+		// Metadata is missing, so nothing references the payload any more
+		if len(data) > 0 && !inline {
+			uc.discardObject(ctx, objectName)
+		}
 		return nil, fmt.Errorf("failed to insert message metadata: %w", err)
 	}
 
+	// Step 5: Drop the oldest messages if this publish pushed the subject over its limits
+	if limits.Trim {
+		uc.trimSubject(ctx, req.Subject)
+	}
+
 	uc.logger.Info("Message published successfully",
 		logger.String("subject", req.Subject),
 		logger.Uint64("sequence", sequence),
+		logger.Uint64("subject_sequence", subjectSeq),
 		logger.String("object_name", objectName),
+		logger.Bool("inline", inline),
+	)
+
+	return &PublishResponse{
+		Sequence:        sequence,
+		SubjectSequence: subjectSeq,
+		ObjectName:      objectName,
+	}, nil
+}
+
+// schedule stores metadata of a delayed message (step 4 for scheduled publishes)
+// Subject trimming happens when the scheduler service delivers the message
+func (uc *PublishUseCase) schedule(ctx context.Context, req *PublishRequest, scheduleID uint64, objectName string, payload []byte) (*PublishResponse, error) {
+	err := uc.messageRepo.ScheduleMessage(scheduleID, req.Subject, req.Headers, objectName, req.DeliverAt, payload)
+	if err != nil {
+		uc.logger.Error("Failed to schedule message",
+			logger.String("subject", req.Subject),
+			logger.Uint64("schedule_id", scheduleID),
+			logger.Error(err),
+		)
+		if len(req.Data) > 0 && payload == nil {
+			uc.discardObject(ctx, objectName)
+		}
+		return nil, fmt.Errorf("failed to schedule message: %w", err)
+	}
+
+	uc.logger.Info("Message scheduled successfully",
+		logger.String("subject", req.Subject),
+		logger.Uint64("schedule_id", scheduleID),
+		logger.Duration("delay", time.Until(req.DeliverAt)),
 	)
 
 	return &PublishResponse{
-		Sequence:   sequence,
 		ObjectName: objectName,
+		ScheduleID: scheduleID,
 	}, nil
 }
 
+// compressPayload compresses the payload if the policy selects an algorithm for it
+// Records content-encoding and original-size in headers
+// Payloads that would not shrink are kept as is
+func (uc *PublishUseCase) compressPayload(req *PublishRequest) ([]byte, error) {
+	alg := uc.compression.Choose(req.Subject, len(req.Data))
+	if alg == compression.None {
+		return req.Data, nil
+	}
+	compressed, err := compression.Compress(alg, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if len(compressed) >= len(req.Data) {
+		return req.Data, nil
+	}
+
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	req.Headers[compression.HeaderContentEncoding] = string(alg)
+	req.Headers[compression.HeaderOriginalSize] = strconv.Itoa(len(req.Data))
+
+	uc.logger.Debug("Payload compressed",
+		logger.String("subject", req.Subject),
+		logger.String("algorithm", string(alg)),
+		logger.Int("original_size", len(req.Data)),
+		logger.Int("compressed_size", len(compressed)),
+	)
+
+	return compressed, nil
+}
+
+// upload stores the payload in MinIO under objectName
+func (uc *PublishUseCase) upload(ctx context.Context, req *PublishRequest, payloadID uint64, objectName string, data []byte) error {
+	contentType := "application/octet-stream"
+	if ct, ok := req.Headers["content-type"]; ok {
+		contentType = ct
+	}
+
+	err := uc.storageRepo.UploadData(ctx, objectName, data, contentType, req.Headers[headerPayloadSHA256])
+	if err != nil {
+		uc.logger.Error("Failed to upload data to storage",
+			logger.String("subject", req.Subject),
+			logger.Uint64("payload_id", payloadID),
+			logger.String("object_name", objectName),
+			logger.Error(err),
+		)
+	}
+	return err
+}
+
+// sealPayload encrypts the payload under a fresh data key if encryption is enabled
+// The message key (subject and payload id) is authenticated with the payload, so payloads cannot be swapped
+// Records the wrapped data key and the message key in headers
+func (uc *PublishUseCase) sealPayload(ctx context.Context, req *PublishRequest, messageKey string, data []byte) ([]byte, error) {
+	if uc.envelope == nil {
+		return data, nil
+	}
+
+	sealed, headers, err := uc.envelope.Seal(ctx, req.Subject, []byte(messageKey), data)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	for k, v := range headers {
+		req.Headers[k] = v
+	}
+
+	return sealed, nil
+}
+
+// trimSubject enforces subject limits right after a publish
+// Failures are only logged: the retention enforcer will catch up
+func (uc *PublishUseCase) trimSubject(ctx context.Context, subject string) {
+	deleted, err := uc.messageRepo.EnforceSubjectLimits(subject)
+	if err != nil {
+		uc.logger.Error("Failed to trim subject",
+			logger.String("subject", subject),
+			logger.Error(err),
+		)
+		return
+	}
+
+	for _, msg := range deleted {
+		if msg.ObjectName != "" {
+			uc.deleteObject(ctx, msg.ObjectName)
+		}
+	}
+
+	if len(deleted) > 0 {
+		uc.logger.Debug("Subject trimmed to its limits",
+			logger.String("subject", subject),
+			logger.Int("deleted", len(deleted)),
+		)
+	}
+}
+
+// deleteObject removes a payload from storage on a best-effort basis
+func (uc *PublishUseCase) deleteObject(ctx context.Context, objectName string) {
+	if err := uc.storageRepo.DeleteObject(ctx, objectName); err != nil {
+		uc.logger.Error("Failed to delete object from storage",
+			logger.String("object_name", objectName),
+			logger.Error(err),
+		)
+	}
+}
+
 // HealthCheck checks if all dependencies are healthy
 func (uc *PublishUseCase) HealthCheck(ctx context.Context) error {
 	// Check message repository
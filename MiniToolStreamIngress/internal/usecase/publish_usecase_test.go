@@ -1,19 +1,34 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/logger"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/compression"
+	"github.com/moroshma/MiniToolStream/pkg/encryption"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
 )
 
 type mockMessageRepository struct {
 	publishFunc       func(subject string, headers map[string]string) (uint64, error)
 	getNextSeqFunc    func() (uint64, error)
-	insertMessageFunc func(sequence uint64, subject string, headers map[string]string, objectName string) error
+	insertMessageFunc func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error)
+	insertIfFunc      func(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error)
+	checkLimitsFunc   func(subject string, size int) (*entity.PublishLimits, error)
+	enforceLimitsFunc func(subject string) ([]entity.MessageInfo, error)
 	pingFunc          func() error
 	closeFunc         func() error
+	scheduleFunc      func(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error
+	memtxUsageFunc    func() (float64, error)
 }
 
 func (m *mockMessageRepository) PublishMessage(subject string, headers map[string]string) (uint64, error) {
@@ -30,11 +45,11 @@ func (m *mockMessageRepository) GetNextSequence() (uint64, error) {
 	return 0, nil
 }
 
-func (m *mockMessageRepository) InsertMessage(sequence uint64, subject string, headers map[string]string, objectName string) error {
+func (m *mockMessageRepository) InsertMessage(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
 	if m.insertMessageFunc != nil {
-		return m.insertMessageFunc(sequence, subject, headers, objectName)
+		return m.insertMessageFunc(subject, headers, objectName, payload)
 	}
-	return nil
+	return 0, 0, nil
 }
 
 func (m *mockMessageRepository) Ping() error {
@@ -51,15 +66,51 @@ func (m *mockMessageRepository) Close() error {
 	return nil
 }
 
+func (m *mockMessageRepository) InsertMessageIf(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error) {
+	if m.insertIfFunc != nil {
+		return m.insertIfFunc(subject, headers, objectName, expect, payload)
+	}
+	return 0, 0, nil
+}
+
+func (m *mockMessageRepository) ScheduleMessage(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error {
+	if m.scheduleFunc != nil {
+		return m.scheduleFunc(sequence, subject, headers, objectName, deliverAt, payload)
+	}
+	return nil
+}
+
+func (m *mockMessageRepository) CheckPublishLimits(subject string, size int) (*entity.PublishLimits, error) {
+	if m.checkLimitsFunc != nil {
+		return m.checkLimitsFunc(subject, size)
+	}
+	return &entity.PublishLimits{Allowed: true}, nil
+}
+
+func (m *mockMessageRepository) GetMemtxUsage() (float64, error) {
+	if m.memtxUsageFunc != nil {
+		return m.memtxUsageFunc()
+	}
+	return 0, nil
+}
+
+func (m *mockMessageRepository) EnforceSubjectLimits(subject string) ([]entity.MessageInfo, error) {
+	if m.enforceLimitsFunc != nil {
+		return m.enforceLimitsFunc(subject)
+	}
+	return nil, nil
+}
+
 type mockStorageRepository struct {
-	uploadFunc       func(ctx context.Context, objectName string, data []byte, contentType string) error
+	uploadFunc       func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error
 	getURLFunc       func(objectName string) string
 	ensureBucketFunc func(ctx context.Context) error
+	deleteObjectFunc func(ctx context.Context, objectName string) error
 }
 
-func (m *mockStorageRepository) UploadData(ctx context.Context, objectName string, data []byte, contentType string) error {
+func (m *mockStorageRepository) UploadData(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 	if m.uploadFunc != nil {
-		return m.uploadFunc(ctx, objectName, data, contentType)
+		return m.uploadFunc(ctx, objectName, data, contentType, checksum)
 	}
 	return nil
 }
@@ -78,6 +129,13 @@ func (m *mockStorageRepository) EnsureBucket(ctx context.Context) error {
 	return nil
 }
 
+func (m *mockStorageRepository) DeleteObject(ctx context.Context, objectName string) error {
+	if m.deleteObjectFunc != nil {
+		return m.deleteObjectFunc(ctx, objectName)
+	}
+	return nil
+}
+
 func TestNewPublishUseCase(t *testing.T) {
 	msgRepo := &mockMessageRepository{}
 	storageRepo := &mockStorageRepository{}
@@ -167,12 +225,12 @@ func TestPublishUseCase_Publish_StorageRepoError(t *testing.T) {
 		getNextSeqFunc: func() (uint64, error) {
 			return 42, nil
 		},
-		insertMessageFunc: func(sequence uint64, subject string, headers map[string]string, objectName string) error {
-			return nil
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 1, 1, nil
 		},
 	}
 	storageRepo := &mockStorageRepository{
-		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType string) error {
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 			return errors.New("minio error")
 		},
 	}
@@ -202,12 +260,12 @@ func TestPublishUseCase_Publish_Success_WithData(t *testing.T) {
 		getNextSeqFunc: func() (uint64, error) {
 			return 123, nil
 		},
-		insertMessageFunc: func(sequence uint64, subject string, headers map[string]string, objectName string) error {
-			return nil
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 124, 1, nil
 		},
 	}
 	storageRepo := &mockStorageRepository{
-		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType string) error {
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 			uploadedData = data
 			uploadedObjectName = objectName
 			uploadedContentType = contentType
@@ -236,17 +294,21 @@ func TestPublishUseCase_Publish_Success_WithData(t *testing.T) {
 	if resp == nil {
 		t.Fatal("expected non-nil response")
 	}
-	if resp.Sequence != 123 {
-		t.Errorf("expected sequence 123, got %d", resp.Sequence)
+	// The sequence is assigned by the insert, the object is named by the payload id
+	if resp.Sequence != 124 {
+		t.Errorf("expected sequence 124, got %d", resp.Sequence)
 	}
-	if resp.ObjectName != "test.subject_123" {
+	if resp.ObjectName != "test.subject/123" {
 		t.Errorf("expected object name 'test.subject_123', got '%s'", resp.ObjectName)
 	}
+	if resp.SubjectSequence != 1 {
+		t.Errorf("expected subject sequence 1, got %d", resp.SubjectSequence)
+	}
 
 	if string(uploadedData) != "test data" {
 		t.Errorf("expected uploaded data 'test data', got '%s'", string(uploadedData))
 	}
-	if uploadedObjectName != "test.subject_123" {
+	if uploadedObjectName != "test.subject/123" {
 		t.Errorf("expected uploaded object name 'test.subject_123', got '%s'", uploadedObjectName)
 	}
 	if uploadedContentType != "text/plain" {
@@ -260,12 +322,12 @@ func TestPublishUseCase_Publish_Success_WithoutData(t *testing.T) {
 		getNextSeqFunc: func() (uint64, error) {
 			return 456, nil
 		},
-		insertMessageFunc: func(sequence uint64, subject string, headers map[string]string, objectName string) error {
-			return nil
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 457, 1, nil
 		},
 	}
 	storageRepo := &mockStorageRepository{
-		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType string) error {
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 			uploadCalled = true
 			return nil
 		},
@@ -289,10 +351,10 @@ func TestPublishUseCase_Publish_Success_WithoutData(t *testing.T) {
 	if resp == nil {
 		t.Fatal("expected non-nil response")
 	}
-	if resp.Sequence != 456 {
-		t.Errorf("expected sequence 456, got %d", resp.Sequence)
+	if resp.Sequence != 457 {
+		t.Errorf("expected sequence 457, got %d", resp.Sequence)
 	}
-	if resp.ObjectName != "test.subject_456" {
+	if resp.ObjectName != "test.subject/456" {
 		t.Errorf("expected object name 'test.subject_456', got '%s'", resp.ObjectName)
 	}
 
@@ -308,12 +370,12 @@ func TestPublishUseCase_Publish_DefaultContentType(t *testing.T) {
 		getNextSeqFunc: func() (uint64, error) {
 			return 789, nil
 		},
-		insertMessageFunc: func(sequence uint64, subject string, headers map[string]string, objectName string) error {
-			return nil
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 1, 1, nil
 		},
 	}
 	storageRepo := &mockStorageRepository{
-		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType string) error {
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
 			uploadedContentType = contentType
 			return nil
 		},
@@ -339,6 +401,110 @@ func TestPublishUseCase_Publish_DefaultContentType(t *testing.T) {
 	}
 }
 
+func TestPublishUseCase_Publish_SubjectLimitExceeded(t *testing.T) {
+	seqCalled := false
+	msgRepo := &mockMessageRepository{
+		checkLimitsFunc: func(subject string, size int) (*entity.PublishLimits, error) {
+			if size != 9 {
+				t.Errorf("expected size 9, got %d", size)
+			}
+			return &entity.PublishLimits{Allowed: false, Reason: "subject has reached max_msgs 10"}, nil
+		},
+		getNextSeqFunc: func() (uint64, error) {
+			seqCalled = true
+			return 1, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "test.subject",
+		Data:    []byte("test data"),
+	})
+	if !errors.Is(err, entity.ErrSubjectLimitExceeded) {
+		t.Fatalf("expected ErrSubjectLimitExceeded, got %v", err)
+	}
+	if seqCalled {
+		t.Error("sequence should not be allocated for a rejected publish")
+	}
+}
+
+func TestPublishUseCase_Publish_TrimsSubject(t *testing.T) {
+	var deletedObjects []string
+	msgRepo := &mockMessageRepository{
+		checkLimitsFunc: func(subject string, size int) (*entity.PublishLimits, error) {
+			return &entity.PublishLimits{Allowed: true, Trim: true}, nil
+		},
+		getNextSeqFunc: func() (uint64, error) {
+			return 11, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 12, 1, nil
+		},
+		enforceLimitsFunc: func(subject string) ([]entity.MessageInfo, error) {
+			return []entity.MessageInfo{{Sequence: 1, Subject: subject, ObjectName: "test.subject/1"}}, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		deleteObjectFunc: func(ctx context.Context, objectName string) error {
+			deletedObjects = append(deletedObjects, objectName)
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+
+	resp, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "test.subject",
+		Data:    []byte("test data"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Sequence != 12 {
+		t.Errorf("expected sequence 12, got %d", resp.Sequence)
+	}
+	if len(deletedObjects) != 1 || deletedObjects[0] != "test.subject/1" {
+		t.Errorf("expected trimmed object test.subject_1 to be deleted, got %v", deletedObjects)
+	}
+}
+
+func TestPublishUseCase_Publish_InsertErrorDeletesObject(t *testing.T) {
+	var deletedObject string
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 7, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			return 0, 0, errors.New("subject limit exceeded: subject has reached max_msgs 10")
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		deleteObjectFunc: func(ctx context.Context, objectName string) error {
+			deletedObject = objectName
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "test.subject",
+		Data:    []byte("test data"),
+	})
+	if err == nil {
+		t.Fatal("expected error from insert")
+	}
+	if deletedObject != "test.subject/7" {
+		t.Errorf("expected orphaned object test.subject_7 to be deleted, got %q", deletedObject)
+	}
+}
+
 func TestPublishUseCase_HealthCheck_Success(t *testing.T) {
 	msgRepo := &mockMessageRepository{
 		pingFunc: func() error {
@@ -404,3 +570,616 @@ func TestPublishUseCase_HealthCheck_StorageRepoUnhealthy(t *testing.T) {
 		t.Fatal("expected error for unhealthy storage repository")
 	}
 }
+
+func TestPublishUseCase_Publish_ConditionalInsert(t *testing.T) {
+	var gotExpect *entity.PublishExpectations
+	plainInsertCalled := false
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 8, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			plainInsertCalled = true
+			return 1, 1, nil
+		},
+		insertIfFunc: func(subject string, headers map[string]string, objectName string, expect *entity.PublishExpectations, payload []byte) (uint64, uint64, error) {
+			gotExpect = expect
+			return 1, 1, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, &mockStorageRepository{}, log)
+
+	last := uint64(7)
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "orders.42",
+		Expect:  &entity.PublishExpectations{LastSubjectSequence: &last},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plainInsertCalled {
+		t.Error("conditional publish must not use the unconditional insert")
+	}
+	if gotExpect == nil || *gotExpect.LastSubjectSequence != 7 {
+		t.Errorf("expectations were not passed to the repository: %+v", gotExpect)
+	}
+}
+
+func TestPublishUseCase_Publish_Scheduled(t *testing.T) {
+	var scheduledAt time.Time
+	insertCalled := false
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 11, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			insertCalled = true
+			return 1, 1, nil
+		},
+		scheduleFunc: func(sequence uint64, subject string, headers map[string]string, objectName string, deliverAt time.Time, payload []byte) error {
+			scheduledAt = deliverAt
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, &mockStorageRepository{}, log)
+
+	deliverAt := time.Now().Add(10 * time.Minute)
+	resp, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject:   "reports.daily",
+		Data:      []byte("payload"),
+		DeliverAt: deliverAt,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if insertCalled {
+		t.Error("delayed message must not be inserted before it is due")
+	}
+	if !scheduledAt.Equal(deliverAt) {
+		t.Errorf("expected delivery at %v, got %v", deliverAt, scheduledAt)
+	}
+	if resp.ScheduleID != 11 || resp.Sequence != 0 {
+		t.Errorf("expected schedule id 11 and no sequence yet, got %+v", resp)
+	}
+	if resp.ObjectName != "reports.daily/11" {
+		t.Errorf("expected object name 'reports.daily_11', got '%s'", resp.ObjectName)
+	}
+
+	// A delivery time that has already passed means publish now
+	resp, err = uc.Publish(context.Background(), &PublishRequest{
+		Subject:   "reports.daily",
+		DeliverAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !insertCalled || resp.ScheduleID != 0 {
+		t.Errorf("expected immediate publish for past delivery time, got %+v", resp)
+	}
+}
+
+func TestPublishUseCase_Publish_ScheduledWithExpectations(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(&mockMessageRepository{}, &mockStorageRepository{}, log)
+
+	last := uint64(3)
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject:   "orders.42",
+		Expect:    &entity.PublishExpectations{LastSubjectSequence: &last},
+		DeliverAt: time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, entity.ErrScheduledWithExpectations) {
+		t.Errorf("expected ErrScheduledWithExpectations, got %v", err)
+	}
+}
+
+func TestPublishUseCase_Publish_CompressesPayload(t *testing.T) {
+	var uploaded []byte
+	var stored map[string]string
+	var checkedSize int
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 5, nil
+		},
+		checkLimitsFunc: func(subject string, size int) (*entity.PublishLimits, error) {
+			checkedSize = size
+			return &entity.PublishLimits{Allowed: true}, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
+			uploaded = data
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+	uc.SetCompressionPolicy(&compression.Policy{Default: compression.Zstd, MinSize: 64})
+
+	payload := bytes.Repeat([]byte(`{"level":"info","msg":"ok"}`), 50)
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "logs.app",
+		Data:    payload,
+		Headers: map[string]string{"data-size": strconv.Itoa(len(payload))},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(uploaded) >= len(payload) {
+		t.Fatalf("expected compressed upload, got %d of %d bytes", len(uploaded), len(payload))
+	}
+	if checkedSize != len(uploaded) {
+		t.Errorf("expected limits checked against stored size %d, got %d", len(uploaded), checkedSize)
+	}
+	if stored["content-encoding"] != "zstd" {
+		t.Errorf("expected content-encoding zstd, got %q", stored["content-encoding"])
+	}
+	if stored["original-size"] != strconv.Itoa(len(payload)) {
+		t.Errorf("expected original-size %d, got %q", len(payload), stored["original-size"])
+	}
+	if stored["data-size"] != strconv.Itoa(len(uploaded)) {
+		t.Errorf("expected data-size %d, got %q", len(uploaded), stored["data-size"])
+	}
+
+	decoded, err := compression.Decompress(compression.Zstd, uploaded)
+	if err != nil || !bytes.Equal(decoded, payload) {
+		t.Errorf("uploaded payload does not decompress to the original: %v", err)
+	}
+
+	// Small payloads are not worth compressing
+	uploaded = nil
+	_, err = uc.Publish(context.Background(), &PublishRequest{Subject: "logs.app", Data: []byte("tiny")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(uploaded) != "tiny" {
+		t.Errorf("expected small payload stored as is, got %q", uploaded)
+	}
+}
+
+// staticKeyManager hands out one fixed data key, standing in for Vault transit
+type staticKeyManager struct {
+	requested []string
+}
+
+func (m *staticKeyManager) GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error) {
+	m.requested = append(m.requested, keyName)
+	return bytes.Repeat([]byte{7}, 32), "vault:v1:wrapped", nil
+}
+
+func (m *staticKeyManager) DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error) {
+	return bytes.Repeat([]byte{7}, 32), nil
+}
+
+func TestPublishUseCase_Publish_EncryptsPayload(t *testing.T) {
+	var uploaded []byte
+	var stored map[string]string
+	var checkedSize int
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			return 9, nil
+		},
+		checkLimitsFunc: func(subject string, size int) (*entity.PublishLimits, error) {
+			checkedSize = size
+			return &entity.PublishLimits{Allowed: true}, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
+			uploaded = data
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	keys := &staticKeyManager{}
+	envelope := encryption.NewEnvelope(keys, "minitoolstream", map[string]string{"acme.*": "tenant-acme"})
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+	uc.SetEnvelope(envelope)
+
+	payload := []byte("account=42;balance=100")
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "acme.payments",
+		Data:    payload,
+		Headers: map[string]string{"data-size": strconv.Itoa(len(payload))},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bytes.Contains(uploaded, payload) {
+		t.Fatal("expected payload to be encrypted before upload")
+	}
+	if checkedSize != len(uploaded) {
+		t.Errorf("expected limits checked against stored size %d, got %d", len(uploaded), checkedSize)
+	}
+	if len(keys.requested) != 1 || keys.requested[0] != "tenant-acme" {
+		t.Errorf("expected data key wrapped by tenant key, got %v", keys.requested)
+	}
+	if stored[encryption.HeaderKeyName] != "tenant-acme" || stored[encryption.HeaderDataKey] != "vault:v1:wrapped" {
+		t.Errorf("expected wrapped data key in headers, got %v", stored)
+	}
+	if stored["data-size"] != strconv.Itoa(len(uploaded)) {
+		t.Errorf("expected data-size %d, got %q", len(uploaded), stored["data-size"])
+	}
+
+	opened, err := envelope.Open(context.Background(), stored, []byte("acme.payments/9"), uploaded)
+	if err != nil || !bytes.Equal(opened, payload) {
+		t.Errorf("uploaded payload does not decrypt to the original: %v", err)
+	}
+
+	// Inline payloads have no object name and are bound to the same message key
+	var inlined []byte
+	msgRepo.insertMessageFunc = func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+		stored, inlined = headers, payload
+		return 1, 2, nil
+	}
+	uc.SetInlinePolicy(&InlinePolicy{MaxSize: 1024, MaxMemoryUsage: 1})
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "acme.payments", Data: payload}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored[encryption.HeaderContext] != "acme.payments/9" {
+		t.Errorf("expected inline payload bound to its message key, got %q", stored[encryption.HeaderContext])
+	}
+	opened, err = envelope.Open(context.Background(), stored, encryption.Context(stored, ""), inlined)
+	if err != nil || !bytes.Equal(opened, payload) {
+		t.Errorf("inline payload does not decrypt to the original: %v", err)
+	}
+}
+
+func TestPublishUseCase_Publish_InlinePayload(t *testing.T) {
+	newUseCase := func(memtxUsage float64) (*PublishUseCase, *[]byte, *string, *[]byte) {
+		var inlined, uploaded []byte
+		var storedObject string
+		msgRepo := &mockMessageRepository{
+			getNextSeqFunc: func() (uint64, error) {
+				return 11, nil
+			},
+			insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+				storedObject = objectName
+				inlined = payload
+				return 1, 1, nil
+			},
+			memtxUsageFunc: func() (float64, error) {
+				return memtxUsage, nil
+			},
+		}
+		storageRepo := &mockStorageRepository{
+			uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
+				uploaded = data
+				return nil
+			},
+		}
+		log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+		uc := NewPublishUseCase(msgRepo, storageRepo, log)
+		uc.SetInlinePolicy(&InlinePolicy{MaxSize: 64, MaxMemoryUsage: 0.8})
+		return uc, &inlined, &storedObject, &uploaded
+	}
+
+	// Small payload is stored in the tuple without touching MinIO
+	uc, inlined, storedObject, uploaded := newUseCase(0.2)
+	resp, err := uc.Publish(context.Background(), &PublishRequest{Subject: "events", Data: []byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(*inlined) != `{"id":1}` || *uploaded != nil {
+		t.Errorf("expected inline payload without upload, inline=%q uploaded=%q", *inlined, *uploaded)
+	}
+	if *storedObject != "" || resp.ObjectName != "" {
+		t.Errorf("expected no object name for inline payload, got %q", *storedObject)
+	}
+
+	// Large payload goes to MinIO
+	uc, inlined, storedObject, uploaded = newUseCase(0.2)
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "events", Data: bytes.Repeat([]byte("x"), 65)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *inlined != nil || len(*uploaded) != 65 || *storedObject != "events/11" {
+		t.Errorf("expected large payload in MinIO, inline=%d uploaded=%d object=%q", len(*inlined), len(*uploaded), *storedObject)
+	}
+
+	// Tarantool close to memtx_memory: fall back to MinIO
+	uc, inlined, _, uploaded = newUseCase(0.95)
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "events", Data: []byte(`{"id":2}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *inlined != nil || *uploaded == nil {
+		t.Error("expected fallback to MinIO when memtx usage is above the limit")
+	}
+}
+
+// mockObjectRefRepository counts references in memory like the object_ref space
+type mockObjectRefRepository struct {
+	refs   map[string]int
+	stored map[string]bool
+	busy   map[string]bool
+}
+
+func newMockObjectRefRepository() *mockObjectRefRepository {
+	return &mockObjectRefRepository{refs: map[string]int{}, stored: map[string]bool{}, busy: map[string]bool{}}
+}
+
+func (m *mockObjectRefRepository) AcquireObject(objectName string) (entity.ObjectState, error) {
+	if m.busy[objectName] {
+		return entity.ObjectBusy, nil
+	}
+	m.refs[objectName]++
+	if m.stored[objectName] {
+		return entity.ObjectStored, nil
+	}
+	return entity.ObjectUpload, nil
+}
+
+func (m *mockObjectRefRepository) MarkObjectStored(objectName string) error {
+	m.stored[objectName] = true
+	return nil
+}
+
+func (m *mockObjectRefRepository) ReleaseObjectRef(objectName string) (bool, error) {
+	m.refs[objectName]--
+	return m.refs[objectName] == 0, nil
+}
+
+func TestPublishUseCase_Publish_Deduplicated(t *testing.T) {
+	seq := uint64(0)
+	var insertErr error
+	var storedObjects []string
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			seq++
+			return seq, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			storedObjects = append(storedObjects, objectName)
+			return 100 + seq, 1, insertErr
+		},
+	}
+	var uploads, deletes []string
+	storageRepo := &mockStorageRepository{
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
+			uploads = append(uploads, objectName)
+			return nil
+		},
+		deleteObjectFunc: func(ctx context.Context, objectName string) error {
+			deletes = append(deletes, objectName)
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	refs := newMockObjectRefRepository()
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+	uc.SetDeduplication(refs)
+
+	snapshot := []byte("config snapshot v42")
+	for _, subject := range []string{"configs.a", "configs.b"} {
+		if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: subject, Data: snapshot}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(uploads) != 1 || uploads[0] != storedObjects[0] {
+		t.Fatalf("expected a single upload of the shared object, got %v", uploads)
+	}
+	if storedObjects[0] != storedObjects[1] || storedObjects[0][:7] != "sha256/" {
+		t.Errorf("expected both messages to reference one sha256 object, got %v", storedObjects)
+	}
+	if refs.refs[storedObjects[0]] != 2 {
+		t.Errorf("expected 2 references, got %d", refs.refs[storedObjects[0]])
+	}
+
+	// A failed insert gives its reference back without deleting the shared object
+	insertErr = errors.New("tarantool unavailable")
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "configs.c", Data: snapshot}); err == nil {
+		t.Fatal("expected error")
+	}
+	if refs.refs[storedObjects[0]] != 2 || len(deletes) != 0 {
+		t.Errorf("expected reference released without delete, refs=%d deletes=%v", refs.refs[storedObjects[0]], deletes)
+	}
+
+	// Content being deleted is stored under the per-message name
+	insertErr = nil
+	refs.busy[storedObjects[0]] = true
+	resp, err := uc.Publish(context.Background(), &PublishRequest{Subject: "configs.d", Data: snapshot})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ObjectName != fmt.Sprintf("configs.d/%d", seq) {
+		t.Errorf("expected per-message object name, got %q", resp.ObjectName)
+	}
+
+	// Tenants never share objects with each other
+	resp, err = uc.Publish(context.Background(), &PublishRequest{Subject: "$TENANT.acme.configs.a", Data: snapshot})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(resp.ObjectName, "$TENANT.acme.sha256/") || !isSharedObject(resp.ObjectName) {
+		t.Errorf("expected a shared object of the tenant, got %q", resp.ObjectName)
+	}
+}
+
+func TestPublishUseCase_Publish_Checksum(t *testing.T) {
+	var uploaded []byte
+	var uploadedChecksum string
+	var stored map[string]string
+	seqAllocated := false
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			seqAllocated = true
+			return 9, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	storageRepo := &mockStorageRepository{
+		uploadFunc: func(ctx context.Context, objectName string, data []byte, contentType, checksum string) error {
+			uploaded = data
+			uploadedChecksum = checksum
+			return nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	uc := NewPublishUseCase(msgRepo, storageRepo, log)
+	uc.SetCompressionPolicy(&compression.Policy{Default: compression.Gzip})
+
+	payload := bytes.Repeat([]byte("reading=42;"), 100)
+	sum := sha256.Sum256(payload)
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "sensors",
+		Data:    payload,
+		Headers: map[string]string{"payload-sha256": hex.EncodeToString(sum[:])},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The checksum describes the compressed bytes that were stored
+	storedSum := sha256.Sum256(uploaded)
+	if stored["payload-sha256"] != hex.EncodeToString(storedSum[:]) {
+		t.Errorf("expected checksum of stored payload, got %q", stored["payload-sha256"])
+	}
+	if uploadedChecksum != stored["payload-sha256"] {
+		t.Errorf("expected checksum passed to storage, got %q", uploadedChecksum)
+	}
+
+	// A payload that does not match the publisher's checksum is rejected before anything is stored
+	seqAllocated = false
+	_, err = uc.Publish(context.Background(), &PublishRequest{
+		Subject: "sensors",
+		Data:    []byte("damaged"),
+		Headers: map[string]string{"payload-sha256": hex.EncodeToString(sum[:])},
+	})
+	if !errors.Is(err, entity.ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if seqAllocated {
+		t.Error("expected no sequence allocated for a rejected payload")
+	}
+}
+
+func TestPublishUseCase_Publish_OverwritesForgedHeaders(t *testing.T) {
+	var stored map[string]string
+	msgRepo := &mockMessageRepository{
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	uc := NewPublishUseCase(msgRepo, &mockStorageRepository{}, log)
+
+	// Subject statistics must not count the size a publisher claims
+	payload := []byte("reading=42")
+	_, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "sensors",
+		Data:    payload,
+		Headers: map[string]string{"data-size": "999999999"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum := sha256.Sum256(payload)
+	if stored["data-size"] != strconv.Itoa(len(payload)) || stored["payload-sha256"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected server-computed size and checksum, got %v", stored)
+	}
+
+	// Messages without a payload are overwritten as well
+	empty := sha256.Sum256(nil)
+	_, err = uc.Publish(context.Background(), &PublishRequest{
+		Subject: "sensors",
+		Headers: map[string]string{"data-size": "4096", "payload-sha256": hex.EncodeToString(empty[:])},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored["data-size"] != "0" || stored["payload-sha256"] != hex.EncodeToString(empty[:]) {
+		t.Errorf("expected zero size and the checksum of no bytes, got %v", stored)
+	}
+
+	// A checksum that does not describe the empty payload is rejected
+	if _, err := uc.Publish(context.Background(), &PublishRequest{
+		Subject: "sensors",
+		Headers: map[string]string{"payload-sha256": hex.EncodeToString(sum[:])},
+	}); !errors.Is(err, entity.ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestPublishUseCase_Publish_ValidatesSchema(t *testing.T) {
+	var stored map[string]string
+	seqAllocated := false
+	msgRepo := &mockMessageRepository{
+		getNextSeqFunc: func() (uint64, error) {
+			seqAllocated = true
+			return 5, nil
+		},
+		insertMessageFunc: func(subject string, headers map[string]string, objectName string, payload []byte) (uint64, uint64, error) {
+			stored = headers
+			return 1, 1, nil
+		},
+	}
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+
+	schemas := newMockSchemaRepository()
+	registered, err := schemas.RegisterSchema(&entity.Schema{Subject: "documents.json", Format: "json", Definition: []byte(documentSchemaV1)}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	uc := NewPublishUseCase(msgRepo, &mockStorageRepository{}, log)
+	uc.SetSchemaRegistry(schemas)
+
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "documents.json", Data: []byte(`{"id":"doc-1"}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored["schema-id"] != strconv.FormatUint(registered.ID, 10) || stored["schema-version"] != "1" {
+		t.Errorf("expected schema headers, got %v", stored)
+	}
+
+	seqAllocated = false
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "documents.json", Data: []byte(`{"id":`)}); !errors.Is(err, entity.ErrSchemaViolation) {
+		t.Fatalf("expected ErrSchemaViolation for malformed JSON, got %v", err)
+	}
+	if seqAllocated {
+		t.Error("expected no sequence allocated for a rejected payload")
+	}
+
+	// The active schema is cached between publishes
+	if schemas.lookups != 1 {
+		t.Errorf("expected 1 schema lookup, got %d", schemas.lookups)
+	}
+
+	// Subjects without a schema are not validated
+	stored = nil
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "logs", Data: []byte("plain text")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := stored["schema-id"]; ok {
+		t.Errorf("expected no schema headers, got %v", stored)
+	}
+
+	// A failing registry rejects the publish instead of skipping validation
+	schemas.getErr = errors.New("tarantool unavailable")
+	if _, err := uc.Publish(context.Background(), &PublishRequest{Subject: "orders", Data: []byte(`{}`)}); err == nil {
+		t.Fatal("expected error when the schema cannot be looked up")
+	}
+}
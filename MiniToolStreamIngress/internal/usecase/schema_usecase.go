@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// SchemaRepository defines the interface for schema registry storage
+type SchemaRepository interface {
+	// RegisterSchema stores the next version unless the latest version differs from expectedVersion
+	RegisterSchema(s *entity.Schema, expectedVersion uint64) (*entity.Schema, error)
+	// GetSchema returns a version of the subject schema, 0 means the latest
+	GetSchema(subject string, version uint64) (*entity.Schema, error)
+	// GetSchemaByID returns a schema by its global ID
+	GetSchemaByID(id uint64) (*entity.Schema, error)
+	// ListSchemaVersions returns the registered versions of a subject, oldest first
+	ListSchemaVersions(subject string) ([]uint64, error)
+	GetSchemaCompatibility(subject string) (schema.Compatibility, error)
+	SetSchemaCompatibility(subject string, mode schema.Compatibility) error
+}
+
+// SchemaUseCase handles registering payload schemas of subjects
+type SchemaUseCase struct {
+	schemaRepo SchemaRepository
+	logger     *logger.Logger
+}
+
+// NewSchemaUseCase creates a new schema use case
+func NewSchemaUseCase(schemaRepo SchemaRepository, log *logger.Logger) *SchemaUseCase {
+	return &SchemaUseCase{
+		schemaRepo: schemaRepo,
+		logger:     log,
+	}
+}
+
+// RegisterSchema adds a schema version to a subject
+// The definition must compile and satisfy the subject compatibility mode against
+// the latest version. Registering the latest definition again returns it unchanged
+func (uc *SchemaUseCase) RegisterSchema(s *entity.Schema) (*entity.Schema, error) {
+	if s == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+	if err := subject.ValidateQualified(s.Subject); err != nil {
+		return nil, err
+	}
+
+	next, err := schema.Compile(s.Format, s.Definition, s.MessageType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrInvalidSchema, err)
+	}
+
+	var expectedVersion uint64
+	latest, err := uc.schemaRepo.GetSchema(s.Subject, 0)
+	switch {
+	case errors.Is(err, entity.ErrSchemaNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get latest schema: %w", err)
+	default:
+		if err := uc.checkCompatibility(latest, next); err != nil {
+			return nil, err
+		}
+		expectedVersion = latest.Version
+	}
+
+	stored, err := uc.schemaRepo.RegisterSchema(s, expectedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register schema: %w", err)
+	}
+
+	uc.logger.Info("Schema registered",
+		logger.String("subject", stored.Subject),
+		logger.Uint64("schema_id", stored.ID),
+		logger.Uint64("version", stored.Version),
+		logger.String("format", string(stored.Format)),
+	)
+
+	return stored, nil
+}
+
+// checkCompatibility checks next against the latest version under the subject mode
+func (uc *SchemaUseCase) checkCompatibility(latest *entity.Schema, next schema.Schema) error {
+	mode, err := uc.schemaRepo.GetSchemaCompatibility(latest.Subject)
+	if err != nil {
+		return fmt.Errorf("failed to get schema compatibility: %w", err)
+	}
+
+	prev, err := schema.Compile(latest.Format, latest.Definition, latest.MessageType)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema version %d: %w", latest.Version, err)
+	}
+
+	if err := schema.CheckCompatibility(mode, prev, next); err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrIncompatibleSchema, err)
+	}
+	return nil
+}
+
+// GetSchema returns a schema version of a subject, 0 means the latest
+func (uc *SchemaUseCase) GetSchema(subjectName string, version uint64) (*entity.Schema, error) {
+	if err := subject.ValidateQualified(subjectName); err != nil {
+		return nil, err
+	}
+	return uc.schemaRepo.GetSchema(subjectName, version)
+}
+
+// GetSchemaByID returns the schema named by the schema-id header of a message
+func (uc *SchemaUseCase) GetSchemaByID(id uint64) (*entity.Schema, error) {
+	if id == 0 {
+		return nil, entity.ErrSchemaNotFound
+	}
+	return uc.schemaRepo.GetSchemaByID(id)
+}
+
+// ListSchemaVersions returns the registered versions of a subject, oldest first
+func (uc *SchemaUseCase) ListSchemaVersions(subjectName string) ([]uint64, error) {
+	if err := subject.ValidateQualified(subjectName); err != nil {
+		return nil, err
+	}
+	versions, err := uc.schemaRepo.ListSchemaVersions(subjectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema versions: %w", err)
+	}
+	return versions, nil
+}
+
+// SetCompatibility changes the compatibility mode of a subject
+// The mode applies to versions registered afterwards
+func (uc *SchemaUseCase) SetCompatibility(subjectName, mode string) error {
+	if err := subject.ValidateQualified(subjectName); err != nil {
+		return err
+	}
+	compatibility, err := schema.ParseCompatibility(mode)
+	if err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrInvalidSchema, err)
+	}
+	if err := uc.schemaRepo.SetSchemaCompatibility(subjectName, compatibility); err != nil {
+		return fmt.Errorf("failed to set schema compatibility: %w", err)
+	}
+
+	uc.logger.Info("Schema compatibility changed",
+		logger.String("subject", subjectName),
+		logger.String("compatibility", string(compatibility)),
+	)
+	return nil
+}
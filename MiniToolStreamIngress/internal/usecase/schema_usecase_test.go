@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// mockSchemaRepository keeps schema versions in memory
+type mockSchemaRepository struct {
+	versions      map[string][]*entity.Schema
+	compatibility map[string]schema.Compatibility
+	nextID        uint64
+	lookups       int
+	getErr        error
+}
+
+func newMockSchemaRepository() *mockSchemaRepository {
+	return &mockSchemaRepository{
+		versions:      make(map[string][]*entity.Schema),
+		compatibility: make(map[string]schema.Compatibility),
+	}
+}
+
+func (m *mockSchemaRepository) RegisterSchema(s *entity.Schema, expectedVersion uint64) (*entity.Schema, error) {
+	versions := m.versions[s.Subject]
+	if uint64(len(versions)) != expectedVersion {
+		return nil, entity.ErrSchemaConflict
+	}
+	m.nextID++
+	stored := *s
+	stored.ID = m.nextID
+	stored.Version = expectedVersion + 1
+	m.versions[s.Subject] = append(versions, &stored)
+	return &stored, nil
+}
+
+func (m *mockSchemaRepository) GetSchema(subject string, version uint64) (*entity.Schema, error) {
+	m.lookups++
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	versions := m.versions[subject]
+	if version == 0 {
+		version = uint64(len(versions))
+	}
+	if version == 0 || version > uint64(len(versions)) {
+		return nil, entity.ErrSchemaNotFound
+	}
+	return versions[version-1], nil
+}
+
+func (m *mockSchemaRepository) GetSchemaByID(id uint64) (*entity.Schema, error) {
+	for _, versions := range m.versions {
+		for _, s := range versions {
+			if s.ID == id {
+				return s, nil
+			}
+		}
+	}
+	return nil, entity.ErrSchemaNotFound
+}
+
+func (m *mockSchemaRepository) ListSchemaVersions(subject string) ([]uint64, error) {
+	versions := []uint64{}
+	for _, s := range m.versions[subject] {
+		versions = append(versions, s.Version)
+	}
+	return versions, nil
+}
+
+func (m *mockSchemaRepository) GetSchemaCompatibility(subject string) (schema.Compatibility, error) {
+	if mode, ok := m.compatibility[subject]; ok {
+		return mode, nil
+	}
+	return schema.CompatibilityBackward, nil
+}
+
+func (m *mockSchemaRepository) SetSchemaCompatibility(subject string, mode schema.Compatibility) error {
+	m.compatibility[subject] = mode
+	return nil
+}
+
+const documentSchemaV1 = `{"type":"object","required":["id"],"properties":{"id":{"type":"string"}}}`
+
+func TestSchemaUseCase_RegisterSchema(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := newMockSchemaRepository()
+	uc := NewSchemaUseCase(repo, log)
+
+	v1, err := uc.RegisterSchema(&entity.Schema{Subject: "documents.json", Format: schema.JSON, Definition: []byte(documentSchemaV1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v1.Version != 1 {
+		t.Errorf("expected version 1, got %d", v1.Version)
+	}
+
+	// An optional property keeps old documents readable
+	v2, err := uc.RegisterSchema(&entity.Schema{
+		Subject:    "documents.json",
+		Format:     schema.JSON,
+		Definition: []byte(`{"type":"object","required":["id"],"properties":{"id":{"type":"string"},"title":{"type":"string"}}}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v2.Version != 2 {
+		t.Errorf("expected version 2, got %d", v2.Version)
+	}
+
+	// A new required property is not backward compatible
+	incompatible := &entity.Schema{
+		Subject:    "documents.json",
+		Format:     schema.JSON,
+		Definition: []byte(`{"type":"object","required":["id","owner"],"properties":{"id":{"type":"string"},"owner":{"type":"string"}}}`),
+	}
+	if _, err := uc.RegisterSchema(incompatible); !errors.Is(err, entity.ErrIncompatibleSchema) {
+		t.Fatalf("expected ErrIncompatibleSchema, got %v", err)
+	}
+
+	// ...unless the subject does not check compatibility
+	if err := uc.SetCompatibility("documents.json", "none"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uc.RegisterSchema(incompatible); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := uc.RegisterSchema(&entity.Schema{Subject: "documents.json", Format: schema.JSON, Definition: []byte(`{"type":"document"}`)}); err == nil {
+		t.Error("expected error for a schema that does not compile")
+	}
+	if _, err := uc.RegisterSchema(&entity.Schema{Subject: "documents/json", Format: schema.JSON, Definition: []byte(documentSchemaV1)}); err == nil {
+		t.Error("expected error for an invalid subject")
+	}
+	if err := uc.SetCompatibility("documents.json", "transitive"); err == nil {
+		t.Error("expected error for an unknown compatibility mode")
+	}
+}
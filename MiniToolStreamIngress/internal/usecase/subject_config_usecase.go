@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// SubjectConfigRepository defines the interface for subject configuration storage
+type SubjectConfigRepository interface {
+	PutSubjectConfig(cfg *entity.SubjectConfig) (*entity.SubjectConfig, error)
+	GetSubjectConfig(subject string) (*entity.SubjectConfig, error)
+	DeleteSubjectConfig(subject string) error
+	ListSubjectConfigs() ([]*entity.SubjectConfig, error)
+}
+
+// SubjectConfigUseCase handles declaring subjects and their retention limits
+type SubjectConfigUseCase struct {
+	configRepo SubjectConfigRepository
+	logger     *logger.Logger
+}
+
+// NewSubjectConfigUseCase creates a new subject config use case
+func NewSubjectConfigUseCase(configRepo SubjectConfigRepository, log *logger.Logger) *SubjectConfigUseCase {
+	return &SubjectConfigUseCase{
+		configRepo: configRepo,
+		logger:     log,
+	}
+}
+
+// DeclareSubject creates a subject or updates its limits
+// Existing data above new limits is trimmed by the retention enforcer
+func (uc *SubjectConfigUseCase) DeclareSubject(cfg *entity.SubjectConfig) (*entity.SubjectConfig, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("%w: config cannot be nil", entity.ErrInvalidSubjectConfig)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Discard == "" {
+		cfg.Discard = entity.DiscardOld
+	}
+	if cfg.Retention == "" {
+		cfg.Retention = entity.RetentionLimits
+	}
+
+	stored, err := uc.configRepo.PutSubjectConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare subject: %w", err)
+	}
+
+	uc.logger.Info("Subject declared",
+		logger.String("subject", stored.Subject),
+		logger.Uint64("max_msgs", stored.MaxMsgs),
+		logger.Uint64("max_bytes", stored.MaxBytes),
+		logger.Duration("max_age", stored.MaxAge),
+		logger.Uint64("max_msg_size", stored.MaxMsgSize),
+		logger.String("discard", string(stored.Discard)),
+		logger.String("retention", string(stored.Retention)),
+	)
+
+	return stored, nil
+}
+
+// GetSubject returns limits of a declared subject
+func (uc *SubjectConfigUseCase) GetSubject(subject string) (*entity.SubjectConfig, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+	return uc.configRepo.GetSubjectConfig(subject)
+}
+
+// DeleteSubject removes limits of a subject, stored messages are kept
+func (uc *SubjectConfigUseCase) DeleteSubject(subject string) error {
+	if subject == "" {
+		return fmt.Errorf("subject cannot be empty")
+	}
+	if err := uc.configRepo.DeleteSubjectConfig(subject); err != nil {
+		return err
+	}
+
+	uc.logger.Info("Subject limits removed", logger.String("subject", subject))
+	return nil
+}
+
+// ListSubjects returns all declared subjects
+func (uc *SubjectConfigUseCase) ListSubjects() ([]*entity.SubjectConfig, error) {
+	return uc.configRepo.ListSubjectConfigs()
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/apikey"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// APIKeyRepository defines the interface for API key storage
+type APIKeyRepository interface {
+	CreateAPIKey(k *entity.APIKey) error
+	GetAPIKey(id string) (*entity.APIKey, error)
+	// TouchAPIKey records a successful exchange
+	TouchAPIKey(id string) error
+	// ListAPIKeys returns the keys of a client, or all keys for an empty clientID
+	ListAPIKeys(clientID string) ([]*entity.APIKey, error)
+	RevokeAPIKey(id string) error
+}
+
+// TokenSigner signs tokens with the active JWT key
+type TokenSigner interface {
+	Sign(claims *auth.Claims) (string, error)
+}
+
+// TokenUseCase exchanges API keys for short-lived tokens
+// Holders of a key never see signing material, and a revoked key stops
+// yielding tokens while tokens already issued expire on their own
+type TokenUseCase struct {
+	keyRepo    APIKeyRepository
+	signer     TokenSigner
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	now        func() time.Time
+	logger     *logger.Logger
+}
+
+// NewTokenUseCase creates a token use case
+// Keys without a token lifetime get defaultTTL, no token outlives maxTTL
+func NewTokenUseCase(keyRepo APIKeyRepository, signer TokenSigner, defaultTTL, maxTTL time.Duration, log *logger.Logger) *TokenUseCase {
+	return &TokenUseCase{
+		keyRepo:    keyRepo,
+		signer:     signer,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		now:        time.Now,
+		logger:     log,
+	}
+}
+
+// CreateAPIKey stores a key granting the client, subjects and permissions of k
+// and returns the key, which is shown only once
+func (uc *TokenUseCase) CreateAPIKey(k *entity.APIKey) (string, error) {
+	if k == nil {
+		return "", fmt.Errorf("%w: api key cannot be nil", entity.ErrInvalidAPIKeyRequest)
+	}
+	if k.ClientID == "" {
+		return "", fmt.Errorf("%w: client_id is required", entity.ErrInvalidAPIKeyRequest)
+	}
+	if len(k.Subjects) == 0 || len(k.Permissions) == 0 {
+		return "", fmt.Errorf("%w: subjects and permissions are required", entity.ErrInvalidAPIKeyRequest)
+	}
+	for _, s := range k.Subjects {
+		if err := subject.ValidatePattern(s); err != nil {
+			return "", fmt.Errorf("%w: %v", entity.ErrInvalidAPIKeyRequest, err)
+		}
+	}
+	if k.TokenTTL < 0 || k.TokenTTL > uc.maxTTL {
+		return "", fmt.Errorf("%w: token ttl must be between 0 and %s", entity.ErrInvalidAPIKeyRequest, uc.maxTTL)
+	}
+	if k.TokenTTL == 0 {
+		k.TokenTTL = uc.defaultTTL
+	}
+	now := uc.now()
+	if !k.ExpiresAt.IsZero() && !k.ExpiresAt.After(now) {
+		return "", fmt.Errorf("%w: expiry must be in the future", entity.ErrInvalidAPIKeyRequest)
+	}
+
+	key, err := apikey.Generate()
+	if err != nil {
+		return "", err
+	}
+	k.ID = key.ID
+	k.Hash = key.Hash()
+	k.CreatedAt = now
+	if err := uc.keyRepo.CreateAPIKey(k); err != nil {
+		return "", err
+	}
+
+	uc.logger.Info("API key created",
+		logger.String("key_id", k.ID),
+		logger.String("client_id", k.ClientID),
+	)
+	return key.String(), nil
+}
+
+// Exchange returns a token for an API key and its expiry
+// Every rejection is reported as entity.ErrInvalidAPIKey, so callers cannot probe for key ids
+func (uc *TokenUseCase) Exchange(raw string) (string, time.Time, error) {
+	key, err := apikey.Parse(raw)
+	if err != nil {
+		return "", time.Time{}, entity.ErrInvalidAPIKey
+	}
+
+	stored, err := uc.keyRepo.GetAPIKey(key.ID)
+	if errors.Is(err, entity.ErrAPIKeyNotFound) {
+		return "", time.Time{}, entity.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := uc.now()
+	if !key.Matches(stored.Hash) || !stored.Active(now) {
+		uc.logger.Warn("API key exchange rejected",
+			logger.String("key_id", key.ID),
+			logger.String("client_id", stored.ClientID),
+		)
+		return "", time.Time{}, entity.ErrInvalidAPIKey
+	}
+
+	ttl := stored.TokenTTL
+	if ttl <= 0 {
+		ttl = uc.defaultTTL
+	}
+	if ttl > uc.maxTTL {
+		ttl = uc.maxTTL
+	}
+	expiresAt := now.Add(ttl)
+	// A token does not outlive the key it was exchanged for
+	if !stored.ExpiresAt.IsZero() && stored.ExpiresAt.Before(expiresAt) {
+		expiresAt = stored.ExpiresAt
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	token, err := uc.signer.Sign(&auth.Claims{
+		ClientID:        stored.ClientID,
+		AllowedSubjects: stored.Subjects,
+		Permissions:     stored.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   "apikey:" + stored.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if err := uc.keyRepo.TouchAPIKey(stored.ID); err != nil {
+		uc.logger.Warn("Failed to record API key use", logger.String("key_id", stored.ID), logger.Error(err))
+	}
+	uc.logger.Debug("API key exchanged",
+		logger.String("key_id", stored.ID),
+		logger.String("client_id", stored.ClientID),
+		logger.String("jti", jti),
+	)
+	return token, expiresAt, nil
+}
+
+// ListAPIKeys returns the keys of a client, or all keys for an empty clientID
+func (uc *TokenUseCase) ListAPIKeys(clientID string) ([]*entity.APIKey, error) {
+	return uc.keyRepo.ListAPIKeys(clientID)
+}
+
+// GetAPIKey returns a stored key
+func (uc *TokenUseCase) GetAPIKey(id string) (*entity.APIKey, error) {
+	return uc.keyRepo.GetAPIKey(id)
+}
+
+// RevokeAPIKey stops a key from being exchanged
+func (uc *TokenUseCase) RevokeAPIKey(id string) error {
+	if err := uc.keyRepo.RevokeAPIKey(id); err != nil {
+		return err
+	}
+	uc.logger.Info("API key revoked", logger.String("key_id", id))
+	return nil
+}
+
+// newTokenID returns a random jti, so single tokens can be revoked
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/apikey"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// mockAPIKeyRepository keeps API keys in memory
+type mockAPIKeyRepository struct {
+	keys    map[string]*entity.APIKey
+	touched []string
+}
+
+func (m *mockAPIKeyRepository) CreateAPIKey(k *entity.APIKey) error {
+	stored := *k
+	m.keys[k.ID] = &stored
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetAPIKey(id string) (*entity.APIKey, error) {
+	k, ok := m.keys[id]
+	if !ok {
+		return nil, entity.ErrAPIKeyNotFound
+	}
+	return k, nil
+}
+
+func (m *mockAPIKeyRepository) TouchAPIKey(id string) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+func (m *mockAPIKeyRepository) ListAPIKeys(clientID string) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	for _, k := range m.keys {
+		if clientID == "" || k.ClientID == clientID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepository) RevokeAPIKey(id string) error {
+	k, ok := m.keys[id]
+	if !ok {
+		return entity.ErrAPIKeyNotFound
+	}
+	k.RevokedAt = time.Now()
+	return nil
+}
+
+// mockSigner records the claims it signs
+type mockSigner struct {
+	claims *auth.Claims
+}
+
+func (m *mockSigner) Sign(claims *auth.Claims) (string, error) {
+	m.claims = claims
+	return "signed." + claims.ID, nil
+}
+
+func newTokenUseCase(t *testing.T) (*TokenUseCase, *mockAPIKeyRepository, *mockSigner) {
+	t.Helper()
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	repo := &mockAPIKeyRepository{keys: make(map[string]*entity.APIKey)}
+	signer := &mockSigner{}
+	return NewTokenUseCase(repo, signer, 15*time.Minute, time.Hour, log), repo, signer
+}
+
+func TestTokenUseCase_CreateAndExchange(t *testing.T) {
+	uc, repo, signer := newTokenUseCase(t)
+	now := time.Unix(1_700_000_000, 0)
+	uc.now = func() time.Time { return now }
+
+	raw, err := uc.CreateAPIKey(&entity.APIKey{
+		ClientID:    "acme/ci-runner",
+		Subjects:    []string{"builds.*"},
+		Permissions: []string{auth.PermissionPublish},
+		Description: "nightly builds",
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if len(repo.keys) != 1 {
+		t.Fatalf("expected 1 stored key, got %d", len(repo.keys))
+	}
+	for _, k := range repo.keys {
+		if strings.Contains(raw, k.Hash) || k.TokenTTL != 15*time.Minute {
+			t.Errorf("unexpected stored key: %+v", k)
+		}
+	}
+
+	token, expiresAt, err := uc.Exchange(raw)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if token == "" || !expiresAt.Equal(now.Add(15*time.Minute)) {
+		t.Errorf("unexpected token %q expiring at %s", token, expiresAt)
+	}
+	claims := signer.claims
+	if claims.ClientID != "acme/ci-runner" || claims.AllowedSubjects[0] != "builds.*" || claims.ID == "" || claims.IssuedAt == nil {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if len(repo.touched) != 1 {
+		t.Errorf("expected the exchange to be recorded, got %v", repo.touched)
+	}
+}
+
+func TestTokenUseCase_Exchange_Rejected(t *testing.T) {
+	uc, repo, _ := newTokenUseCase(t)
+	now := time.Unix(1_700_000_000, 0)
+	uc.now = func() time.Time { return now }
+
+	create := func(k *entity.APIKey) string {
+		k.ClientID = "edge-7"
+		k.Subjects = []string{"telemetry.*"}
+		k.Permissions = []string{auth.PermissionPublish}
+		raw, err := uc.CreateAPIKey(k)
+		if err != nil {
+			t.Fatalf("CreateAPIKey failed: %v", err)
+		}
+		return raw
+	}
+
+	valid := create(&entity.APIKey{})
+	expiring := create(&entity.APIKey{ExpiresAt: now.Add(time.Minute)})
+	revoked := create(&entity.APIKey{})
+	for id, k := range repo.keys {
+		if strings.Contains(revoked, id) {
+			k.RevokedAt = now
+		}
+	}
+
+	// A token does not outlive its key
+	if _, expiresAt, err := uc.Exchange(expiring); err != nil || !expiresAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected token to expire with its key, got %s, %v", expiresAt, err)
+	}
+
+	key, _ := apikey.Parse(valid)
+	key.Secret = "guessed"
+	wrongSecret := key.String()
+	now = now.Add(2 * time.Minute)
+
+	for name, raw := range map[string]string{
+		"malformed":    "not-a-key",
+		"unknown id":   "mts_0123456789abcdef_secret",
+		"wrong secret": wrongSecret,
+		"expired":      expiring,
+		"revoked":      revoked,
+	} {
+		if _, _, err := uc.Exchange(raw); !errors.Is(err, entity.ErrInvalidAPIKey) {
+			t.Errorf("%s: expected ErrInvalidAPIKey, got %v", name, err)
+		}
+	}
+}
+
+func TestTokenUseCase_CreateAPIKey_Invalid(t *testing.T) {
+	uc, _, _ := newTokenUseCase(t)
+
+	for name, k := range map[string]*entity.APIKey{
+		"no client":       {Subjects: []string{"a"}, Permissions: []string{"publish"}},
+		"no permissions":  {ClientID: "c", Subjects: []string{"a"}},
+		"invalid pattern": {ClientID: "c", Subjects: []string{"orders*"}, Permissions: []string{"publish"}},
+		"ttl above max":   {ClientID: "c", Subjects: []string{"a"}, Permissions: []string{"publish"}, TokenTTL: 2 * time.Hour},
+		"expired":         {ClientID: "c", Subjects: []string{"a"}, Permissions: []string{"publish"}, ExpiresAt: time.Now().Add(-time.Hour)},
+	} {
+		if _, err := uc.CreateAPIKey(k); !errors.Is(err, entity.ErrInvalidAPIKeyRequest) {
+			t.Errorf("%s: expected ErrInvalidAPIKeyRequest, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/internal/domain/entity"
+	"github.com/moroshma/MiniToolStream/MiniToolStreamIngress/pkg/schema"
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+)
+
+// Headers identifying the schema a payload was validated against
+// Only validateSchema sets them, publishers cannot
+const (
+	HeaderSchemaID      = "schema-id"
+	HeaderSchemaVersion = "schema-version"
+)
+
+// schemaCacheTTL bounds how long a newly registered schema version goes unnoticed by publishes
+const schemaCacheTTL = 5 * time.Second
+
+// SchemaLookup returns the active schema of a subject
+type SchemaLookup interface {
+	// GetSchema returns a version of the subject schema, 0 means the latest
+	GetSchema(subject string, version uint64) (*entity.Schema, error)
+}
+
+// activeSchema is a cached lookup result, schema is nil for subjects without one
+type activeSchema struct {
+	schema    *entity.Schema
+	compiled  schema.Schema
+	checkedAt time.Time
+}
+
+// schemaCache keeps the latest schema of each subject published to
+type schemaCache struct {
+	mu       sync.Mutex
+	subjects map[string]*activeSchema
+}
+
+// SetSchemaRegistry enables payload validation against the latest schema of the subject, nil disables it
+func (uc *PublishUseCase) SetSchemaRegistry(registry SchemaLookup) {
+	uc.schemas = registry
+}
+
+// validateSchema checks the payload against the active schema of its subject
+// and stamps schema-id and schema-version. Subjects without a schema, and
+// empty payloads, are not validated
+func (uc *PublishUseCase) validateSchema(req *PublishRequest) error {
+	if uc.schemas == nil || len(req.Data) == 0 {
+		return nil
+	}
+
+	active, err := uc.activeSchema(req.Subject)
+	if err != nil {
+		return fmt.Errorf("failed to get subject schema: %w", err)
+	}
+	if active.schema == nil {
+		return nil
+	}
+
+	if err := active.compiled.Validate(req.Data); err != nil {
+		return fmt.Errorf("%w (version %d): %v", entity.ErrSchemaViolation, active.schema.Version, err)
+	}
+
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	req.Headers[HeaderSchemaID] = strconv.FormatUint(active.schema.ID, 10)
+	req.Headers[HeaderSchemaVersion] = strconv.FormatUint(active.schema.Version, 10)
+	return nil
+}
+
+// activeSchema returns the cached latest schema of a subject, refreshing it at most once per schemaCacheTTL
+// A failing lookup is not cached, so the publish is rejected rather than left unvalidated
+func (uc *PublishUseCase) activeSchema(subject string) (*activeSchema, error) {
+	uc.schemaCache.mu.Lock()
+	defer uc.schemaCache.mu.Unlock()
+
+	cached := uc.schemaCache.subjects[subject]
+	if cached != nil && time.Since(cached.checkedAt) < schemaCacheTTL {
+		return cached, nil
+	}
+
+	latest, err := uc.schemas.GetSchema(subject, 0)
+	if errors.Is(err, entity.ErrSchemaNotFound) {
+		latest, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	active := &activeSchema{schema: latest, checkedAt: time.Now()}
+	switch {
+	case latest == nil:
+	case cached != nil && cached.schema != nil && cached.schema.ID == latest.ID:
+		active.compiled = cached.compiled
+	default:
+		if active.compiled, err = schema.Compile(latest.Format, latest.Definition, latest.MessageType); err != nil {
+			return nil, fmt.Errorf("schema %d does not compile: %w", latest.ID, err)
+		}
+		uc.logger.Debug("Subject schema loaded",
+			logger.String("subject", subject),
+			logger.Uint64("schema_id", latest.ID),
+			logger.Uint64("version", latest.Version),
+		)
+	}
+
+	if uc.schemaCache.subjects == nil {
+		uc.schemaCache.subjects = make(map[string]*activeSchema)
+	}
+	uc.schemaCache.subjects[subject] = active
+	return active, nil
+}
@@ -0,0 +1,82 @@
+// Package apikey generates long-lived API keys that are exchanged for short-lived tokens
+//
+// A key reads "mts_<id>_<secret>". The id is public and locates the key, only
+// the SHA-256 of the secret is stored, so a leaked store yields no usable keys
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix marks API keys, which makes leaked keys easy to find by scanners
+const Prefix = "mts_"
+
+const (
+	idBytes     = 8
+	secretBytes = 32
+)
+
+// ErrMalformed is returned for a string that is not an API key
+var ErrMalformed = errors.New("malformed api key")
+
+// Key is an API key as handed to its holder
+type Key struct {
+	ID     string
+	Secret string
+}
+
+// Generate creates a random key
+func Generate() (Key, error) {
+	id := make([]byte, idBytes)
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(id); err != nil {
+		return Key{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return Key{
+		ID:     hex.EncodeToString(id),
+		Secret: base64.RawURLEncoding.EncodeToString(secret),
+	}, nil
+}
+
+// Parse splits a key into its id and secret
+func Parse(s string) (Key, error) {
+	rest, ok := strings.CutPrefix(s, Prefix)
+	if !ok {
+		return Key{}, ErrMalformed
+	}
+	// The secret is base64url and may itself contain "_"
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(id) != 2*idBytes || secret == "" {
+		return Key{}, ErrMalformed
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return Key{}, ErrMalformed
+	}
+	return Key{ID: id, Secret: secret}, nil
+}
+
+// String returns the key as handed to its holder
+func (k Key) String() string {
+	return Prefix + k.ID + "_" + k.Secret
+}
+
+// Hash returns the stored form of the secret
+func (k Key) Hash() string {
+	sum := sha256.Sum256([]byte(k.Secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether the secret hashes to hash
+func (k Key) Matches(hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.Hash()), []byte(hash)) == 1
+}
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateParse(t *testing.T) {
+	key, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	s := key.String()
+	if !strings.HasPrefix(s, Prefix) {
+		t.Errorf("expected prefix %s, got %s", Prefix, s)
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed != key {
+		t.Errorf("expected %+v, got %+v", key, parsed)
+	}
+	if !parsed.Matches(key.Hash()) {
+		t.Error("expected parsed key to match its hash")
+	}
+
+	other, _ := Generate()
+	if other.Matches(key.Hash()) {
+		t.Error("expected another key not to match")
+	}
+}
+
+func TestParse_SecretWithUnderscore(t *testing.T) {
+	key, err := Parse("mts_0123456789abcdef_a_b-c")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if key.ID != "0123456789abcdef" || key.Secret != "a_b-c" {
+		t.Errorf("unexpected key: %+v", key)
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"eyJhbGciOiJSUzI1NiJ9.e30.sig",
+		"mts_0123456789abcdef",
+		"mts_0123456789abcdef_",
+		"mts_0123_secret",
+		"mts_0123456789abcdeg_secret",
+	} {
+		if _, err := Parse(s); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%q: expected ErrMalformed, got %v", s, err)
+		}
+	}
+}
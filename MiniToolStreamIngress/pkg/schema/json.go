@@ -0,0 +1,382 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonTypes are the type names JSON Schema defines
+var jsonTypes = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+// jsonAnnotations are keywords that do not affect validation
+var jsonAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+	"format": true,
+}
+
+// jsonSchema is the supported subset of JSON Schema: type, properties, required,
+// additionalProperties (boolean only), items, enum, minLength, maxLength, minimum,
+// maximum and pattern. Other keywords are rejected rather than silently ignored
+type jsonSchema struct {
+	types      []string
+	properties map[string]*jsonSchema
+	required   []string
+	// closed is set by additionalProperties: false
+	closed    bool
+	items     *jsonSchema
+	enum      []any
+	minLength *float64
+	maxLength *float64
+	minimum   *float64
+	maximum   *float64
+	pattern   *regexp.Regexp
+}
+
+func compileJSON(definition []byte) (*jsonSchema, error) {
+	var doc any
+	if err := decodeJSON(definition, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	s, err := parseJSONSchema(doc, "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return s, nil
+}
+
+func parseJSONSchema(doc any, path string) (*jsonSchema, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object", path)
+	}
+
+	s := &jsonSchema{}
+	for key, value := range obj {
+		var err error
+		switch key {
+		case "type":
+			s.types, err = parseJSONTypes(value)
+		case "properties":
+			props, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: properties must be an object", path)
+			}
+			s.properties = make(map[string]*jsonSchema, len(props))
+			for name, prop := range props {
+				if s.properties[name], err = parseJSONSchema(prop, path+"."+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = parseStrings(value)
+		case "additionalProperties":
+			allowed, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: only boolean additionalProperties is supported", path)
+			}
+			s.closed = !allowed
+		case "items":
+			s.items, err = parseJSONSchema(value, path+"[]")
+		case "enum":
+			enum, ok := value.([]any)
+			if !ok || len(enum) == 0 {
+				return nil, fmt.Errorf("%s: enum must be a non-empty array", path)
+			}
+			s.enum = enum
+		case "minLength":
+			s.minLength, err = parseNumber(value)
+		case "maxLength":
+			s.maxLength, err = parseNumber(value)
+		case "minimum":
+			s.minimum, err = parseNumber(value)
+		case "maximum":
+			s.maximum, err = parseNumber(value)
+		case "pattern":
+			expr, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: pattern must be a string", path)
+			}
+			s.pattern, err = regexp.Compile(expr)
+		default:
+			if !jsonAnnotations[key] {
+				return nil, fmt.Errorf("%s: unsupported keyword %q", path, key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+	return s, nil
+}
+
+func parseJSONTypes(value any) ([]string, error) {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []any:
+		var err error
+		if types, err = parseStrings(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+	for _, t := range types {
+		if !slices.Contains(jsonTypes, t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+	return types, nil
+}
+
+func parseStrings(value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of strings")
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func parseNumber(value any) (*float64, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Format implements Schema
+func (s *jsonSchema) Format() Format {
+	return JSON
+}
+
+// Validate implements Schema
+func (s *jsonSchema) Validate(payload []byte) error {
+	var doc any
+	if err := decodeJSON(payload, &doc); err != nil {
+		return fmt.Errorf("%w: invalid JSON: %v", ErrInvalidPayload, err)
+	}
+	if err := s.validate(doc, "$"); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validate(v any, path string) error {
+	if t := jsonType(v); len(s.types) > 0 && !s.allows(t) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.types, " or "), t)
+	}
+	if len(s.enum) > 0 && !slices.ContainsFunc(s.enum, func(e any) bool { return equalJSON(e, v) }) {
+		return fmt.Errorf("%s: value is not one of the allowed values", path)
+	}
+
+	switch val := v.(type) {
+	case string:
+		length := float64(utf8.RuneCountInString(val))
+		if s.minLength != nil && length < *s.minLength {
+			return fmt.Errorf("%s: shorter than %v characters", path, *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			return fmt.Errorf("%s: longer than %v characters", path, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			return fmt.Errorf("%s: does not match pattern %s", path, s.pattern)
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if s.minimum != nil && f < *s.minimum {
+			return fmt.Errorf("%s: less than %v", path, *s.minimum)
+		}
+		if s.maximum != nil && f > *s.maximum {
+			return fmt.Errorf("%s: greater than %v", path, *s.maximum)
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := val[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for _, name := range sortedKeys(val) {
+			prop, ok := s.properties[name]
+			if !ok {
+				if s.closed {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+				continue
+			}
+			if err := prop.validate(val[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.items != nil {
+			for i, item := range val {
+				if err := s.items.validate(item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// allows reports whether the schema accepts values of JSON type t
+func (s *jsonSchema) allows(t string) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, t) || (t == "integer" && slices.Contains(s.types, "number"))
+}
+
+// readProblems lists why data valid under writer may be rejected by s
+// Constraints a reader does not have are never a problem
+func (s *jsonSchema) readProblems(writer *jsonSchema, path string) []string {
+	var problems []string
+
+	if len(s.types) > 0 {
+		if len(writer.types) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: type restricted to %s", path, strings.Join(s.types, " or ")))
+		}
+		for _, t := range writer.types {
+			if !s.allows(t) {
+				problems = append(problems, fmt.Sprintf("%s: type %s is no longer accepted", path, t))
+			}
+		}
+	}
+
+	for _, name := range s.required {
+		if !slices.Contains(writer.required, name) {
+			problems = append(problems, fmt.Sprintf("%s: property %q is required but may be missing", path, name))
+		}
+	}
+	for _, name := range sortedKeys(writer.properties) {
+		prop, ok := s.properties[name]
+		if !ok {
+			if s.closed {
+				problems = append(problems, fmt.Sprintf("%s: property %q is not accepted", path, name))
+			}
+			continue
+		}
+		problems = append(problems, prop.readProblems(writer.properties[name], path+"."+name)...)
+	}
+	if s.closed && !writer.closed {
+		problems = append(problems, fmt.Sprintf("%s: additional properties are not accepted", path))
+	}
+
+	if s.items != nil {
+		items := writer.items
+		if items == nil {
+			items = &jsonSchema{}
+		}
+		problems = append(problems, s.items.readProblems(items, path+"[]")...)
+	}
+
+	if len(s.enum) > 0 {
+		if len(writer.enum) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: values restricted to an enum", path))
+		}
+		for _, e := range writer.enum {
+			if !slices.ContainsFunc(s.enum, func(r any) bool { return equalJSON(r, e) }) {
+				problems = append(problems, fmt.Sprintf("%s: enum value %v is no longer accepted", path, e))
+			}
+		}
+	}
+
+	if narrowedMin(s.minLength, writer.minLength) || narrowedMax(s.maxLength, writer.maxLength) {
+		problems = append(problems, fmt.Sprintf("%s: length range narrowed", path))
+	}
+	if narrowedMin(s.minimum, writer.minimum) || narrowedMax(s.maximum, writer.maximum) {
+		problems = append(problems, fmt.Sprintf("%s: value range narrowed", path))
+	}
+	if s.pattern != nil && (writer.pattern == nil || writer.pattern.String() != s.pattern.String()) {
+		problems = append(problems, fmt.Sprintf("%s: pattern changed", path))
+	}
+
+	return problems
+}
+
+func narrowedMin(reader, writer *float64) bool {
+	return reader != nil && (writer == nil || *writer < *reader)
+}
+
+func narrowedMax(reader, writer *float64) bool {
+	return reader != nil && (writer == nil || *writer > *reader)
+}
+
+// decodeJSON decodes exactly one JSON value, keeping numbers as json.Number
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := val.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equalJSON compares decoded JSON values, numbers by value
+func equalJSON(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufSchema validates payloads as serialized messages of one type
+type protobufSchema struct {
+	message protoreflect.MessageDescriptor
+}
+
+// compileProtobuf builds the message type from a serialized FileDescriptorSet
+// The set must contain every file the message depends on, as produced by
+// protoc --include_imports --descriptor_set_out
+func compileProtobuf(definition []byte, messageType string) (*protobufSchema, error) {
+	if messageType == "" {
+		return nil, fmt.Errorf("protobuf schema needs a message type")
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(definition, &set); err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set: %w", err)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, fmt.Errorf("message type %s: %w", messageType, err)
+	}
+	message, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", messageType)
+	}
+	return &protobufSchema{message: message}, nil
+}
+
+// Format implements Schema
+func (s *protobufSchema) Format() Format {
+	return Protobuf
+}
+
+// Validate implements Schema
+// Unknown fields are accepted, as any protobuf reader would do
+func (s *protobufSchema) Validate(payload []byte) error {
+	msg := dynamicpb.NewMessage(s.message)
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+// readProblems lists fields whose encoding changed between writer and s
+// Adding and removing optional fields is always compatible
+func (s *protobufSchema) readProblems(writer *protobufSchema) []string {
+	return messageReadProblems(s.message, writer.message, map[protoreflect.FullName]bool{})
+}
+
+func messageReadProblems(reader, writer protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) []string {
+	if seen[reader.FullName()] {
+		return nil
+	}
+	seen[reader.FullName()] = true
+
+	var problems []string
+	readerFields := reader.Fields()
+	writerFields := writer.Fields()
+
+	for i := 0; i < readerFields.Len(); i++ {
+		rf := readerFields.Get(i)
+		wf := writerFields.ByNumber(rf.Number())
+		if wf == nil {
+			if rf.Cardinality() == protoreflect.Required {
+				problems = append(problems, fmt.Sprintf("%s: required field %d may be missing", reader.FullName(), rf.Number()))
+			}
+			continue
+		}
+
+		if rf.Kind() != wf.Kind() || rf.IsList() != wf.IsList() {
+			problems = append(problems, fmt.Sprintf("%s: field %d changed from %s %s to %s %s",
+				reader.FullName(), rf.Number(), wf.Cardinality(), wf.Kind(), rf.Cardinality(), rf.Kind()))
+			continue
+		}
+		if rf.Cardinality() == protoreflect.Required && wf.Cardinality() != protoreflect.Required {
+			problems = append(problems, fmt.Sprintf("%s: field %d became required", reader.FullName(), rf.Number()))
+		}
+		if rf.IsMap() != wf.IsMap() {
+			problems = append(problems, fmt.Sprintf("%s: field %d changed between map and list", reader.FullName(), rf.Number()))
+			continue
+		}
+		if rf.Message() != nil && wf.Message() != nil {
+			problems = append(problems, messageReadProblems(rf.Message(), wf.Message(), seen)...)
+		}
+	}
+	return problems
+}
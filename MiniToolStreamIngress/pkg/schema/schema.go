@@ -0,0 +1,124 @@
+// Package schema compiles payload schemas, validates payloads and checks
+// compatibility between schema versions
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format identifies how a schema definition is written
+type Format string
+
+const (
+	// JSON is a JSON Schema document
+	JSON Format = "json"
+	// Protobuf is a serialized FileDescriptorSet plus the name of the message type
+	Protobuf Format = "protobuf"
+)
+
+// Compatibility defines which schema changes a subject accepts
+type Compatibility string
+
+const (
+	// CompatibilityNone accepts any new version
+	CompatibilityNone Compatibility = "none"
+	// CompatibilityBackward requires the new version to read data written with the previous one
+	CompatibilityBackward Compatibility = "backward"
+	// CompatibilityForward requires the previous version to read data written with the new one
+	CompatibilityForward Compatibility = "forward"
+	// CompatibilityFull requires both backward and forward compatibility
+	CompatibilityFull Compatibility = "full"
+)
+
+var (
+	// ErrInvalidPayload is returned when a payload does not match its schema
+	ErrInvalidPayload = errors.New("payload does not match schema")
+
+	// ErrIncompatible is returned when a new schema version breaks the compatibility mode
+	ErrIncompatible = errors.New("incompatible schema")
+)
+
+// Schema is a compiled schema definition
+type Schema interface {
+	// Format returns the definition format
+	Format() Format
+	// Validate checks a payload, errors wrap ErrInvalidPayload
+	Validate(payload []byte) error
+}
+
+// ParseFormat parses a schema format name
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case JSON:
+		return JSON, nil
+	case Protobuf:
+		return Protobuf, nil
+	default:
+		return "", fmt.Errorf("unsupported schema format: %q", name)
+	}
+}
+
+// ParseCompatibility parses a compatibility mode, "" means backward
+func ParseCompatibility(name string) (Compatibility, error) {
+	switch Compatibility(strings.ToLower(strings.TrimSpace(name))) {
+	case "", CompatibilityBackward:
+		return CompatibilityBackward, nil
+	case CompatibilityForward:
+		return CompatibilityForward, nil
+	case CompatibilityFull:
+		return CompatibilityFull, nil
+	case CompatibilityNone:
+		return CompatibilityNone, nil
+	default:
+		return "", fmt.Errorf("unsupported schema compatibility: %q", name)
+	}
+}
+
+// Compile parses a schema definition
+// messageType is the fully-qualified protobuf message name and is ignored for JSON
+func Compile(format Format, definition []byte, messageType string) (Schema, error) {
+	switch format {
+	case JSON:
+		return compileJSON(definition)
+	case Protobuf:
+		return compileProtobuf(definition, messageType)
+	default:
+		return nil, fmt.Errorf("unsupported schema format: %q", format)
+	}
+}
+
+// CheckCompatibility reports why next cannot follow prev under mode, nil if it can
+func CheckCompatibility(mode Compatibility, prev, next Schema) error {
+	if mode == CompatibilityNone {
+		return nil
+	}
+	if prev.Format() != next.Format() {
+		return fmt.Errorf("%w: format changed from %s to %s", ErrIncompatible, prev.Format(), next.Format())
+	}
+
+	var problems []string
+	if mode == CompatibilityBackward || mode == CompatibilityFull {
+		problems = append(problems, readProblems(next, prev)...)
+	}
+	if mode == CompatibilityForward || mode == CompatibilityFull {
+		problems = append(problems, readProblems(prev, next)...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w (%s): %s", ErrIncompatible, mode, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// readProblems lists why data written with writer may not be readable with reader
+func readProblems(reader, writer Schema) []string {
+	switch r := reader.(type) {
+	case *jsonSchema:
+		return r.readProblems(writer.(*jsonSchema), "$")
+	case *protobufSchema:
+		return r.readProblems(writer.(*protobufSchema))
+	default:
+		return []string{fmt.Sprintf("unsupported schema format: %q", reader.Format())}
+	}
+}
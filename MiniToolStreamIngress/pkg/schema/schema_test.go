@@ -0,0 +1,207 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const orderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Order",
+	"type": "object",
+	"required": ["id", "amount"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "minLength": 1},
+		"amount": {"type": "number", "minimum": 0},
+		"currency": {"type": "string", "enum": ["EUR", "USD"]},
+		"items": {"type": "array", "items": {"type": "integer"}}
+	}
+}`
+
+func mustCompile(t *testing.T, format Format, definition []byte, messageType string) Schema {
+	t.Helper()
+	s, err := Compile(format, definition, messageType)
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	return s
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	s := mustCompile(t, JSON, []byte(orderSchema), "")
+
+	valid := []string{
+		`{"id":"o-1","amount":10}`,
+		`{"id":"o-1","amount":10.5,"currency":"EUR","items":[1,2,3]}`,
+	}
+	for _, payload := range valid {
+		if err := s.Validate([]byte(payload)); err != nil {
+			t.Errorf("Validate(%s) = %v, want nil", payload, err)
+		}
+	}
+
+	invalid := []string{
+		`{"id":"o-1"`,
+		`{"id":"o-1","amount":10} {}`,
+		`{"amount":10}`,
+		`{"id":"","amount":10}`,
+		`{"id":"o-1","amount":-1}`,
+		`{"id":"o-1","amount":"10"}`,
+		`{"id":"o-1","amount":10,"currency":"GBP"}`,
+		`{"id":"o-1","amount":10,"items":[1.5]}`,
+		`{"id":"o-1","amount":10,"note":"x"}`,
+	}
+	for _, payload := range invalid {
+		if err := s.Validate([]byte(payload)); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("Validate(%s) = %v, want ErrInvalidPayload", payload, err)
+		}
+	}
+}
+
+func TestJSONSchema_RejectsUnsupportedKeywords(t *testing.T) {
+	for _, definition := range []string{
+		`{"$ref": "#/definitions/order"}`,
+		`{"oneOf": [{"type": "string"}]}`,
+		`{"additionalProperties": {"type": "string"}}`,
+		`{"type": "decimal"}`,
+		`[]`,
+	} {
+		if _, err := Compile(JSON, []byte(definition), ""); err == nil {
+			t.Errorf("Compile(%s) = nil error, want error", definition)
+		}
+	}
+}
+
+func TestCheckCompatibility_JSON(t *testing.T) {
+	v1 := mustCompile(t, JSON, []byte(`{"type":"object","required":["id"],"properties":{"id":{"type":"string"}}}`), "")
+	// Adds an optional property: readable both ways
+	v2 := mustCompile(t, JSON, []byte(`{"type":"object","required":["id"],"properties":{"id":{"type":"string"},"note":{"type":"string"}}}`), "")
+	// Adds a required property: new readers reject old data
+	v3 := mustCompile(t, JSON, []byte(`{"type":"object","required":["id","amount"],"properties":{"id":{"type":"string"},"amount":{"type":"number"}}}`), "")
+	// Changes the type of id
+	v4 := mustCompile(t, JSON, []byte(`{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`), "")
+
+	tests := []struct {
+		name       string
+		mode       Compatibility
+		prev, next Schema
+		wantErr    bool
+	}{
+		{"optional property full", CompatibilityFull, v1, v2, false},
+		{"new required property backward", CompatibilityBackward, v1, v3, true},
+		{"new required property forward", CompatibilityForward, v1, v3, false},
+		{"dropped required property backward", CompatibilityBackward, v3, v1, false},
+		{"dropped required property forward", CompatibilityForward, v3, v1, true},
+		{"type change backward", CompatibilityBackward, v1, v4, true},
+		{"type change without checks", CompatibilityNone, v1, v4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatibility(tt.mode, tt.prev, tt.next)
+			if tt.wantErr && !errors.Is(err, ErrIncompatible) {
+				t.Errorf("expected ErrIncompatible, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// orderDescriptorSet builds a FileDescriptorSet for message shop.Order with the given fields
+func orderDescriptorSet(t *testing.T, fields ...*descriptorpb.FieldDescriptorProto) []byte {
+	t.Helper()
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("shop/order.proto"),
+		Package: proto.String("shop"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  proto.String("Order"),
+			Field: fields,
+		}},
+	}}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal descriptor set: %v", err)
+	}
+	return data
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+}
+
+func TestProtobufSchema(t *testing.T) {
+	v1 := mustCompile(t, Protobuf, orderDescriptorSet(t,
+		field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+	), "shop.Order")
+
+	var payload []byte
+	payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+	payload = protowire.AppendString(payload, "o-1")
+	payload = protowire.AppendTag(payload, 2, protowire.VarintType)
+	payload = protowire.AppendVarint(payload, 1000)
+	if err := v1.Validate(payload); err != nil {
+		t.Errorf("unexpected error for valid message: %v", err)
+	}
+
+	// Field 1 is a proto3 string, so it must be valid UTF-8
+	var wrong []byte
+	wrong = protowire.AppendTag(wrong, 1, protowire.BytesType)
+	wrong = protowire.AppendBytes(wrong, []byte{0xff, 0xfe})
+	if err := v1.Validate(wrong); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for invalid string, got %v", err)
+	}
+	if err := v1.Validate([]byte{0xff, 0xff}); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for garbage, got %v", err)
+	}
+
+	v2 := mustCompile(t, Protobuf, orderDescriptorSet(t,
+		field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+		field("note", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+	), "shop.Order")
+	if err := CheckCompatibility(CompatibilityFull, v1, v2); err != nil {
+		t.Errorf("expected a new field to be compatible, got %v", err)
+	}
+
+	v3 := mustCompile(t, Protobuf, orderDescriptorSet(t,
+		field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+	), "shop.Order")
+	if err := CheckCompatibility(CompatibilityBackward, v1, v3); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("expected a field type change to be incompatible, got %v", err)
+	}
+
+	if _, err := Compile(Protobuf, orderDescriptorSet(t), "shop.Missing"); err == nil {
+		t.Error("expected error for unknown message type")
+	}
+	if err := CheckCompatibility(CompatibilityBackward, v1, mustCompile(t, JSON, []byte(`{}`), "")); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("expected a format change to be incompatible, got %v", err)
+	}
+}
+
+func TestParseCompatibility(t *testing.T) {
+	tests := map[string]Compatibility{"": CompatibilityBackward, "FULL": CompatibilityFull, "none": CompatibilityNone}
+	for name, want := range tests {
+		got, err := ParseCompatibility(name)
+		if err != nil || got != want {
+			t.Errorf("ParseCompatibility(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseCompatibility("transitive"); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
@@ -0,0 +1,194 @@
+// Package authz enforces per-method authorization policies ahead of gRPC handlers
+//
+// A Policy names the permission each RPC requires from the client's token and
+// whether the subject and the durable consumer of the request are checked.
+// Methods missing from the policy are denied, so every RPC a server registers
+// needs a deliberate rule.
+package authz
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// PermissionAdmin allows the administrative RPCs and acting on consumers owned by other clients
+const PermissionAdmin = "admin"
+
+// ErrNotConsumerOwner is returned by ConsumerOwners for consumers of other clients
+var ErrNotConsumerOwner = errors.New("durable consumer is owned by another client")
+
+// Rule is the authorization an RPC requires from an authenticated client
+type Rule struct {
+	// Public allows every client, the method is also exempt from authentication
+	Public bool
+	// Permission must be granted by the client's token, empty allows every authenticated client
+	Permission string
+	// Subject requires the subject of the request to be allowed by the token
+	Subject bool
+	// Durable requires the client to own the durable consumer of the request
+	Durable bool
+}
+
+// Policy maps RPC method names to their rules
+type Policy map[string]Rule
+
+// Missing returns the full names of the methods of services that have no rule
+func (p Policy) Missing(services map[string]grpc.ServiceInfo) []string {
+	var missing []string
+	for name, service := range services {
+		for _, method := range service.Methods {
+			if _, ok := p[method.Name]; !ok {
+				missing = append(missing, "/"+name+"/"+method.Name)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// ConsumerOwners checks who may use a durable consumer
+type ConsumerOwners interface {
+	// CheckConsumerOwner returns ErrNotConsumerOwner if clientID may not use the consumer
+	// Subject and durable name are those of the request
+	CheckConsumerOwner(ctx context.Context, durableName, subject, clientID string) error
+}
+
+// subjectRequest and durableRequest are implemented by requests naming a subject or consumer
+type subjectRequest interface {
+	GetSubject() string
+}
+
+type durableRequest interface {
+	GetDurableName() string
+}
+
+// Authorizer enforces a Policy ahead of the handlers
+// Unauthenticated requests, allowed when auth.require_auth is off, pass unchecked.
+// Methods missing from the policy are denied to every client
+type Authorizer struct {
+	policy Policy
+	owners ConsumerOwners
+	logger *logger.Logger
+}
+
+// NewAuthorizer creates an authorizer for policy
+func NewAuthorizer(policy Policy, log *logger.Logger) *Authorizer {
+	return &Authorizer{policy: policy, logger: log}
+}
+
+// SetConsumerOwners enables durable ownership checks, nil disables them
+func (a *Authorizer) SetConsumerOwners(owners ConsumerOwners) {
+	a.owners = owners
+}
+
+// UnaryInterceptor returns the interceptor for unary RPCs
+// It must run after authentication so the claims are known
+func (a *Authorizer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.authorize(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns the interceptor for server-streaming RPCs
+// The request is checked when the handler receives it
+func (a *Authorizer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &authzStream{ServerStream: stream, authz: a, method: info.FullMethod})
+	}
+}
+
+// authorize checks req of fullMethod against the policy
+func (a *Authorizer) authorize(ctx context.Context, fullMethod string, req interface{}) error {
+	method := path.Base(fullMethod)
+	rule, ok := a.policy[method]
+	if !ok {
+		a.logger.Error("Request denied: method has no authorization rule", logger.String("method", fullMethod))
+		return status.Errorf(codes.PermissionDenied, "%s permission denied: no authorization rule", method)
+	}
+	if rule.Public {
+		return nil
+	}
+	claims, ok := auth.GetClaimsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var subj, durable string
+	if r, ok := req.(subjectRequest); ok {
+		subj = r.GetSubject()
+	}
+	if r, ok := req.(durableRequest); ok {
+		durable = r.GetDurableName()
+	}
+
+	if rule.Permission != "" && !claims.CheckPermission(rule.Permission) {
+		return a.deny(method, claims.ClientID, subj, "missing "+rule.Permission+" permission")
+	}
+
+	if rule.Subject {
+		// Subjects outside the grammar could be mistaken for claim patterns,
+		// they are left to the handler to reject
+		if subject.Validate(subj) != nil {
+			return nil
+		}
+		if !claims.CheckSubjectAccess(subj) {
+			return a.deny(method, claims.ClientID, subj, "subject not allowed")
+		}
+	}
+
+	if rule.Durable && a.owners != nil && durable != "" && !claims.CheckPermission(PermissionAdmin) {
+		err := a.owners.CheckConsumerOwner(ctx, durable, subj, claims.ClientID)
+		if errors.Is(err, ErrNotConsumerOwner) {
+			// The owner is not revealed to other clients
+			return a.deny(method, claims.ClientID, subj, ErrNotConsumerOwner.Error())
+		}
+		if err != nil {
+			a.logger.Error("Failed to check consumer owner",
+				logger.String("method", method),
+				logger.String("durable_name", durable),
+				logger.Error(err),
+			)
+			return status.Error(codes.Unavailable, "failed to check consumer owner")
+		}
+	}
+	return nil
+}
+
+// deny logs and returns a PERMISSION_DENIED status
+func (a *Authorizer) deny(method, clientID, subj, reason string) error {
+	a.logger.Warn("Request denied by authorization policy",
+		logger.String("method", method),
+		logger.String("client_id", clientID),
+		logger.String("subject", subj),
+		logger.String("reason", reason),
+	)
+	return status.Errorf(codes.PermissionDenied, "%s permission denied: %s", method, reason)
+}
+
+// authzStream authorizes the request of a server-streaming RPC
+type authzStream struct {
+	grpc.ServerStream
+	authz  *Authorizer
+	method string
+}
+
+// RecvMsg checks the request before the handler sees it
+func (s *authzStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.authz.authorize(s.Context(), s.method, m)
+}
@@ -0,0 +1,187 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStream/pkg/logger"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+type testRequest struct {
+	subject     string
+	durableName string
+}
+
+func (r *testRequest) GetSubject() string     { return r.subject }
+func (r *testRequest) GetDurableName() string { return r.durableName }
+
+type mockConsumerOwners struct {
+	checkFunc func(ctx context.Context, durableName, subject, clientID string) error
+}
+
+func (m *mockConsumerOwners) CheckConsumerOwner(ctx context.Context, durableName, subject, clientID string) error {
+	if m.checkFunc != nil {
+		return m.checkFunc(ctx, durableName, subject, clientID)
+	}
+	return nil
+}
+
+// mockServerStream receives req once, as an authenticated client when claims are set
+type mockServerStream struct {
+	req    *testRequest
+	claims *auth.Claims
+}
+
+func (m *mockServerStream) Context() context.Context {
+	if m.claims == nil {
+		return context.Background()
+	}
+	return context.WithValue(context.Background(), auth.ClaimsContextKey{}, m.claims)
+}
+func (m *mockServerStream) SetHeader(md metadata.MD) error  { return nil }
+func (m *mockServerStream) SendHeader(md metadata.MD) error { return nil }
+func (m *mockServerStream) SetTrailer(md metadata.MD)       {}
+func (m *mockServerStream) SendMsg(msg interface{}) error   { return nil }
+func (m *mockServerStream) RecvMsg(msg interface{}) error {
+	*msg.(*testRequest) = *m.req
+	return nil
+}
+
+var testPolicy = Policy{
+	"Publish":  {Permission: "publish", Subject: true},
+	"Fetch":    {Permission: "fetch", Subject: true, Durable: true},
+	"Config":   {Permission: PermissionAdmin},
+	"Login":    {Public: true},
+	"Describe": {},
+}
+
+func TestAuthorizer_UnaryInterceptor(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	interceptor := NewAuthorizer(testPolicy, log).UnaryInterceptor()
+
+	loader := &auth.Claims{ClientID: "loader", Permissions: []string{"publish"}, AllowedSubjects: []string{"orders.*"}}
+	reader := &auth.Claims{ClientID: "reader", Permissions: []string{"fetch"}, AllowedSubjects: []string{"orders.*"}}
+
+	tests := []struct {
+		name     string
+		claims   *auth.Claims
+		method   string
+		subject  string
+		wantCode codes.Code
+	}{
+		{"unauthenticated", nil, "/IngressService/Publish", "payments", codes.OK},
+		{"allowed", loader, "/IngressService/Publish", "orders.42", codes.OK},
+		{"missing permission", reader, "/IngressService/Publish", "orders.42", codes.PermissionDenied},
+		{"subject not allowed", loader, "/IngressService/Publish", "payments.1", codes.PermissionDenied},
+		{"invalid subject left to the handler", loader, "/IngressService/Publish", "orders..42", codes.OK},
+		{"admin required", loader, "/ConfigService/Config", "", codes.PermissionDenied},
+		{"outside the policy", nil, "/grpc.health.v1.Health/Check", "", codes.PermissionDenied},
+		{"outside the policy authenticated", loader, "/grpc.health.v1.Health/Check", "", codes.PermissionDenied},
+		{"public", nil, "/TokenService/Login", "", codes.OK},
+		{"public authenticated", reader, "/TokenService/Login", "", codes.OK},
+		{"any authenticated client", reader, "/Reflection/Describe", "", codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = context.WithValue(ctx, auth.ClaimsContextKey{}, tt.claims)
+			}
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return nil, nil
+			}
+			_, err := interceptor(ctx, &testRequest{subject: tt.subject}, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected %v, got %v", tt.wantCode, err)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_StreamInterceptor(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "json", OutputPath: "stdout"})
+	authz := NewAuthorizer(testPolicy, log)
+
+	var checked []string
+	authz.SetConsumerOwners(&mockConsumerOwners{
+		checkFunc: func(ctx context.Context, durableName, subject, clientID string) error {
+			checked = append(checked, durableName+" "+subject+" "+clientID)
+			switch clientID {
+			case "billing-service":
+				return nil
+			case "flaky":
+				return errors.New("connection refused")
+			}
+			return ErrNotConsumerOwner
+		},
+	})
+	interceptor := authz.StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/EgressService/Fetch", IsServerStream: true}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&testRequest{})
+	}
+	req := &testRequest{subject: "orders.42", durableName: "billing"}
+	fetch := func(claims *auth.Claims) error {
+		return interceptor(nil, &mockServerStream{req: req, claims: claims}, info, handler)
+	}
+
+	if err := fetch(&auth.Claims{ClientID: "billing-service", Permissions: []string{"fetch"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checked) != 1 || checked[0] != "billing orders.42 billing-service" {
+		t.Errorf("unexpected ownership checks: %v", checked)
+	}
+
+	if err := fetch(&auth.Claims{ClientID: "reporting", Permissions: []string{"fetch"}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for another client's consumer, got %v", err)
+	}
+
+	if err := fetch(&auth.Claims{ClientID: "flaky", Permissions: []string{"fetch"}}); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable when the owner cannot be checked, got %v", err)
+	}
+
+	checked = nil
+	if err := fetch(&auth.Claims{ClientID: "operator", Permissions: []string{"fetch", PermissionAdmin}}); err != nil {
+		t.Errorf("expected admin to use any consumer, got %v", err)
+	}
+	if len(checked) != 0 {
+		t.Errorf("expected no ownership check for admin, got %v", checked)
+	}
+
+	if err := fetch(&auth.Claims{ClientID: "billing-service", Permissions: []string{"subscribe"}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without fetch permission, got %v", err)
+	}
+
+	if err := fetch(nil); err != nil {
+		t.Errorf("expected unauthenticated requests to pass, got %v", err)
+	}
+}
+
+func TestPolicy_Missing(t *testing.T) {
+	services := map[string]grpc.ServiceInfo{
+		"IngressService": {Methods: []grpc.MethodInfo{{Name: "Publish"}, {Name: "PublishBatch"}}},
+		"EgressService":  {Methods: []grpc.MethodInfo{{Name: "Fetch", IsServerStream: true}, {Name: "Seek"}}},
+	}
+
+	missing := testPolicy.Missing(services)
+	if len(missing) != 2 || missing[0] != "/EgressService/Seek" || missing[1] != "/IngressService/PublishBatch" {
+		t.Errorf("unexpected methods without a rule: %v", missing)
+	}
+	if missing := testPolicy.Missing(map[string]grpc.ServiceInfo{"ConfigService": {Methods: []grpc.MethodInfo{{Name: "Config"}}}}); len(missing) != 0 {
+		t.Errorf("expected every method to have a rule, got %v", missing)
+	}
+}
@@ -0,0 +1,121 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithm identifies a payload encoding as written to the content-encoding header
+type Algorithm string
+
+const (
+	// None stores payloads as they are
+	None Algorithm = ""
+	// Gzip compresses payloads with gzip
+	Gzip Algorithm = "gzip"
+	// Zstd compresses payloads with Zstandard
+	Zstd Algorithm = "zstd"
+)
+
+// Headers describing an encoded payload
+const (
+	// HeaderContentEncoding names the algorithm the stored payload is encoded with
+	HeaderContentEncoding = "content-encoding"
+	// HeaderOriginalSize is the payload size before encoding
+	HeaderOriginalSize = "original-size"
+)
+
+// Encoder and decoder are safe for concurrent EncodeAll/DecodeAll calls
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ParseAlgorithm parses an algorithm name, "" and "none" mean no compression
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none", "identity":
+		return None, nil
+	case "gzip":
+		return Gzip, nil
+	case "zstd":
+		return Zstd, nil
+	default:
+		return None, fmt.Errorf("unsupported compression algorithm: %q", name)
+	}
+}
+
+// Compress encodes data with the given algorithm
+func Compress(alg Algorithm, data []byte) ([]byte, error) {
+	switch alg {
+	case None:
+		return data, nil
+	case Zstd:
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	case Gzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %q", alg)
+	}
+}
+
+// Decompress decodes data written by Compress
+func Decompress(alg Algorithm, data []byte) ([]byte, error) {
+	switch alg {
+	case None:
+		return data, nil
+	case Zstd:
+		out, err := zstdDecoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode zstd payload: %w", err)
+		}
+		return out, nil
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gzip payload: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gzip payload: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %q", alg)
+	}
+}
+
+// Policy decides which algorithm to use for a payload
+type Policy struct {
+	// Default is used for subjects without an override
+	Default Algorithm
+	// MinSize is the smallest payload worth compressing, in bytes
+	MinSize int
+	// Subjects overrides the algorithm per subject, None disables compression
+	Subjects map[string]Algorithm
+}
+
+// Choose returns the algorithm for a payload of given size published to subject
+func (p *Policy) Choose(subject string, size int) Algorithm {
+	if p == nil || size == 0 || size < p.MinSize {
+		return None
+	}
+	if alg, ok := p.Subjects[subject]; ok {
+		return alg
+	}
+	return p.Default
+}
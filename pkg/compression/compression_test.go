@@ -0,0 +1,79 @@
+package compression
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompress_RoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte(`{"level":"info","msg":"request served"}`), 100)
+
+	for _, alg := range []Algorithm{None, Gzip, Zstd} {
+		t.Run(string(alg), func(t *testing.T) {
+			compressed, err := Compress(alg, payload)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if alg != None && len(compressed) >= len(payload) {
+				t.Errorf("expected %s to shrink repetitive payload, got %d of %d bytes", alg, len(compressed), len(payload))
+			}
+
+			decompressed, err := Decompress(alg, compressed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(decompressed, payload) {
+				t.Error("round trip changed the payload")
+			}
+		})
+	}
+}
+
+func TestDecompress_Corrupted(t *testing.T) {
+	if _, err := Decompress(Zstd, []byte("not zstd")); err == nil {
+		t.Error("expected error for corrupted zstd payload")
+	}
+	if _, err := Decompress(Gzip, []byte("not gzip")); err == nil {
+		t.Error("expected error for corrupted gzip payload")
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := map[string]Algorithm{"": None, "none": None, "GZIP": Gzip, "zstd": Zstd}
+	for name, want := range tests {
+		got, err := ParseAlgorithm(name)
+		if err != nil || got != want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseAlgorithm("brotli"); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
+
+func TestPolicy_Choose(t *testing.T) {
+	policy := &Policy{
+		Default:  Zstd,
+		MinSize:  1024,
+		Subjects: map[string]Algorithm{"logs.raw": Gzip, "images": None},
+	}
+
+	if alg := policy.Choose("events", 512); alg != None {
+		t.Errorf("expected small payload to stay uncompressed, got %q", alg)
+	}
+	if alg := policy.Choose("events", 4096); alg != Zstd {
+		t.Errorf("expected default algorithm, got %q", alg)
+	}
+	if alg := policy.Choose("logs.raw", 4096); alg != Gzip {
+		t.Errorf("expected subject override, got %q", alg)
+	}
+	if alg := policy.Choose("images", 4096); alg != None {
+		t.Errorf("expected compression disabled for subject, got %q", alg)
+	}
+
+	var disabled *Policy
+	if alg := disabled.Choose("events", 4096); alg != None {
+		t.Errorf("expected nil policy to disable compression, got %q", alg)
+	}
+}
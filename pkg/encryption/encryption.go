@@ -0,0 +1,162 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// AlgorithmAES256GCM is the only payload cipher written to the encryption header
+const AlgorithmAES256GCM = "aes-256-gcm"
+
+// Headers describing an encrypted payload
+const (
+	// HeaderEncryption names the cipher the stored payload is sealed with
+	HeaderEncryption = "encryption"
+	// HeaderKeyName is the key-encryption key that wraps the data key
+	HeaderKeyName = "encryption-key"
+	// HeaderDataKey is the wrapped per-message data key
+	HeaderDataKey = "encryption-data-key"
+	// HeaderContext is the additional authenticated data the payload is sealed with
+	HeaderContext = "encryption-context"
+)
+
+const (
+	dataKeySize = 32
+	nonceSize   = 12
+	tagSize     = 16
+)
+
+// Overhead is the number of bytes Seal adds to a payload
+const Overhead = nonceSize + tagSize
+
+// KeyManager generates and unwraps data keys with a named key-encryption key
+type KeyManager interface {
+	GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error)
+	DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error)
+}
+
+// Envelope seals payloads with per-message data keys wrapped by a KeyManager
+type Envelope struct {
+	keys       KeyManager
+	defaultKey string
+	// subjectKeys maps a subject or "prefix.*" pattern to a key name
+	subjectKeys map[string]string
+}
+
+// NewEnvelope creates an envelope; subjectKeys entries may be exact subjects or "prefix.*" patterns
+func NewEnvelope(keys KeyManager, defaultKey string, subjectKeys map[string]string) *Envelope {
+	return &Envelope{
+		keys:        keys,
+		defaultKey:  defaultKey,
+		subjectKeys: subjectKeys,
+	}
+}
+
+// KeyFor returns the key-encryption key for subject, preferring exact matches and then the longest pattern
+func (e *Envelope) KeyFor(subject string) string {
+	if key, ok := e.subjectKeys[subject]; ok {
+		return key
+	}
+
+	best, bestLen := e.defaultKey, -1
+	for pattern, key := range e.subjectKeys {
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if !ok || !strings.HasPrefix(subject, prefix) {
+			continue
+		}
+		if len(prefix) > bestLen {
+			best, bestLen = key, len(prefix)
+		}
+	}
+	return best
+}
+
+// Seal encrypts plaintext for subject and returns the ciphertext with the headers needed to open it
+// aad binds the ciphertext to its context and is recorded in the headers, callers pass a stable message id
+func (e *Envelope) Seal(ctx context.Context, subject string, aad, plaintext []byte) ([]byte, map[string]string, error) {
+	keyName := e.KeyFor(subject)
+	if keyName == "" {
+		return nil, nil, fmt.Errorf("no encryption key configured for subject %s", subject)
+	}
+
+	dataKey, wrapped, err := e.keys.GenerateDataKey(ctx, keyName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer clear(dataKey)
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]byte, nonceSize, nonceSize+len(plaintext)+tagSize)
+	if _, err := rand.Read(out); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out = gcm.Seal(out, out[:nonceSize], plaintext, aad)
+
+	headers := map[string]string{
+		HeaderEncryption: AlgorithmAES256GCM,
+		HeaderKeyName:    keyName,
+		HeaderDataKey:    wrapped,
+		HeaderContext:    string(aad),
+	}
+	return out, headers, nil
+}
+
+// Open decrypts a payload sealed by Seal using the key named in its headers
+func (e *Envelope) Open(ctx context.Context, headers map[string]string, aad, ciphertext []byte) ([]byte, error) {
+	if alg := headers[HeaderEncryption]; alg != AlgorithmAES256GCM {
+		return nil, fmt.Errorf("unsupported encryption algorithm: %q", alg)
+	}
+	if len(ciphertext) < Overhead {
+		return nil, fmt.Errorf("encrypted payload is too short")
+	}
+
+	dataKey, err := e.keys.DecryptDataKey(ctx, headers[HeaderKeyName], headers[HeaderDataKey])
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Context returns the additional authenticated data recorded by Seal
+// Payloads sealed before the context was recorded are bound to their object name
+func Context(headers map[string]string, objectName string) []byte {
+	if aad, ok := headers[HeaderContext]; ok {
+		return []byte(aad)
+	}
+	return []byte(objectName)
+}
+
+// IsEncrypted reports whether headers describe an encrypted payload
+func IsEncrypted(headers map[string]string) bool {
+	return headers[HeaderEncryption] != ""
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("invalid data key size: %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,139 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+// fakeKeyManager mimics Vault transit: wrapped keys carry the key version and
+// remain decryptable after the key is rotated
+type fakeKeyManager struct {
+	versions map[string]int
+	wrapped  map[string][]byte
+}
+
+func newFakeKeyManager() *fakeKeyManager {
+	return &fakeKeyManager{versions: map[string]int{}, wrapped: map[string][]byte{}}
+}
+
+func (f *fakeKeyManager) rotate(keyName string) {
+	f.versions[keyName]++
+}
+
+func (f *fakeKeyManager) GenerateDataKey(ctx context.Context, keyName string) ([]byte, string, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", err
+	}
+	wrapped := fmt.Sprintf("vault:v%d:%s:%d", f.versions[keyName]+1, keyName, len(f.wrapped))
+	f.wrapped[wrapped] = bytes.Clone(key)
+	return key, wrapped, nil
+}
+
+func (f *fakeKeyManager) DecryptDataKey(ctx context.Context, keyName, wrapped string) ([]byte, error) {
+	key, ok := f.wrapped[wrapped]
+	if !ok {
+		return nil, fmt.Errorf("unknown data key for %s", keyName)
+	}
+	return bytes.Clone(key), nil
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	env := NewEnvelope(newFakeKeyManager(), "default", nil)
+	payload := []byte("card=4111111111111111")
+
+	sealed, headers, err := env.Seal(ctx, "payments", []byte("payments_1"), payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sealed) != len(payload)+Overhead {
+		t.Errorf("expected %d sealed bytes, got %d", len(payload)+Overhead, len(sealed))
+	}
+	if bytes.Contains(sealed, payload) {
+		t.Error("sealed payload contains plaintext")
+	}
+	if !IsEncrypted(headers) || headers[HeaderKeyName] != "default" || headers[HeaderDataKey] == "" || headers[HeaderContext] != "payments_1" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+
+	opened, err := env.Open(ctx, headers, []byte("payments_1"), sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(opened, payload) {
+		t.Error("round trip changed the payload")
+	}
+
+	if _, err := env.Open(ctx, headers, []byte("payments_2"), sealed); err == nil {
+		t.Error("expected error when payload is moved to another object")
+	}
+}
+
+func TestEnvelope_OpenAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	keys := newFakeKeyManager()
+	env := NewEnvelope(keys, "default", nil)
+
+	sealed, headers, err := env.Seal(ctx, "events", []byte("events_1"), []byte("before rotation"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keys.rotate("default")
+	_, rotated, err := env.Seal(ctx, "events", []byte("events_2"), []byte("after rotation"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated[HeaderDataKey][:8] != "vault:v2" {
+		t.Errorf("expected new messages to use the rotated key, got %s", rotated[HeaderDataKey])
+	}
+
+	opened, err := env.Open(ctx, headers, []byte("events_1"), sealed)
+	if err != nil {
+		t.Fatalf("expected old message to open after rotation: %v", err)
+	}
+	if string(opened) != "before rotation" {
+		t.Errorf("unexpected payload: %q", opened)
+	}
+}
+
+func TestEnvelope_KeyFor(t *testing.T) {
+	env := NewEnvelope(nil, "default", map[string]string{
+		"acme.*":         "tenant-acme",
+		"acme.billing.*": "acme-billing",
+		"payments":       "payments",
+	})
+
+	tests := map[string]string{
+		"payments":            "payments",
+		"acme.orders":         "tenant-acme",
+		"acme.billing.events": "acme-billing",
+		"events":              "default",
+	}
+	for subject, want := range tests {
+		if got := env.KeyFor(subject); got != want {
+			t.Errorf("KeyFor(%q) = %q, want %q", subject, got, want)
+		}
+	}
+}
+
+func TestEnvelope_OpenRejectsUnknownAlgorithm(t *testing.T) {
+	env := NewEnvelope(newFakeKeyManager(), "default", nil)
+	_, err := env.Open(context.Background(), map[string]string{HeaderEncryption: "rot13"}, nil, make([]byte, 64))
+	if err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := Context(map[string]string{HeaderContext: "payments_7"}, ""); string(got) != "payments_7" {
+		t.Errorf("expected recorded context, got %q", got)
+	}
+	if got := Context(map[string]string{HeaderEncryption: AlgorithmAES256GCM}, "payments_3"); string(got) != "payments_3" {
+		t.Errorf("expected object name for payloads without a recorded context, got %q", got)
+	}
+}
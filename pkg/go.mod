@@ -0,0 +1,22 @@
+module github.com/moroshma/MiniToolStream/pkg
+
+go 1.24.0
+
+require (
+	github.com/go-jose/go-jose/v4 v4.1.3
+	github.com/golang-jwt/jwt/v5 v5.2.1
+	github.com/klauspost/compress v1.17.11
+	github.com/moroshma/MiniToolStreamConnector/auth v0.2.0
+	go.uber.org/zap v1.27.0
+	google.golang.org/grpc v1.77.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251111163417-95abcf5c77ba // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+)
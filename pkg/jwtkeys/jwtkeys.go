@@ -0,0 +1,245 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// Fields of the key set secret
+// private_key and public_key hold the active pair, as written by jwt-gen -generate-keys
+const (
+	FieldPrivateKey = "private_key"
+	FieldPublicKey  = "public_key"
+	// FieldKeyID is the kid of the active pair, absent before the first rotation
+	FieldKeyID = "kid"
+	// FieldKeys maps every kid to {public_key, created_at, retired_at}
+	FieldKeys = "keys"
+
+	FieldCreatedAt = "created_at"
+	FieldRetiredAt = "retired_at"
+)
+
+// ErrNoKeys is returned when the secret holds no public key
+var ErrNoKeys = errors.New("no JWT keys found")
+
+// ErrNoSigningKey is returned by Sign when the secret holds no private key
+var ErrNoSigningKey = errors.New("no JWT signing key found")
+
+// Source reads the key set secret
+type Source interface {
+	ReadKeySet(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// Key is a public key tokens may be signed with
+type Key struct {
+	ID        string
+	PublicKey *rsa.PublicKey
+	CreatedAt time.Time
+	// RetiredAt is zero while tokens signed with the key are accepted
+	RetiredAt time.Time
+}
+
+// Retired reports whether tokens signed with the key are rejected
+func (k *Key) Retired() bool {
+	return !k.RetiredAt.IsZero()
+}
+
+// KeySet is the set of keys loaded from the secret
+type KeySet struct {
+	ActiveID string
+	Keys     map[string]*Key
+	// SigningKey is the private half of the active pair, nil unless the secret holds a matching one
+	SigningKey *rsa.PrivateKey
+}
+
+// KeyID derives the kid of a key from its public half
+// Secrets written before the first rotation carry no kid, jwt-gen derives the same one
+func KeyID(pub *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// ParseKeySet parses the data of the key set secret
+func ParseKeySet(data map[string]interface{}) (*KeySet, error) {
+	set := &KeySet{Keys: make(map[string]*Key)}
+
+	if entries, ok := data[FieldKeys].(map[string]interface{}); ok {
+		for id, raw := range entries {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %s: invalid entry", id)
+			}
+			key, err := parseKey(id, entry)
+			if err != nil {
+				return nil, err
+			}
+			set.Keys[id] = key
+		}
+	}
+
+	// The active pair is stored outside the key map as well, and alone
+	// before the first rotation
+	if pem, _ := data[FieldPublicKey].(string); pem != "" {
+		pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pem))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		id, _ := data[FieldKeyID].(string)
+		if id == "" {
+			if id, err = KeyID(pub); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := set.Keys[id]; !ok {
+			set.Keys[id] = &Key{ID: id, PublicKey: pub}
+		}
+		set.ActiveID = id
+	}
+
+	// Verification does not need the private key, a broken one only disables Sign
+	if pem, _ := data[FieldPrivateKey].(string); pem != "" && set.ActiveID != "" {
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(pem))
+		if err == nil && priv.PublicKey.Equal(set.Keys[set.ActiveID].PublicKey) {
+			set.SigningKey = priv
+		}
+	}
+
+	if len(set.Keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if active, ok := set.Keys[set.ActiveID]; ok && active.Retired() {
+		return nil, fmt.Errorf("active key %s is retired", set.ActiveID)
+	}
+	return set, nil
+}
+
+func parseKey(id string, entry map[string]interface{}) (*Key, error) {
+	pem, _ := entry[FieldPublicKey].(string)
+	pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pem))
+	if err != nil {
+		return nil, fmt.Errorf("key %s: failed to parse public key: %w", id, err)
+	}
+	key := &Key{ID: id, PublicKey: pub}
+
+	if s, _ := entry[FieldCreatedAt].(string); s != "" {
+		if key.CreatedAt, err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, fmt.Errorf("key %s: invalid %s: %w", id, FieldCreatedAt, err)
+		}
+	}
+	if s, _ := entry[FieldRetiredAt].(string); s != "" {
+		if key.RetiredAt, err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, fmt.Errorf("key %s: invalid %s: %w", id, FieldRetiredAt, err)
+		}
+	}
+	return key, nil
+}
+
+// Verifier validates tokens against a key set that can be reloaded while serving
+type Verifier struct {
+	source Source
+	path   string
+	issuer string
+	keys   atomic.Pointer[KeySet]
+}
+
+// NewVerifier creates a verifier for the key set at path, Reload must succeed before use
+func NewVerifier(source Source, path, issuer string) *Verifier {
+	return &Verifier{source: source, path: path, issuer: issuer}
+}
+
+// Reload reads the key set again, the previous set stays in use on error
+func (v *Verifier) Reload(ctx context.Context) error {
+	data, err := v.source.ReadKeySet(ctx, v.path)
+	if err != nil {
+		return err
+	}
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return err
+	}
+	v.keys.Store(set)
+	return nil
+}
+
+// Keys returns the key set in use
+func (v *Verifier) Keys() *KeySet {
+	return v.keys.Load()
+}
+
+// Sign issues a token for claims with the active key, setting the issuer
+func (v *Verifier) Sign(claims *auth.Claims) (string, error) {
+	set := v.keys.Load()
+	if set == nil || set.SigningKey == nil {
+		return "", ErrNoSigningKey
+	}
+	claims.Issuer = v.issuer
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = set.ActiveID
+	signed, err := token.SignedString(set.SigningKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// ValidateToken checks the signature, issuer and expiry of a token
+// Tokens naming a kid must be signed with that key, tokens without one,
+// issued before key IDs were introduced, may be signed with any key that is not retired
+func (v *Verifier) ValidateToken(tokenString string) (*auth.Claims, error) {
+	set := v.keys.Load()
+	if set == nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, ErrNoKeys)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+
+	claims := &auth.Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		id, _ := token.Header["kid"].(string)
+		if id != "" {
+			key, ok := set.Keys[id]
+			if !ok {
+				return nil, fmt.Errorf("unknown key %s", id)
+			}
+			if key.Retired() {
+				return nil, fmt.Errorf("key %s is retired", id)
+			}
+			return key.PublicKey, nil
+		}
+
+		var keys jwt.VerificationKeySet
+		for _, key := range set.Keys {
+			if !key.Retired() {
+				keys.Keys = append(keys.Keys, key.PublicKey)
+			}
+		}
+		return keys, nil
+	}, opts...)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, auth.ErrTokenExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,240 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+type fakeSource struct {
+	data map[string]interface{}
+	err  error
+}
+
+func (f *fakeSource) ReadKeySet(ctx context.Context, path string) (map[string]interface{}, error) {
+	return f.data, f.err
+}
+
+func newKey(t *testing.T) (*rsa.PrivateKey, string, string) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	id, err := KeyID(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to derive kid: %v", err)
+	}
+	return priv, id, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(t *testing.T, priv *rsa.PrivateKey, kid, issuer string, ttl time.Duration) string {
+	t.Helper()
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &auth.Claims{
+		ClientID:    "publisher-1",
+		Permissions: []string{auth.PermissionPublish},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return s
+}
+
+func TestParseKeySet_Legacy(t *testing.T) {
+	_, id, pub := newKey(t)
+
+	set, err := ParseKeySet(map[string]interface{}{FieldPrivateKey: "unused", FieldPublicKey: pub})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if set.ActiveID != id || len(set.Keys) != 1 {
+		t.Errorf("expected the legacy key as %s, got %+v", id, set)
+	}
+
+	if _, err := ParseKeySet(map[string]interface{}{}); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("expected ErrNoKeys, got %v", err)
+	}
+}
+
+func TestParseKeySet_RetiredActiveKey(t *testing.T) {
+	_, id, pub := newKey(t)
+
+	_, err := ParseKeySet(map[string]interface{}{
+		FieldPublicKey: pub,
+		FieldKeyID:     id,
+		FieldKeys: map[string]interface{}{
+			id: map[string]interface{}{FieldPublicKey: pub, FieldRetiredAt: time.Now().Format(time.RFC3339)},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for a retired active key")
+	}
+}
+
+func TestVerifier_Rotation(t *testing.T) {
+	oldPriv, oldID, oldPub := newKey(t)
+	newPriv, newID, newPub := newKey(t)
+
+	// Before the rotation only the legacy fields exist
+	source := &fakeSource{data: map[string]interface{}{FieldPublicKey: oldPub}}
+	v := NewVerifier(source, "secret/data/minitoolstream/jwt", "minitoolstream")
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	legacy := sign(t, oldPriv, "", "minitoolstream", time.Hour)
+	if _, err := v.ValidateToken(legacy); err != nil {
+		t.Fatalf("legacy token rejected: %v", err)
+	}
+	if _, err := v.ValidateToken(sign(t, newPriv, newID, "minitoolstream", time.Hour)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("expected unknown kid to be rejected, got %v", err)
+	}
+
+	// Rotation keeps the old key until it is retired
+	now := time.Now().Format(time.RFC3339)
+	source.data = map[string]interface{}{
+		FieldPublicKey: newPub,
+		FieldKeyID:     newID,
+		FieldKeys: map[string]interface{}{
+			oldID: map[string]interface{}{FieldPublicKey: oldPub},
+			newID: map[string]interface{}{FieldPublicKey: newPub, FieldCreatedAt: now},
+		},
+	}
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, token := range map[string]string{
+		"legacy":  legacy,
+		"old kid": sign(t, oldPriv, oldID, "minitoolstream", time.Hour),
+		"new kid": sign(t, newPriv, newID, "minitoolstream", time.Hour),
+	} {
+		claims, err := v.ValidateToken(token)
+		if err != nil {
+			t.Errorf("%s token rejected: %v", name, err)
+			continue
+		}
+		if claims.ClientID != "publisher-1" {
+			t.Errorf("%s token: unexpected client %q", name, claims.ClientID)
+		}
+	}
+
+	// Retiring the old key rejects its tokens, with or without a kid
+	source.data[FieldKeys].(map[string]interface{})[oldID] = map[string]interface{}{FieldPublicKey: oldPub, FieldRetiredAt: now}
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := v.ValidateToken(legacy); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected legacy token to be rejected, got %v", err)
+	}
+	if _, err := v.ValidateToken(sign(t, oldPriv, oldID, "minitoolstream", time.Hour)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected retired kid to be rejected, got %v", err)
+	}
+	if _, err := v.ValidateToken(sign(t, newPriv, newID, "minitoolstream", time.Hour)); err != nil {
+		t.Errorf("active key token rejected: %v", err)
+	}
+}
+
+func TestVerifier_ReloadFailureKeepsKeys(t *testing.T) {
+	priv, id, pub := newKey(t)
+	source := &fakeSource{data: map[string]interface{}{FieldPublicKey: pub}}
+	v := NewVerifier(source, "secret/data/minitoolstream/jwt", "minitoolstream")
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source.err = errors.New("vault sealed")
+	if err := v.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if _, err := v.ValidateToken(sign(t, priv, id, "minitoolstream", time.Hour)); err != nil {
+		t.Errorf("token rejected after failed reload: %v", err)
+	}
+}
+
+func TestVerifier_ValidateToken_Claims(t *testing.T) {
+	priv, id, pub := newKey(t)
+	v := NewVerifier(&fakeSource{data: map[string]interface{}{FieldPublicKey: pub}}, "", "minitoolstream")
+
+	if _, err := v.ValidateToken(sign(t, priv, id, "minitoolstream", time.Hour)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected error before the first reload, got %v", err)
+	}
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := v.ValidateToken(sign(t, priv, id, "minitoolstream", -time.Minute)); !errors.Is(err, auth.ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+	if _, err := v.ValidateToken(sign(t, priv, id, "other-issuer", time.Hour)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected wrong issuer to be rejected, got %v", err)
+	}
+	if _, err := v.ValidateToken("not-a-token"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected malformed token to be rejected, got %v", err)
+	}
+}
+
+func TestVerifier_Sign(t *testing.T) {
+	priv, id, pub := newKey(t)
+	privPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+
+	source := &fakeSource{data: map[string]interface{}{FieldPublicKey: pub, FieldKeyID: id}}
+	v := NewVerifier(source, "secret/data/minitoolstream/jwt", "minitoolstream")
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims := &auth.Claims{
+		ClientID:         "ci-runner",
+		Permissions:      []string{auth.PermissionPublish},
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}
+	if _, err := v.Sign(claims); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("expected ErrNoSigningKey without a private key, got %v", err)
+	}
+
+	source.data[FieldPrivateKey] = privPEM
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := v.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	parsed, err := v.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("signed token rejected: %v", err)
+	}
+	if parsed.ClientID != "ci-runner" || parsed.Issuer != "minitoolstream" {
+		t.Errorf("unexpected claims: %+v", parsed)
+	}
+
+	// A private key of another pair is not used
+	other, _, _ := newKey(t)
+	source.data[FieldPrivateKey] = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(other)}))
+	if err := v.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := v.Sign(claims); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("expected ErrNoSigningKey for a mismatched private key, got %v", err)
+	}
+}
@@ -92,6 +92,10 @@ func Int(key string, val int) zap.Field {
 	return zap.Int(key, val)
 }
 
+func Int64(key string, val int64) zap.Field {
+	return zap.Int64(key, val)
+}
+
 func Bool(key string, val bool) zap.Field {
 	return zap.Bool(key, val)
 }
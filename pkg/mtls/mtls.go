@@ -0,0 +1,177 @@
+// Package mtls serves TLS with certificates reloaded from disk and maps
+// verified client certificates to the same claims a JWT carries
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Files locates the server certificate and the client CA
+type Files struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables client certificate verification, empty accepts no client certificates
+	ClientCAFile string
+	// RequireClientCert rejects clients without a certificate signed by the client CA
+	RequireClientCert bool
+}
+
+type material struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// Reloader serves the certificates last read from Files
+// Connections already established keep the certificate they were set up with
+type Reloader struct {
+	files   Files
+	current atomic.Pointer[material]
+}
+
+// NewReloader reads the files once, they must be valid
+func NewReloader(files Files) (*Reloader, error) {
+	if files.RequireClientCert && files.ClientCAFile == "" {
+		return nil, fmt.Errorf("requiring client certificates needs a client CA")
+	}
+	r := &Reloader{files: files}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again, the previous certificates stay in use on error
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	m := &material{cert: &cert}
+	if r.files.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.files.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA: %w", err)
+		}
+		m.clientCAs = x509.NewCertPool()
+		if !m.clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA %s", r.files.ClientCAFile)
+		}
+	}
+
+	r.current.Store(m)
+	return nil
+}
+
+// Certificate returns the leaf of the server certificate in use
+func (r *Reloader) Certificate() (*x509.Certificate, error) {
+	cert := r.current.Load().cert
+	if cert.Leaf != nil {
+		return cert.Leaf, nil
+	}
+	return x509.ParseCertificate(cert.Certificate[0])
+}
+
+// TLSConfig returns a server configuration that picks up reloaded files on new handshakes
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			m := r.current.Load()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*m.cert},
+				ClientAuth:   tls.NoClientCert,
+			}
+			if m.clientCAs != nil {
+				cfg.ClientCAs = m.clientCAs
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+				if r.files.RequireClientCert {
+					cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Identity grants the permissions of a JWT to clients presenting a matching certificate
+type Identity struct {
+	// CommonName matches the certificate subject CN
+	CommonName string
+	// SAN matches a DNS name, URI or email address of the certificate
+	SAN string
+
+	ClientID    string
+	Subjects    []string
+	Permissions []string
+}
+
+// matches reports whether cert carries the identity's name
+func (id *Identity) matches(cert *x509.Certificate) bool {
+	if id.CommonName != "" && cert.Subject.CommonName == id.CommonName {
+		return true
+	}
+	if id.SAN == "" {
+		return false
+	}
+	for _, name := range cert.DNSNames {
+		if name == id.SAN {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.String() == id.SAN {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == id.SAN {
+			return true
+		}
+	}
+	return false
+}
+
+// Identities maps client certificates to claims, the first matching identity wins
+type Identities []Identity
+
+// Claims returns the claims of the identity matching cert
+func (ids Identities) Claims(cert *x509.Certificate) (*auth.Claims, bool) {
+	for i := range ids {
+		if ids[i].matches(cert) {
+			return &auth.Claims{
+				ClientID:        ids[i].ClientID,
+				AllowedSubjects: ids[i].Subjects,
+				Permissions:     ids[i].Permissions,
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// PeerClaims returns the claims of the verified client certificate of the connection
+// Certificates that were not verified against the client CA are ignored
+func (ids Identities) PeerClaims(ctx context.Context) (*auth.Claims, bool) {
+	if len(ids) == 0 {
+		return nil, false
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return ids.Claims(info.State.VerifiedChains[0][0])
+}
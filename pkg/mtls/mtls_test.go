@@ -0,0 +1,214 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// issue signs a certificate for tmpl and returns it with its PEM encoded pair
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	files := Files{
+		CertFile:          filepath.Join(dir, "tls.crt"),
+		KeyFile:           filepath.Join(dir, "tls.key"),
+		ClientCAFile:      filepath.Join(dir, "ca.crt"),
+		RequireClientCert: true,
+	}
+	_, certPEM, keyPEM := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "ingress-1"}})
+	writeFile(t, files.CertFile, certPEM)
+	writeFile(t, files.KeyFile, keyPEM)
+	writeFile(t, files.ClientCAFile, ca.pem())
+
+	r, err := NewReloader(files)
+	if err != nil {
+		t.Fatalf("NewReloader failed: %v", err)
+	}
+	cfg, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert || cfg.ClientCAs == nil {
+		t.Errorf("expected client certificates to be required and verified")
+	}
+	if cert, _ := r.Certificate(); cert.Subject.CommonName != "ingress-1" {
+		t.Errorf("expected ingress-1, got %s", cert.Subject.CommonName)
+	}
+
+	// A renewed certificate is served to new handshakes
+	_, certPEM, keyPEM = ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "ingress-2"}})
+	writeFile(t, files.CertFile, certPEM)
+	writeFile(t, files.KeyFile, keyPEM)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if cert, _ := r.Certificate(); cert.Subject.CommonName != "ingress-2" {
+		t.Errorf("expected ingress-2 after reload, got %s", cert.Subject.CommonName)
+	}
+
+	// A broken file keeps the previous certificate
+	writeFile(t, files.KeyFile, []byte("garbage"))
+	if err := r.Reload(); err == nil {
+		t.Error("expected reload of a broken key to fail")
+	}
+	if cert, _ := r.Certificate(); cert.Subject.CommonName != "ingress-2" {
+		t.Errorf("expected ingress-2 to stay in use, got %s", cert.Subject.CommonName)
+	}
+}
+
+func TestNewReloader_RequireWithoutCA(t *testing.T) {
+	if _, err := NewReloader(Files{CertFile: "a", KeyFile: "b", RequireClientCert: true}); err == nil {
+		t.Error("expected error when requiring client certificates without a CA")
+	}
+}
+
+func TestIdentities_Claims(t *testing.T) {
+	ca := newTestCA(t)
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/stream/sa/archiver")
+	archiver, _, _ := ca.issue(t, &x509.Certificate{
+		Subject: pkix.Name{CommonName: "archiver"},
+		URIs:    []*url.URL{spiffe},
+	})
+	router, _, _ := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "router"},
+		DNSNames:    []string{"router.stream.svc"},
+		IPAddresses: []net.IP{net.IPv4(10, 0, 0, 1)},
+	})
+	unknown, _, _ := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}})
+
+	ids := Identities{
+		{SAN: spiffe.String(), ClientID: "archiver", Subjects: []string{"*"}, Permissions: []string{"subscribe"}},
+		{SAN: "router.stream.svc", ClientID: "acme/router", Subjects: []string{"orders.*"}, Permissions: []string{"publish"}},
+		{CommonName: "archiver", ClientID: "shadowed"},
+	}
+
+	claims, ok := ids.Claims(archiver)
+	if !ok || claims.ClientID != "archiver" || claims.Permissions[0] != "subscribe" {
+		t.Errorf("expected archiver claims, got %+v", claims)
+	}
+	claims, ok = ids.Claims(router)
+	if !ok || claims.ClientID != "acme/router" || claims.AllowedSubjects[0] != "orders.*" {
+		t.Errorf("expected router claims, got %+v", claims)
+	}
+	if _, ok := ids.Claims(unknown); ok {
+		t.Error("expected no claims for an unknown certificate")
+	}
+}
+
+func TestIdentities_PeerClaims(t *testing.T) {
+	ca := newTestCA(t)
+	cert, _, _ := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "archiver"}})
+	ids := Identities{{CommonName: "archiver", ClientID: "archiver"}}
+
+	withState := func(state tls.ConnectionState) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		ids  Identities
+		ok   bool
+	}{
+		{"verified certificate", withState(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca.cert}}}), ids, true},
+		{"unverified certificate", withState(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}), ids, false},
+		{"no peer", context.Background(), ids, false},
+		{"no identities", withState(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca.cert}}}), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, ok := tt.ids.PeerClaims(tt.ctx)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && claims.ClientID != "archiver" {
+				t.Errorf("expected archiver, got %s", claims.ClientID)
+			}
+		})
+	}
+}
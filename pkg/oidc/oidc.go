@@ -0,0 +1,256 @@
+// Package oidc validates tokens of an external OpenID Connect provider
+//
+// Signing keys are read from the provider's JWKS, by URL or from a local file,
+// and refreshed periodically and whenever a token names an unknown key. The
+// provider knows nothing about subjects, so a rules file maps claims such as
+// groups or scopes to the subjects and permissions of MiniToolStream claims
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// ErrNoKeys is returned when the JWKS has no signing keys
+var ErrNoKeys = errors.New("no signing keys in JWKS")
+
+// minRefreshInterval limits refreshes triggered by tokens naming an unknown key
+const minRefreshInterval = 30 * time.Second
+
+// fetchTimeout bounds a refresh triggered while validating a token
+const fetchTimeout = 5 * time.Second
+
+// signingMethods are the algorithms accepted from the provider
+var signingMethods = []string{
+	jwt.SigningMethodRS256.Alg(), jwt.SigningMethodRS384.Alg(), jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodPS256.Alg(), jwt.SigningMethodPS384.Alg(), jwt.SigningMethodPS512.Alg(),
+	jwt.SigningMethodES256.Alg(), jwt.SigningMethodES384.Alg(), jwt.SigningMethodES512.Alg(),
+}
+
+// Source fetches the JWKS document
+type Source interface {
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// URLSource fetches the JWKS from the provider's jwks_uri
+type URLSource struct {
+	URL    string
+	Client *http.Client
+}
+
+// Fetch downloads the JWKS
+func (s *URLSource) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+	// A key set is a few kilobytes, anything much larger is not one
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// FileSource reads the JWKS from a local file, e.g. a static key set for tests
+type FileSource string
+
+// Fetch reads the JWKS file
+func (s FileSource) Fetch(context.Context) ([]byte, error) {
+	data, err := os.ReadFile(string(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	return data, nil
+}
+
+// ParseJWKS returns the public signing keys of a JWKS document
+// Keys marked for encryption are dropped, private keys are reduced to their public half
+func ParseJWKS(data []byte) (*jose.JSONWebKeySet, error) {
+	var doc jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	set := &jose.JSONWebKeySet{}
+	for _, key := range doc.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+		if !key.Valid() {
+			continue
+		}
+		set.Keys = append(set.Keys, key)
+	}
+	if len(set.Keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return set, nil
+}
+
+// Verifier validates tokens of one provider
+type Verifier struct {
+	source   Source
+	issuer   string
+	audience string
+	rules    *Rules
+	keys     atomic.Pointer[jose.JSONWebKeySet]
+	now      func() time.Time
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+}
+
+// NewVerifier creates a verifier for tokens of issuer, Refresh must succeed before use
+func NewVerifier(source Source, issuer, audience string, rules *Rules) *Verifier {
+	return &Verifier{
+		source:   source,
+		issuer:   issuer,
+		audience: audience,
+		rules:    rules,
+		now:      time.Now,
+	}
+}
+
+// Refresh fetches the JWKS again, the previous keys stay in use on error
+func (v *Verifier) Refresh(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refreshLocked(ctx)
+}
+
+func (v *Verifier) refreshLocked(ctx context.Context) error {
+	v.lastRefresh = v.now()
+	data, err := v.source.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	set, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	v.keys.Store(set)
+	return nil
+}
+
+// KeyIDs returns the IDs of the keys in use
+func (v *Verifier) KeyIDs() []string {
+	set := v.keys.Load()
+	if set == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		ids = append(ids, key.KeyID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Issued reports whether a token claims to be issued by the provider, without verifying it
+func (v *Verifier) Issued(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	iss, _ := claims.GetIssuer()
+	return iss == v.issuer
+}
+
+// ValidateToken checks the signature, issuer, audience and expiry of a token
+// and maps its claims to permissions
+func (v *Verifier) ValidateToken(tokenString string) (*auth.Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+	}
+
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, mapClaims, v.keyFunc, opts...)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, auth.ErrTokenExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+
+	claims, err := v.rules.Claims(mapClaims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// keyFunc returns the key named by the token, refreshing the JWKS once if it is unknown
+// Tokens without a kid may be signed with any key of the set
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	set := v.keys.Load()
+	if set == nil {
+		return nil, ErrNoKeys
+	}
+
+	id, _ := token.Header["kid"].(string)
+	if id == "" {
+		var keys jwt.VerificationKeySet
+		for _, key := range set.Keys {
+			keys.Keys = append(keys.Keys, key.Key)
+		}
+		return keys, nil
+	}
+
+	if keys := set.Key(id); len(keys) > 0 {
+		return keys[0].Key, nil
+	}
+	// The provider may have rotated its keys since the last refresh
+	if keys := v.refreshForKey(id); len(keys) > 0 {
+		return keys[0].Key, nil
+	}
+	return nil, fmt.Errorf("unknown key %s", id)
+}
+
+// refreshForKey fetches the JWKS unless it was fetched recently and returns the keys named id
+func (v *Verifier) refreshForKey(id string) []jose.JSONWebKey {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Another request may have refreshed the keys while this one waited
+	if keys := v.keys.Load().Key(id); len(keys) > 0 {
+		return keys
+	}
+	if v.now().Sub(v.lastRefresh) < minRefreshInterval {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	if err := v.refreshLocked(ctx); err != nil {
+		return nil
+	}
+	return v.keys.Load().Key(id)
+}
@@ -0,0 +1,228 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/realms/stream"
+	testAudience = "minitoolstream"
+)
+
+var testRules = &Rules{
+	Rules: []Rule{
+		{Claim: "groups", Value: "stream-publishers", Subjects: []string{"orders.*"}, Permissions: []string{"publish"}},
+		{Claim: "scope", Value: "stream:read", Subjects: []string{"orders.*", "logs.*"}, Permissions: []string{"subscribe", "fetch"}},
+		{Claim: "realm_access.roles", Value: "stream-admin", Subjects: []string{"*"}, Permissions: []string{"*"}},
+	},
+}
+
+func writeJWKS(t *testing.T, path string, keys ...jose.JSONWebKey) {
+	t.Helper()
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func validClaims(extra jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":    testIssuer,
+		"aud":    []string{testAudience, "account"},
+		"sub":    "svc-loader",
+		"jti":    "4f1c",
+		"iat":    time.Now().Unix(),
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"groups": []string{"stream-publishers"},
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestVerifier_ValidateToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path,
+		jose.JSONWebKey{Key: &rsaKey.PublicKey, KeyID: "rsa-1", Algorithm: "RS256", Use: "sig"},
+		jose.JSONWebKey{Key: &ecKey.PublicKey, KeyID: "ec-1", Algorithm: "ES256", Use: "sig"},
+	)
+	v := NewVerifier(FileSource(path), testIssuer, testAudience, testRules)
+	if err := v.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"rsa key", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(nil)), nil},
+		{"ec key", sign(t, jwt.SigningMethodES256, ecKey, "ec-1", validClaims(nil)), nil},
+		{"no kid", sign(t, jwt.SigningMethodRS256, rsaKey, "", validClaims(nil)), nil},
+		{"unknown key", sign(t, jwt.SigningMethodRS256, otherKey, "rsa-2", validClaims(nil)), auth.ErrInvalidToken},
+		{"wrong key for kid", sign(t, jwt.SigningMethodRS256, otherKey, "rsa-1", validClaims(nil)), auth.ErrInvalidToken},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(jwt.MapClaims{"iss": "https://evil.example.com"})), auth.ErrInvalidToken},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(jwt.MapClaims{"aud": "account"})), auth.ErrInvalidToken},
+		{"expired", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), auth.ErrTokenExpired},
+		{"no expiry", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(jwt.MapClaims{"exp": nil})), auth.ErrInvalidToken},
+		{"no matching rule", sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims(jwt.MapClaims{"groups": []string{"finance"}})), auth.ErrInvalidToken},
+		{"hmac", sign(t, jwt.SigningMethodHS256, []byte("secret"), "rsa-1", validClaims(nil)), auth.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.ValidateToken(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.ClientID != "svc-loader" || claims.ID != "4f1c" || claims.IssuedAt == nil {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifier_RefreshOnUnknownKey(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := []jose.JSONWebKey{{Key: &oldKey.PublicKey, KeyID: "old", Use: "sig"}}
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: jwks})
+	}))
+	defer srv.Close()
+
+	v := NewVerifier(&URLSource{URL: srv.URL}, testIssuer, testAudience, testRules)
+	now := time.Unix(1_700_000_000, 0)
+	v.now = func() time.Time { return now }
+	if err := v.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	// The provider rotates its key
+	jwks = append(jwks, jose.JSONWebKey{Key: &newKey.PublicKey, KeyID: "new", Use: "sig"})
+	token := sign(t, jwt.SigningMethodRS256, newKey, "new", validClaims(nil))
+
+	// Right after a refresh the unknown key does not trigger another one
+	if _, err := v.ValidateToken(token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected 1 fetch, got %d", fetches)
+	}
+
+	now = now.Add(minRefreshInterval)
+	if _, err := v.ValidateToken(token); err != nil {
+		t.Fatalf("expected the rotated key to be fetched, got %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+	if ids := v.KeyIDs(); len(ids) != 2 || ids[0] != "new" || ids[1] != "old" {
+		t.Errorf("unexpected key IDs: %v", ids)
+	}
+}
+
+func TestVerifier_Issued(t *testing.T) {
+	v := NewVerifier(FileSource(""), testIssuer, testAudience, testRules)
+	key := []byte("secret")
+
+	if !v.Issued(sign(t, jwt.SigningMethodHS256, key, "", jwt.MapClaims{"iss": testIssuer})) {
+		t.Error("expected token of the provider to be recognised")
+	}
+	if v.Issued(sign(t, jwt.SigningMethodHS256, key, "", jwt.MapClaims{"iss": "minitoolstream"})) {
+		t.Error("expected token of another issuer not to be recognised")
+	}
+	if v.Issued("not-a-token") {
+		t.Error("expected garbage not to be recognised")
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	sigKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Private keys are reduced to their public half, encryption keys are dropped
+	data, _ := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: sigKey, KeyID: "sig"},
+		{Key: &encKey.PublicKey, KeyID: "enc", Use: "enc"},
+	}})
+	set, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS failed: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].KeyID != "sig" || !set.Keys[0].IsPublic() {
+		t.Errorf("unexpected key set: %+v", set.Keys)
+	}
+
+	data, _ = json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &encKey.PublicKey, KeyID: "enc", Use: "enc"}}})
+	if _, err := ParseJWKS(data); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("expected ErrNoKeys, got %v", err)
+	}
+}
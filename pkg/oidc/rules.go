@@ -0,0 +1,157 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoPermissions is returned for a token no rule applies to
+var ErrNoPermissions = errors.New("token matches no permission rule")
+
+// Rule grants subjects and permissions to tokens whose claim contains Value
+type Rule struct {
+	// Claim is the claim name, nested claims are addressed with dots, e.g. "realm_access.roles"
+	Claim       string   `yaml:"claim"`
+	Value       string   `yaml:"value"`
+	Subjects    []string `yaml:"subjects"`
+	Permissions []string `yaml:"permissions"`
+}
+
+// Rules maps provider claims to MiniToolStream claims
+// A token gets the union of the subjects and permissions of all matching rules
+type Rules struct {
+	// ClientIDClaim names the claim used as client_id, "sub" if empty
+	ClientIDClaim string `yaml:"client_id_claim"`
+	// ClientIDPrefix is prepended to the client_id, e.g. "acme/" places all provider clients in a tenant
+	ClientIDPrefix string `yaml:"client_id_prefix"`
+	Rules          []Rule `yaml:"rules"`
+}
+
+// LoadRules reads a rules file
+func LoadRules(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer f.Close()
+
+	rules := &Rules{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Validate checks every rule names a claim, a value and what it grants
+func (r *Rules) Validate() error {
+	if len(r.Rules) == 0 {
+		return fmt.Errorf("no rules defined")
+	}
+	for i, rule := range r.Rules {
+		if rule.Claim == "" || rule.Value == "" {
+			return fmt.Errorf("rule %d needs a claim and a value", i+1)
+		}
+		if len(rule.Subjects) == 0 || len(rule.Permissions) == 0 {
+			return fmt.Errorf("rule %d (%s=%s) grants no subjects or permissions", i+1, rule.Claim, rule.Value)
+		}
+	}
+	return nil
+}
+
+// Claims maps the claims of a verified token
+func (r *Rules) Claims(claims jwt.MapClaims) (*auth.Claims, error) {
+	idClaim := r.ClientIDClaim
+	if idClaim == "" {
+		idClaim = "sub"
+	}
+	id, _ := lookupClaim(claims, idClaim).(string)
+	if id == "" {
+		return nil, fmt.Errorf("token has no %s claim", idClaim)
+	}
+
+	result := &auth.Claims{ClientID: r.ClientIDPrefix + id}
+	subjects := make(map[string]bool)
+	permissions := make(map[string]bool)
+	for _, rule := range r.Rules {
+		if !contains(claimValues(claims, rule.Claim), rule.Value) {
+			continue
+		}
+		for _, s := range rule.Subjects {
+			if !subjects[s] {
+				subjects[s] = true
+				result.AllowedSubjects = append(result.AllowedSubjects, s)
+			}
+		}
+		for _, p := range rule.Permissions {
+			if !permissions[p] {
+				permissions[p] = true
+				result.Permissions = append(result.Permissions, p)
+			}
+		}
+	}
+	if len(result.Permissions) == 0 {
+		return nil, ErrNoPermissions
+	}
+
+	// The registered claims keep revocation by jti and issue time working
+	result.ID, _ = claims["jti"].(string)
+	result.Issuer, _ = claims.GetIssuer()
+	result.Subject, _ = claims.GetSubject()
+	result.Audience, _ = claims.GetAudience()
+	result.ExpiresAt, _ = claims.GetExpirationTime()
+	result.IssuedAt, _ = claims.GetIssuedAt()
+	result.NotBefore, _ = claims.GetNotBefore()
+	return result, nil
+}
+
+// lookupClaim returns a claim, following dots into nested objects
+func lookupClaim(claims jwt.MapClaims, name string) interface{} {
+	var val interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(name, ".") {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		val = obj[part]
+	}
+	return val
+}
+
+// claimValues returns the string values of a claim
+// A string holds space separated values, as OAuth "scope" does, arrays hold one value per item
+func claimValues(claims jwt.MapClaims, name string) []string {
+	switch v := lookupClaim(claims, name).(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package oidc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRules_Claims(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       *Rules
+		claims      jwt.MapClaims
+		clientID    string
+		subjects    []string
+		permissions []string
+	}{
+		{
+			name:        "group",
+			rules:       testRules,
+			claims:      jwt.MapClaims{"sub": "svc-loader", "groups": []interface{}{"staff", "stream-publishers"}},
+			clientID:    "svc-loader",
+			subjects:    []string{"orders.*"},
+			permissions: []string{"publish"},
+		},
+		{
+			name:        "scope string and group are merged",
+			rules:       testRules,
+			claims:      jwt.MapClaims{"sub": "svc-loader", "scope": "openid stream:read", "groups": []interface{}{"stream-publishers"}},
+			clientID:    "svc-loader",
+			subjects:    []string{"orders.*", "logs.*"},
+			permissions: []string{"publish", "subscribe", "fetch"},
+		},
+		{
+			name:        "nested claim",
+			rules:       testRules,
+			claims:      jwt.MapClaims{"sub": "alice", "realm_access": map[string]interface{}{"roles": []interface{}{"stream-admin"}}},
+			clientID:    "alice",
+			subjects:    []string{"*"},
+			permissions: []string{"*"},
+		},
+		{
+			name:        "client id claim and prefix",
+			rules:       &Rules{ClientIDClaim: "azp", ClientIDPrefix: "acme/", Rules: testRules.Rules},
+			claims:      jwt.MapClaims{"sub": "7d3a", "azp": "ci-runner", "scope": "stream:read"},
+			clientID:    "acme/ci-runner",
+			subjects:    []string{"orders.*", "logs.*"},
+			permissions: []string{"subscribe", "fetch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.rules.Claims(tt.claims)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.ClientID != tt.clientID {
+				t.Errorf("expected client_id %s, got %s", tt.clientID, claims.ClientID)
+			}
+			if !reflect.DeepEqual(claims.AllowedSubjects, tt.subjects) {
+				t.Errorf("expected subjects %v, got %v", tt.subjects, claims.AllowedSubjects)
+			}
+			if !reflect.DeepEqual(claims.Permissions, tt.permissions) {
+				t.Errorf("expected permissions %v, got %v", tt.permissions, claims.Permissions)
+			}
+		})
+	}
+}
+
+func TestRules_Claims_Rejected(t *testing.T) {
+	if _, err := testRules.Claims(jwt.MapClaims{"sub": "svc-loader", "scope": "openid"}); !errors.Is(err, ErrNoPermissions) {
+		t.Errorf("expected ErrNoPermissions, got %v", err)
+	}
+	if _, err := testRules.Claims(jwt.MapClaims{"groups": []interface{}{"stream-publishers"}}); err == nil {
+		t.Error("expected error for a token without sub")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	content := `client_id_claim: azp
+rules:
+  - claim: groups
+    value: stream-publishers
+    subjects: ["orders.*"]
+    permissions: ["publish"]
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if rules.ClientIDClaim != "azp" || len(rules.Rules) != 1 || rules.Rules[0].Permissions[0] != "publish" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	invalid := map[string]string{
+		"unknown field": "rules:\n  - claim: groups\n    value: a\n    subjects: [\"*\"]\n    permission: [\"publish\"]\n",
+		"no rules":      "client_id_claim: sub\n",
+		"no grant":      "rules:\n  - claim: groups\n    value: a\n    subjects: [\"*\"]\n",
+	}
+	for name, content := range invalid {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRules(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package quota enforces per-client and per-subject rate limits and storage quotas
+//
+// Rate limits are token buckets kept in Tarantool, so every replica draws from
+// the same buckets. A bucket holds one second of its rate
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/moroshma/MiniToolStream/pkg/subject"
+)
+
+// ErrExceeded is matched by every *ExceededError
+var ErrExceeded = errors.New("quota exceeded")
+
+// ExceededError describes a rejected request
+type ExceededError struct {
+	Reason string
+	// RetryAfter is when the request may succeed, 0 if waiting does not help
+	RetryAfter time.Duration
+}
+
+// Error implements error
+func (e *ExceededError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("quota exceeded: %s, retry after %s", e.Reason, e.RetryAfter)
+	}
+	return "quota exceeded: " + e.Reason
+}
+
+// Is makes errors.Is(err, ErrExceeded) match
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrExceeded
+}
+
+// Limit is a set of quotas, 0 in any field means unlimited
+type Limit struct {
+	MessagesPerSecond float64
+	BytesPerSecond    float64
+	// MaxStoredBytes caps payload bytes kept in the stream
+	MaxStoredBytes uint64
+}
+
+// SubjectLimit applies a limit to a subject or "prefix.*" pattern
+// The limit is shared by all clients using matching subjects
+type SubjectLimit struct {
+	Pattern string
+	Limit
+}
+
+// Policy defines which limits apply to a request
+type Policy struct {
+	// Default applies to every client without an override, including anonymous ones
+	Default Limit
+	// Clients overrides the default per client ID
+	Clients map[string]Limit
+	// Subjects apply in addition to the client limit, every matching pattern counts
+	Subjects []SubjectLimit
+}
+
+// Bucket is a token bucket to draw from
+type Bucket struct {
+	Key  string
+	Rate float64
+	// Cost is the number of tokens requested
+	Cost float64
+}
+
+// Store keeps token buckets shared by all replicas
+type Store interface {
+	// ConsumeQuota draws from all buckets at once and returns how long to wait
+	// if any of them is short. force charges the buckets anyway, letting them go
+	// into debt that later requests have to wait out
+	ConsumeQuota(buckets []Bucket, force bool) (time.Duration, error)
+}
+
+// UsageStore reports stored payload bytes for storage quotas
+type UsageStore interface {
+	// GetStoredBytes returns payload bytes stored in subjects matching pattern
+	GetStoredBytes(pattern string) (uint64, error)
+	// GetClientStoredBytes returns payload bytes stored by a client
+	GetClientStoredBytes(clientID string) (uint64, error)
+}
+
+// Limiter checks requests against a policy
+type Limiter struct {
+	policy *Policy
+	store  Store
+	usage  UsageStore
+	// scope keeps buckets of different operations apart, e.g. "publish" and "fetch"
+	scope string
+}
+
+// NewLimiter creates a limiter whose buckets are named after scope
+func NewLimiter(policy *Policy, store Store, scope string) *Limiter {
+	return &Limiter{
+		policy: policy,
+		store:  store,
+		scope:  scope,
+	}
+}
+
+// SetUsageStore enables storage quotas, nil disables them
+func (l *Limiter) SetUsageStore(usage UsageStore) {
+	l.usage = usage
+}
+
+// clientLimit returns the limit of a client
+func (l *Limiter) clientLimit(clientID string) Limit {
+	if limit, ok := l.policy.Clients[clientID]; ok {
+		return limit
+	}
+	return l.policy.Default
+}
+
+// buckets lists the buckets a request of the given size draws from
+func (l *Limiter) buckets(clientID, subj string, messages, bytes int64) []Bucket {
+	var buckets []Bucket
+	add := func(owner string, limit Limit) {
+		if limit.MessagesPerSecond > 0 {
+			buckets = append(buckets, Bucket{Key: l.scope + ":msgs:" + owner, Rate: limit.MessagesPerSecond, Cost: float64(messages)})
+		}
+		if limit.BytesPerSecond > 0 {
+			buckets = append(buckets, Bucket{Key: l.scope + ":bytes:" + owner, Rate: limit.BytesPerSecond, Cost: float64(bytes)})
+		}
+	}
+
+	add("client:"+clientID, l.clientLimit(clientID))
+	for _, s := range l.policy.Subjects {
+		if subject.Match(s.Pattern, subj) {
+			add("subject:"+s.Pattern, s.Limit)
+		}
+	}
+	return buckets
+}
+
+// Acquire admits a request of the given size or returns an *ExceededError
+// A request of size 0 is admitted unless earlier charges left a bucket in debt
+func (l *Limiter) Acquire(clientID, subj string, messages, bytes int64) error {
+	buckets := l.buckets(clientID, subj, messages, bytes)
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	wait, err := l.store.ConsumeQuota(buckets, false)
+	if err != nil {
+		return fmt.Errorf("failed to consume quota: %w", err)
+	}
+	if wait > 0 {
+		return &ExceededError{Reason: "rate limit", RetryAfter: wait}
+	}
+	return nil
+}
+
+// Charge records usage of an admitted request whose size was not known up front
+func (l *Limiter) Charge(clientID, subj string, messages, bytes int64) error {
+	buckets := l.buckets(clientID, subj, messages, bytes)
+	if len(buckets) == 0 || (messages == 0 && bytes == 0) {
+		return nil
+	}
+
+	if _, err := l.store.ConsumeQuota(buckets, true); err != nil {
+		return fmt.Errorf("failed to charge quota: %w", err)
+	}
+	return nil
+}
+
+// CheckStorage rejects a payload that would take the client or a subject pattern over its storage quota
+// Client usage is only known for authenticated clients
+func (l *Limiter) CheckStorage(clientID, subj string, size int64) error {
+	if l.usage == nil || size <= 0 {
+		return nil
+	}
+
+	if limit := l.clientLimit(clientID).MaxStoredBytes; limit > 0 && clientID != "" {
+		used, err := l.usage.GetClientStoredBytes(clientID)
+		if err != nil {
+			return fmt.Errorf("failed to get client storage usage: %w", err)
+		}
+		if exceeds(used, size, limit) {
+			return &ExceededError{Reason: fmt.Sprintf("client %s stores %d of %d bytes", clientID, used, limit)}
+		}
+	}
+
+	for _, s := range l.policy.Subjects {
+		if s.MaxStoredBytes == 0 || !subject.Match(s.Pattern, subj) {
+			continue
+		}
+		used, err := l.usage.GetStoredBytes(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("failed to get subject storage usage: %w", err)
+		}
+		if exceeds(used, size, s.MaxStoredBytes) {
+			return &ExceededError{Reason: fmt.Sprintf("subjects %s store %d of %d bytes", s.Pattern, used, s.MaxStoredBytes)}
+		}
+	}
+	return nil
+}
+
+func exceeds(used uint64, size int64, limit uint64) bool {
+	return used >= limit || uint64(size) > limit-used
+}
+
+// RetryAfterSeconds rounds a wait up to whole seconds, as used by Retry-After headers
+func RetryAfterSeconds(wait time.Duration) int64 {
+	return int64(math.Ceil(wait.Seconds()))
+}
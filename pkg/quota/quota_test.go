@@ -0,0 +1,169 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryStore is an in-memory Store with a manual clock
+type memoryStore struct {
+	now     time.Time
+	tokens  map[string]float64
+	updated map[string]time.Time
+	stored  map[string]uint64
+	clients map[string]uint64
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		now:     time.Unix(1700000000, 0),
+		tokens:  make(map[string]float64),
+		updated: make(map[string]time.Time),
+		stored:  make(map[string]uint64),
+		clients: make(map[string]uint64),
+	}
+}
+
+func (m *memoryStore) level(b Bucket) float64 {
+	tokens, ok := m.tokens[b.Key]
+	if !ok {
+		return b.Rate
+	}
+	return min(b.Rate, tokens+m.now.Sub(m.updated[b.Key]).Seconds()*b.Rate)
+}
+
+func (m *memoryStore) ConsumeQuota(buckets []Bucket, force bool) (time.Duration, error) {
+	var wait time.Duration
+	for _, b := range buckets {
+		need := min(b.Cost, b.Rate)
+		if level := m.level(b); level < need {
+			wait = max(wait, time.Duration((need-level)/b.Rate*float64(time.Second)))
+		}
+	}
+	if wait > 0 && !force {
+		return wait, nil
+	}
+	for _, b := range buckets {
+		m.tokens[b.Key] = m.level(b) - b.Cost
+		m.updated[b.Key] = m.now
+	}
+	return 0, nil
+}
+
+func (m *memoryStore) GetStoredBytes(pattern string) (uint64, error) {
+	return m.stored[pattern], nil
+}
+
+func (m *memoryStore) GetClientStoredBytes(clientID string) (uint64, error) {
+	return m.clients[clientID], nil
+}
+
+func TestLimiter_Acquire(t *testing.T) {
+	store := newMemoryStore()
+	limiter := NewLimiter(&Policy{
+		Default: Limit{MessagesPerSecond: 2},
+		Clients: map[string]Limit{"bulk": {BytesPerSecond: 100}},
+	}, store, "publish")
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Acquire("app", "orders", 1, 10); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	err := limiter.Acquire("app", "orders", 1, 10)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected ExceededError, got %v", err)
+	}
+	if exceeded.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected retry after 500ms, got %s", exceeded.RetryAfter)
+	}
+
+	// Other clients have their own buckets
+	if err := limiter.Acquire("other", "orders", 1, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store.now = store.now.Add(time.Second)
+	if err := limiter.Acquire("app", "orders", 1, 10); err != nil {
+		t.Fatalf("expected bucket to refill, got %v", err)
+	}
+
+	// A payload larger than the bucket passes when the bucket is full and leaves it in debt
+	if err := limiter.Acquire("bulk", "orders", 1, 300); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.now = store.now.Add(time.Second)
+	if err := limiter.Acquire("bulk", "orders", 1, 1); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected bucket in debt to reject, got %v", err)
+	}
+}
+
+func TestLimiter_SubjectLimitsAreShared(t *testing.T) {
+	store := newMemoryStore()
+	limiter := NewLimiter(&Policy{
+		Subjects: []SubjectLimit{{Pattern: "logs.*", Limit: Limit{MessagesPerSecond: 1}}},
+	}, store, "publish")
+
+	if err := limiter.Acquire("a", "logs.app", 1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := limiter.Acquire("b", "logs.db", 1, 0); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected the subject bucket to be shared, got %v", err)
+	}
+	if err := limiter.Acquire("b", "orders", 1, 0); err != nil {
+		t.Fatalf("unexpected error for an unlimited subject: %v", err)
+	}
+}
+
+func TestLimiter_Charge(t *testing.T) {
+	store := newMemoryStore()
+	limiter := NewLimiter(&Policy{Default: Limit{BytesPerSecond: 1000}}, store, "fetch")
+
+	// Admission with no cost only fails while the bucket is in debt
+	if err := limiter.Acquire("app", "orders", 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := limiter.Charge("app", "orders", 10, 3000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := limiter.Acquire("app", "orders", 0, 0); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected rejection while in debt, got %v", err)
+	}
+
+	store.now = store.now.Add(2 * time.Second)
+	if err := limiter.Acquire("app", "orders", 0, 0); err != nil {
+		t.Fatalf("expected debt to be paid off, got %v", err)
+	}
+}
+
+func TestLimiter_CheckStorage(t *testing.T) {
+	store := newMemoryStore()
+	store.clients["app"] = 900
+	store.stored["logs.*"] = 5000
+	limiter := NewLimiter(&Policy{
+		Default:  Limit{MaxStoredBytes: 1000},
+		Subjects: []SubjectLimit{{Pattern: "logs.*", Limit: Limit{MaxStoredBytes: 5000}}},
+	}, store, "publish")
+
+	// Storage quotas need usage figures
+	if err := limiter.CheckStorage("app", "orders", 1<<20); err != nil {
+		t.Fatalf("unexpected error without a usage store: %v", err)
+	}
+	limiter.SetUsageStore(store)
+
+	if err := limiter.CheckStorage("app", "orders", 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := limiter.CheckStorage("app", "orders", 101); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected client storage quota to be exceeded, got %v", err)
+	}
+	if err := limiter.CheckStorage("", "orders", 5000); err != nil {
+		t.Fatalf("anonymous usage is not tracked, got %v", err)
+	}
+	if err := limiter.CheckStorage("other", "logs.app", 1); !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected subject storage quota to be exceeded, got %v", err)
+	}
+}
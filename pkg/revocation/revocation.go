@@ -0,0 +1,113 @@
+// Package revocation rejects tokens revoked before they expire
+//
+// Tokens are revoked one by one by their jti, or all tokens of a client issued
+// up to a point in time. Lookups are cached for a short time, so a revocation
+// takes effect within the cache TTL on every replica
+package revocation
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+// ErrRevoked is returned for a revoked token
+var ErrRevoked = errors.New("token revoked")
+
+// maxCacheEntries bounds the cache, expired entries are dropped when it is full
+const maxCacheEntries = 10000
+
+// Status is the revocation state of a token
+type Status struct {
+	// TokenRevoked is set when the token's jti is revoked
+	TokenRevoked bool
+	// ClientNotBefore revokes the client's tokens issued up to this time, zero if none
+	ClientNotBefore time.Time
+}
+
+// Store looks up revocations shared by all replicas
+type Store interface {
+	CheckRevocation(jti, clientID string) (Status, error)
+}
+
+type cacheEntry struct {
+	status    Status
+	expiresAt time.Time
+}
+
+// Checker checks tokens against a Store
+type Checker struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewChecker creates a checker caching lookups for ttl, 0 disables the cache
+func NewChecker(store Store, ttl time.Duration) *Checker {
+	return &Checker{
+		store:   store,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Check returns ErrRevoked if the token of claims is revoked
+func (c *Checker) Check(claims *auth.Claims) error {
+	status, err := c.lookup(claims.ID, claims.ClientID)
+	if err != nil {
+		return err
+	}
+
+	if status.TokenRevoked {
+		return ErrRevoked
+	}
+	if !status.ClientNotBefore.IsZero() {
+		// Tokens without an issue time cannot be told apart from revoked ones
+		if claims.IssuedAt == nil || !claims.IssuedAt.After(status.ClientNotBefore) {
+			return ErrRevoked
+		}
+	}
+	return nil
+}
+
+// lookup returns the status of a token, from the cache if it is fresh
+func (c *Checker) lookup(jti, clientID string) (Status, error) {
+	key := jti + "\x00" + clientID
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.status, nil
+	}
+
+	status, err := c.store.CheckRevocation(jti, clientID)
+	if err != nil {
+		return Status{}, err
+	}
+	if c.ttl <= 0 {
+		return status, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	c.entries[key] = cacheEntry{status: status, expiresAt: now.Add(c.ttl)}
+	return status, nil
+}
@@ -0,0 +1,113 @@
+package revocation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+)
+
+type fakeStore struct {
+	tokens  map[string]bool
+	clients map[string]time.Time
+	calls   int
+	err     error
+}
+
+func (f *fakeStore) CheckRevocation(jti, clientID string) (Status, error) {
+	f.calls++
+	if f.err != nil {
+		return Status{}, f.err
+	}
+	return Status{TokenRevoked: f.tokens[jti], ClientNotBefore: f.clients[clientID]}, nil
+}
+
+func claimsAt(jti, clientID string, issuedAt time.Time) *auth.Claims {
+	return &auth.Claims{
+		ClientID: clientID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       jti,
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	cutoff := time.Unix(1_700_000_000, 0)
+	store := &fakeStore{
+		tokens:  map[string]bool{"leaked": true},
+		clients: map[string]time.Time{"edge-7": cutoff},
+	}
+	c := NewChecker(store, 0)
+
+	tests := []struct {
+		name    string
+		claims  *auth.Claims
+		revoked bool
+	}{
+		{"valid token", claimsAt("a1", "publisher-1", cutoff), false},
+		{"revoked jti", claimsAt("leaked", "publisher-1", cutoff), true},
+		{"client token before cutoff", claimsAt("b1", "edge-7", cutoff.Add(-time.Hour)), true},
+		{"client token at cutoff", claimsAt("b2", "edge-7", cutoff), true},
+		{"client token after cutoff", claimsAt("b3", "edge-7", cutoff.Add(time.Second)), false},
+		{"client token without iat", &auth.Claims{ClientID: "edge-7"}, true},
+		{"token without jti", claimsAt("", "publisher-1", cutoff), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Check(tt.claims)
+			if tt.revoked && !errors.Is(err, ErrRevoked) {
+				t.Errorf("expected ErrRevoked, got %v", err)
+			}
+			if !tt.revoked && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestChecker_Cache(t *testing.T) {
+	store := &fakeStore{tokens: map[string]bool{}}
+	c := NewChecker(store, 10*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return now }
+	claims := claimsAt("a1", "publisher-1", now)
+
+	for i := 0; i < 3; i++ {
+		if err := c.Check(claims); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if store.calls != 1 {
+		t.Errorf("expected 1 store call, got %d", store.calls)
+	}
+
+	// A revocation takes effect once the cached entry expires
+	store.tokens["a1"] = true
+	if err := c.Check(claims); err != nil {
+		t.Errorf("expected cached status, got %v", err)
+	}
+	now = now.Add(10 * time.Second)
+	if err := c.Check(claims); !errors.Is(err, ErrRevoked) {
+		t.Errorf("expected ErrRevoked after the cache TTL, got %v", err)
+	}
+}
+
+func TestChecker_StoreError(t *testing.T) {
+	store := &fakeStore{err: errors.New("connection refused")}
+	c := NewChecker(store, time.Minute)
+
+	err := c.Check(claimsAt("a1", "publisher-1", time.Now()))
+	if err == nil || errors.Is(err, ErrRevoked) {
+		t.Errorf("expected store error, got %v", err)
+	}
+
+	// Errors are not cached
+	store.err = nil
+	if err := c.Check(claimsAt("a1", "publisher-1", time.Now())); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
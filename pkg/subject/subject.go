@@ -0,0 +1,178 @@
+// Package subject defines the subject naming grammar shared by ingress and egress
+//
+// A subject is a dot-separated list of tokens, e.g. "orders.eu.created".
+// Tokens are non-empty and consist of ASCII letters, digits, '_' and '-'.
+// Subjects are at most MaxLength bytes long and must not start with ReservedPrefix.
+// Patterns additionally allow "*" for every subject and "prefix.*" for a subtree,
+// the same semantics as subject patterns in JWT claims.
+//
+// Subjects of a tenant are stored qualified as "$TENANT.{tenant}.{subject}".
+// Clients always use the unqualified name; '$' never occurs in it, so a client
+// cannot name a subject of another tenant.
+package subject
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxLength is the longest subject in bytes
+	MaxLength = 255
+	// ReservedPrefix starts subjects used by the system itself
+	ReservedPrefix = "$SYS."
+	// Wildcard matches any subject, or any subtree when used as the last token of a pattern
+	Wildcard = "*"
+	// TenantPrefix starts subjects and object keys qualified with a tenant
+	TenantPrefix = "$TENANT."
+	// MaxTenantLength is the longest tenant name, short enough to be part of a bucket name
+	MaxTenantLength = 32
+)
+
+// objectKeySeparator splits subject and sequence in object keys, it never occurs in a subject
+const objectKeySeparator = "/"
+
+// ErrInvalid is returned for subjects and patterns that do not follow the grammar
+var ErrInvalid = errors.New("invalid subject")
+
+// Validate checks that s is a subject messages can be published to or read from
+func Validate(s string) error {
+	if s == "" {
+		return fmt.Errorf("%w: subject cannot be empty", ErrInvalid)
+	}
+	if len(s) > MaxLength {
+		return fmt.Errorf("%w: subject is longer than %d bytes", ErrInvalid, MaxLength)
+	}
+	if strings.HasPrefix(s, ReservedPrefix) {
+		return fmt.Errorf("%w: %q uses the reserved %s prefix", ErrInvalid, s, ReservedPrefix)
+	}
+
+	for _, token := range strings.Split(s, ".") {
+		if token == "" {
+			return fmt.Errorf("%w: %q has an empty token", ErrInvalid, s)
+		}
+		for _, c := range token {
+			if !validTokenChar(c) {
+				return fmt.Errorf("%w: %q contains %q, only letters, digits, '_' and '-' are allowed", ErrInvalid, s, c)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidatePattern checks a subject pattern: "*", "prefix.*" or an exact subject
+func ValidatePattern(pattern string) error {
+	if pattern == Wildcard {
+		return nil
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "."+Wildcard); ok {
+		return Validate(prefix)
+	}
+	return Validate(pattern)
+}
+
+// Match reports whether subject matches pattern
+// "" and "*" match every subject, "prefix.*" matches subjects below prefix
+func Match(pattern, subject string) bool {
+	if pattern == "" || pattern == Wildcard || pattern == subject {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, Wildcard); ok && strings.HasSuffix(prefix, ".") {
+		return strings.HasPrefix(subject, prefix)
+	}
+	return false
+}
+
+// ValidateTenant checks a tenant name: lowercase letters, digits and '-',
+// so that it can be used in subjects and bucket names
+func ValidateTenant(tenant string) error {
+	if tenant == "" || len(tenant) > MaxTenantLength {
+		return fmt.Errorf("%w: tenant must be 1 to %d characters long", ErrInvalid, MaxTenantLength)
+	}
+	for _, c := range tenant {
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && c != '-' {
+			return fmt.Errorf("%w: tenant %q contains %q, only lowercase letters, digits and '-' are allowed", ErrInvalid, tenant, c)
+		}
+	}
+	return nil
+}
+
+// Qualify returns the stored name of subject s of a tenant
+// The default tenant "" keeps unqualified names. s may also be a pattern or an object key
+func Qualify(tenant, s string) string {
+	if tenant == "" {
+		return s
+	}
+	return TenantPrefix + tenant + "." + s
+}
+
+// Unqualify splits a stored name into its tenant and the name the tenant uses
+func Unqualify(name string) (string, string) {
+	rest, ok := strings.CutPrefix(name, TenantPrefix)
+	if !ok {
+		return "", name
+	}
+	tenant, s, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", name
+	}
+	return tenant, s
+}
+
+// ValidateQualified checks a stored subject, qualified or not
+func ValidateQualified(s string) error {
+	if rest, ok := strings.CutPrefix(s, TenantPrefix); ok {
+		tenant, name, _ := strings.Cut(rest, ".")
+		if err := ValidateTenant(tenant); err != nil {
+			return err
+		}
+		return Validate(name)
+	}
+	return Validate(s)
+}
+
+// ValidateQualifiedPattern checks a pattern over stored subjects, qualified or not
+// "$TENANT.<tenant>.*" covers every subject of a tenant
+func ValidateQualifiedPattern(pattern string) error {
+	if pattern == Wildcard {
+		return nil
+	}
+	prefix, wildcard := strings.CutSuffix(pattern, "."+Wildcard)
+	if rest, ok := strings.CutPrefix(prefix, TenantPrefix); ok && wildcard && !strings.Contains(rest, ".") {
+		return ValidateTenant(rest)
+	}
+	return ValidateQualified(prefix)
+}
+
+// ObjectKey returns the storage key of the payload of a message
+// The subject is used verbatim: the grammar keeps it safe for object keys
+func ObjectKey(subject string, sequence uint64) string {
+	return ObjectPrefix(subject) + strconv.FormatUint(sequence, 10)
+}
+
+// ObjectPrefix returns the key prefix shared by all payloads of a subject and no other subject
+func ObjectPrefix(subject string) string {
+	return subject + objectKeySeparator
+}
+
+// ParseObjectKey splits a key built by ObjectKey back into subject and sequence
+func ParseObjectKey(key string) (string, uint64, error) {
+	i := strings.LastIndex(key, objectKeySeparator)
+	if i < 0 {
+		return "", 0, fmt.Errorf("%q is not a message object key", key)
+	}
+	sequence, err := strconv.ParseUint(key[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%q is not a message object key: %w", key, err)
+	}
+	if err := ValidateQualified(key[:i]); err != nil {
+		return "", 0, err
+	}
+	return key[:i], sequence, nil
+}
+
+func validTokenChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
@@ -0,0 +1,147 @@
+package subject
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{"orders", "orders.eu.created", "logs_raw", "tenant-1.events", strings.Repeat("a", MaxLength)}
+	for _, s := range valid {
+		if err := Validate(s); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", s, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"orders..created",
+		".orders",
+		"orders.",
+		"orders/eu",
+		"orders eu",
+		"../etc",
+		"заказы",
+		"orders.*",
+		"$SYS.health",
+		strings.Repeat("a", MaxLength+1),
+	}
+	for _, s := range invalid {
+		if err := Validate(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalid", s, err)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, p := range []string{"*", "acme.*", "acme.billing.*", "payments"} {
+		if err := ValidatePattern(p); err != nil {
+			t.Errorf("ValidatePattern(%q) = %v, want nil", p, err)
+		}
+	}
+	for _, p := range []string{"", "acme*", "*.orders", "acme.*.orders", ".*"} {
+		if err := ValidatePattern(p); err == nil {
+			t.Errorf("ValidatePattern(%q) = nil, want error", p)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"", "orders", true},
+		{"*", "orders.eu", true},
+		{"orders", "orders", true},
+		{"orders", "orders.eu", false},
+		{"orders.*", "orders.eu", true},
+		{"orders.*", "orders.eu.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "ordersx.eu", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestObjectKey_RoundTrip(t *testing.T) {
+	key := ObjectKey("orders.eu", 42)
+	if key != "orders.eu/42" {
+		t.Errorf("unexpected object key %q", key)
+	}
+
+	subject, sequence, err := ParseObjectKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subject != "orders.eu" || sequence != 42 {
+		t.Errorf("ParseObjectKey(%q) = %q, %d", key, subject, sequence)
+	}
+
+	// Keys of one subject never share a prefix with another subject
+	if strings.HasPrefix(ObjectKey("orders_eu", 1), ObjectPrefix("orders")) {
+		t.Error("expected prefixes of different subjects not to overlap")
+	}
+
+	if subject, _, err := ParseObjectKey(ObjectKey(Qualify("acme", "orders"), 7)); err != nil || subject != "$TENANT.acme.orders" {
+		t.Errorf("ParseObjectKey of a tenant key = %q, %v", subject, err)
+	}
+
+	for _, bad := range []string{"orders_42", "orders/", "orders/x", "sha256/" + strings.Repeat("ab", 32), "$TENANT.Acme.orders/1"} {
+		if _, _, err := ParseObjectKey(bad); err == nil {
+			t.Errorf("ParseObjectKey(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestQualify(t *testing.T) {
+	if got := Qualify("", "orders"); got != "orders" {
+		t.Errorf("Qualify for the default tenant = %q, want orders", got)
+	}
+
+	qualified := Qualify("acme", "orders.eu")
+	if qualified != "$TENANT.acme.orders.eu" {
+		t.Errorf("unexpected qualified subject %q", qualified)
+	}
+	if tenant, s := Unqualify(qualified); tenant != "acme" || s != "orders.eu" {
+		t.Errorf("Unqualify(%q) = %q, %q", qualified, tenant, s)
+	}
+	if tenant, s := Unqualify("orders.eu"); tenant != "" || s != "orders.eu" {
+		t.Errorf("Unqualify of an unqualified subject = %q, %q", tenant, s)
+	}
+	if err := ValidateQualified(qualified); err != nil {
+		t.Errorf("ValidateQualified(%q) = %v, want nil", qualified, err)
+	}
+
+	for _, p := range []string{"*", Qualify("acme", Wildcard), Qualify("acme", "orders.*"), qualified, "orders.*"} {
+		if err := ValidateQualifiedPattern(p); err != nil {
+			t.Errorf("ValidateQualifiedPattern(%q) = %v, want nil", p, err)
+		}
+	}
+	for _, p := range []string{"", "$TENANT.*", "$TENANT.Acme.*", "$TENANT.acme", "orders*"} {
+		if err := ValidateQualifiedPattern(p); err == nil {
+			t.Errorf("ValidateQualifiedPattern(%q) = nil, want error", p)
+		}
+	}
+
+	// Tenant patterns cover the tenant and nothing else
+	if !Match(Qualify("acme", Wildcard), qualified) || Match(Qualify("acme", Wildcard), Qualify("acme-eu", "orders")) {
+		t.Error("expected the tenant pattern to match only subjects of the tenant")
+	}
+
+	// A client cannot reach another tenant through the subject name
+	if err := Validate(qualified); err == nil {
+		t.Errorf("Validate(%q) = nil, want error", qualified)
+	}
+
+	for _, tenant := range []string{"", "Acme", "acme.eu", "acme/eu", strings.Repeat("a", MaxTenantLength+1)} {
+		if err := ValidateTenant(tenant); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ValidateTenant(%q) = %v, want ErrInvalid", tenant, err)
+		}
+	}
+}
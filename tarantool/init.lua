@@ -29,7 +29,7 @@ box.once('init', function()
 
     -- Space 1: message
     -- Stores metadata about each message in the stream
-    -- object_name is auto-generated as {{subject}}_{{sequence}} and used as MinIO/S3 key
+    -- object_name is auto-generated as {subject}/{sequence} and used as MinIO/S3 key
     local message = box.schema.space.create('message', {
         if_not_exists = true,
         engine = 'memtx',
@@ -105,15 +105,462 @@ box.once('init', function()
     print('MiniToolStream: Spaces and indexes created successfully')
 end)
 
+-- Space 3: subjects
+-- Catalogue of subjects with running statistics
+-- Maintained by insert_message and delete_message so that listing subjects
+-- and counting messages never has to scan the message space
+box.once('subjects_v1', function()
+    local subjects = box.schema.space.create('subjects', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'subject', type = 'string'},           -- Topic/channel name (PK)
+            {name = 'first_sequence', type = 'unsigned'},  -- Oldest stored sequence (0 if empty)
+            {name = 'last_sequence', type = 'unsigned'},   -- Newest sequence ever published
+            {name = 'message_count', type = 'unsigned'},   -- Number of stored messages
+            {name = 'total_bytes', type = 'unsigned'},     -- Sum of payload sizes (data-size header)
+            {name = 'first_publish_at', type = 'unsigned'},-- create_at of the oldest stored message
+            {name = 'last_publish_at', type = 'unsigned'}  -- create_at of the newest message
+        }
+    })
+
+    subjects:create_index('primary', {
+        parts = {'subject'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    -- Backfill statistics from already stored messages
+    for _, tuple in box.space.message.index.primary:pairs() do
+        local subject = tuple[4]
+        local size = tonumber(type(tuple[2]) == 'table' and tuple[2]['data-size'] or nil) or 0
+        local existing = subjects:get(subject)
+        if existing == nil then
+            subjects:insert({subject, tuple[1], tuple[1], 1, size, tuple[5], tuple[5]})
+        else
+            subjects:update(subject, {
+                {'=', 3, tuple[1]},
+                {'+', 4, 1},
+                {'+', 5, size},
+                {'=', 7, tuple[5]}
+            })
+        end
+    end
+
+    print('MiniToolStream: subjects space created')
+end)
+
+-- Space 4: subject_config
+-- Explicit subject (stream) declarations with retention limits
+-- 0 in any limit field means "unlimited"
+box.once('subject_config_v1', function()
+    local subject_config = box.schema.space.create('subject_config', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'subject', type = 'string'},        -- Topic/channel name (PK)
+            {name = 'max_msgs', type = 'unsigned'},     -- Max stored messages
+            {name = 'max_bytes', type = 'unsigned'},    -- Max stored payload bytes
+            {name = 'max_age', type = 'unsigned'},      -- Max message age in seconds
+            {name = 'max_msg_size', type = 'unsigned'}, -- Max single payload size in bytes
+            {name = 'discard', type = 'string'},        -- 'old' (drop oldest) or 'new' (reject publish)
+            {name = 'updated_at', type = 'unsigned'}    -- Unix timestamp of last change
+        }
+    })
+
+    subject_config:create_index('primary', {
+        parts = {'subject'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: subject_config space created')
+end)
+
+-- Retention mode of a declared subject:
+-- 'limits' (default) - messages are removed by TTL and subject limits only
+-- 'interest' - a message is removed once every consumer of the subject has acked it
+-- 'workqueue' - a message is removed as soon as any consumer acks it
+box.once('subject_config_v2', function()
+    local format = box.space.subject_config:format()
+    table.insert(format, {name = 'retention', type = 'string', is_nullable = true})
+    box.space.subject_config:format(format)
+
+    print('MiniToolStream: subject_config retention field added')
+end)
+
+-- Per-subject sequence
+-- Every message also gets a dense sequence within its subject (1, 2, 3, ...)
+-- so consumers can tell a gap in a subject from traffic on other subjects
+box.once('subject_sequence_v1', function()
+    -- subjects remembers the last allocated per-subject sequence
+    -- (kept when messages are deleted, so numbers are never reused)
+    local subjects_format = box.space.subjects:format()
+    table.insert(subjects_format, {name = 'last_subject_sequence', type = 'unsigned', is_nullable = true})
+    box.space.subjects:format(subjects_format)
+
+    -- Number already stored messages of every subject in stream order
+    local counters = {}
+    for _, tuple in box.space.message.index.subject_sequence:pairs() do
+        local subject = tuple[4]
+        counters[subject] = (counters[subject] or 0) + 1
+        box.space.message:update(tuple[1], {{'=', 6, counters[subject]}})
+    end
+    for subject, last in pairs(counters) do
+        box.space.subjects:update(subject, {{'=', 8, last}})
+    end
+
+    local message_format = box.space.message:format()
+    table.insert(message_format, {name = 'subject_seq', type = 'unsigned'})
+    box.space.message:format(message_format)
+
+    -- Secondary index: by subject + per-subject sequence (cursor by subject sequence)
+    box.space.message:create_index('subject_seq', {
+        parts = {'subject', 'subject_seq'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: per-subject sequence added')
+end)
+
+-- Space 5: scheduled_message
+-- Messages published with a future delivery time
+-- They are invisible to readers until the ingress scheduler moves them to the
+-- message space, where they get their global and per-subject sequences
+box.once('scheduled_message_v1', function()
+    local scheduled = box.schema.space.create('scheduled_message', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'schedule_id', type = 'unsigned'}, -- Sequence allocated at publish time (PK)
+            {name = 'subject', type = 'string'},       -- Topic/channel name
+            {name = 'headers', type = 'map'},          -- Message metadata
+            {name = 'object_name', type = 'string'},   -- MinIO object key
+            {name = 'create_at', type = 'unsigned'},   -- Unix timestamp of the publish
+            {name = 'deliver_at', type = 'unsigned'}   -- Unix timestamp when the message becomes visible
+        }
+    })
+
+    scheduled:create_index('primary', {
+        parts = {'schedule_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    -- Secondary index: by due time, ties broken by publish order
+    scheduled:create_index('deliver_at', {
+        parts = {'deliver_at', 'schedule_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    -- Secondary index: by subject
+    scheduled:create_index('subject', {
+        parts = {'subject', 'schedule_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: scheduled_message space created')
+end)
+
+-- Per-message expiry
+-- A publisher may limit the lifetime of a single message with the expires-at
+-- header; the time is copied to its own field so cleanup can use an index
+box.once('message_expiry_v1', function()
+    local format = box.space.message:format()
+    table.insert(format, {name = 'expires_at', type = 'unsigned', is_nullable = true})
+    box.space.message:format(format)
+
+    -- Secondary index: by expiry time (messages without expiry are not indexed)
+    box.space.message:create_index('expires_at', {
+        parts = {{field = 'expires_at', type = 'unsigned', is_nullable = true, exclude_null = true}},
+        if_not_exists = true,
+        unique = false,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: per-message expiry added')
+end)
+
+-- Inline payloads
+-- Small payloads may be stored in the tuple instead of MinIO; such messages
+-- have an empty object_name. The field is 'any' because binary call
+-- arguments arrive in Lua as strings
+box.once('inline_payload_v1', function()
+    local format = box.space.message:format()
+    table.insert(format, {name = 'payload', type = 'any', is_nullable = true})
+    box.space.message:format(format)
+
+    local scheduled_format = box.space.scheduled_message:format()
+    table.insert(scheduled_format, {name = 'payload', type = 'any', is_nullable = true})
+    box.space.scheduled_message:format(scheduled_format)
+
+    print('MiniToolStream: inline payloads added')
+end)
+
+-- Space 6: object_ref
+-- Reference counts of content-addressed payloads (object_name = sha256/<hex>)
+-- Several messages may point at one object; it is deleted with the last reference
+box.once('object_ref_v1', function()
+    local object_ref = box.schema.space.create('object_ref', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'object_name', type = 'string'},                      -- MinIO object key (PK)
+            {name = 'refs', type = 'unsigned'},                           -- Messages referencing the object
+            {name = 'stored', type = 'boolean'},                          -- Upload confirmed by a publisher
+            {name = 'released_at', type = 'unsigned', is_nullable = true} -- Set when the last reference is gone
+        }
+    })
+
+    object_ref:create_index('primary', {
+        parts = {'object_name'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: object_ref space created')
+end)
+
+-- Space 7: schema
+-- Versioned payload schemas of subjects (JSON Schema or protobuf descriptor set)
+-- Versions are numbered per subject (1, 2, 3, ...); ids are global and never reused
+box.once('schema_registry_v1', function()
+    box.schema.sequence.create('schema_id_seq', {if_not_exists = true})
+
+    local schema = box.schema.space.create('schema', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'id', type = 'unsigned'},           -- Global schema id (PK)
+            {name = 'subject', type = 'string'},        -- Topic/channel name
+            {name = 'version', type = 'unsigned'},      -- Version within the subject
+            {name = 'format', type = 'string'},         -- 'json' or 'protobuf'
+            {name = 'definition', type = 'any'},        -- Schema document or FileDescriptorSet (binary arrives as string)
+            {name = 'message_type', type = 'string'},   -- Protobuf message name, '' for JSON
+            {name = 'create_at', type = 'unsigned'}     -- Unix timestamp of registration
+        }
+    })
+
+    schema:create_index('primary', {
+        parts = {'id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    schema:create_index('subject_version', {
+        parts = {'subject', 'version'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    -- Space 8: schema_subject
+    -- Compatibility mode of a subject: 'backward' (default), 'forward', 'full' or 'none'
+    local schema_subject = box.schema.space.create('schema_subject', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'subject', type = 'string'},        -- Topic/channel name (PK)
+            {name = 'compatibility', type = 'string'},  -- Compatibility mode
+            {name = 'updated_at', type = 'unsigned'}    -- Unix timestamp of last change
+        }
+    })
+
+    schema_subject:create_index('primary', {
+        parts = {'subject'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: schema registry spaces created')
+end)
+
+-- Space 9: quota_bucket
+-- Token buckets of ingress and egress rate limits, shared by all replicas
+-- Space 10: client_usage
+-- Payload bytes stored per publishing client (publisher-id header)
+box.once('quota_v1', function()
+    local quota_bucket = box.schema.space.create('quota_bucket', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'key', type = 'string'},            -- Bucket name, e.g. publish:msgs:client:<id> (PK)
+            {name = 'tokens', type = 'number'},         -- Tokens left, negative while in debt
+            {name = 'updated_at', type = 'number'}      -- Time of last refill (Unix time, fractional)
+        }
+    })
+
+    quota_bucket:create_index('primary', {
+        parts = {'key'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    local client_usage = box.schema.space.create('client_usage', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'client_id', type = 'string'},      -- JWT client_id of the publisher (PK)
+            {name = 'stored_bytes', type = 'unsigned'}  -- Payload bytes of its stored messages
+        }
+    })
+
+    client_usage:create_index('primary', {
+        parts = {'client_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: quota spaces created')
+end)
+
+-- Durable consumer ownership
+-- A consumer belongs to the client that used it first; other clients may use
+-- it only when listed in allowed_clients. Consumers created before ownership
+-- have no owner and are claimed by their next authenticated client
+box.once('consumer_owner_v1', function()
+    local format = box.space.consumers:format()
+    table.insert(format, {name = 'owner', type = 'string', is_nullable = true})
+    table.insert(format, {name = 'allowed_clients', type = 'array', is_nullable = true})
+    box.space.consumers:format(format)
+
+    print('MiniToolStream: consumer ownership added')
+end)
+
+-- Token revocation
+-- A token is revoked by its jti, or with every other token of its client issued
+-- up to not_before. Entries are purged once the tokens they cover have expired
+box.once('revocation_v1', function()
+    local revoked_token = box.schema.space.create('revoked_token', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'jti', type = 'string'},            -- JWT ID of the revoked token (PK)
+            {name = 'client_id', type = 'string'},      -- JWT client_id, for listing
+            {name = 'expires_at', type = 'unsigned'},   -- Expiry of the token, purge time (Unix time)
+            {name = 'revoked_at', type = 'unsigned'},   -- Time of revocation (Unix time)
+            {name = 'reason', type = 'string'}
+        }
+    })
+
+    revoked_token:create_index('primary', {
+        parts = {'jti'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    revoked_token:create_index('expires_at', {
+        parts = {'expires_at'},
+        if_not_exists = true,
+        unique = false,
+        type = 'TREE'
+    })
+
+    local revoked_client = box.schema.space.create('revoked_client', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'client_id', type = 'string'},      -- JWT client_id (PK)
+            {name = 'not_before', type = 'unsigned'},   -- Tokens issued up to this time are revoked (Unix time)
+            {name = 'expires_at', type = 'unsigned'},   -- Expiry of the last revoked token, purge time (Unix time)
+            {name = 'revoked_at', type = 'unsigned'},   -- Time of revocation (Unix time)
+            {name = 'reason', type = 'string'}
+        }
+    })
+
+    revoked_client:create_index('primary', {
+        parts = {'client_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    revoked_client:create_index('expires_at', {
+        parts = {'expires_at'},
+        if_not_exists = true,
+        unique = false,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: revocation spaces created')
+end)
+
+box.once('api_keys_v1', function()
+    local api_key = box.schema.space.create('api_key', {
+        if_not_exists = true,
+        engine = 'memtx',
+        format = {
+            {name = 'key_id', type = 'string'},         -- Public part of the key (PK)
+            {name = 'hash', type = 'string'},           -- SHA-256 of the secret part, hex
+            {name = 'client_id', type = 'string'},      -- client_id of the exchanged tokens
+            {name = 'subjects', type = 'array'},        -- Subject patterns of the exchanged tokens
+            {name = 'permissions', type = 'array'},     -- Permissions of the exchanged tokens
+            {name = 'token_ttl', type = 'unsigned'},    -- Lifetime of the exchanged tokens, seconds
+            {name = 'created_at', type = 'unsigned'},   -- Creation time (Unix time)
+            {name = 'expires_at', type = 'unsigned'},   -- Expiry of the key (Unix time), 0 if it does not expire
+            {name = 'revoked_at', type = 'unsigned'},   -- Time of revocation (Unix time), 0 while active
+            {name = 'last_used_at', type = 'unsigned'}, -- Time of the last exchange (Unix time), 0 if never used
+            {name = 'description', type = 'string'}
+        }
+    })
+
+    api_key:create_index('primary', {
+        parts = {'key_id'},
+        if_not_exists = true,
+        unique = true,
+        type = 'TREE'
+    })
+
+    api_key:create_index('client_id', {
+        parts = {'client_id'},
+        if_not_exists = true,
+        unique = false,
+        type = 'TREE'
+    })
+
+    print('MiniToolStream: api_key space created')
+end)
+
 -- Global sequence counter (in-memory, atomically incremented)
 local global_sequence = 0
 
+-- Newest sequence given to a stored message, set in the inserting transaction
+-- Payload and schedule ids never become messages, so global_sequence can be ahead of it
+local last_message_sequence = 0
+
 -- Initialize global sequence from existing data
 -- This runs on EVERY start to restore sequence from persisted data
 local function init_global_sequence()
     local max_seq = box.space.message.index.primary:max()
     if max_seq ~= nil then
-        global_sequence = max_seq[1]
+        last_message_sequence = max_seq[1]
+    end
+    -- Subjects remember their last sequence after its message is deleted
+    for _, stats in box.space.subjects:pairs() do
+        last_message_sequence = math.max(last_message_sequence, stats[3])
+    end
+    global_sequence = last_message_sequence
+
+    -- Payload and schedule ids come from the same counter and name MinIO
+    -- objects, so they must not be handed out again either
+    local max_scheduled = box.space.scheduled_message.index.primary:max()
+    if max_scheduled ~= nil and max_scheduled[1] > global_sequence then
+        global_sequence = max_scheduled[1]
     end
     print('MiniToolStream: Global sequence initialized to ' .. global_sequence)
 end
@@ -122,43 +569,338 @@ end
 init_global_sequence()
 
 -- Function to get next global sequence (thread-safe)
+-- The counter also hands out payload and schedule ids
 function get_next_sequence()
     global_sequence = global_sequence + 1
     return global_sequence
 end
 
--- Function to insert a message with pre-allocated sequence
--- This allows caller to upload payload to MinIO BEFORE inserting metadata
--- @param sequence uint64 - pre-allocated sequence number
--- @param subject string - topic/channel name
--- @param headers table - map of headers (metadata)
--- @param object_name string - MinIO object key (already uploaded)
--- @return sequence number of the published message
-function insert_message(sequence, subject, headers, object_name)
-    local create_at = os.time()
+-- Payload size of a message as reported by the data-size header
+-- @param headers table - message headers
+-- @return number - payload size in bytes or 0
+local function payload_size(headers)
+    if type(headers) ~= 'table' then
+        return 0
+    end
+    return tonumber(headers['data-size']) or 0
+end
+
+-- Expiry time of a message as reported by the expires-at header
+-- @param headers table - message headers
+-- @return number - Unix timestamp or nil if the message does not expire
+local function message_expires_at(headers)
+    if type(headers) ~= 'table' then
+        return nil
+    end
+    return tonumber(headers['expires-at'])
+end
+
+-- Update stored bytes of the publishing client
+-- Must be called inside the same transaction as the insert or delete
+local function client_usage_on_change(headers, delta)
+    if type(headers) ~= 'table' or delta == 0 then
+        return
+    end
+    local client_id = headers['publisher-id']
+    if client_id == nil or client_id == '' then
+        return
+    end
+
+    local existing = box.space.client_usage:get(client_id)
+    local bytes = (existing ~= nil and existing[2] or 0) + delta
+    box.space.client_usage:replace({client_id, math.max(bytes, 0)})
+end
+
+-- Update subject statistics after a message was inserted
+-- Must be called inside the same transaction as the insert
+local function subject_stats_on_insert(subject, sequence, subject_seq, size, create_at)
+    local existing = box.space.subjects:get(subject)
+    if existing == nil then
+        box.space.subjects:insert({subject, sequence, sequence, 1, size, create_at, create_at, subject_seq})
+        return
+    end
+
+    local ops = {
+        {'=', 3, math.max(existing[3], sequence)},
+        {'+', 4, 1},
+        {'+', 5, size},
+        {'=', 7, math.max(existing[7], create_at)},
+        {'=', 8, subject_seq}
+    }
+    if existing[4] == 0 then
+        -- Subject was empty: the new message is also the oldest one
+        table.insert(ops, {'=', 2, sequence})
+        table.insert(ops, {'=', 6, create_at})
+    end
+    box.space.subjects:update(subject, ops)
+end
+
+-- Update subject statistics after a message was deleted
+-- Must be called inside the same transaction as the delete
+local function subject_stats_on_delete(tuple)
+    local subject = tuple[4]
+    local existing = box.space.subjects:get(subject)
+    if existing == nil then
+        return
+    end
+
+    local count = existing[4] > 0 and existing[4] - 1 or 0
+    local bytes = existing[5] - math.min(existing[5], payload_size(tuple[2]))
+    local first_sequence = existing[2]
+    local first_publish_at = existing[6]
+
+    if count == 0 then
+        first_sequence = 0
+        first_publish_at = 0
+    elseif tuple[1] == first_sequence then
+        -- Oldest message removed: move to the next stored one
+        local next_tuple = box.space.message.index.subject_sequence:select(
+            {subject, tuple[1]}, {iterator = 'GT', limit = 1})[1]
+        if next_tuple ~= nil and next_tuple[4] == subject then
+            first_sequence = next_tuple[1]
+            first_publish_at = next_tuple[5]
+        end
+    end
+
+    -- last_sequence is kept so the subject remembers how far it got
+    box.space.subjects:update(subject, {
+        {'=', 2, first_sequence},
+        {'=', 4, count},
+        {'=', 5, bytes},
+        {'=', 6, first_publish_at}
+    })
+end
+
+-- Seconds a released content-addressed object stays reserved for its pending
+-- MinIO delete; publishers store such content under a per-message name meanwhile
+local object_release_grace = 60
+
+-- Whether an object name is content-addressed and reference counted
+local function is_shared_object(object_name)
+    if type(object_name) ~= 'string' then
+        return false
+    end
+    -- Objects are shared within a tenant, whose names are $TENANT.<tenant>.sha256/<hex>
+    local name = object_name:match('^%$TENANT%.[^.]+%.(.*)$') or object_name
+    -- Message keys of a subject named "sha256" share the prefix but never have a full digest
+    return #name == 71 and name:sub(1, 7) == 'sha256/'
+end
+
+-- Drop one reference of a content-addressed object
+-- Must be called inside a transaction
+-- @return boolean - true if it was the last reference and the object must be deleted
+local function release_object(object_name)
+    local row = box.space.object_ref:get(object_name)
+    if row == nil or row[2] == 0 then
+        return false
+    end
+
+    if row[2] > 1 then
+        box.space.object_ref:update(object_name, {{'-', 2, 1}})
+        return false
+    end
+
+    box.space.object_ref:update(object_name, {{'=', 2, 0}, {'=', 4, os.time()}})
+    return true
+end
 
-    -- Normalize headers: convert array to map if needed
-    local normalized_headers
+-- Function to delete a single message and keep subject statistics in sync
+-- @param tuple - message tuple to delete
+-- @return table {sequence, subject, object_name} describing the deleted message;
+--         object_name is empty if the payload is still referenced by other messages
+function delete_message(tuple)
+    local object_name = tuple[3]
+    box.atomic(function()
+        box.space.message:delete(tuple[1])
+        subject_stats_on_delete(tuple)
+        client_usage_on_change(tuple[2], -payload_size(tuple[2]))
+        if is_shared_object(object_name) and not release_object(object_name) then
+            object_name = ''
+        end
+    end)
+
+    return {
+        sequence = tuple[1],
+        subject = tuple[4],
+        object_name = object_name
+    }
+end
+
+-- Retention mode of a subject ('limits' unless declared otherwise)
+-- @param subject string - topic name
+-- @return string - 'limits', 'interest' or 'workqueue'
+local function subject_retention(subject)
+    local cfg = box.space.subject_config:get(subject)
+    if cfg == nil or cfg[8] == nil then
+        return 'limits'
+    end
+    return cfg[8]
+end
+
+-- Delete all messages of a subject up to a sequence (inclusive)
+-- @param subject string - topic name
+-- @param sequence uint64 - last sequence to delete
+-- @return array of {sequence, subject, object_name} for deleted messages
+local function delete_subject_messages_up_to(subject, sequence)
+    local deleted = {}
+    while true do
+        local oldest = box.space.message.index.subject_sequence:min({subject})
+        if oldest == nil or oldest[1] > sequence then
+            break
+        end
+        table.insert(deleted, delete_message(oldest))
+    end
+    return deleted
+end
+
+-- Check whether publishing a payload of given size would violate subject limits
+-- Only limits that reject the publish are reported: max_msg_size always,
+-- max_msgs/max_bytes when the discard policy is 'new'
+-- @param subject string - topic name
+-- @param size number - payload size in bytes
+-- @return string - violation reason or nil if the publish is allowed
+-- @return boolean - true if the publish will push a discard 'old' subject over its limits
+local function subject_limit_violation(subject, size)
+    local cfg = box.space.subject_config:get(subject)
+    if cfg == nil then
+        return nil, false
+    end
+
+    local max_msgs, max_bytes, max_msg_size, discard = cfg[2], cfg[3], cfg[5], cfg[6]
+
+    if max_msg_size > 0 and size > max_msg_size then
+        return string.format('message size %d exceeds max_msg_size %d', size, max_msg_size), false
+    end
+
+    local count, bytes = 0, 0
+    local stats = box.space.subjects:get(subject)
+    if stats ~= nil then
+        count, bytes = stats[4], stats[5]
+    end
+
+    local over_msgs = max_msgs > 0 and count + 1 > max_msgs
+    local over_bytes = max_bytes > 0 and bytes + size > max_bytes
+
+    if discard == 'new' then
+        if over_msgs then
+            return string.format('subject has reached max_msgs %d', max_msgs), false
+        end
+        if over_bytes then
+            return string.format('subject would exceed max_bytes %d', max_bytes), false
+        end
+        return nil, false
+    end
+
+    return nil, over_msgs or over_bytes
+end
+
+-- Check optimistic-concurrency conditions of a publish
+-- @param subject string - topic name
+-- @param expected table - {last_sequence, last_subject_sequence}, nil fields are not checked
+-- @return string - conflict description or nil if all conditions hold
+local function expected_sequence_conflict(subject, expected)
+    if expected == nil then
+        return nil
+    end
+
+    if expected.last_sequence ~= nil then
+        local actual = last_message_sequence
+        if actual ~= expected.last_sequence then
+            return string.format('expected last sequence %d, actual %d',
+                expected.last_sequence, actual)
+        end
+    end
+
+    if expected.last_subject_sequence ~= nil then
+        local actual = get_latest_sequence_for_subject(subject)
+        if actual ~= expected.last_subject_sequence then
+            return string.format('expected last subject sequence %d, actual %d',
+                expected.last_subject_sequence, actual)
+        end
+    end
+
+    return nil
+end
+
+-- Normalize headers: convert array to map if needed
+-- @param headers table - map of headers (metadata) or nil
+-- @return table - map of headers
+local function normalize_headers(headers)
     if headers == nil or (type(headers) == 'table' and #headers == 0 and next(headers) == nil) then
         -- Empty or nil - create an empty map explicitly
-        normalized_headers = {}
+        return {}
     elseif type(headers) == 'table' and #headers > 0 then
         -- It's an array, convert to map (should not happen but handle it)
-        normalized_headers = {}
-    else
-        -- It's already a proper map
-        normalized_headers = headers
+        return {}
     end
+    -- It's already a proper map
+    return headers
+end
+
+-- Store a message and update subject statistics
+-- Must be called inside a transaction
+-- @return uint64 - per-subject sequence of the message
+local function store_message(sequence, subject, headers, object_name, create_at, payload)
+    -- Per-subject sequence is allocated in commit order
+    local stats = box.space.subjects:get(subject)
+    local subject_seq = (stats ~= nil and stats[8] or 0) + 1
 
     box.space.message:insert({
         sequence,
-        normalized_headers,
+        headers,
         object_name,
         subject,
-        create_at
+        create_at,
+        subject_seq,
+        message_expires_at(headers) or box.NULL,
+        payload or box.NULL
     })
+    subject_stats_on_insert(subject, sequence, subject_seq, payload_size(headers), create_at)
+    client_usage_on_change(headers, payload_size(headers))
+    last_message_sequence = sequence
 
-    return sequence
+    return subject_seq
+end
+
+-- Function to insert a message after its payload is stored
+-- The caller uploads the payload to MinIO BEFORE inserting metadata, under a
+-- name built from an id taken with get_next_sequence; the global and
+-- per-subject sequences are both assigned here, so they follow commit order
+-- @param subject string - topic/channel name
+-- @param headers table - map of headers (metadata)
+-- @param object_name string - MinIO object key (already uploaded), empty for inline payloads
+-- @param expected table - optional optimistic-concurrency conditions:
+--        {last_sequence = uint64, last_subject_sequence = uint64}
+--        last_sequence is the newest sequence published across all subjects, deleting
+--        that message does not change it,
+--        last_subject_sequence is the newest stored sequence of this subject (0 = subject is empty)
+-- @param payload string - optional payload stored in the tuple instead of MinIO
+-- @return sequence number of the published message and its per-subject sequence
+function insert_message(subject, headers, object_name, expected, payload)
+    local create_at = os.time()
+    local normalized_headers = normalize_headers(headers)
+
+    -- Re-check limits here: this is the authoritative check, the one done
+    -- by check_publish_limits before upload can race with other publishers
+    local violation = subject_limit_violation(subject, payload_size(normalized_headers))
+    if violation ~= nil then
+        error('subject limit exceeded: ' .. violation)
+    end
+
+    -- Nothing yields between this check and the insert, so concurrent
+    -- writers cannot slip in between
+    local conflict = expected_sequence_conflict(subject, expected)
+    if conflict ~= nil then
+        error('sequence conflict: ' .. conflict)
+    end
+
+    local sequence, subject_seq
+    box.atomic(function()
+        sequence = get_next_sequence()
+        subject_seq = store_message(sequence, subject, normalized_headers, object_name, create_at, payload)
+    end)
+
+    return sequence, subject_seq
 end
 
 -- Function to publish a message (legacy, for backward compatibility)
@@ -166,9 +908,180 @@ end
 -- @param headers table - map of headers (metadata)
 -- @return sequence number of the published message
 function publish_message(subject, headers)
-    local sequence = get_next_sequence()
-    local object_name = subject .. "_" .. sequence
-    return insert_message(sequence, subject, headers, object_name)
+    local object_name = subject .. "_" .. get_next_sequence()
+    return insert_message(subject, headers, object_name)
+end
+
+-- Function to take a reference to a content-addressed object before publishing
+-- @param object_name string - sha256/<hex> object key
+-- @return string - 'stored' if the object is already in MinIO,
+--         'upload' if the caller must upload it (uploads of equal content are idempotent),
+--         'busy' if the object is being deleted; the caller must use a per-message name
+function acquire_object(object_name)
+    local result
+    box.atomic(function()
+        local row = box.space.object_ref:get(object_name)
+        if row ~= nil and row[4] ~= nil and row[4] + object_release_grace > os.time() then
+            result = 'busy'
+        elseif row == nil or row[4] ~= nil then
+            box.space.object_ref:replace({object_name, 1, false, box.NULL})
+            result = 'upload'
+        else
+            box.space.object_ref:update(object_name, {{'+', 2, 1}})
+            result = row[3] and 'stored' or 'upload'
+        end
+    end)
+    return result
+end
+
+-- Function to confirm that a content-addressed object was uploaded
+-- @param object_name string - sha256/<hex> object key
+function mark_object_stored(object_name)
+    local row = box.space.object_ref:get(object_name)
+    if row ~= nil and row[4] == nil then
+        box.space.object_ref:update(object_name, {{'=', 3, true}})
+    end
+end
+
+-- Function to give back a reference taken by acquire_object when the publish failed
+-- @param object_name string - sha256/<hex> object key
+-- @return boolean - true if it was the last reference and the object must be deleted
+function release_object_ref(object_name)
+    local last
+    box.atomic(function()
+        last = release_object(object_name)
+    end)
+    return last
+end
+
+-- Function to get the number of messages referencing a content-addressed object
+-- @param object_name string - sha256/<hex> object key
+-- @return uint64 - reference count, 0 if unknown
+function get_object_refs(object_name)
+    local row = box.space.object_ref:get(object_name)
+    if row == nil then
+        return 0
+    end
+    return row[2]
+end
+
+-- Function to report how much of memtx_memory is in use
+-- Publishers stop storing payloads inline when it gets close to 1
+-- @return number - used fraction of the memtx quota (0..1)
+function get_memtx_usage()
+    local slab = box.slab.info()
+    if slab.quota_size == 0 then
+        return 0
+    end
+    return slab.arena_used / slab.quota_size
+end
+
+-- Function to store a message for delayed delivery
+-- The message stays invisible until deliver_at; then the ingress scheduler assigns it
+-- a new global sequence, so it is ordered after everything already published
+-- @param sequence uint64 - pre-allocated sequence, becomes the schedule id
+-- @param subject string - topic/channel name
+-- @param headers table - map of headers (metadata)
+-- @param object_name string - MinIO object key (already uploaded), empty for inline payloads
+-- @param deliver_at number - Unix timestamp when the message becomes visible
+-- @param payload string - optional payload stored in the tuple instead of MinIO
+-- @return uint64 - schedule id
+function schedule_message(sequence, subject, headers, object_name, deliver_at, payload)
+    local normalized_headers = normalize_headers(headers)
+
+    -- Reject early what would be rejected at delivery anyway
+    local violation = subject_limit_violation(subject, payload_size(normalized_headers))
+    if violation ~= nil then
+        error('subject limit exceeded: ' .. violation)
+    end
+
+    box.space.scheduled_message:insert({
+        sequence,
+        subject,
+        normalized_headers,
+        object_name,
+        os.time(),
+        deliver_at,
+        payload or box.NULL
+    })
+
+    return sequence
+end
+
+-- Function to deliver scheduled messages that are due
+-- Messages are delivered in (deliver_at, schedule_id) order: messages due at
+-- the same second keep their publish order
+-- A message that no longer fits the subject limits or expired while waiting is
+-- dropped, a delivery that pushes a discard 'old' subject over its limits trims it.
+-- Ingress calls this periodically and deletes the returned MinIO objects
+-- @param batch_size number - max messages to deliver in one call (default 1000)
+-- @return array of {schedule_id, sequence, subject} for delivered messages,
+--         array of {sequence, subject, object_name} for removed messages; sequence is 0
+--         for dropped ones and object_name empty if the payload is inline or still referenced
+function deliver_scheduled_messages(batch_size)
+    batch_size = batch_size or 1000
+    local now = os.time()
+
+    -- Collect first: the loop below modifies the index
+    local due = {}
+    for _, tuple in box.space.scheduled_message.index.deliver_at:pairs() do
+        if tuple[6] > now or #due >= batch_size then
+            break
+        end
+        table.insert(due, tuple)
+    end
+
+    local delivered, deleted = {}, {}
+    for _, due_tuple in ipairs(due) do
+        local subject, object_name = due_tuple[2], due_tuple[4]
+        local violation, trim, sequence
+
+        -- Every ingress instance delivers, commits yield, so another one may have taken it
+        box.atomic(function()
+            local tuple = box.space.scheduled_message:get(due_tuple[1])
+            if tuple == nil then
+                return
+            end
+            box.space.scheduled_message:delete(tuple[1])
+
+            violation, trim = subject_limit_violation(subject, payload_size(tuple[3]))
+            local expires_at = message_expires_at(tuple[3])
+            if expires_at ~= nil and expires_at <= now then
+                violation = 'expired before delivery'
+            end
+
+            if violation ~= nil then
+                if is_shared_object(object_name) and not release_object(object_name) then
+                    object_name = ''
+                end
+                return
+            end
+            sequence = get_next_sequence()
+            store_message(sequence, subject, tuple[3], object_name, now, tuple[7])
+        end)
+
+        if violation ~= nil then
+            table.insert(deleted, {sequence = 0, subject = subject, object_name = object_name})
+            print(string.format('MiniToolStream: dropped scheduled message %d of subject "%s": %s',
+                due_tuple[1], subject, violation))
+        elseif sequence ~= nil then
+            table.insert(delivered, {schedule_id = due_tuple[1], sequence = sequence, subject = subject})
+            if trim then
+                for _, info in ipairs(enforce_subject_limits(subject)) do
+                    table.insert(deleted, info)
+                end
+            end
+        end
+    end
+
+    return delivered, deleted
+end
+
+-- Function to count messages of a subject waiting for delivery
+-- @param subject string - topic name
+-- @return uint64 - number of scheduled messages
+function get_scheduled_message_count(subject)
+    return box.space.scheduled_message.index.subject:count({subject})
 end
 
 -- Function to get message by sequence
@@ -181,7 +1094,7 @@ end
 -- Function to get message by sequence with fields decoded
 -- Returns message as a table with all fields named
 -- @param sequence uint64 - message sequence number
--- @return table {sequence, headers, object_name, subject, create_at} or nil
+-- @return table {sequence, headers, object_name, subject, create_at, subject_sequence, expires_at, payload} or nil
 function get_message_by_sequence_decoded(sequence)
     local tuple = box.space.message:get(sequence)
     if tuple == nil then
@@ -193,7 +1106,10 @@ function get_message_by_sequence_decoded(sequence)
         headers = tuple[2],
         object_name = tuple[3],
         subject = tuple[4],
-        create_at = tuple[5]
+        create_at = tuple[5],
+        subject_sequence = tuple[6],
+        expires_at = tuple[7],
+        payload = tuple[8]
     }
 end
 
@@ -215,86 +1131,376 @@ function get_messages_by_subject(subject, start_sequence, limit)
             break
         end
 
-        table.insert(messages, tuple)
-        count = count + 1
+        table.insert(messages, tuple)
+        count = count + 1
+    end
+
+    return messages
+end
+
+-- Function to read a bounded range of a subject (stateless, no consumer cursor)
+-- @param subject string - topic name
+-- @param from_sequence uint64 - first sequence (inclusive)
+-- @param to_sequence uint64 - last sequence (inclusive), 0 means unbounded
+-- @param limit number - max messages to return
+-- @return array of tuples
+function get_messages_range(subject, from_sequence, to_sequence, limit)
+    local messages = {}
+    local count = 0
+
+    for _, tuple in box.space.message.index.subject_sequence:pairs({subject, from_sequence}, {iterator = 'GE'}) do
+        if tuple[4] ~= subject then
+            break
+        end
+
+        if to_sequence > 0 and tuple[1] > to_sequence then
+            break
+        end
+
+        if count >= limit then
+            break
+        end
+
+        table.insert(messages, tuple)
+        count = count + 1
+    end
+
+    return messages
+end
+
+-- Function to get messages of a subject by per-subject sequence
+-- @param subject string - topic name
+-- @param start_subject_sequence uint64 - first per-subject sequence (inclusive)
+-- @param limit number - max messages to return
+-- @return array of tuples ordered by per-subject sequence
+function get_messages_by_subject_sequence(subject, start_subject_sequence, limit)
+    local messages = {}
+
+    for _, tuple in box.space.message.index.subject_seq:pairs({subject, start_subject_sequence}, {iterator = 'GE'}) do
+        if tuple[4] ~= subject or #messages >= limit then
+            break
+        end
+        table.insert(messages, tuple)
+    end
+
+    return messages
+end
+
+-- Function to translate a per-subject sequence to the global sequence
+-- @param subject string - topic name
+-- @param subject_sequence uint64 - per-subject sequence
+-- @return uint64 - global sequence or 0 if the message does not exist
+function resolve_subject_sequence(subject, subject_sequence)
+    local tuple = box.space.message.index.subject_seq:get({subject, subject_sequence})
+    if tuple == nil then
+        return 0
+    end
+    return tuple[1]
+end
+
+-- Function to get latest sequence for a subject
+-- @param subject string - topic name
+-- @return uint64 - latest sequence or 0
+function get_latest_sequence_for_subject(subject)
+    local max_tuple = box.space.message.index.subject_sequence:max({subject})
+    if max_tuple == nil or max_tuple[4] ~= subject then
+        return 0
+    end
+    return max_tuple[1]
+end
+
+-- Function to update consumer position
+-- @param durable_name string - consumer group name
+-- @param subject string - topic name
+-- @param last_sequence uint64 - last read sequence
+function update_consumer_position(durable_name, subject, last_sequence)
+    local key = {durable_name, subject}
+    local existing = box.space.consumers:get(key)
+
+    if existing == nil then
+        box.space.consumers:insert({durable_name, subject, last_sequence})
+    else
+        box.space.consumers:update(key, {{'=', 3, last_sequence}})
+    end
+
+    return true
+end
+
+-- Tenant part of a stored subject or durable name, '' for the default tenant
+local function name_tenant(name)
+    return name:match('^%$TENANT%.([^.]+)%.') or ''
+end
+
+-- Function to acknowledge messages of a durable consumer
+-- Moves the consumer position and applies the subject retention mode:
+-- in 'workqueue' mode everything up to the acked sequence is removed,
+-- in 'interest' mode everything up to the slowest consumer position is removed.
+-- Consumers of other tenants only read an imported subject and do not hold its messages
+-- @param durable_name string - consumer group name
+-- @param subject string - topic name
+-- @param sequence uint64 - acked sequence
+-- @return array of {sequence, subject, object_name} for deleted messages
+function ack_message(durable_name, subject, sequence)
+    update_consumer_position(durable_name, subject, sequence)
+
+    local retention = subject_retention(subject)
+    if retention == 'workqueue' then
+        return delete_subject_messages_up_to(subject, sequence)
+    end
+
+    if retention == 'interest' then
+        local floor = nil
+        local tenant = name_tenant(subject)
+        for _, consumer in box.space.consumers.index.subject:pairs(subject) do
+            if name_tenant(consumer[1]) == tenant and (floor == nil or consumer[3] < floor) then
+                floor = consumer[3]
+            end
+        end
+        if floor ~= nil then
+            return delete_subject_messages_up_to(subject, floor)
+        end
+    end
+
+    return {}
+end
+
+-- Function to get consumer position
+-- @param durable_name string - consumer group name
+-- @param subject string - topic name
+-- @return uint64 - last read sequence or 0
+function get_consumer_position(durable_name, subject)
+    local tuple = box.space.consumers:get({durable_name, subject})
+    if tuple == nil then
+        return 0
+    end
+    return tuple[3]
+end
+
+-- Function to check, and on first use record, the owner of a durable consumer
+-- A consumer that does not exist yet is created at position 0 and owned by client_id
+-- @param durable_name string - consumer group name
+-- @param subject string - topic name
+-- @param client_id string - JWT client_id of the caller
+-- @return allowed boolean, owner string
+function claim_consumer(durable_name, subject, client_id)
+    local key = {durable_name, subject}
+    local tuple = box.space.consumers:get(key)
+
+    if tuple == nil then
+        box.space.consumers:insert({durable_name, subject, 0, client_id})
+        return true, client_id
+    end
+
+    local owner = tuple[4]
+    if owner == nil then
+        box.space.consumers:update(key, {{'=', 4, client_id}})
+        return true, client_id
+    end
+    if owner == client_id then
+        return true, owner
+    end
+    for _, allowed in ipairs(tuple[5] or {}) do
+        if allowed == client_id then
+            return true, owner
+        end
+    end
+    return false, owner
+end
+
+-- Function to hand a durable consumer over to another owner (administrative)
+-- @param durable_name string - consumer group name
+-- @param subject string - topic name
+-- @param owner string - JWT client_id of the new owner
+-- @param allowed_clients array - other client IDs allowed to use the consumer
+-- @return true, or nil and an error message if the consumer does not exist
+function transfer_consumer(durable_name, subject, owner, allowed_clients)
+    local key = {durable_name, subject}
+    if box.space.consumers:get(key) == nil then
+        return nil, 'consumer not found'
+    end
+    box.space.consumers:update(key, {{'=', 4, owner}, {'=', 5, allowed_clients or {}}})
+    return true
+end
+
+-- Function to get all consumers for a subject
+-- @param subject string - topic name
+-- @return array of tuples
+function get_consumers_by_subject(subject)
+    local result = {}
+    for _, tuple in box.space.consumers.index.subject:pairs(subject) do
+        table.insert(result, tuple)
+    end
+    return result
+end
+
+-- Function to revoke a single token
+-- @param jti string - JWT ID of the token
+-- @param client_id string - JWT client_id of the token
+-- @param expires_at number - expiry of the token (Unix time), the entry is purged after it
+-- @param reason string - free-form note for list_revocations
+-- @return true
+function revoke_token(jti, client_id, expires_at, reason)
+    box.space.revoked_token:replace({jti, client_id or '', expires_at, os.time(), reason or ''})
+    return true
+end
+
+-- Function to revoke every token of a client issued up to not_before
+-- A later revocation of the same client extends the earlier one
+-- @param client_id string - JWT client_id
+-- @param not_before number - tokens issued up to this time are revoked (Unix time)
+-- @param expires_at number - expiry of the longest-lived revoked token (Unix time)
+-- @param reason string - free-form note for list_revocations
+-- @return true
+function revoke_client(client_id, not_before, expires_at, reason)
+    local tuple = box.space.revoked_client:get(client_id)
+    if tuple ~= nil then
+        not_before = math.max(not_before, tuple[2])
+        expires_at = math.max(expires_at, tuple[3])
+    end
+    box.space.revoked_client:replace({client_id, not_before, expires_at, os.time(), reason or ''})
+    return true
+end
+
+-- Function to check whether a token is revoked
+-- @param jti string - JWT ID of the token, may be empty
+-- @param client_id string - JWT client_id of the token
+-- @return token_revoked boolean, client_not_before number (0 if the client is not revoked)
+function check_revocation(jti, client_id)
+    local token_revoked = false
+    if jti ~= nil and jti ~= '' then
+        token_revoked = box.space.revoked_token:get(jti) ~= nil
     end
 
-    return messages
+    local not_before = 0
+    local tuple = box.space.revoked_client:get(client_id or '')
+    if tuple ~= nil then
+        not_before = tuple[2]
+    end
+    return token_revoked, not_before
 end
 
--- Function to get latest sequence for a subject
--- @param subject string - topic name
--- @return uint64 - latest sequence or 0
-function get_latest_sequence_for_subject(subject)
-    local max_tuple = box.space.message.index.subject_sequence:max({subject})
-    if max_tuple == nil or max_tuple[4] ~= subject then
-        return 0
+-- Function to list revocations that are still in effect
+-- @return table {tokens = array of revoked_token tuples, clients = array of revoked_client tuples}
+function list_revocations()
+    local result = {tokens = {}, clients = {}}
+    for _, tuple in box.space.revoked_token:pairs() do
+        table.insert(result.tokens, tuple)
     end
-    return max_tuple[1]
+    for _, tuple in box.space.revoked_client:pairs() do
+        table.insert(result.clients, tuple)
+    end
+    return result
 end
 
--- Function to update consumer position
--- @param durable_name string - consumer group name
--- @param subject string - topic name
--- @param last_sequence uint64 - last read sequence
-function update_consumer_position(durable_name, subject, last_sequence)
-    local key = {durable_name, subject}
-    local existing = box.space.consumers:get(key)
-
-    if existing == nil then
-        box.space.consumers:insert({durable_name, subject, last_sequence})
-    else
-        box.space.consumers:update(key, {{'=', 3, last_sequence}})
+-- Function to store a new API key
+-- @param key_id string - public part of the key
+-- @param hash string - SHA-256 of the secret part, hex
+-- @param client_id string - client_id of the exchanged tokens
+-- @param subjects table - subject patterns of the exchanged tokens
+-- @param permissions table - permissions of the exchanged tokens
+-- @param token_ttl number - lifetime of the exchanged tokens, seconds
+-- @param expires_at number - expiry of the key (Unix time), 0 if it does not expire
+-- @param description string - free-form note for list_api_keys
+-- @return tuple of the key
+function create_api_key(key_id, hash, client_id, subjects, permissions, token_ttl, expires_at, description)
+    if box.space.api_key:get(key_id) ~= nil then
+        error('api key ' .. key_id .. ' already exists')
     end
+    return box.space.api_key:insert({
+        key_id, hash, client_id, subjects, permissions, token_ttl,
+        os.time(), expires_at or 0, 0, 0, description or ''
+    })
+end
 
-    return true
+-- Function to get an API key
+-- @param key_id string - public part of the key
+-- @return tuple or nil
+function get_api_key(key_id)
+    return box.space.api_key:get(key_id)
 end
 
--- Function to get consumer position
--- @param durable_name string - consumer group name
--- @param subject string - topic name
--- @return uint64 - last read sequence or 0
-function get_consumer_position(durable_name, subject)
-    local tuple = box.space.consumers:get({durable_name, subject})
-    if tuple == nil then
-        return 0
-    end
-    return tuple[3]
+-- Function to record a successful exchange of an API key
+-- @param key_id string - public part of the key
+-- @return true
+function touch_api_key(key_id)
+    box.space.api_key:update(key_id, {{'=', 'last_used_at', os.time()}})
+    return true
 end
 
--- Function to get all consumers for a subject
--- @param subject string - topic name
+-- Function to list API keys, optionally of one client
+-- @param client_id string - client_id to filter by, empty for all keys
 -- @return array of tuples
-function get_consumers_by_subject(subject)
+function list_api_keys(client_id)
     local result = {}
-    for _, tuple in box.space.consumers.index.subject:pairs(subject) do
+    local iter
+    if client_id ~= nil and client_id ~= '' then
+        iter = box.space.api_key.index.client_id:pairs(client_id)
+    else
+        iter = box.space.api_key:pairs()
+    end
+    for _, tuple in iter do
         table.insert(result, tuple)
     end
     return result
 end
 
--- Function to delete old messages (TTL cleanup)
--- @param ttl_seconds number - time to live in seconds
--- @return deleted_count, array of deleted message info
-function delete_old_messages(ttl_seconds)
-    local current_time = os.time()
-    local cutoff_time = current_time - ttl_seconds
-    local deleted_count = 0
-    local deleted_messages = {}
+-- Function to revoke an API key, tokens already exchanged stay valid until they expire
+-- @param key_id string - public part of the key
+-- @return true if the key exists
+function revoke_api_key(key_id)
+    local tuple = box.space.api_key:get(key_id)
+    if tuple == nil then
+        return false
+    end
+    if tuple[9] == 0 then
+        box.space.api_key:update(key_id, {{'=', 'revoked_at', os.time()}})
+    end
+    return true
+end
 
-    for _, tuple in box.space.message.index.create_at:pairs() do
-        if tuple[5] < cutoff_time then
-            table.insert(deleted_messages, {
-                sequence = tuple[1],
-                subject = tuple[4],
-                object_name = tuple[3]
-            })
-            box.space.message:delete(tuple[1])
-            deleted_count = deleted_count + 1
+-- Function to delete revocations whose tokens have expired anyway
+-- @return number of deleted entries
+function purge_revocations()
+    local now = os.time()
+    local deleted = 0
+
+    for _, space in ipairs({box.space.revoked_token, box.space.revoked_client}) do
+        local expired = {}
+        for _, tuple in space.index.expires_at:pairs(now, {iterator = 'LT'}) do
+            table.insert(expired, tuple[1])
+        end
+        for _, key in ipairs(expired) do
+            space:delete(key)
+            deleted = deleted + 1
+        end
+    end
+
+    return deleted
+end
+
+-- Function to delete messages whose own expiry time has passed
+-- Only messages published with an expires-at header are considered;
+-- the caller is responsible for deleting the returned MinIO objects
+-- @param batch_size number - max messages to delete in one call (default 1000)
+-- @return array of {sequence, subject, object_name} for deleted messages
+function delete_expired_messages(batch_size)
+    batch_size = batch_size or 1000
+    local now = os.time()
+
+    -- Collect first: deleting while iterating would invalidate the iterator
+    local expired = {}
+    for _, tuple in box.space.message.index.expires_at:pairs() do
+        if tuple[7] > now or #expired >= batch_size then
+            break
         end
+        table.insert(expired, tuple)
     end
 
-    return deleted_count, deleted_messages
+    local deleted = {}
+    for _, tuple in ipairs(expired) do
+        table.insert(deleted, delete_message(tuple))
+    end
+    return deleted
 end
 
 
@@ -348,178 +1554,544 @@ end
 -- @param subject string - topic name
 -- @return uint64 - total message count for subject
 function get_subject_message_count(subject)
-    local count = 0
-    for _, _ in box.space.message.index.subject:pairs(subject) do
-        count = count + 1
+    local info = box.space.subjects:get(subject)
+    if info == nil then
+        return 0
     end
-    return count
+    return info[4]
 end
 
--- Global TTL configuration
-local ttl_config = {
-    enabled = false,
-    default_ttl = 86400,  -- 24 hours in seconds
-    check_interval = 3600, -- 1 hour in seconds
-    channels = {}  -- Map of channel -> ttl_seconds
-}
+-- Match a subject against a pattern
+-- Same semantics as JWT subject patterns: "*" matches everything,
+-- "images.*" matches "images" and everything below it, anything else is exact
+-- @param pattern string - subject pattern ('' matches everything)
+-- @param subject string - subject name
+-- @return boolean
+local function match_subject_pattern(pattern, subject)
+    if pattern == nil or pattern == '' or pattern == '*' then
+        return true
+    end
+    if pattern == subject then
+        return true
+    end
+    if pattern:sub(-2) == '.*' then
+        local prefix = pattern:sub(1, -3)
+        return subject == prefix or subject:sub(1, #prefix + 1) == prefix .. '.'
+    end
+    return false
+end
 
-local ttl_fiber = nil
+-- Convert a subjects tuple to a named table
+local function subject_info(tuple)
+    return {
+        subject = tuple[1],
+        first_sequence = tuple[2],
+        last_sequence = tuple[3],
+        message_count = tuple[4],
+        total_bytes = tuple[5],
+        first_publish_at = tuple[6],
+        last_publish_at = tuple[7],
+        last_subject_sequence = tuple[8] or 0
+    }
+end
 
--- Function to update TTL configuration
--- @param config table - TTL configuration {enabled, default_ttl, check_interval, channels}
-function configure_ttl(config)
-    if config.enabled ~= nil then
-        ttl_config.enabled = config.enabled
+-- Function to get statistics of a single subject
+-- @param subject string - topic name
+-- @return table {subject, first_sequence, last_sequence, message_count, total_bytes,
+--                first_publish_at, last_publish_at} or nil
+function get_subject_info(subject)
+    local tuple = box.space.subjects:get(subject)
+    if tuple == nil then
+        return nil
     end
-    if config.default_ttl then
-        ttl_config.default_ttl = config.default_ttl
+    return subject_info(tuple)
+end
+
+-- Function to list subjects page by page in name order
+-- @param pattern string - subject pattern filter ('' or '*' for all)
+-- @param after string - return subjects strictly after this name ('' to start from the beginning)
+-- @param limit number - max subjects to return
+-- @return table {subjects = array of subject info, next = cursor for the next page or ''}
+function list_subjects(pattern, after, limit)
+    limit = limit or 100
+    local result = {}
+    local last = ''
+    local iterator = 'GT'
+    if after == nil or after == '' then
+        iterator = 'ALL'
+        after = nil
     end
-    if config.check_interval then
-        ttl_config.check_interval = config.check_interval
+
+    for _, tuple in box.space.subjects.index.primary:pairs(after, {iterator = iterator}) do
+        if #result >= limit then
+            return {subjects = result, next = last}
+        end
+        if match_subject_pattern(pattern, tuple[1]) then
+            table.insert(result, subject_info(tuple))
+            last = tuple[1]
+        end
     end
-    if config.channels then
-        ttl_config.channels = config.channels
+
+    return {subjects = result, next = ''}
+end
+
+-- Convert a subject_config tuple to a named table
+local function subject_config_info(tuple)
+    return {
+        subject = tuple[1],
+        max_msgs = tuple[2],
+        max_bytes = tuple[3],
+        max_age = tuple[4],
+        max_msg_size = tuple[5],
+        discard = tuple[6],
+        updated_at = tuple[7],
+        retention = tuple[8] or 'limits'
+    }
+end
+
+-- Function to declare a subject or update its limits
+-- @param subject string - topic name
+-- @param config table - {max_msgs, max_bytes, max_age, max_msg_size, discard, retention},
+--                        missing limits mean unlimited
+-- @return table - stored configuration
+function put_subject_config(subject, config)
+    if subject == nil or subject == '' then
+        error('subject cannot be empty')
     end
+    config = config or {}
 
-    print('MiniToolStream: TTL configuration updated')
-    print('  Enabled: ' .. tostring(ttl_config.enabled))
-    print('  Default TTL: ' .. ttl_config.default_ttl .. ' seconds')
-    print('  Check interval: ' .. ttl_config.check_interval .. ' seconds')
+    local discard = config.discard
+    if discard == nil or discard == '' then
+        discard = 'old'
+    end
+    if discard ~= 'old' and discard ~= 'new' then
+        error('invalid discard policy: ' .. tostring(discard))
+    end
 
-    -- Restart TTL fiber if enabled
-    if ttl_config.enabled then
-        start_ttl_cleanup()
-    else
-        stop_ttl_cleanup()
+    local retention = config.retention
+    if retention == nil or retention == '' then
+        retention = 'limits'
+    end
+    if retention ~= 'limits' and retention ~= 'interest' and retention ~= 'workqueue' then
+        error('invalid retention policy: ' .. tostring(retention))
     end
 
-    return true
+    local now = os.time()
+    local tuple = {
+        subject,
+        config.max_msgs or 0,
+        config.max_bytes or 0,
+        config.max_age or 0,
+        config.max_msg_size or 0,
+        discard,
+        now,
+        retention
+    }
+
+    box.atomic(function()
+        box.space.subject_config:replace(tuple)
+        -- Declared subjects show up in the catalogue even before the first publish
+        if box.space.subjects:get(subject) == nil then
+            box.space.subjects:insert({subject, 0, 0, 0, 0, 0, 0})
+        end
+    end)
+
+    return subject_config_info(tuple)
+end
+
+-- Function to get limits of a declared subject
+-- @param subject string - topic name
+-- @return table - configuration or nil if the subject was not declared
+function get_subject_config(subject)
+    local tuple = box.space.subject_config:get(subject)
+    if tuple == nil then
+        return nil
+    end
+    return subject_config_info(tuple)
 end
 
--- Function to get TTL for a specific subject
+-- Function to remove limits of a subject (messages are kept)
 -- @param subject string - topic name
--- @return number - TTL in seconds
-local function get_subject_ttl(subject)
-    if ttl_config.channels[subject] then
-        return ttl_config.channels[subject]
+-- @return boolean - true if a configuration was removed
+function delete_subject_config(subject)
+    return box.space.subject_config:delete(subject) ~= nil
+end
+
+-- Function to list all declared subjects
+-- @return array of configurations
+function list_subject_configs()
+    local result = {}
+    for _, tuple in box.space.subject_config:pairs() do
+        table.insert(result, subject_config_info(tuple))
     end
-    return ttl_config.default_ttl
+    return result
 end
 
--- Background fiber function for TTL cleanup
-local function ttl_cleanup_fiber()
-    print('MiniToolStream: TTL cleanup fiber started')
+-- Convert a schema tuple to a named table
+local function schema_info(tuple)
+    return {
+        id = tuple[1],
+        subject = tuple[2],
+        version = tuple[3],
+        format = tuple[4],
+        definition = tuple[5],
+        message_type = tuple[6],
+        create_at = tuple[7]
+    }
+end
 
-    while ttl_config.enabled do
-        local start_time = os.time()
-        local total_deleted = 0
+-- Latest schema tuple of a subject or nil
+local function latest_schema(subject)
+    return box.space.schema.index.subject_version:max({subject})
+end
 
-        -- Group messages by subject for efficient cleanup
-        local subjects = {}
-        for _, tuple in box.space.message.index.primary:pairs() do
-            local subject = tuple[4]
-            if not subjects[subject] then
-                subjects[subject] = {}
-            end
-            table.insert(subjects[subject], {
-                sequence = tuple[1],
-                create_at = tuple[5],
-                object_name = tuple[3]
-            })
+-- Function to register a new schema version of a subject
+-- Compatibility with the latest version is checked by the caller, which passes
+-- the version it checked against; registering the latest definition again is a no-op
+-- @param subject string - topic name
+-- @param format string - 'json' or 'protobuf'
+-- @param definition string - schema document or serialized FileDescriptorSet
+-- @param message_type string - protobuf message name, '' for JSON
+-- @param expected_version number - latest version the caller checked (0 if none)
+-- @return table - registered (or identical latest) schema
+function register_schema(subject, format, definition, message_type, expected_version)
+    if subject == nil or subject == '' then
+        error('subject cannot be empty')
+    end
+    if format ~= 'json' and format ~= 'protobuf' then
+        error('invalid schema format: ' .. tostring(format))
+    end
+    if definition == nil or definition == '' then
+        error('schema definition cannot be empty')
+    end
+    message_type = message_type or ''
+
+    return box.atomic(function()
+        local latest = latest_schema(subject)
+        if latest ~= nil and latest[4] == format and latest[5] == definition and latest[6] == message_type then
+            return schema_info(latest)
         end
 
-        -- Process each subject with its specific TTL
-        for subject, messages in pairs(subjects) do
-            local ttl_seconds = get_subject_ttl(subject)
-            local cutoff_time = start_time - ttl_seconds
-            local deleted_count = 0
+        local latest_version = 0
+        if latest ~= nil then
+            latest_version = latest[3]
+        end
+        if expected_version ~= nil and expected_version ~= latest_version then
+            error(string.format('schema version conflict: latest version of "%s" is %d', subject, latest_version))
+        end
 
-            for _, msg in ipairs(messages) do
-                if msg.create_at < cutoff_time then
-                    box.space.message:delete(msg.sequence)
-                    deleted_count = deleted_count + 1
-                    total_deleted = total_deleted + 1
-                end
+        local tuple = box.space.schema:insert({
+            box.sequence.schema_id_seq:next(),
+            subject,
+            latest_version + 1,
+            format,
+            definition,
+            message_type,
+            os.time()
+        })
+        return schema_info(tuple)
+    end)
+end
+
+-- Function to get a schema by its global id
+-- @param id number - schema id
+-- @return table - schema or nil if not found
+function get_schema_by_id(id)
+    local tuple = box.space.schema:get(id)
+    if tuple == nil then
+        return nil
+    end
+    return schema_info(tuple)
+end
+
+-- Function to get a schema version of a subject
+-- @param subject string - topic name
+-- @param version number - schema version, 0 for the latest
+-- @return table - schema or nil if not found
+function get_schema(subject, version)
+    local tuple
+    if version == nil or version == 0 then
+        tuple = latest_schema(subject)
+    else
+        tuple = box.space.schema.index.subject_version:get({subject, version})
+    end
+    if tuple == nil then
+        return nil
+    end
+    return schema_info(tuple)
+end
+
+-- Function to list schema versions of a subject, oldest first
+-- @param subject string - topic name
+-- @return array of version numbers
+function list_schema_versions(subject)
+    local result = {}
+    for _, tuple in box.space.schema.index.subject_version:pairs({subject}, {iterator = 'EQ'}) do
+        table.insert(result, tuple[3])
+    end
+    return result
+end
+
+-- Function to get the compatibility mode of a subject
+-- @param subject string - topic name
+-- @return string - compatibility mode, 'backward' if not set
+function get_schema_compatibility(subject)
+    local tuple = box.space.schema_subject:get(subject)
+    if tuple == nil then
+        return 'backward'
+    end
+    return tuple[2]
+end
+
+-- Function to set the compatibility mode of a subject
+-- Applies to versions registered from now on
+-- @param subject string - topic name
+-- @param mode string - 'backward', 'forward', 'full' or 'none'
+-- @return string - stored mode
+function set_schema_compatibility(subject, mode)
+    if subject == nil or subject == '' then
+        error('subject cannot be empty')
+    end
+    if mode ~= 'backward' and mode ~= 'forward' and mode ~= 'full' and mode ~= 'none' then
+        error('invalid schema compatibility: ' .. tostring(mode))
+    end
+
+    box.space.schema_subject:replace({subject, mode, os.time()})
+    return mode
+end
+
+-- Function to draw from several token buckets at once
+-- A bucket holds one second of its rate. A request larger than that passes
+-- when the bucket is full and leaves it in debt
+-- @param buckets array of {key, rate, cost} - rate in tokens per second
+-- @param force boolean - charge the buckets even if some are short
+-- @return number - seconds to wait before the request may pass, 0 if it was admitted
+function consume_quota(buckets, force)
+    local now = require('clock').time()
+
+    return box.atomic(function()
+        local levels = {}
+        local wait = 0
+        for i, bucket in ipairs(buckets) do
+            local key, rate, cost = bucket[1], bucket[2], bucket[3]
+            local tokens = rate
+            local row = box.space.quota_bucket:get(key)
+            if row ~= nil then
+                tokens = math.min(rate, row[2] + (now - row[3]) * rate)
             end
+            levels[i] = tokens
 
-            if deleted_count > 0 then
-                print(string.format('MiniToolStream: TTL cleanup - deleted %d messages from subject "%s" (TTL: %ds)',
-                    deleted_count, subject, ttl_seconds))
+            local need = math.min(cost, rate)
+            if tokens < need then
+                wait = math.max(wait, (need - tokens) / rate)
             end
         end
 
-        if total_deleted > 0 then
-            local duration = os.time() - start_time
-            print(string.format('MiniToolStream: TTL cleanup completed - total deleted: %d messages in %d seconds',
-                total_deleted, duration))
+        if wait > 0 and not force then
+            return wait
         end
 
-        -- Sleep until next check
-        require('fiber').sleep(ttl_config.check_interval)
+        for i, bucket in ipairs(buckets) do
+            box.space.quota_bucket:replace({bucket[1], levels[i] - bucket[3], now})
+        end
+        return 0
+    end)
+end
+
+-- Function to get payload bytes stored in subjects matching a pattern
+-- @param pattern string - "*", "prefix.*" or an exact subject
+-- @return number - stored bytes
+function get_stored_bytes(pattern)
+    local total = 0
+    for _, tuple in box.space.subjects:pairs() do
+        if match_subject_pattern(pattern, tuple[1]) then
+            total = total + tuple[5]
+        end
     end
+    return total
+end
 
-    print('MiniToolStream: TTL cleanup fiber stopped')
+-- Function to get payload bytes stored by a publishing client
+-- @param client_id string - JWT client_id
+-- @return number - stored bytes
+function get_client_stored_bytes(client_id)
+    local tuple = box.space.client_usage:get(client_id)
+    if tuple == nil then
+        return 0
+    end
+    return tuple[2]
 end
 
--- Function to start TTL cleanup fiber
-function start_ttl_cleanup()
-    if not ttl_config.enabled then
-        print('MiniToolStream: TTL cleanup is disabled')
-        return false
+-- Function to check limits before the payload is uploaded
+-- @param subject string - topic name
+-- @param size number - payload size in bytes
+-- @return table {allowed = boolean, reason = string, trim = boolean}
+--         trim is true when the subject must be trimmed after the publish (discard 'old')
+function check_publish_limits(subject, size)
+    local violation, trim = subject_limit_violation(subject, size or 0)
+    if violation ~= nil then
+        return {allowed = false, reason = violation, trim = false}
+    end
+    return {allowed = true, reason = '', trim = trim}
+end
+
+-- Function to trim a subject down to its configured limits
+-- Age limits apply to every policy, count and size limits only to discard 'old'
+-- @param subject string - topic name
+-- @param batch_size number - max messages to delete in one call (default 1000)
+-- @return array of {sequence, subject, object_name} for deleted messages
+function enforce_subject_limits(subject, batch_size)
+    batch_size = batch_size or 1000
+    local deleted = {}
+
+    local cfg = box.space.subject_config:get(subject)
+    if cfg == nil then
+        return deleted
     end
 
-    -- Stop existing fiber if running
-    if ttl_fiber ~= nil and ttl_fiber:status() ~= 'dead' then
-        print('MiniToolStream: TTL cleanup fiber already running')
-        return true
+    local max_msgs, max_bytes, max_age, discard = cfg[2], cfg[3], cfg[4], cfg[6]
+    local cutoff_time = 0
+    if max_age > 0 then
+        cutoff_time = os.time() - max_age
     end
 
-    -- Start new fiber
-    ttl_fiber = require('fiber').create(ttl_cleanup_fiber)
-    ttl_fiber:name('ttl_cleanup')
+    while #deleted < batch_size do
+        local oldest = box.space.message.index.subject_sequence:min({subject})
+        if oldest == nil then
+            break
+        end
 
-    print('MiniToolStream: TTL cleanup fiber started successfully')
-    return true
-end
+        local expired = oldest[5] < cutoff_time
+        local over_limits = false
+        if discard == 'old' then
+            local stats = box.space.subjects:get(subject)
+            over_limits = stats ~= nil and (
+                (max_msgs > 0 and stats[4] > max_msgs) or
+                (max_bytes > 0 and stats[5] > max_bytes))
+        end
 
--- Function to stop TTL cleanup fiber
-function stop_ttl_cleanup()
-    if ttl_fiber == nil or ttl_fiber:status() == 'dead' then
-        print('MiniToolStream: TTL cleanup fiber is not running')
-        return true
+        if not expired and not over_limits then
+            break
+        end
+
+        table.insert(deleted, delete_message(oldest))
     end
 
-    ttl_config.enabled = false
+    return deleted
+end
 
-    -- Wait for fiber to finish (max 5 seconds)
-    local wait_time = 0
-    while ttl_fiber:status() ~= 'dead' and wait_time < 5 do
-        require('fiber').sleep(0.5)
-        wait_time = wait_time + 0.5
+-- Function to trim every declared subject down to its limits
+-- @param batch_size number - max messages to delete per subject in one call (default 1000)
+-- @return array of {sequence, subject, object_name} for deleted messages
+function enforce_all_subject_limits(batch_size)
+    local deleted = {}
+    for _, tuple in box.space.subject_config:pairs() do
+        for _, info in ipairs(enforce_subject_limits(tuple[1], batch_size)) do
+            table.insert(deleted, info)
+        end
     end
+    return deleted
+end
 
-    if ttl_fiber:status() ~= 'dead' then
-        ttl_fiber:cancel()
-        print('MiniToolStream: TTL cleanup fiber force stopped')
-    else
-        print('MiniToolStream: TTL cleanup fiber stopped gracefully')
+-- Global TTL configuration
+-- Ingress deletes old messages with delete_old_messages and removes their MinIO objects,
+-- Tarantool only keeps the per-channel TTLs
+local ttl_config = {
+    enabled = false,
+    default_ttl = 86400,  -- 24 hours in seconds
+    channels = {}  -- Map of channel -> ttl_seconds
+}
+
+-- Function to update TTL configuration
+-- @param config table - TTL configuration {enabled, default_ttl, channels}
+function configure_ttl(config)
+    if config.enabled ~= nil then
+        ttl_config.enabled = config.enabled
+    end
+    if config.default_ttl then
+        ttl_config.default_ttl = config.default_ttl
+    end
+    if config.channels then
+        ttl_config.channels = config.channels
     end
 
+    print('MiniToolStream: TTL configuration updated')
+    print('  Enabled: ' .. tostring(ttl_config.enabled))
+    print('  Default TTL: ' .. ttl_config.default_ttl .. ' seconds')
+
     return true
 end
 
+-- Function to delete old messages (TTL cleanup)
+-- Channels configured with configure_ttl keep their own TTL, interest and
+-- work-queue subjects are only trimmed by acks and explicit limits.
+-- The caller is responsible for deleting the returned MinIO objects
+-- @param ttl_seconds number - time to live of other subjects in seconds
+-- @return deleted_count, array of {sequence, subject, object_name} for deleted messages
+function delete_old_messages(ttl_seconds)
+    local current_time = os.time()
+
+    -- Collect first: deleting while iterating would invalidate the iterator
+    local old = {}
+    for _, tuple in box.space.message.index.create_at:pairs() do
+        local subject = tuple[4]
+        local cutoff_time = current_time - (ttl_config.channels[subject] or ttl_seconds)
+        if tuple[5] < cutoff_time and subject_retention(subject) == 'limits' then
+            table.insert(old, tuple)
+        end
+    end
+
+    local deleted_messages = {}
+    for _, tuple in ipairs(old) do
+        -- Acks and limits may have removed it once a commit yielded
+        local current = box.space.message:get(tuple[1])
+        if current ~= nil then
+            table.insert(deleted_messages, delete_message(current))
+        end
+    end
+    return #deleted_messages, deleted_messages
+end
+
 -- Function to get TTL status
 function get_ttl_status()
     local status = {
         enabled = ttl_config.enabled,
         default_ttl = ttl_config.default_ttl,
-        check_interval = ttl_config.check_interval,
-        fiber_running = ttl_fiber ~= nil and ttl_fiber:status() ~= 'dead',
         channels = ttl_config.channels
     }
     return status
 end
 
+-- Purge of expired revocations
+local revocation_purge_interval = 60 -- seconds
+local revocation_purge_fiber = nil
+
+-- Background fiber function purging revocations of expired tokens
+local function revocation_purge_loop()
+    print('MiniToolStream: revocation purge fiber started')
+
+    while true do
+        local ok, result = pcall(purge_revocations)
+        if not ok then
+            print('MiniToolStream: revocation purge error: ' .. tostring(result))
+        end
+        require('fiber').sleep(revocation_purge_interval)
+    end
+end
+
+-- Function to start the revocation purge fiber (started automatically on boot)
+function start_revocation_purge()
+    if revocation_purge_fiber ~= nil and revocation_purge_fiber:status() ~= 'dead' then
+        return true
+    end
+
+    revocation_purge_fiber = require('fiber').create(revocation_purge_loop)
+    revocation_purge_fiber:name('revocation_purge')
+    return true
+end
+
+start_revocation_purge()
+
 -- Create user for application access
 box.once('create_app_user', function()
     box.schema.user.create('minitoolstream_connector', {
@@ -70,8 +70,6 @@ check_ttl_status() {
         print('TTL Status:')
         print('  Enabled: ' .. tostring(status.enabled))
         print('  Default TTL: ' .. status.default_ttl .. ' seconds')
-        print('  Check Interval: ' .. status.check_interval .. ' seconds')
-        print('  Fiber Running: ' .. tostring(status.fiber_running))
         conn:close()
     " 2>/dev/null
 }
@@ -168,7 +166,7 @@ echo "  - images: 3 minutes → Should be deleted after ~3 minutes"
 echo "  - other: 5 minutes → Should be deleted after ~5 minutes"
 echo ""
 echo "Observations:"
-echo "  - Messages are automatically cleaned up by Tarantool fiber"
+echo "  - Messages are automatically cleaned up by the Ingress TTL service"
 echo "  - MinIO objects have lifecycle policies for automatic expiration"
 echo "  - Each channel has independent TTL configuration"
 echo ""
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	vault "github.com/hashicorp/vault/api"
+)
+
+// keySet is the JWT key secret in Vault
+// private_key and public_key stay the active pair so older readers keep working,
+// keys lists the public half of every key the servers may accept
+type keySet struct {
+	data    map[string]interface{}
+	version json.Number
+}
+
+// readKeySet reads the key secret at -vault-path
+func readKeySet(ctx context.Context, vaultClient *vault.Client) (*keySet, error) {
+	secret, err := vaultClient.Logical().ReadWithContext(ctx, *vaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys from Vault: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no keys at %s, run -generate-keys first", *vaultPath)
+	}
+	data, _ := secret.Data["data"].(map[string]interface{})
+	if pub, _ := data["public_key"].(string); pub == "" {
+		return nil, fmt.Errorf("no keys at %s, run -generate-keys first", *vaultPath)
+	}
+
+	set := &keySet{data: data}
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		set.version, _ = metadata["version"].(json.Number)
+	}
+	return set, nil
+}
+
+// write saves the key set, failing if it was changed since it was read
+func (s *keySet) write(ctx context.Context, vaultClient *vault.Client) error {
+	body := map[string]interface{}{"data": s.data}
+	if s.version != "" {
+		body["options"] = map[string]interface{}{"cas": s.version}
+	}
+	if _, err := vaultClient.Logical().WriteWithContext(ctx, *vaultPath, body); err != nil {
+		return fmt.Errorf("failed to write keys to Vault: %w", err)
+	}
+	return nil
+}
+
+// activeKey returns the signing key and its kid
+func (s *keySet) activeKey() (*rsa.PrivateKey, string, error) {
+	privPEM, _ := s.data["private_key"].(string)
+	priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privPEM))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse private key: %w", err)
+	}
+	kid, _ := s.data["kid"].(string)
+	if kid == "" {
+		// Keys written before the first rotation have no kid, the servers derive the same one
+		if kid, err = keyID(&priv.PublicKey); err != nil {
+			return nil, "", err
+		}
+	}
+	return priv, kid, nil
+}
+
+// keys returns the key map, adding the active pair of a secret written before the first rotation
+func (s *keySet) keys() (map[string]interface{}, error) {
+	keys, _ := s.data["keys"].(map[string]interface{})
+	if keys == nil {
+		keys = make(map[string]interface{})
+	}
+	if kid, _ := s.data["kid"].(string); kid == "" {
+		pubPEM, _ := s.data["public_key"].(string)
+		pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pubPEM))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		if kid, err = keyID(pub); err != nil {
+			return nil, err
+		}
+		keys[kid] = map[string]interface{}{"public_key": pubPEM}
+		s.data["kid"] = kid
+	}
+	s.data["keys"] = keys
+	return keys, nil
+}
+
+// keyID derives the kid of a key, matching the servers' jwtkeys.KeyID
+func keyID(pub *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// rotateKeys generates a new active key, previous keys stay accepted until retired
+func rotateKeys(ctx context.Context, vaultClient *vault.Client) error {
+	set, err := readKeySet(ctx, vaultClient)
+	if err != nil {
+		return err
+	}
+	keys, err := set.keys()
+	if err != nil {
+		return err
+	}
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	kid, err := keyID(&priv.PublicKey)
+	if err != nil {
+		return err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	privPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+
+	// Only the public half of previous keys is kept
+	keys[kid] = map[string]interface{}{
+		"public_key": pubPEM,
+		"created_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	set.data["private_key"] = privPEM
+	set.data["public_key"] = pubPEM
+	set.data["kid"] = kid
+
+	if err := set.write(ctx, vaultClient); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ New signing key %s saved to Vault\n\n", kid)
+	printKeys(set)
+	fmt.Printf("\nServers pick up the key at their next reload (auth.key_reload_interval) or on SIGHUP.\n")
+	fmt.Printf("Retire a previous key with -retire-key once the tokens signed with it have expired.\n")
+	return nil
+}
+
+// retireKey stops the servers from accepting tokens signed with kid
+func retireKey(ctx context.Context, vaultClient *vault.Client, kid string) error {
+	set, err := readKeySet(ctx, vaultClient)
+	if err != nil {
+		return err
+	}
+	keys, err := set.keys()
+	if err != nil {
+		return err
+	}
+	_, activeKID, err := set.activeKey()
+	if err != nil {
+		return err
+	}
+	if kid == activeKID {
+		return fmt.Errorf("key %s is the active signing key, run -rotate-keys first", kid)
+	}
+
+	entry, ok := keys[kid].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("key %s not found", kid)
+	}
+	if retired, _ := entry["retired_at"].(string); retired != "" {
+		fmt.Printf("Key %s was already retired at %s\n", kid, retired)
+		return nil
+	}
+	entry["retired_at"] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := set.write(ctx, vaultClient); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Key %s retired\n\n", kid)
+	printKeys(set)
+	return nil
+}
+
+// printKeys lists the keys of a set
+func printKeys(set *keySet) {
+	keys, _ := set.data["keys"].(map[string]interface{})
+	active, _ := set.data["kid"].(string)
+
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Printf("%-18s %-22s %s\n", "KID", "CREATED", "STATUS")
+	for _, id := range ids {
+		entry, _ := keys[id].(map[string]interface{})
+		created, _ := entry["created_at"].(string)
+		if created == "" {
+			created = "-"
+		}
+		state := "accepted"
+		if retired, _ := entry["retired_at"].(string); retired != "" {
+			state = "retired " + retired
+		}
+		if id == active {
+			state = "active"
+		}
+		fmt.Printf("%-18s %-22s %s\n", id, created, state)
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
@@ -9,14 +11,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	vault "github.com/hashicorp/vault/api"
 	"github.com/moroshma/MiniToolStreamConnector/auth"
 )
 
 var (
-	clientID        = flag.String("client", "", "Client ID (required)")
+	clientID        = flag.String("client", "", "Client ID (required for token generation)")
+	tenant          = flag.String("tenant", "", "Tenant of the client, issued as client ID 'tenant/client'")
 	subjects        = flag.String("subjects", "*", "Comma-separated list of allowed subjects (e.g., 'images.*,logs.*')")
-	permissions     = flag.String("permissions", "publish,subscribe,fetch", "Comma-separated list of permissions")
+	permissions     = flag.String("permissions", "publish,subscribe,fetch,ack", "Comma-separated list of permissions: publish, subscribe, fetch, ack, admin or *")
 	duration        = flag.Duration("duration", 24*time.Hour, "Token validity duration")
 	vaultAddr       = flag.String("vault-addr", os.Getenv("VAULT_ADDR"), "Vault address")
 	vaultToken      = flag.String("vault-token", os.Getenv("VAULT_TOKEN"), "Vault token")
@@ -24,11 +28,49 @@ var (
 	issuer          = flag.String("issuer", "minitoolstream", "JWT issuer")
 	generateKeysCmd = flag.Bool("generate-keys", false, "Generate new RSA keys and save to Vault")
 	showPublicKey   = flag.Bool("show-public-key", false, "Show public key from Vault")
+	rotateKeysCmd   = flag.Bool("rotate-keys", false, "Generate a new signing key, previous keys stay accepted until retired")
+	retireKeyID     = flag.String("retire-key", "", "Stop accepting tokens signed with the key of this kid")
+
+	revokeCmd         = flag.Bool("revoke", false, "Revoke the token given with -token or -jti, or all tokens of -client")
+	listRevokedCmd    = flag.Bool("list-revoked", false, "List revocations that are still in effect")
+	revokeToken       = flag.String("token", "", "Token to revoke")
+	revokeJTI         = flag.String("jti", "", "JWT ID of the token to revoke")
+	revokeReason      = flag.String("reason", "", "Reason recorded with a revocation")
+	tarantoolAddr     = flag.String("tarantool-addr", envOr("TARANTOOL_ADDRESS", "localhost:3301"), "Tarantool address, for revocations")
+	tarantoolUser     = flag.String("tarantool-user", envOr("TARANTOOL_USER", "minitoolstream_connector"), "Tarantool user")
+	tarantoolPassword = flag.String("tarantool-password", envOr("TARANTOOL_PASSWORD", "changeme"), "Tarantool password")
 )
 
+// knownPermissions are the permissions checked by ingress and egress
+var knownPermissions = map[string]bool{
+	"publish":   true,
+	"subscribe": true,
+	"fetch":     true,
+	"ack":       true,
+	"admin":     true,
+	"*":         true,
+}
+
 func main() {
 	flag.Parse()
 
+	ctx := context.Background()
+
+	// Revocations are kept in Tarantool and need no Vault access
+	if *revokeCmd {
+		if err := revoke(ctx); err != nil {
+			log.Fatalf("Failed to revoke: %v", err)
+		}
+		return
+	}
+
+	if *listRevokedCmd {
+		if err := listRevoked(ctx); err != nil {
+			log.Fatalf("Failed to list revocations: %v", err)
+		}
+		return
+	}
+
 	if *vaultAddr == "" {
 		log.Fatal("Vault address is required (use -vault-addr or VAULT_ADDR env var)")
 	}
@@ -47,8 +89,6 @@ func main() {
 	}
 	vaultClient.SetToken(*vaultToken)
 
-	ctx := context.Background()
-
 	// Handle generate-keys command
 	if *generateKeysCmd {
 		if err := generateAndSaveKeys(ctx, vaultClient); err != nil {
@@ -66,6 +106,22 @@ func main() {
 		return
 	}
 
+	// Handle rotate-keys command
+	if *rotateKeysCmd {
+		if err := rotateKeys(ctx, vaultClient); err != nil {
+			log.Fatalf("Failed to rotate keys: %v", err)
+		}
+		return
+	}
+
+	// Handle retire-key command
+	if *retireKeyID != "" {
+		if err := retireKey(ctx, vaultClient, *retireKeyID); err != nil {
+			log.Fatalf("Failed to retire key: %v", err)
+		}
+		return
+	}
+
 	// Generate JWT token
 	if *clientID == "" {
 		flag.Usage()
@@ -103,8 +159,12 @@ func showPublicKeyFromVault(ctx context.Context, vaultClient *vault.Client) erro
 }
 
 func generateToken(ctx context.Context, vaultClient *vault.Client) error {
-	// Create JWT manager from Vault
-	jwtManager, err := auth.NewJWTManagerFromVault(ctx, vaultClient, *vaultPath, *issuer)
+	// Tokens are signed with the active key and name it in the kid header
+	set, err := readKeySet(ctx, vaultClient)
+	if err != nil {
+		return err
+	}
+	signingKey, kid, err := set.activeKey()
 	if err != nil {
 		return err
 	}
@@ -124,25 +184,78 @@ func generateToken(ctx context.Context, vaultClient *vault.Client) error {
 		perms = strings.Split(*permissions, ",")
 		for i := range perms {
 			perms[i] = strings.TrimSpace(perms[i])
+			if !knownPermissions[perms[i]] {
+				return fmt.Errorf("unknown permission %q", perms[i])
+			}
 		}
 	}
 
-	// Generate token
-	token, err := jwtManager.GenerateToken(*clientID, allowedSubjects, perms, *duration)
+	id, err := qualifiedClientID()
 	if err != nil {
 		return err
 	}
 
+	// The jti lets the token be revoked on its own
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	// Generate token
+	now := time.Now()
+	claims := &auth.Claims{
+		ClientID:        id,
+		AllowedSubjects: allowedSubjects,
+		Permissions:     perms,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			Issuer:    *issuer,
+			Subject:   id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(*duration)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	jwtToken.Header["kid"] = kid
+	token, err := jwtToken.SignedString(signingKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign token: %w", err)
+	}
+
 	// Print token info
 	fmt.Printf("JWT Token generated successfully:\n\n")
-	fmt.Printf("Client ID:        %s\n", *clientID)
+	fmt.Printf("Client ID:        %s\n", id)
 	fmt.Printf("Allowed Subjects: %v\n", allowedSubjects)
 	fmt.Printf("Permissions:      %v\n", perms)
 	fmt.Printf("Valid For:        %v\n", *duration)
-	fmt.Printf("Issuer:           %s\n\n", *issuer)
+	fmt.Printf("Issuer:           %s\n", *issuer)
+	fmt.Printf("Key ID:           %s\n", kid)
+	fmt.Printf("Token ID (jti):   %s\n\n", claims.ID)
 	fmt.Printf("Token:\n%s\n\n", token)
 	fmt.Printf("Use this token in your client by setting the Authorization header:\n")
 	fmt.Printf("Authorization: Bearer %s\n", token)
 
 	return nil
 }
+
+// qualifiedClientID returns -client, prefixed with -tenant if given
+// The servers take the tenant from the client ID, up to the first "/"
+func qualifiedClientID() (string, error) {
+	id := *clientID
+	if *tenant != "" {
+		if strings.Contains(*tenant, "/") {
+			return "", fmt.Errorf("tenant cannot contain '/'")
+		}
+		id = *tenant + "/" + id
+	}
+	return id, nil
+}
+
+// envOr returns the environment variable key, or def if it is unset
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
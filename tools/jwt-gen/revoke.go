@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/moroshma/MiniToolStreamConnector/auth"
+	"github.com/tarantool/go-tarantool/v2"
+)
+
+// connectTarantool connects to the Tarantool instance holding the revocation list
+func connectTarantool(ctx context.Context) (*tarantool.Connection, error) {
+	dialer := tarantool.NetDialer{
+		Address:  *tarantoolAddr,
+		User:     *tarantoolUser,
+		Password: *tarantoolPassword,
+	}
+	conn, err := tarantool.Connect(ctx, dialer, tarantool.Opts{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Tarantool: %w", err)
+	}
+	return conn, nil
+}
+
+// revoke revokes a token given with -token or -jti, or all tokens of the -client
+func revoke(ctx context.Context) error {
+	conn, err := connectTarantool(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	now := time.Now()
+	// Without the token its expiry is unknown, -duration must cover the longest-lived one
+	expiresAt := now.Add(*duration)
+
+	switch {
+	case *revokeToken != "":
+		// The signature is not checked: revoking a forged token does no harm
+		claims := &auth.Claims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(*revokeToken, claims); err != nil {
+			return fmt.Errorf("failed to parse token: %w", err)
+		}
+		if claims.ID == "" {
+			return fmt.Errorf("token has no jti, revoke its client with -client instead")
+		}
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		return revokeByID(conn, claims.ID, claims.ClientID, expiresAt)
+
+	case *revokeJTI != "":
+		id := ""
+		if *clientID != "" {
+			if id, err = qualifiedClientID(); err != nil {
+				return err
+			}
+		}
+		return revokeByID(conn, *revokeJTI, id, expiresAt)
+
+	case *clientID != "":
+		id, err := qualifiedClientID()
+		if err != nil {
+			return err
+		}
+		args := []interface{}{id, uint64(now.Unix()), uint64(expiresAt.Unix()), *revokeReason}
+		if _, err := conn.Do(tarantool.NewCall17Request("revoke_client").Args(args)).Get(); err != nil {
+			return fmt.Errorf("failed to revoke client: %w", err)
+		}
+		fmt.Printf("✓ All tokens of client %s issued before %s revoked\n", id, now.Format(time.RFC3339))
+		fmt.Printf("The revocation is kept until %s\n", expiresAt.Format(time.RFC3339))
+		return nil
+
+	default:
+		return fmt.Errorf("-revoke needs -token, -jti or -client")
+	}
+}
+
+func revokeByID(conn *tarantool.Connection, jti, client string, expiresAt time.Time) error {
+	args := []interface{}{jti, client, uint64(expiresAt.Unix()), *revokeReason}
+	if _, err := conn.Do(tarantool.NewCall17Request("revoke_token").Args(args)).Get(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	fmt.Printf("✓ Token %s revoked\n", jti)
+	fmt.Printf("The revocation is kept until %s\n", expiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// listRevoked prints the revocations still in effect
+func listRevoked(ctx context.Context) error {
+	conn, err := connectTarantool(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := conn.Do(tarantool.NewCall17Request("list_revocations").Args([]interface{}{})).Get()
+	if err != nil {
+		return fmt.Errorf("failed to list revocations: %w", err)
+	}
+	if len(resp) == 0 {
+		return fmt.Errorf("empty response from Tarantool")
+	}
+	result, ok := resp[0].(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected response format from list_revocations")
+	}
+
+	fmt.Println("Revoked tokens:")
+	fmt.Printf("%-34s %-24s %-22s %s\n", "JTI", "CLIENT", "EXPIRES", "REASON")
+	for _, tuple := range tuples(result["tokens"]) {
+		fmt.Printf("%-34v %-24v %-22s %v\n", tuple[0], tuple[1], unixTime(tuple[2]), tuple[4])
+	}
+
+	fmt.Println("\nRevoked clients:")
+	fmt.Printf("%-24s %-22s %-22s %s\n", "CLIENT", "ISSUED BEFORE", "EXPIRES", "REASON")
+	for _, tuple := range tuples(result["clients"]) {
+		fmt.Printf("%-24v %-22s %-22s %v\n", tuple[0], unixTime(tuple[1]), unixTime(tuple[2]), tuple[4])
+	}
+	return nil
+}
+
+// tuples converts a Lua array of 5-field tuples
+func tuples(val interface{}) [][]interface{} {
+	list, _ := val.([]interface{})
+	result := make([][]interface{}, 0, len(list))
+	for _, item := range list {
+		if tuple, ok := item.([]interface{}); ok && len(tuple) >= 5 {
+			result = append(result, tuple)
+		}
+	}
+	return result
+}
+
+// unixTime formats a Unix time returned by Tarantool
+func unixTime(val interface{}) string {
+	var sec int64
+	switch v := val.(type) {
+	case int64:
+		sec = v
+	case uint64:
+		sec = int64(v)
+	case int8:
+		sec = int64(v)
+	case int16:
+		sec = int64(v)
+	case int32:
+		sec = int64(v)
+	case uint8:
+		sec = int64(v)
+	case uint16:
+		sec = int64(v)
+	case uint32:
+		sec = int64(v)
+	case int:
+		sec = int64(v)
+	default:
+		return fmt.Sprint(val)
+	}
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}